@@ -0,0 +1,148 @@
+// Package admin provides authenticated operational endpoints for incident response.
+// Importing it pulls in net/http/pprof, which registers its handlers on
+// http.DefaultServeMux; never serve DefaultServeMux on a public port.
+package admin
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRole is the role required by Register when Options.Roles is empty
+const DefaultRole = "admin"
+
+// Options configures the debug endpoints. Nil dependencies are left out of the payload.
+type Options struct {
+	Config      *config.Config // Reported with secrets redacted
+	Database    *database.DB
+	Cache       *cache.Client
+	Roles       []string // Roles allowed to access the endpoints (default DefaultRole)
+	EnablePprof bool     // Also mount net/http/pprof under /debug/pprof
+}
+
+// DebugInfo is the payload of the debug endpoint
+type DebugInfo struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	GoVersion  string                 `json:"go_version"`
+	NumCPU     int                    `json:"num_cpu"`
+	Goroutines int                    `json:"goroutines"`
+	Memory     MemoryStats            `json:"memory"`
+	Database   map[string]interface{} `json:"database,omitempty"`
+	Cache      *CacheStats            `json:"cache,omitempty"`
+	Config     *config.Config         `json:"config,omitempty"`
+}
+
+// MemoryStats is the subset of runtime.MemStats useful during incidents
+type MemoryStats struct {
+	Alloc        uint64 `json:"alloc_bytes"`
+	TotalAlloc   uint64 `json:"total_alloc_bytes"`
+	Sys          uint64 `json:"sys_bytes"`
+	HeapAlloc    uint64 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64 `json:"heap_inuse_bytes"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+}
+
+// CacheStats reports the Redis pool and circuit breaker
+type CacheStats struct {
+	Pool    map[string]interface{} `json:"pool"`
+	Breaker map[string]interface{} `json:"breaker"`
+}
+
+// Register mounts GET /debug/info and, when enabled, the pprof handlers on router,
+// all behind middleware.RequireRole. The router must already run authentication,
+// e.g. middleware.JWT, so the caller's roles are known.
+func Register(router gin.IRouter, opts Options) {
+	roles := opts.Roles
+	if len(roles) == 0 {
+		roles = []string{DefaultRole}
+	}
+
+	group := router.Group("/debug", middleware.RequireRole(roles...))
+	group.GET("/info", DebugHandler(opts))
+
+	if opts.EnablePprof {
+		group.GET("/pprof/", gin.WrapF(pprof.Index))
+		group.GET("/pprof/:profile", pprofHandler)
+		group.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	}
+}
+
+// DebugHandler returns a handler reporting runtime, database, cache and redacted
+// configuration state in one payload. It performs no authorization itself; use
+// Register or mount it behind admin-only middleware.
+func DebugHandler(opts Options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, collect(opts))
+	}
+}
+
+// collect gathers the debug payload
+func collect(opts Options) DebugInfo {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	info := DebugInfo{
+		Timestamp:  time.Now().UTC(),
+		GoVersion:  runtime.Version(),
+		NumCPU:     runtime.NumCPU(),
+		Goroutines: runtime.NumGoroutine(),
+		Memory: MemoryStats{
+			Alloc:        mem.Alloc,
+			TotalAlloc:   mem.TotalAlloc,
+			Sys:          mem.Sys,
+			HeapAlloc:    mem.HeapAlloc,
+			HeapInuse:    mem.HeapInuse,
+			HeapObjects:  mem.HeapObjects,
+			NumGC:        mem.NumGC,
+			PauseTotalNs: mem.PauseTotalNs,
+		},
+	}
+
+	if opts.Database != nil {
+		stats, err := opts.Database.GetStats()
+		if err != nil {
+			stats = map[string]interface{}{"error": err.Error()}
+		}
+		info.Database = stats
+	}
+	if opts.Cache != nil {
+		info.Cache = &CacheStats{
+			Pool:    opts.Cache.GetStats(),
+			Breaker: opts.Cache.GetBreakerStats(),
+		}
+	}
+	if opts.Config != nil {
+		redacted := opts.Config.Redacted()
+		info.Config = &redacted
+	}
+
+	return info
+}
+
+// pprofHandler serves a named profile. pprof.Index only resolves names under the
+// literal /debug/pprof/ path, so profiles are looked up directly to work under
+// any route prefix.
+func pprofHandler(c *gin.Context) {
+	switch name := c.Param("profile"); name {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,126 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/admin"
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/Reg-Kris/pyairtable-go-shared/middleware"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "test-secret"
+
+func newAdminEngine(t *testing.T, enablePprof bool) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	client, _ := sharedtesting.NewTestCache(t)
+	db := sharedtesting.NewTestDB(t)
+	t.Cleanup(db.Cleanup)
+
+	engine := gin.New()
+	protected := engine.Group("/admin", middleware.JWT(middleware.AuthConfig{JWTSecret: testSecret}))
+	admin.Register(protected, admin.Options{
+		Config: &config.Config{
+			Database: config.DatabaseConfig{Host: "db", Password: "db-secret"},
+			Auth:     config.AuthConfig{JWTSecret: testSecret},
+		},
+		Database:    db.DB,
+		Cache:       client,
+		EnablePprof: enablePprof,
+	})
+	return engine
+}
+
+func request(t *testing.T, engine *gin.Engine, path string, roles ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if roles != nil {
+		token, err := middleware.CreateToken(&middleware.JWTClaims{UserID: "usr_1", Roles: roles}, testSecret)
+		if err != nil {
+			t.Fatalf("CreateToken() error = %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestDebugInfo(t *testing.T) {
+	engine := newAdminEngine(t, false)
+
+	w := request(t, engine, "/admin/debug/info", admin.DefaultRole)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, section := range []string{"goroutines", "memory", "database", "cache", "config"} {
+		if _, ok := body[section]; !ok {
+			t.Errorf("expected a %q section in %s", section, w.Body.String())
+		}
+	}
+
+	var info admin.DebugInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if info.Goroutines == 0 || info.Memory.Sys == 0 {
+		t.Errorf("expected runtime stats, got %+v", info)
+	}
+	if _, ok := info.Database["open_connections"]; !ok {
+		t.Errorf("expected database pool stats, got %v", info.Database)
+	}
+	if info.Cache == nil || info.Cache.Breaker["state"] != "closed" {
+		t.Errorf("expected cache breaker stats, got %+v", info.Cache)
+	}
+	if info.Config.Database.Password != config.RedactedValue || info.Config.Auth.JWTSecret != config.RedactedValue {
+		t.Errorf("expected secrets to be redacted, got %+v", info.Config)
+	}
+}
+
+func TestDebugRequiresAdminRole(t *testing.T) {
+	engine := newAdminEngine(t, true)
+
+	tests := []struct {
+		name       string
+		path       string
+		roles      []string
+		wantStatus int
+	}{
+		{name: "unauthenticated", path: "/admin/debug/info", wantStatus: http.StatusUnauthorized},
+		{name: "non-admin", path: "/admin/debug/info", roles: []string{"user"}, wantStatus: http.StatusForbidden},
+		{name: "pprof non-admin", path: "/admin/debug/pprof/goroutine", roles: []string{"user"}, wantStatus: http.StatusForbidden},
+		{name: "pprof admin", path: "/admin/debug/pprof/goroutine?debug=1", roles: []string{admin.DefaultRole}, wantStatus: http.StatusOK},
+		{name: "pprof index", path: "/admin/debug/pprof/", roles: []string{admin.DefaultRole}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(t, engine, tt.path, tt.roles...)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPprofDisabledByDefault(t *testing.T) {
+	engine := newAdminEngine(t, false)
+
+	w := request(t, engine, "/admin/debug/pprof/goroutine", admin.DefaultRole)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
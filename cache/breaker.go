@@ -0,0 +1,67 @@
+package cache
+
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/go-redis/redis/v8"
+	"github.com/sony/gobreaker"
+)
+
+// Default circuit breaker settings, used for zero BreakerConfig fields
+const (
+	DefaultBreakerMaxRequests  = 3
+	DefaultBreakerInterval     = 10 * time.Second
+	DefaultBreakerTimeout      = 60 * time.Second
+	DefaultBreakerFailureRatio = 0.6
+	DefaultBreakerMinRequests  = 3
+)
+
+// breakerSettings builds the gobreaker settings from cfg, filling in the defaults
+func breakerSettings(cfg config.BreakerConfig) gobreaker.Settings {
+	maxRequests := cfg.MaxRequests
+	if maxRequests == 0 {
+		maxRequests = DefaultBreakerMaxRequests
+	}
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = DefaultBreakerInterval
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultBreakerTimeout
+	}
+	failureRatio := cfg.FailureRatio
+	if failureRatio <= 0 {
+		failureRatio = DefaultBreakerFailureRatio
+	}
+	minRequests := cfg.MinRequests
+	if minRequests == 0 {
+		minRequests = DefaultBreakerMinRequests
+	}
+
+	return gobreaker.Settings{
+		Name:        "redis-cache",
+		MaxRequests: maxRequests,
+		Interval:    interval,
+		Timeout:     timeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			ratio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= minRequests && ratio >= failureRatio
+		},
+		IsSuccessful: isBreakerSuccess,
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			// Log state changes
+			fmt.Printf("Circuit breaker %s changed from %v to %v\n", name, from, to)
+		},
+	}
+}
+
+// isBreakerSuccess reports whether a Redis call counts as a success for the
+// breaker. A miss (redis.Nil) is a valid answer from a healthy Redis, so a burst
+// of misses, e.g. a stampede on a cold key, doesn't open the breaker.
+func isBreakerSuccess(err error) bool {
+	return err == nil || stderrors.Is(err, redis.Nil)
+}
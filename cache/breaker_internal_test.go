@@ -0,0 +1,97 @@
+package cache
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/go-redis/redis/v8"
+	"github.com/sony/gobreaker"
+)
+
+func TestBreakerSettings(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             config.BreakerConfig
+		wantMaxRequests uint32
+		wantInterval    time.Duration
+		wantTimeout     time.Duration
+		trips           gobreaker.Counts
+		holds           gobreaker.Counts
+	}{
+		{
+			name:            "defaults",
+			wantMaxRequests: DefaultBreakerMaxRequests,
+			wantInterval:    DefaultBreakerInterval,
+			wantTimeout:     DefaultBreakerTimeout,
+			trips:           gobreaker.Counts{Requests: 3, TotalFailures: 2},
+			holds:           gobreaker.Counts{Requests: 2, TotalFailures: 2},
+		},
+		{
+			name:            "tolerant of short blips",
+			cfg:             config.BreakerConfig{MaxRequests: 10, Interval: 30, Timeout: 5, FailureRatio: 0.9, MinRequests: 20},
+			wantMaxRequests: 10,
+			wantInterval:    30 * time.Second,
+			wantTimeout:     5 * time.Second,
+			trips:           gobreaker.Counts{Requests: 20, TotalFailures: 18},
+			holds:           gobreaker.Counts{Requests: 19, TotalFailures: 19},
+		},
+		{
+			name:            "ratio only",
+			cfg:             config.BreakerConfig{FailureRatio: 0.9},
+			wantMaxRequests: DefaultBreakerMaxRequests,
+			wantInterval:    DefaultBreakerInterval,
+			wantTimeout:     DefaultBreakerTimeout,
+			trips:           gobreaker.Counts{Requests: 10, TotalFailures: 9},
+			holds:           gobreaker.Counts{Requests: 10, TotalFailures: 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := breakerSettings(tt.cfg)
+
+			if settings.MaxRequests != tt.wantMaxRequests || settings.Interval != tt.wantInterval || settings.Timeout != tt.wantTimeout {
+				t.Errorf("settings = %d, %v, %v", settings.MaxRequests, settings.Interval, settings.Timeout)
+			}
+			if !settings.ReadyToTrip(tt.trips) {
+				t.Errorf("expected %+v to trip the breaker", tt.trips)
+			}
+			if settings.ReadyToTrip(tt.holds) {
+				t.Errorf("expected %+v not to trip the breaker", tt.holds)
+			}
+		})
+	}
+}
+
+func TestBreakerSettings_MissesAreSuccesses(t *testing.T) {
+	settings := breakerSettings(config.BreakerConfig{})
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "success", err: nil, want: true},
+		{name: "miss", err: redis.Nil, want: true},
+		{name: "wrapped miss", err: fmt.Errorf("get: %w", redis.Nil), want: true},
+		{name: "outage", err: stderrors.New("connection refused"), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := settings.IsSuccessful(tt.err); got != tt.want {
+			t.Errorf("%s: IsSuccessful(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+
+	// Misses alone never open the breaker
+	breaker := gobreaker.NewCircuitBreaker(settings)
+	for i := 0; i < 10; i++ {
+		breaker.Execute(func() (interface{}, error) { return nil, redis.Nil })
+	}
+	if state := breaker.State(); state != gobreaker.StateClosed {
+		t.Errorf("state after misses = %v, want closed", state)
+	}
+}
@@ -4,18 +4,30 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"time"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
 	"github.com/go-redis/redis/v8"
 	"github.com/sony/gobreaker"
+	"golang.org/x/sync/singleflight"
 )
 
 // Client represents a Redis client with circuit breaker
 type Client struct {
 	redis   *redis.Client
 	breaker *gobreaker.CircuitBreaker
+	metrics *metrics.Registry // set by WithMetrics
+	log     *logger.Logger    // set by WithMetrics
+	stale   *staleLRU         // set by WithStaleFallback
+	flights *singleflight.Group
+	codec   Codec // JSONCodec unless set by WithCodec
+
+	scanBatchSize     int64
+	compressThreshold int // 0 when compression is disabled
 }
 
 // New creates a new Redis client with circuit breaker
@@ -36,77 +48,205 @@ func New(cfg *config.RedisConfig) (*Client, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	// Configure circuit breaker
-	breaker := gobreaker.NewCircuitBreaker(gobreaker.Settings{
-		Name:        "redis-cache",
-		MaxRequests: 3,
-		Interval:    10 * time.Second,
-		Timeout:     60 * time.Second,
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= 3 && failureRatio >= 0.6
-		},
-		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-			// Log state changes
-			fmt.Printf("Circuit breaker %s changed from %v to %v\n", name, from, to)
-		},
-	})
+	breaker := gobreaker.NewCircuitBreaker(breakerSettings(cfg.Breaker))
+
+	scanBatchSize := int64(cfg.ScanBatchSize)
+	if scanBatchSize <= 0 {
+		scanBatchSize = DefaultScanBatchSize
+	}
 
 	return &Client{
-		redis:   rdb,
-		breaker: breaker,
+		redis:             rdb,
+		breaker:           breaker,
+		flights:           &singleflight.Group{},
+		codec:             JSONCodec{},
+		scanBatchSize:     scanBatchSize,
+		compressThreshold: compressionThreshold(cfg.Compression),
 	}, nil
 }
 
 // Set stores a value in cache with expiration
 func (c *Client) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	data, err := json.Marshal(value)
+	start := time.Now()
+	err := c.set(ctx, key, value, expiration)
+	c.observe(OperationSet, key, start, err)
+	return err
+}
+
+func (c *Client) set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := c.codec.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
 
+	return c.setEncoded(ctx, key, data, expiration)
+}
+
+// storeEncoded stores already encoded data, recording it like Set
+func (c *Client) storeEncoded(ctx context.Context, key string, data []byte, expiration time.Duration) error {
+	start := time.Now()
+	err := c.setEncoded(ctx, key, data, expiration)
+	c.observe(OperationSet, key, start, err)
+	return err
+}
+
+func (c *Client) setEncoded(ctx context.Context, key string, data []byte, expiration time.Duration) error {
+	stored, err := c.compress(data)
+	if err != nil {
+		return err
+	}
+
 	_, err = c.breaker.Execute(func() (interface{}, error) {
-		return nil, c.redis.Set(ctx, key, data, expiration).Err()
+		return nil, c.redis.Set(ctx, key, stored, expiration).Err()
 	})
 
 	if err != nil {
 		return fmt.Errorf("failed to set cache: %w", err)
 	}
 
+	if c.stale != nil {
+		c.stale.remember(key, data)
+	}
+
 	return nil
 }
 
 // Get retrieves a value from cache
 func (c *Client) Get(ctx context.Context, key string, dest interface{}) error {
+	start := time.Now()
+	err := c.get(ctx, key, dest)
+
+	var unavailable *unavailableError
+	if c.stale != nil && stderrors.As(err, &unavailable) && c.serveStale(key, dest) {
+		c.record(OperationGet, key, start, ResultStale)
+		return nil
+	}
+
+	c.observe(OperationGet, key, start, err)
+	return err
+}
+
+func (c *Client) get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.getEncoded(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := c.codec.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+
+	if c.stale != nil {
+		c.stale.remember(key, data)
+	}
+
+	return nil
+}
+
+// getEncoded reads the encoded value of key
+func (c *Client) getEncoded(ctx context.Context, key string) ([]byte, error) {
 	result, err := c.breaker.Execute(func() (interface{}, error) {
 		return c.redis.Get(ctx, key).Result()
 	})
 
 	if err != nil {
 		if err == redis.Nil {
-			return ErrCacheMiss
+			return nil, ErrCacheMiss
 		}
-		return fmt.Errorf("failed to get cache: %w", err)
+		return nil, &unavailableError{err: err}
 	}
 
 	data, ok := result.(string)
 	if !ok {
-		return fmt.Errorf("unexpected result type: %T", result)
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	return decompress([]byte(data))
+}
+
+// GetOrSet reads key into dest, or on a cache miss calls loader, stores its result
+// with ttl and decodes it into dest. Errors from Get other than ErrCacheMiss are
+// returned without calling the loader, and nothing is cached when the loader fails.
+// If storing the loaded value fails, dest is still filled and the error returned.
+func (c *Client) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader func() (interface{}, error)) error {
+	err := c.Get(ctx, key, dest)
+	if err == nil || !stderrors.Is(err, ErrCacheMiss) {
+		return err
+	}
+
+	value, err := loader()
+	if err != nil {
+		return err
 	}
 
-	if err := json.Unmarshal([]byte(data), dest); err != nil {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	if err := c.codec.Unmarshal(data, dest); err != nil {
 		return fmt.Errorf("failed to unmarshal value: %w", err)
 	}
 
-	return nil
+	return c.storeEncoded(ctx, key, data, ttl)
+}
+
+// GetOrSetSingle is GetOrSet with stampede protection: when several callers miss
+// the same key at once, only one runs loader while the others wait and share its
+// result, each decoding it into its own dest. Loader errors are returned to every
+// waiter and nothing is cached. The value is stored even if the caller that ran
+// the loader has its context cancelled.
+func (c *Client) GetOrSetSingle(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader func() (interface{}, error)) error {
+	err := c.Get(ctx, key, dest)
+	if err == nil || !stderrors.Is(err, ErrCacheMiss) {
+		return err
+	}
+
+	result, err, _ := c.flights.Do(key, func() (interface{}, error) {
+		// A flight that just finished may have filled the cache after our miss
+		if cached, err := c.getEncoded(ctx, key); err == nil {
+			return cached, nil
+		}
+
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		return data, c.storeEncoded(context.WithoutCancel(ctx), key, data, ttl)
+	})
+
+	data, ok := result.([]byte)
+	if !ok {
+		return err
+	}
+	if unmarshalErr := c.codec.Unmarshal(data, dest); unmarshalErr != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", unmarshalErr)
+	}
+	return err
 }
 
 // Delete removes a key from cache
 func (c *Client) Delete(ctx context.Context, key string) error {
+	start := time.Now()
+	err := c.delete(ctx, key)
+	c.observe(OperationDelete, key, start, err)
+	return err
+}
+
+func (c *Client) delete(ctx context.Context, key string) error {
 	_, err := c.breaker.Execute(func() (interface{}, error) {
 		return nil, c.redis.Del(ctx, key).Err()
 	})
 
+	if c.stale != nil {
+		c.stale.forget(key)
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to delete cache: %w", err)
 	}
@@ -116,6 +256,13 @@ func (c *Client) Delete(ctx context.Context, key string) error {
 
 // Exists checks if a key exists in cache
 func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	found, err := c.exists(ctx, key)
+	c.record(OperationExists, key, start, existsResult(found, err))
+	return found, err
+}
+
+func (c *Client) exists(ctx context.Context, key string) (bool, error) {
 	result, err := c.breaker.Execute(func() (interface{}, error) {
 		return c.redis.Exists(ctx, key).Result()
 	})
@@ -134,10 +281,21 @@ func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
 
 // SetNX sets a key only if it doesn't exist
 func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
-	data, err := json.Marshal(value)
+	start := time.Now()
+	ok, err := c.setNX(ctx, key, value, expiration)
+	c.observe(OperationSetNX, key, start, err)
+	return ok, err
+}
+
+func (c *Client) setNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := c.codec.Marshal(value)
 	if err != nil {
 		return false, fmt.Errorf("failed to marshal value: %w", err)
 	}
+	data, err = c.compress(data)
+	if err != nil {
+		return false, err
+	}
 
 	result, err := c.breaker.Execute(func() (interface{}, error) {
 		return c.redis.SetNX(ctx, key, data, expiration).Result()
@@ -157,6 +315,13 @@ func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expir
 
 // Expire sets expiration for a key
 func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	start := time.Now()
+	err := c.expire(ctx, key, expiration)
+	c.observe(OperationExpire, key, start, err)
+	return err
+}
+
+func (c *Client) expire(ctx context.Context, key string, expiration time.Duration) error {
 	_, err := c.breaker.Execute(func() (interface{}, error) {
 		return nil, c.redis.Expire(ctx, key, expiration).Err()
 	})
@@ -170,6 +335,13 @@ func (c *Client) Expire(ctx context.Context, key string, expiration time.Duratio
 
 // Increment increments a numeric value
 func (c *Client) Increment(ctx context.Context, key string) (int64, error) {
+	start := time.Now()
+	count, err := c.increment(ctx, key)
+	c.observe(OperationIncrement, key, start, err)
+	return count, err
+}
+
+func (c *Client) increment(ctx context.Context, key string) (int64, error) {
 	result, err := c.breaker.Execute(func() (interface{}, error) {
 		return c.redis.Incr(ctx, key).Result()
 	})
@@ -188,6 +360,13 @@ func (c *Client) Increment(ctx context.Context, key string) (int64, error) {
 
 // IncrementBy increments a numeric value by delta
 func (c *Client) IncrementBy(ctx context.Context, key string, delta int64) (int64, error) {
+	start := time.Now()
+	count, err := c.incrementBy(ctx, key, delta)
+	c.observe(OperationIncrement, key, start, err)
+	return count, err
+}
+
+func (c *Client) incrementBy(ctx context.Context, key string, delta int64) (int64, error) {
 	result, err := c.breaker.Execute(func() (interface{}, error) {
 		return c.redis.IncrBy(ctx, key, delta).Result()
 	})
@@ -206,6 +385,13 @@ func (c *Client) IncrementBy(ctx context.Context, key string, delta int64) (int6
 
 // GetMultiple retrieves multiple values from cache
 func (c *Client) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	start := time.Now()
+	results, err := c.getMultiple(ctx, keys)
+	c.observe(OperationGetMultiple, c.batchKey(keys), start, err)
+	return results, err
+}
+
+func (c *Client) getMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
 	if len(keys) == 0 {
 		return make(map[string]interface{}), nil
 	}
@@ -226,13 +412,124 @@ func (c *Client) GetMultiple(ctx context.Context, keys []string) (map[string]int
 	results := make(map[string]interface{})
 	for i, key := range keys {
 		if i < len(values) && values[i] != nil {
-			results[key] = values[i]
+			value := values[i]
+			if data, ok := value.(string); ok {
+				decompressed, err := decompress([]byte(data))
+				if err != nil {
+					return nil, err
+				}
+				value = string(decompressed)
+			}
+			results[key] = value
 		}
 	}
 
 	return results, nil
 }
 
+// SetMultiple stores several values with the same expiration in one pipelined round trip
+func (c *Client) SetMultiple(ctx context.Context, items map[string]interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := c.setMultiple(ctx, items, expiration)
+	c.observe(OperationSetMultiple, c.itemsKey(items), start, err)
+	return err
+}
+
+func (c *Client) setMultiple(ctx context.Context, items map[string]interface{}, expiration time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string][]byte, len(items))
+	stored := make(map[string][]byte, len(items))
+	for key, value := range items {
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value for %q: %w", key, err)
+		}
+		encoded[key] = data
+		if stored[key], err = c.compress(data); err != nil {
+			return err
+		}
+	}
+
+	_, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for key, data := range stored {
+				pipe.Set(ctx, key, data, expiration)
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to set multiple: %w", err)
+	}
+
+	if c.stale != nil {
+		for key, data := range encoded {
+			c.stale.remember(key, data)
+		}
+	}
+
+	return nil
+}
+
+// GetMultipleInto retrieves several values in one pipelined round trip and stores
+// each one found undecoded in dest, so callers can unmarshal into the right type.
+// Values are raw JSON with the default codec. Missing keys are left out of dest.
+func (c *Client) GetMultipleInto(ctx context.Context, keys []string, dest map[string]json.RawMessage) error {
+	start := time.Now()
+	err := c.getMultipleInto(ctx, keys, dest)
+	c.observe(OperationGetMultiple, c.batchKey(keys), start, err)
+	return err
+}
+
+func (c *Client) getMultipleInto(ctx context.Context, keys []string, dest map[string]json.RawMessage) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		cmds, err := c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Get(ctx, key)
+			}
+			return nil
+		})
+		// A pipeline reports the first command error, which is redis.Nil for a miss
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		return cmds, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to get multiple: %w", err)
+	}
+
+	cmds, ok := result.([]redis.Cmder)
+	if !ok {
+		return fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	for i, cmd := range cmds {
+		data, err := cmd.(*redis.StringCmd).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get %q: %w", keys[i], err)
+		}
+		if data, err = decompress(data); err != nil {
+			return err
+		}
+		dest[keys[i]] = json.RawMessage(data)
+	}
+
+	return nil
+}
+
 // Health checks the Redis connection health
 func (c *Client) Health(ctx context.Context) error {
 	_, err := c.breaker.Execute(func() (interface{}, error) {
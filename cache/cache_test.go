@@ -0,0 +1,210 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+type cachedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGetOrSet(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	calls := 0
+	loader := func() (interface{}, error) {
+		calls++
+		return cachedUser{ID: 1, Name: "Ada"}, nil
+	}
+
+	var first cachedUser
+	if err := client.GetOrSet(ctx, "user:1", &first, time.Minute, loader); err != nil {
+		t.Fatalf("GetOrSet() on miss error = %v", err)
+	}
+	if first != (cachedUser{ID: 1, Name: "Ada"}) || calls != 1 {
+		t.Fatalf("GetOrSet() on miss = %+v after %d loader calls", first, calls)
+	}
+	if ttl := server.TTL("user:1"); ttl != time.Minute {
+		t.Errorf("expected the loaded value to be cached for a minute, TTL = %v", ttl)
+	}
+
+	var second cachedUser
+	if err := client.GetOrSet(ctx, "user:1", &second, time.Minute, loader); err != nil {
+		t.Fatalf("GetOrSet() on hit error = %v", err)
+	}
+	if second != first || calls != 1 {
+		t.Errorf("GetOrSet() on hit = %+v after %d loader calls, want cached value", second, calls)
+	}
+}
+
+func TestGetOrSet_LoaderError(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+	errLoad := stderrors.New("database unavailable")
+
+	var dest cachedUser
+	err := client.GetOrSet(ctx, "user:1", &dest, time.Minute, func() (interface{}, error) {
+		return nil, errLoad
+	})
+	if !stderrors.Is(err, errLoad) {
+		t.Fatalf("GetOrSet() error = %v, want loader error", err)
+	}
+	if server.Exists("user:1") {
+		t.Error("expected nothing to be cached when the loader fails")
+	}
+}
+
+func TestGetOrSet_PropagatesCacheErrors(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	server.Close()
+
+	called := false
+	var dest cachedUser
+	err := client.GetOrSet(context.Background(), "user:1", &dest, time.Minute, func() (interface{}, error) {
+		called = true
+		return cachedUser{ID: 1}, nil
+	})
+	if err == nil {
+		t.Fatal("expected the cache error to be returned")
+	}
+	if called {
+		t.Error("expected the loader not to run when the cache fails")
+	}
+}
+
+func TestGetOrSetSingle_DeduplicatesLoaders(t *testing.T) {
+	client, _ := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	const callers = 20
+	var calls int32
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return map[string]string{"name": "Ada"}, nil
+	}
+
+	start := make(chan struct{})
+	results := make([]map[string]string, callers)
+	errs := make([]error, callers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = client.GetOrSetSingle(ctx, "hot", &results[i], time.Minute, loader)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("loader ran %d times, want 1", got)
+	}
+	for i := range results {
+		if errs[i] != nil || results[i]["name"] != "Ada" {
+			t.Fatalf("caller %d got %v, %v", i, results[i], errs[i])
+		}
+	}
+
+	results[0]["name"] = "changed"
+	if results[1]["name"] != "Ada" {
+		t.Error("expected each caller to decode its own copy of the shared result")
+	}
+}
+
+func TestGetOrSetSingle_LoaderErrorReachesAllWaiters(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+	errLoad := stderrors.New("database unavailable")
+
+	const callers = 10
+	start := make(chan struct{})
+	errs := make([]error, callers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			var dest map[string]string
+			errs[i] = client.GetOrSetSingle(ctx, "hot", &dest, time.Minute, func() (interface{}, error) {
+				time.Sleep(50 * time.Millisecond)
+				return nil, errLoad
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if !stderrors.Is(err, errLoad) {
+			t.Errorf("caller %d error = %v, want loader error", i, err)
+		}
+	}
+	if server.Exists("hot") {
+		t.Error("expected nothing to be cached when the loader fails")
+	}
+}
+
+func TestSetMultipleAndGetMultipleInto(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	items := map[string]interface{}{
+		"user:1":  cachedUser{ID: 1, Name: "Ada"},
+		"count:1": 42,
+	}
+	if err := client.SetMultiple(ctx, items, time.Minute); err != nil {
+		t.Fatalf("SetMultiple() error = %v", err)
+	}
+	if ttl := server.TTL("user:1"); ttl != time.Minute {
+		t.Errorf("expected the TTL to be applied, got %v", ttl)
+	}
+
+	dest := make(map[string]json.RawMessage)
+	if err := client.GetMultipleInto(ctx, []string{"user:1", "missing", "count:1"}, dest); err != nil {
+		t.Fatalf("GetMultipleInto() error = %v", err)
+	}
+	if len(dest) != 2 {
+		t.Fatalf("expected missing keys to be left out, got %v", dest)
+	}
+
+	var user cachedUser
+	if err := json.Unmarshal(dest["user:1"], &user); err != nil || user != (cachedUser{ID: 1, Name: "Ada"}) {
+		t.Errorf("user:1 = %+v, %v", user, err)
+	}
+	if string(dest["count:1"]) != "42" {
+		t.Errorf("count:1 = %s, want 42", dest["count:1"])
+	}
+}
+
+func TestGetMultipleInto_AllMissing(t *testing.T) {
+	client, _ := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	dest := make(map[string]json.RawMessage)
+	if err := client.GetMultipleInto(ctx, []string{"a", "b"}, dest); err != nil {
+		t.Fatalf("GetMultipleInto() error = %v", err)
+	}
+	if len(dest) != 0 {
+		t.Errorf("expected no values, got %v", dest)
+	}
+
+	if err := client.SetMultiple(ctx, nil, time.Minute); err != nil {
+		t.Errorf("SetMultiple() with no items error = %v", err)
+	}
+}
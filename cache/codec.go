@@ -0,0 +1,35 @@
+package cache
+
+import "encoding/json"
+
+// Codec encodes values stored in Redis and decodes them on read
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes values with encoding/json. It is the default codec.
+type JSONCodec struct{}
+
+// Marshal implements Codec
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec returns a copy of the client that encodes values with codec, e.g. a
+// msgpack or protobuf codec for large values. Clients sharing keys must use the
+// same codec. A nil codec restores the JSON default.
+func (c *Client) WithCodec(codec Codec) *Client {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	encoded := *c
+	encoded.codec = codec
+	return &encoded
+}
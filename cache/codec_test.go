@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+// gobCodec is a binary codec standing in for msgpack or protobuf
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func TestWithCodec(t *testing.T) {
+	base, server := sharedtesting.NewTestCache(t)
+	client := base.WithCodec(gobCodec{})
+	ctx := context.Background()
+	want := cachedUser{ID: 1, Name: "Ada"}
+
+	if err := client.Set(ctx, "user:1", want, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	stored, err := server.Get("user:1")
+	if err != nil {
+		t.Fatalf("expected user:1 to be stored: %v", err)
+	}
+	if json.Valid([]byte(stored)) {
+		t.Errorf("expected the gob codec to be used, stored %q", stored)
+	}
+
+	var got cachedUser
+	if err := client.Get(ctx, "user:1", &got); err != nil || got != want {
+		t.Errorf("Get() = %+v, %v, want %+v", got, err, want)
+	}
+
+	var loaded cachedUser
+	err = client.GetOrSet(ctx, "user:2", &loaded, time.Minute, func() (interface{}, error) {
+		return cachedUser{ID: 2, Name: "Grace"}, nil
+	})
+	if err != nil || loaded.Name != "Grace" {
+		t.Fatalf("GetOrSet() = %+v, %v", loaded, err)
+	}
+	var reloaded cachedUser
+	if err := client.Get(ctx, "user:2", &reloaded); err != nil || reloaded != loaded {
+		t.Errorf("Get() after GetOrSet() = %+v, %v", reloaded, err)
+	}
+
+	if ok, err := client.SetNX(ctx, "user:3", want, time.Minute); err != nil || !ok {
+		t.Fatalf("SetNX() = %v, %v", ok, err)
+	}
+	var fromSetNX cachedUser
+	if err := client.Get(ctx, "user:3", &fromSetNX); err != nil || fromSetNX != want {
+		t.Errorf("Get() after SetNX() = %+v, %v", fromSetNX, err)
+	}
+
+	// The original client keeps the JSON default
+	if err := base.Set(ctx, "user:4", want, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if stored, _ := server.Get("user:4"); stored != `{"id":1,"name":"Ada"}` {
+		t.Errorf("expected JSON from the default codec, stored %q", stored)
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+)
+
+// DefaultCompressionThreshold is the smallest value compressed when compression
+// is enabled without a threshold
+const DefaultCompressionThreshold = 1024
+
+// compressedMarker prefixes gzip-compressed values. Encoded values never start with
+// a zero byte followed by the gzip magic, so uncompressed values read back as is.
+const compressedMarker = 0x00
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compressionThreshold returns the threshold New uses, or 0 when disabled
+func compressionThreshold(cfg config.CompressionConfig) int {
+	if !cfg.Enabled {
+		return 0
+	}
+	if cfg.Threshold <= 0 {
+		return DefaultCompressionThreshold
+	}
+	return cfg.Threshold
+}
+
+// compress gzips data of at least the configured threshold and marks it as such
+func (c *Client) compress(data []byte) ([]byte, error) {
+	if c.compressThreshold == 0 || len(data) < c.compressThreshold {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(compressedMarker)
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress undoes compress. It is applied to every read, whether compression is
+// enabled or not, so clients can switch it on and off while sharing keys.
+func decompress(data []byte) ([]byte, error) {
+	if len(data) < 1+len(gzipMagic) || data[0] != compressedMarker || !bytes.HasPrefix(data[1:], gzipMagic) {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,91 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// connectCache connects a client with the given compression settings to server
+func connectCache(t *testing.T, server *miniredis.Miniredis, compression config.CompressionConfig) *cache.Client {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(server.Addr())
+	portNum, _ := strconv.Atoi(port)
+
+	client, err := cache.New(&config.RedisConfig{Host: host, Port: portNum, Compression: compression})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestCompression(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := connectCache(t, server, config.CompressionConfig{Enabled: true, Threshold: 256})
+	plain := connectCache(t, server, config.CompressionConfig{})
+	ctx := context.Background()
+
+	large := strings.Repeat("pyairtable ", 1000)
+	if err := client.Set(ctx, "large", large, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := client.Set(ctx, "small", "tiny", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	stored, _ := server.Get("large")
+	if len(stored) >= len(large) || json.Valid([]byte(stored)) {
+		t.Errorf("expected the large value to be stored compressed, got %d bytes", len(stored))
+	}
+	if stored, _ := server.Get("small"); stored != `"tiny"` {
+		t.Errorf("expected the small value to be stored as is, got %q", stored)
+	}
+
+	// Reads decompress whether or not the reading client compresses
+	for name, reader := range map[string]*cache.Client{"compressing": client, "plain": plain} {
+		var got string
+		if err := reader.Get(ctx, "large", &got); err != nil || got != large {
+			t.Errorf("%s Get() = %d bytes, %v", name, len(got), err)
+		}
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := plain.GetMultipleInto(ctx, []string{"large", "small"}, raw); err != nil {
+		t.Fatalf("GetMultipleInto() error = %v", err)
+	}
+	if !json.Valid(raw["large"]) || string(raw["small"]) != `"tiny"` {
+		t.Errorf("GetMultipleInto() returned undecompressed values")
+	}
+
+	if err := client.HSet(ctx, "hash", "field", large); err != nil {
+		t.Fatalf("HSet() error = %v", err)
+	}
+	var field string
+	if err := plain.HGet(ctx, "hash", "field", &field); err != nil || field != large {
+		t.Errorf("HGet() = %d bytes, %v", len(field), err)
+	}
+}
+
+func TestCompression_DisabledByDefault(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := connectCache(t, server, config.CompressionConfig{Threshold: 1})
+	ctx := context.Background()
+
+	large := strings.Repeat("pyairtable ", 1000)
+	if err := client.Set(ctx, "large", large, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if stored, _ := server.Get("large"); !json.Valid([]byte(stored)) {
+		t.Error("expected values to be stored uncompressed unless compression is enabled")
+	}
+}
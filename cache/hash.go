@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// HSet stores a field of a hash, e.g. one attribute of a session, without
+// rewriting the rest of it
+func (c *Client) HSet(ctx context.Context, key, field string, value interface{}) error {
+	start := time.Now()
+	err := c.hset(ctx, key, field, value)
+	c.observe(OperationHSet, key, start, err)
+	return err
+}
+
+func (c *Client) hset(ctx context.Context, key, field string, value interface{}) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	if data, err = c.compress(data); err != nil {
+		return err
+	}
+
+	_, err = c.breaker.Execute(func() (interface{}, error) {
+		return nil, c.redis.HSet(ctx, key, field, data).Err()
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to set hash field: %w", err)
+	}
+
+	return nil
+}
+
+// HGet retrieves a field of a hash, returning ErrCacheMiss when the field or the
+// hash does not exist
+func (c *Client) HGet(ctx context.Context, key, field string, dest interface{}) error {
+	start := time.Now()
+	err := c.hget(ctx, key, field, dest)
+	c.observe(OperationHGet, key, start, err)
+	return err
+}
+
+func (c *Client) hget(ctx context.Context, key, field string, dest interface{}) error {
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.redis.HGet(ctx, key, field).Result()
+	})
+
+	if err != nil {
+		if err == redis.Nil {
+			return ErrCacheMiss
+		}
+		return fmt.Errorf("failed to get hash field: %w", err)
+	}
+
+	data, ok := result.(string)
+	if !ok {
+		return fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	decompressed, err := decompress([]byte(data))
+	if err != nil {
+		return err
+	}
+
+	if err := c.codec.Unmarshal(decompressed, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+
+	return nil
+}
+
+// HGetAll retrieves all fields of a hash as their encoded values, raw JSON with the
+// default codec. A missing hash returns an empty map.
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	start := time.Now()
+	fields, err := c.hgetAll(ctx, key)
+	c.observe(OperationHGetAll, key, start, err)
+	return fields, err
+}
+
+func (c *Client) hgetAll(ctx context.Context, key string) (map[string]string, error) {
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.redis.HGetAll(ctx, key).Result()
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hash: %w", err)
+	}
+
+	fields, ok := result.(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	for field, value := range fields {
+		decompressed, err := decompress([]byte(value))
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = string(decompressed)
+	}
+
+	return fields, nil
+}
+
+// HDel removes fields from a hash
+func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
+	start := time.Now()
+	err := c.hdel(ctx, key, fields...)
+	c.observe(OperationHDel, key, start, err)
+	return err
+}
+
+func (c *Client) hdel(ctx context.Context, key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	_, err := c.breaker.Execute(func() (interface{}, error) {
+		return nil, c.redis.HDel(ctx, key, fields...).Err()
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete hash fields: %w", err)
+	}
+
+	return nil
+}
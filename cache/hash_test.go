@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+func TestHashOperations(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	if err := client.HSet(ctx, "session:1", "user", cachedUser{ID: 1, Name: "Ada"}); err != nil {
+		t.Fatalf("HSet() error = %v", err)
+	}
+	if err := client.HSet(ctx, "session:1", "theme", "dark"); err != nil {
+		t.Fatalf("HSet() error = %v", err)
+	}
+	if got := server.HGet("session:1", "theme"); got != `"dark"` {
+		t.Errorf("expected the field to be stored as JSON, got %q", got)
+	}
+
+	var user cachedUser
+	if err := client.HGet(ctx, "session:1", "user", &user); err != nil {
+		t.Fatalf("HGet() error = %v", err)
+	}
+	if user != (cachedUser{ID: 1, Name: "Ada"}) {
+		t.Errorf("HGet() = %+v", user)
+	}
+
+	fields, err := client.HGetAll(ctx, "session:1")
+	if err != nil {
+		t.Fatalf("HGetAll() error = %v", err)
+	}
+	if len(fields) != 2 || fields["theme"] != `"dark"` {
+		t.Errorf("HGetAll() = %v", fields)
+	}
+
+	if err := client.HDel(ctx, "session:1", "theme"); err != nil {
+		t.Fatalf("HDel() error = %v", err)
+	}
+	var theme string
+	if err := client.HGet(ctx, "session:1", "theme", &theme); !stderrors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("HGet() on a deleted field error = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestHashMissingKey(t *testing.T) {
+	client, _ := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	var value string
+	if err := client.HGet(ctx, "missing", "field", &value); !stderrors.Is(err, cache.ErrCacheMiss) {
+		t.Errorf("HGet() on a missing hash error = %v, want ErrCacheMiss", err)
+	}
+
+	fields, err := client.HGetAll(ctx, "missing")
+	if err != nil || len(fields) != 0 {
+		t.Errorf("HGetAll() on a missing hash = %v, %v", fields, err)
+	}
+}
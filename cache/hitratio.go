@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+)
+
+// DefaultHitRatioWindow is the reporting window used when none is configured
+const DefaultHitRatioWindow = time.Minute
+
+// HitStats holds cumulative hit and miss counters
+type HitStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitStatsSource provides cumulative hit and miss counters
+type HitStatsSource interface {
+	HitStats() HitStats
+}
+
+// HitStats returns the cumulative hits and misses of the Redis connection pool
+func (c *Client) HitStats() HitStats {
+	stats := c.redis.PoolStats()
+	return HitStats{Hits: uint64(stats.Hits), Misses: uint64(stats.Misses)}
+}
+
+// HitRatioReporter periodically updates the cache hit ratio gauge with the ratio
+// observed during the last window rather than over the process lifetime
+type HitRatioReporter struct {
+	source    HitStatsSource
+	registry  *metrics.Registry
+	cacheType string
+	window    time.Duration
+
+	mu   sync.Mutex
+	last HitStats
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHitRatioReporter creates a reporter recording the ratio of source under the
+// given cache type label every window
+func NewHitRatioReporter(source HitStatsSource, registry *metrics.Registry, cacheType string, window time.Duration) *HitRatioReporter {
+	if window <= 0 {
+		window = DefaultHitRatioWindow
+	}
+
+	return &HitRatioReporter{
+		source:    source,
+		registry:  registry,
+		cacheType: cacheType,
+		window:    window,
+		last:      source.HitStats(),
+	}
+}
+
+// Start begins reporting in the background
+func (r *HitRatioReporter) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go r.run(r.stop, r.done)
+}
+
+// Stop stops background reporting
+func (r *HitRatioReporter) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Report records the hit ratio since the previous report and returns it. When there
+// was no traffic in the window the gauge is left unchanged and ok is false.
+func (r *HitRatioReporter) Report() (ratio float64, ok bool) {
+	current := r.source.HitStats()
+
+	r.mu.Lock()
+	hits := delta(current.Hits, r.last.Hits)
+	misses := delta(current.Misses, r.last.Misses)
+	r.last = current
+	r.mu.Unlock()
+
+	total := hits + misses
+	if total == 0 {
+		return 0, false
+	}
+
+	ratio = float64(hits) / float64(total)
+	r.registry.RecordCacheHitRatio(r.cacheType, ratio)
+
+	return ratio, true
+}
+
+// run reports every window until stopped
+func (r *HitRatioReporter) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.Report()
+		}
+	}
+}
+
+// delta returns the counter increase, treating a decrease as a counter reset
+func delta(current, previous uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeStats is a HitStatsSource with settable counters
+type fakeStats struct {
+	mu    sync.Mutex
+	stats HitStats
+}
+
+func (f *fakeStats) HitStats() HitStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+func (f *fakeStats) add(hits, misses uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stats.Hits += hits
+	f.stats.Misses += misses
+}
+
+func (f *fakeStats) set(hits, misses uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stats = HitStats{Hits: hits, Misses: misses}
+}
+
+func TestHitRatioReporter_ReportsDeltas(t *testing.T) {
+	registry := metrics.New("test")
+	source := &fakeStats{}
+	source.add(1000, 0) // lifetime history before the reporter starts
+
+	reporter := NewHitRatioReporter(source, registry, "redis", time.Minute)
+	gauge := registry.CacheHitRatio.WithLabelValues("redis")
+
+	steps := []struct {
+		name      string
+		hits      uint64
+		misses    uint64
+		wantRatio float64
+		wantOK    bool
+	}{
+		{name: "first window ignores lifetime totals", hits: 25, misses: 75, wantRatio: 0.25, wantOK: true},
+		{name: "second window", hits: 90, misses: 10, wantRatio: 0.9, wantOK: true},
+		{name: "idle window keeps previous value", wantRatio: 0.9, wantOK: false},
+		{name: "all misses", misses: 40, wantRatio: 0, wantOK: true},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			source.add(step.hits, step.misses)
+
+			ratio, ok := reporter.Report()
+			if ok != step.wantOK {
+				t.Fatalf("Report() ok = %v, want %v", ok, step.wantOK)
+			}
+			if ok && ratio != step.wantRatio {
+				t.Errorf("Report() ratio = %v, want %v", ratio, step.wantRatio)
+			}
+			if got := testutil.ToFloat64(gauge); got != step.wantRatio {
+				t.Errorf("gauge = %v, want %v", got, step.wantRatio)
+			}
+		})
+	}
+}
+
+func TestHitRatioReporter_CounterReset(t *testing.T) {
+	registry := metrics.New("test")
+	source := &fakeStats{}
+	source.set(500, 500)
+
+	reporter := NewHitRatioReporter(source, registry, "redis", time.Minute)
+
+	// Counters restart (e.g. new pool); the new values are the delta
+	source.set(3, 1)
+	if ratio, ok := reporter.Report(); !ok || ratio != 0.75 {
+		t.Errorf("Report() = %v, %v; want 0.75", ratio, ok)
+	}
+}
+
+func TestHitRatioReporter_Background(t *testing.T) {
+	registry := metrics.New("test")
+	source := &fakeStats{}
+
+	reporter := NewHitRatioReporter(source, registry, "redis", 10*time.Millisecond)
+	reporter.Start()
+	defer reporter.Stop()
+
+	source.add(4, 1)
+
+	gauge := registry.CacheHitRatio.WithLabelValues("redis")
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if testutil.ToFloat64(gauge) == 0.8 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("gauge = %v, want 0.8 after a window", testutil.ToFloat64(gauge))
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+)
+
+// Cache operation names used as metric labels
+const (
+	OperationGet             = "get"
+	OperationSet             = "set"
+	OperationDelete          = "delete"
+	OperationExists          = "exists"
+	OperationSetNX           = "setnx"
+	OperationExpire          = "expire"
+	OperationIncrement       = "incr"
+	OperationGetMultiple     = "get_multiple"
+	OperationSetMultiple     = "set_multiple"
+	OperationHSet            = "hset"
+	OperationHGet            = "hget"
+	OperationHGetAll         = "hgetall"
+	OperationHDel            = "hdel"
+	OperationDeleteByPattern = "delete_pattern"
+	OperationTime            = "time"
+	OperationIncrWindow      = "incr_window"
+)
+
+// Cache operation results used as metric labels
+const (
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// WithMetrics returns a copy of the client whose operations record their duration
+// and result into registry and log at debug level via LogCacheOperation. Lookups of
+// single keys (Get, HGet, Exists) report hit or miss, other operations success or
+// error. Either argument may be nil. The original client stays uninstrumented and both
+// share the same connection pool and circuit breaker.
+func (c *Client) WithMetrics(registry *metrics.Registry, log *logger.Logger) *Client {
+	instrumented := *c
+	instrumented.metrics = registry
+	instrumented.log = log
+	return &instrumented
+}
+
+// observe records a finished operation when the client is instrumented
+func (c *Client) observe(operation, key string, start time.Time, err error) {
+	c.record(operation, key, start, operationResult(operation, err))
+}
+
+// record reports an operation's duration and result
+func (c *Client) record(operation, key string, start time.Time, result string) {
+	if c.metrics == nil && c.log == nil {
+		return
+	}
+
+	duration := time.Since(start)
+
+	if c.metrics != nil {
+		c.metrics.RecordCacheOperation(operation, result, duration)
+	}
+	if c.log != nil {
+		c.log.LogCacheOperation(operation, key, result == ResultHit || result == ResultStale, duration.Milliseconds())
+	}
+}
+
+// operationResult maps an operation's error to its result label
+func operationResult(operation string, err error) string {
+	switch {
+	case stderrors.Is(err, ErrCacheMiss):
+		return ResultMiss
+	case err != nil:
+		return ResultError
+	case operation == OperationGet || operation == OperationHGet:
+		return ResultHit
+	default:
+		return ResultSuccess
+	}
+}
+
+// existsResult reports Exists as a hit or miss like a read
+func existsResult(found bool, err error) string {
+	switch {
+	case err != nil:
+		return ResultError
+	case found:
+		return ResultHit
+	default:
+		return ResultMiss
+	}
+}
+
+// batchKey describes the keys of a batch operation for the debug log
+func (c *Client) batchKey(keys []string) string {
+	if c.log == nil || len(keys) == 0 {
+		return ""
+	}
+	return describeBatch(keys[0], len(keys))
+}
+
+// itemsKey is batchKey for the items of SetMultiple
+func (c *Client) itemsKey(items map[string]interface{}) string {
+	if c.log == nil {
+		return ""
+	}
+	for key := range items {
+		return describeBatch(key, len(items))
+	}
+	return ""
+}
+
+func describeBatch(first string, count int) string {
+	if count == 1 {
+		return first
+	}
+	return fmt.Sprintf("%s (+%d more)", first, count-1)
+}
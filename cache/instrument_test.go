@@ -0,0 +1,122 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithMetrics_RecordsOperations(t *testing.T) {
+	raw, _ := sharedtesting.NewTestCache(t)
+	registry := metrics.New("test")
+	core, logs := observer.New(zapcore.DebugLevel)
+	client := raw.WithMetrics(registry, &logger.Logger{Logger: zap.New(core)})
+	ctx := context.Background()
+
+	var dest string
+	if err := client.Get(ctx, "missing", &dest); err != cache.ErrCacheMiss {
+		t.Fatalf("Get() error = %v, want ErrCacheMiss", err)
+	}
+	if err := client.Set(ctx, "present", "value", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := client.Get(ctx, "present", &dest); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err := client.Delete(ctx, "present"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	counters := []struct {
+		operation string
+		result    string
+	}{
+		{cache.OperationGet, cache.ResultMiss},
+		{cache.OperationGet, cache.ResultHit},
+		{cache.OperationSet, cache.ResultSuccess},
+		{cache.OperationDelete, cache.ResultSuccess},
+	}
+	for _, c := range counters {
+		if got := testutil.ToFloat64(registry.CacheOperationsTotal.WithLabelValues(c.operation, c.result)); got != 1 {
+			t.Errorf("cache_operations_total{%s,%s} = %v, want 1", c.operation, c.result, got)
+		}
+	}
+
+	if got := testutil.CollectAndCount(registry.CacheOperationDuration, "test_cache_operation_duration_seconds"); got != 3 {
+		t.Errorf("expected duration histograms for get, set and delete, got %d", got)
+	}
+
+	entries := logs.FilterMessage("Cache operation").All()
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 debug log entries, got %d", len(entries))
+	}
+	if hit := entries[2].ContextMap()["hit"]; hit != true {
+		t.Errorf("expected the second get to be logged as a hit, got %v", hit)
+	}
+
+	t.Run("raw client is not instrumented", func(t *testing.T) {
+		if err := raw.Get(ctx, "missing", &dest); err != cache.ErrCacheMiss {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got := testutil.ToFloat64(registry.CacheOperationsTotal.WithLabelValues(cache.OperationGet, cache.ResultMiss)); got != 1 {
+			t.Errorf("raw client recorded metrics: miss count = %v", got)
+		}
+	})
+}
+
+func TestWithMetrics_RecordsAllOperations(t *testing.T) {
+	raw, _ := sharedtesting.NewTestCache(t)
+	registry := metrics.New("test")
+	client := raw.WithMetrics(registry, nil)
+	ctx := context.Background()
+
+	client.Exists(ctx, "counter")
+	client.Increment(ctx, "counter")
+	client.IncrementBy(ctx, "counter", 2)
+	client.Exists(ctx, "counter")
+	client.Expire(ctx, "counter", time.Minute)
+	client.SetNX(ctx, "lock", "owner", time.Minute)
+	client.SetMultiple(ctx, map[string]interface{}{"a": 1, "b": 2}, time.Minute)
+	client.GetMultiple(ctx, []string{"a", "b"})
+	client.HSet(ctx, "session", "user", "usr_1")
+	var user string
+	client.HGet(ctx, "session", "user", &user)
+	client.HGet(ctx, "session", "missing", &user)
+	client.HGetAll(ctx, "session")
+	client.HDel(ctx, "session", "user")
+	client.DeleteByPattern(ctx, "a*")
+
+	counters := []struct {
+		operation string
+		result    string
+		want      float64
+	}{
+		{cache.OperationExists, cache.ResultMiss, 1},
+		{cache.OperationExists, cache.ResultHit, 1},
+		{cache.OperationIncrement, cache.ResultSuccess, 2},
+		{cache.OperationExpire, cache.ResultSuccess, 1},
+		{cache.OperationSetNX, cache.ResultSuccess, 1},
+		{cache.OperationSetMultiple, cache.ResultSuccess, 1},
+		{cache.OperationGetMultiple, cache.ResultSuccess, 1},
+		{cache.OperationHSet, cache.ResultSuccess, 1},
+		{cache.OperationHGet, cache.ResultHit, 1},
+		{cache.OperationHGet, cache.ResultMiss, 1},
+		{cache.OperationHGetAll, cache.ResultSuccess, 1},
+		{cache.OperationHDel, cache.ResultSuccess, 1},
+		{cache.OperationDeleteByPattern, cache.ResultSuccess, 1},
+	}
+	for _, c := range counters {
+		if got := testutil.ToFloat64(registry.CacheOperationsTotal.WithLabelValues(c.operation, c.result)); got != c.want {
+			t.Errorf("cache_operations_total{%s,%s} = %v, want %v", c.operation, c.result, got, c.want)
+		}
+	}
+}
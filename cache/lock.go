@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/go-redis/redis/v8"
+)
+
+// unlockScript deletes the lock only if it still holds our token
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// renewScript extends the lock TTL only if it still holds our token
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Errors returned by Lock and its methods
+var (
+	ErrLockNotAcquired = stderrors.New("lock is held by another owner")
+	ErrLockLost        = stderrors.New("lock expired or was taken over")
+)
+
+// Lock is a distributed lock held in Redis. It is renewed in the background until
+// Unlock is called or the context passed to Client.Lock is cancelled.
+type Lock struct {
+	client *Client
+	key    string
+	token  string
+	ttl    atomic.Int64 // nanoseconds, read by the renewal loop
+
+	stop    chan struct{}
+	renewWg sync.WaitGroup
+	once    sync.Once
+	err     error
+}
+
+// Lock acquires a distributed lock on key using SET NX with a random token, returning
+// ErrLockNotAcquired when another holder owns it. While held, the lock TTL is renewed
+// every ttl/3 until Unlock is called or ctx is cancelled, so long-running holders
+// don't lose it; if the holder dies the lock expires after ttl.
+func (c *Client) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.redis.SetNX(ctx, key, token, ttl).Result()
+	})
+	if err != nil {
+		// The SET may have reached Redis before the error (e.g. ctx cancelled
+		// mid-flight); clear it so the key isn't stuck until the TTL expires
+		if cleanupErr := c.releaseAbandonedLock(ctx, key, token); cleanupErr != nil {
+			return nil, fmt.Errorf("failed to acquire lock: %w (cleanup: %v)", err, cleanupErr)
+		}
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	if ok, _ := result.(bool); !ok {
+		return nil, ErrLockNotAcquired
+	}
+
+	lock := &Lock{client: c, key: key, token: token, stop: make(chan struct{})}
+	lock.ttl.Store(int64(ttl))
+
+	lock.renewWg.Add(1)
+	go func() {
+		defer lock.renewWg.Done()
+		lock.renew(ctx)
+	}()
+
+	return lock, nil
+}
+
+// lockCleanupTimeout bounds releasing a lock whose acquisition failed
+const lockCleanupTimeout = 5 * time.Second
+
+// releaseAbandonedLock deletes key if it holds token, through the circuit breaker
+// and with its own timeout since ctx may already be done
+func (c *Client) releaseAbandonedLock(ctx context.Context, key, token string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lockCleanupTimeout)
+	defer cancel()
+
+	_, err := c.breaker.Execute(func() (interface{}, error) {
+		return unlockScript.Run(ctx, c.redis, []string{key}, token).Result()
+	})
+	return err
+}
+
+// Key returns the locked key
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Refresh extends the lock to ttl from now and makes later background renewals use
+// ttl. It returns ErrLockLost when the lock expired or was taken over.
+func (l *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	result, err := l.client.breaker.Execute(func() (interface{}, error) {
+		return renewScript.Run(ctx, l.client.redis, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh lock: %w", err)
+	}
+	if renewed, _ := result.(int64); renewed == 0 {
+		return ErrLockLost
+	}
+
+	l.ttl.Store(int64(ttl))
+	return nil
+}
+
+// Unlock stops renewal and releases the lock only if it is still ours. It is safe
+// to call more than once; later calls return the first call's result.
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.once.Do(func() {
+		close(l.stop)
+		l.renewWg.Wait()
+
+		_, err := l.client.breaker.Execute(func() (interface{}, error) {
+			return unlockScript.Run(ctx, l.client.redis, []string{l.key}, l.token).Result()
+		})
+		if err != nil {
+			l.err = fmt.Errorf("failed to release lock: %w", err)
+		}
+	})
+	return l.err
+}
+
+// renew periodically extends the lock TTL until stopped, ctx ends or the lock is lost
+func (l *Lock) renew(ctx context.Context) {
+	ttl := time.Duration(l.ttl.Load())
+	interval := ttl / 3
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Pick up a TTL changed by Refresh
+			if current := time.Duration(l.ttl.Load()); current != ttl && current/3 > 0 {
+				ttl = current
+				ticker.Reset(ttl / 3)
+			}
+
+			result, err := l.client.breaker.Execute(func() (interface{}, error) {
+				return renewScript.Run(ctx, l.client.redis, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+			})
+			if err != nil {
+				continue
+			}
+			if renewed, _ := result.(int64); renewed == 0 {
+				// The lock expired or was taken over; stop renewing it
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package cache_test
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+func TestLock_Contention(t *testing.T) {
+	client, _ := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	lock, err := client.Lock(ctx, "lock:job", time.Minute)
+	if err != nil {
+		t.Fatalf("first Lock() error = %v", err)
+	}
+
+	if _, err := client.Lock(ctx, "lock:job", time.Minute); !stderrors.Is(err, cache.ErrLockNotAcquired) {
+		t.Fatalf("second Lock() error = %v, want ErrLockNotAcquired", err)
+	}
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	again, err := client.Lock(ctx, "lock:job", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() after unlock error = %v", err)
+	}
+	defer again.Unlock(ctx)
+}
+
+func TestLock_SafeRelease(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	lock, err := client.Lock(ctx, "lock:job", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	// Our lock expires and another holder takes it over
+	server.FastForward(2 * time.Minute)
+	other, err := client.Lock(ctx, "lock:job", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() after expiry error = %v", err)
+	}
+	defer other.Unlock(ctx)
+	owner, _ := server.Get("lock:job")
+
+	if err := lock.Refresh(ctx, time.Minute); !stderrors.Is(err, cache.ErrLockLost) {
+		t.Errorf("Refresh() of a lost lock error = %v, want ErrLockLost", err)
+	}
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if current, _ := server.Get("lock:job"); current != owner {
+		t.Error("stale unlock released a lock held by someone else")
+	}
+
+	// Unlocking twice is a no-op
+	if err := lock.Unlock(ctx); err != nil {
+		t.Errorf("second Unlock() error = %v", err)
+	}
+}
+
+func TestLock_Refresh(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	lock, err := client.Lock(ctx, "lock:job", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	defer lock.Unlock(ctx)
+
+	if err := lock.Refresh(ctx, 10*time.Minute); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if ttl := server.TTL("lock:job"); ttl != 10*time.Minute {
+		t.Errorf("TTL after Refresh() = %v, want 10m", ttl)
+	}
+
+	server.FastForward(5 * time.Minute)
+	if !server.Exists("lock:job") {
+		t.Error("expected the refreshed lock to outlive its original TTL")
+	}
+}
+
+func TestLock_Expiry(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := client.Lock(ctx, "lock:job", 10*time.Second); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	// A crashed holder stops renewing; the lock becomes available after its TTL
+	cancel()
+	server.FastForward(11 * time.Second)
+
+	lock, err := client.Lock(context.Background(), "lock:job", 10*time.Second)
+	if err != nil {
+		t.Fatalf("Lock() after expiry error = %v", err)
+	}
+	defer lock.Unlock(context.Background())
+}
+
+func TestLock_AutoRenewal(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+
+	lock, err := client.Lock(context.Background(), "lock:job", 150*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	// Consume most of the TTL, then give the renewal loop a chance to extend it
+	server.FastForward(120 * time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	server.FastForward(120 * time.Millisecond)
+
+	if !server.Exists("lock:job") {
+		t.Fatal("expected lock to be renewed while held")
+	}
+
+	if err := lock.Unlock(context.Background()); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if server.Exists("lock:job") {
+		t.Error("expected lock to be released")
+	}
+}
+
+func TestLock_FailedAcquireCleansUp(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Lock(cancelled, "lock:job", time.Minute); err == nil {
+		t.Fatal("expected Lock() with a cancelled context to fail")
+	}
+	if server.Exists("lock:job") {
+		t.Error("expected the failed acquisition not to leave the lock behind")
+	}
+
+	server.Close()
+	_, err := client.Lock(context.Background(), "lock:job", time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "cleanup") {
+		t.Errorf("Lock() error = %v, want the failed cleanup reported", err)
+	}
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultScanBatchSize is the SCAN COUNT hint used when RedisConfig.ScanBatchSize is unset
+const DefaultScanBatchSize = 100
+
+// DeleteByPattern deletes all keys matching a glob pattern such as "workspace:123:*"
+// and returns how many were deleted. Local stale copies matching the pattern are
+// dropped even when the scan fails. Keys are found with SCAN rather than KEYS so
+// Redis isn't blocked, and each batch is deleted in one pipelined round trip. It
+// stops between batches when ctx is cancelled, returning the count so far; keys
+// written concurrently may be missed.
+func (c *Client) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	start := time.Now()
+	deleted, err := c.deleteByPattern(ctx, pattern)
+	c.observe(OperationDeleteByPattern, pattern, start, err)
+	return deleted, err
+}
+
+func (c *Client) deleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	// Drop matching stale copies whatever SCAN returns, so a failed or partial
+	// scan can't leave deleted values to be served while Redis is down
+	if c.stale != nil {
+		defer c.stale.forgetMatching(pattern)
+	}
+
+	var deleted int64
+	var cursor uint64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		result, err := c.breaker.Execute(func() (interface{}, error) {
+			keys, next, err := c.redis.Scan(ctx, cursor, pattern, c.scanBatchSize).Result()
+			return scanPage{keys: keys, next: next}, err
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to scan keys: %w", err)
+		}
+		page := result.(scanPage)
+
+		if len(page.keys) > 0 {
+			count, err := c.deleteKeys(ctx, page.keys)
+			deleted += count
+			if err != nil {
+				return deleted, err
+			}
+		}
+
+		cursor = page.next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// scanPage is one SCAN reply
+type scanPage struct {
+	keys []string
+	next uint64
+}
+
+// deleteKeys pipelines a DEL per key and returns how many existed
+func (c *Client) deleteKeys(ctx context.Context, keys []string) (int64, error) {
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(ctx, key)
+			}
+			return nil
+		})
+	})
+
+	if c.stale != nil {
+		for _, key := range keys {
+			c.stale.forget(key)
+		}
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete keys: %w", err)
+	}
+
+	var deleted int64
+	for _, cmd := range result.([]redis.Cmder) {
+		deleted += cmd.(*redis.IntCmd).Val()
+	}
+	return deleted, nil
+}
+
+// matchGlob reports whether key matches a Redis glob pattern: * and ? wildcards,
+// [abc], [^a] and [a-z] classes, and backslash escapes
+func matchGlob(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if matchGlob(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return false
+			}
+			class := pattern[1 : end+1]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			if matchClass(class, key[0]) == negate {
+				return false
+			}
+			pattern = pattern[end+1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+// matchClass reports whether b is in a glob character class such as "a-z0"
+func matchClass(class string, b byte) bool {
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			if class[i] <= b && b <= class[i+2] {
+				return true
+			}
+			i += 2
+			continue
+		}
+		if class[i] == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package cache_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newScanTestCache connects a client with a small SCAN batch to a fresh server
+func newScanTestCache(t *testing.T, batchSize int) (*cache.Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	host, port, _ := net.SplitHostPort(server.Addr())
+	portNum, _ := strconv.Atoi(port)
+
+	client, err := cache.New(&config.RedisConfig{Host: host, Port: portNum, ScanBatchSize: batchSize})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	client, server := newScanTestCache(t, 100)
+	ctx := context.Background()
+
+	for i := 0; i < 7; i++ {
+		server.Set(fmt.Sprintf("workspace:123:record:%d", i), "{}")
+	}
+	server.Set("workspace:1234:record:1", "{}")
+	server.Set("workspace:456:record:1", "{}")
+
+	deleted, err := client.DeleteByPattern(ctx, "workspace:123:*")
+	if err != nil {
+		t.Fatalf("DeleteByPattern() error = %v", err)
+	}
+	if deleted != 7 {
+		t.Errorf("DeleteByPattern() = %d, want 7", deleted)
+	}
+
+	keys := server.Keys()
+	if len(keys) != 2 {
+		t.Errorf("expected only non-matching keys to remain, got %v", keys)
+	}
+
+	deleted, err = client.DeleteByPattern(ctx, "workspace:123:*")
+	if err != nil || deleted != 0 {
+		t.Errorf("DeleteByPattern() with no matches = %d, %v", deleted, err)
+	}
+}
+
+func TestDeleteByPattern_Batches(t *testing.T) {
+	client, server := newScanTestCache(t, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 7; i++ {
+		server.Set(fmt.Sprintf("workspace:123:record:%d", i), "{}")
+	}
+	server.Set("workspace:456:record:1", "{}")
+
+	// miniredis cursors are offsets, so deleting while scanning skips keys that
+	// Redis would return; repeat until nothing matches and check the total
+	var total int64
+	for pass := 0; pass < 10; pass++ {
+		deleted, err := client.DeleteByPattern(ctx, "workspace:123:*")
+		if err != nil {
+			t.Fatalf("DeleteByPattern() error = %v", err)
+		}
+		if deleted == 0 {
+			break
+		}
+		total += deleted
+	}
+
+	if total != 7 {
+		t.Errorf("deleted %d keys in total, want 7", total)
+	}
+	if keys := server.Keys(); len(keys) != 1 {
+		t.Errorf("expected only the non-matching key to remain, got %v", keys)
+	}
+}
+
+func TestDeleteByPattern_Cancelled(t *testing.T) {
+	client, server := newScanTestCache(t, 2)
+	server.Set("workspace:123:a", "{}")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deleted, err := client.DeleteByPattern(ctx, "workspace:123:*")
+	if !stderrors.Is(err, context.Canceled) || deleted != 0 {
+		t.Errorf("DeleteByPattern() = %d, %v; want context.Canceled", deleted, err)
+	}
+	if !server.Exists("workspace:123:a") {
+		t.Error("expected nothing to be deleted after cancellation")
+	}
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults for StaleConfig
+const (
+	DefaultStaleMaxEntries   = 1000
+	DefaultStaleMaxStaleness = 5 * time.Minute
+)
+
+// ResultStale labels reads served from a stale local copy
+const ResultStale = "stale"
+
+// StaleConfig configures serving stale values while Redis is unavailable
+type StaleConfig struct {
+	MaxEntries   int           // Values kept in the local LRU (default 1000)
+	MaxStaleness time.Duration // Oldest copy that may be served (default 5m)
+}
+
+// WithStaleFallback returns a copy of the client that remembers the values it reads
+// and writes in a local LRU. When Redis fails or the circuit breaker is open, Get
+// serves the remembered value if it was seen within MaxStaleness instead of failing,
+// and counts it in the cache_stale_serves_total metric when instrumented. Misses are
+// never served stale, and Delete and DeleteByPattern drop the local copies.
+func (c *Client) WithStaleFallback(cfg StaleConfig) *Client {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultStaleMaxEntries
+	}
+	if cfg.MaxStaleness <= 0 {
+		cfg.MaxStaleness = DefaultStaleMaxStaleness
+	}
+
+	fallback := *c
+	fallback.stale = newStaleLRU(cfg)
+	return &fallback
+}
+
+// unavailableError reports that Redis or the circuit breaker failed a read
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return fmt.Sprintf("failed to get cache: %v", e.err)
+}
+
+func (e *unavailableError) Unwrap() error {
+	return e.err
+}
+
+// staleEntry is a remembered value and when it was last seen in Redis
+type staleEntry struct {
+	key    string
+	data   []byte
+	seenAt time.Time
+}
+
+// staleLRU keeps the most recently used values for stale reads
+type staleLRU struct {
+	cfg StaleConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newStaleLRU(cfg StaleConfig) *staleLRU {
+	return &staleLRU{
+		cfg:     cfg,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// remember stores the latest value of key, evicting the least recently used entry when full
+func (l *staleLRU) remember(key string, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*staleEntry)
+		entry.data = data
+		entry.seenAt = l.now()
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&staleEntry{key: key, data: data, seenAt: l.now()})
+	if l.order.Len() > l.cfg.MaxEntries {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*staleEntry).key)
+	}
+}
+
+// forget drops the local copy of key
+func (l *staleLRU) forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		l.order.Remove(element)
+		delete(l.entries, key)
+	}
+}
+
+// forgetMatching drops the local copies of keys matching a Redis glob pattern
+func (l *staleLRU) forgetMatching(pattern string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.entries {
+		if matchGlob(pattern, key) {
+			l.order.Remove(element)
+			delete(l.entries, key)
+		}
+	}
+}
+
+// lookup returns the copy of key if it is within the staleness bound
+func (l *staleLRU) lookup(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*staleEntry)
+	if l.now().Sub(entry.seenAt) > l.cfg.MaxStaleness {
+		l.order.Remove(element)
+		delete(l.entries, key)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	return entry.data, true
+}
+
+// serveStale decodes the local copy of key into dest after a failed read
+func (c *Client) serveStale(key string, dest interface{}) bool {
+	data, ok := c.stale.lookup(key)
+	if !ok {
+		return false
+	}
+	if err := c.codec.Unmarshal(data, dest); err != nil {
+		return false
+	}
+
+	if c.metrics != nil {
+		c.metrics.RecordCacheStaleServe()
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleLRU_MaxStaleness(t *testing.T) {
+	now := time.Now()
+	lru := newStaleLRU(StaleConfig{MaxEntries: 10, MaxStaleness: time.Minute})
+	lru.now = func() time.Time { return now }
+
+	lru.remember("key", []byte(`"value"`))
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    bool
+	}{
+		{name: "fresh", elapsed: 0, want: true},
+		{name: "within window", elapsed: time.Minute, want: true},
+		{name: "past window", elapsed: time.Minute + time.Second, want: false},
+		{name: "expired entries are dropped", elapsed: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lru.now = func() time.Time { return now.Add(tt.elapsed) }
+			if _, ok := lru.lookup("key"); ok != tt.want {
+				t.Errorf("lookup() ok = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	lru := newStaleLRU(StaleConfig{MaxEntries: 2, MaxStaleness: time.Minute})
+
+	lru.remember("a", []byte("1"))
+	lru.remember("b", []byte("2"))
+	lru.lookup("a")
+	lru.remember("c", []byte("3"))
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := lru.lookup(key); ok != want {
+			t.Errorf("lookup(%q) ok = %v, want %v", key, ok, want)
+		}
+	}
+
+	lru.forget("a")
+	if _, ok := lru.lookup("a"); ok {
+		t.Error("expected forgotten key to be gone")
+	}
+	if got := lru.order.Len(); got != 1 {
+		t.Errorf("expected 1 entry left, got %d", got)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"workspace:123:*", "workspace:123:tables", true},
+		{"workspace:123:*", "workspace:1234:tables", false},
+		{"*", "", true},
+		{"user:?", "user:7", true},
+		{"user:?", "user:42", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`literal\*`, "literal*", true},
+		{`literal\*`, "literally", false},
+		{"*:name", "workspace:1:name", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sony/gobreaker"
+)
+
+func TestWithStaleFallback_ServesStaleWhileBreakerOpen(t *testing.T) {
+	raw, server := sharedtesting.NewTestCache(t)
+	registry := metrics.New("test")
+	client := raw.WithMetrics(registry, nil).WithStaleFallback(cache.StaleConfig{MaxStaleness: time.Minute})
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "written", "from set", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	server.Set("read", `"from get"`)
+	var dest string
+	if err := client.Get(ctx, "read", &dest); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	server.Set("deleted", `"gone"`)
+	if err := client.Get(ctx, "deleted", &dest); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err := client.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	server.Close()
+
+	// Enough failed reads to outweigh the earlier successes and trip the breaker
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"written", "from set"},
+		{"read", "from get"},
+	}
+	for i := 0; i < 5; i++ {
+		for _, tt := range tests {
+			var got string
+			if err := client.Get(ctx, tt.key, &got); err != nil {
+				t.Fatalf("Get(%q) error = %v, want stale value", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		}
+	}
+
+	if state := raw.GetBreakerStats()["state"]; state != gobreaker.StateOpen.String() {
+		t.Fatalf("expected the breaker to be open, got %v", state)
+	}
+	if err := client.Get(ctx, "read", &dest); err != nil {
+		t.Errorf("expected a stale read while the breaker is open, got %v", err)
+	}
+
+	if err := client.Get(ctx, "deleted", &dest); err == nil {
+		t.Error("expected deleted keys not to be served stale")
+	}
+	if err := client.Get(ctx, "unknown", &dest); err == nil {
+		t.Error("expected unknown keys to fail")
+	}
+
+	if got := testutil.ToFloat64(registry.CacheStaleServesTotal); got != 11 {
+		t.Errorf("cache_stale_serves_total = %v, want 11", got)
+	}
+	if got := testutil.ToFloat64(registry.CacheOperationsTotal.WithLabelValues(cache.OperationGet, cache.ResultStale)); got != 11 {
+		t.Errorf("cache_operations_total{get,stale} = %v, want 11", got)
+	}
+}
+
+func TestWithStaleFallback_DisabledByDefault(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	server.Close()
+
+	var dest string
+	if err := client.Get(ctx, "key", &dest); err == nil {
+		t.Error("expected Get() to fail without a stale fallback")
+	}
+}
+
+func TestWithStaleFallback_DeleteByPatternForgetsWhenScanFails(t *testing.T) {
+	raw, server := sharedtesting.NewTestCache(t)
+	client := raw.WithStaleFallback(cache.StaleConfig{MaxStaleness: time.Minute})
+	ctx := context.Background()
+
+	for _, key := range []string{"workspace:1:name", "workspace:2:name"} {
+		if err := client.Set(ctx, key, "cached", time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	server.Close()
+
+	if _, err := client.DeleteByPattern(ctx, "workspace:1:*"); err == nil {
+		t.Fatal("expected DeleteByPattern() to fail while Redis is down")
+	}
+
+	var dest string
+	if err := client.Get(ctx, "workspace:1:name", &dest); err == nil {
+		t.Error("expected keys matching the pattern not to be served stale")
+	}
+	if err := client.Get(ctx, "workspace:2:name", &dest); err != nil {
+		t.Errorf("Get() of a key outside the pattern error = %v, want stale value", err)
+	}
+}
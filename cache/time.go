@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Time returns the Redis server's clock from the TIME command. Services sharing a
+// Redis can use it to agree on time-based boundaries, such as rate limit windows,
+// even when their own clocks drift or are stepped.
+func (c *Client) Time(ctx context.Context) (time.Time, error) {
+	start := time.Now()
+	now, err := c.serverTime(ctx)
+	c.observe(OperationTime, "", start, err)
+	return now, err
+}
+
+func (c *Client) serverTime(ctx context.Context) (time.Time, error) {
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.redis.Time(ctx).Result()
+	})
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get server time: %w", err)
+	}
+
+	now, ok := result.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected result type: %T", result)
+	}
+
+	return now, nil
+}
+
+// incrWindowScript counts a hit in the window of ARGV[1] milliseconds containing
+// the Redis server's clock, keeping the window start and count in one hash that
+// expires when the window ends. It returns {count, window start ms, now ms}.
+var incrWindowScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local size = tonumber(ARGV[1])
+local start = now - now % size
+if tonumber(redis.call("HGET", KEYS[1], "start")) ~= start then
+	redis.call("DEL", KEYS[1])
+	redis.call("HSET", KEYS[1], "start", start)
+	redis.call("PEXPIRE", KEYS[1], start + size - now)
+end
+return {redis.call("HINCRBY", KEYS[1], "count", 1), start, now}
+`)
+
+// WindowCount is the state of a fixed window after IncrementWindow
+type WindowCount struct {
+	Count int64     // Hits in the window, including this one
+	Start time.Time // Start of the window by the Redis server's clock
+	Now   time.Time // The Redis server's clock
+}
+
+// IncrementWindow counts a hit against key in the current fixed window of the
+// given size, aligned to the Unix epoch on the Redis server's clock. Reading TIME,
+// incrementing and setting the expiry happen in one script, so it takes a single
+// round trip and replicas with drifting clocks agree on window boundaries.
+func (c *Client) IncrementWindow(ctx context.Context, key string, size time.Duration) (WindowCount, error) {
+	start := time.Now()
+	window, err := c.incrementWindow(ctx, key, size)
+	c.observe(OperationIncrWindow, key, start, err)
+	return window, err
+}
+
+func (c *Client) incrementWindow(ctx context.Context, key string, size time.Duration) (WindowCount, error) {
+	if size < time.Millisecond {
+		return WindowCount{}, fmt.Errorf("window size %v is shorter than a millisecond", size)
+	}
+
+	result, err := c.breaker.Execute(func() (interface{}, error) {
+		return incrWindowScript.Run(ctx, c.redis, []string{key}, size.Milliseconds()).Int64Slice()
+	})
+	if err != nil {
+		return WindowCount{}, fmt.Errorf("failed to increment window: %w", err)
+	}
+
+	values, ok := result.([]int64)
+	if !ok || len(values) != 3 {
+		return WindowCount{}, fmt.Errorf("unexpected result: %v", result)
+	}
+	return WindowCount{
+		Count: values[0],
+		Start: time.UnixMilli(values[1]),
+		Now:   time.UnixMilli(values[2]),
+	}, nil
+}
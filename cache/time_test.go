@@ -0,0 +1,58 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+func TestTime(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	serverNow := time.Date(2024, 1, 1, 12, 0, 0, 500000000, time.UTC)
+	server.SetTime(serverNow)
+
+	now, err := client.Time(ctx)
+	if err != nil {
+		t.Fatalf("Time() error = %v", err)
+	}
+	if !now.Equal(serverNow) {
+		t.Errorf("Time() = %v, want the server's %v", now, serverNow)
+	}
+
+	server.Close()
+	if _, err := client.Time(ctx); err == nil {
+		t.Error("expected an error with Redis down")
+	}
+}
+
+func TestIncrementWindow(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+	ctx := context.Background()
+
+	windowStart := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	server.SetTime(windowStart.Add(50 * time.Second))
+
+	for want := int64(1); want <= 2; want++ {
+		window, err := client.IncrementWindow(ctx, "ratelimit:client-1", time.Minute)
+		if err != nil {
+			t.Fatalf("IncrementWindow() error = %v", err)
+		}
+		if window.Count != want || !window.Start.Equal(windowStart) || !window.Now.Equal(windowStart.Add(50*time.Second)) {
+			t.Errorf("IncrementWindow() = %+v, want count %d in the window at %v", window, want, windowStart)
+		}
+	}
+	if ttl := server.TTL("ratelimit:client-1"); ttl != 10*time.Second {
+		t.Errorf("TTL = %v, want the rest of the window", ttl)
+	}
+
+	// The next window starts a new count
+	server.SetTime(windowStart.Add(time.Minute))
+	window, err := client.IncrementWindow(ctx, "ratelimit:client-1", time.Minute)
+	if err != nil || window.Count != 1 || !window.Start.Equal(windowStart.Add(time.Minute)) {
+		t.Errorf("IncrementWindow() in the next window = %+v, %v, want a count of 1", window, err)
+	}
+}
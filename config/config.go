@@ -3,7 +3,9 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -24,7 +26,12 @@ type ServerConfig struct {
 	Port         int    `mapstructure:"port" default:"8080"`
 	ReadTimeout  int    `mapstructure:"read_timeout" default:"30"`
 	WriteTimeout int    `mapstructure:"write_timeout" default:"30"`
+	// HandlerTimeout bounds request handling in seconds (0 disables); it must be
+	// shorter than WriteTimeout so the timeout response can still be written
+	HandlerTimeout int `mapstructure:"handler_timeout" default:"25"`
 	Environment  string `mapstructure:"environment" default:"development"`
+	// TrustedProxies lists CIDRs or IPs of load balancers whose X-Forwarded-For is honored
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // DatabaseConfig contains database connection configuration
@@ -38,6 +45,13 @@ type DatabaseConfig struct {
 	MaxOpenConns int    `mapstructure:"max_open_conns" default:"25"`
 	MaxIdleConns int    `mapstructure:"max_idle_conns" default:"25"`
 	MaxLifetime  int    `mapstructure:"max_lifetime" default:"300"`
+	// RetryAttempts is how many times New retries a failed connection (0 fails fast);
+	// the delay starts at RetryBackoff seconds (at least 1) and doubles up to 30s
+	RetryAttempts int `mapstructure:"retry_attempts" default:"0"`
+	RetryBackoff  int `mapstructure:"retry_backoff" default:"1"`
+	// SlowQueryThresholdMs is the duration above which queries are logged as slow;
+	// 0 uses database.DefaultSlowQueryThreshold and a negative value disables it
+	SlowQueryThresholdMs int `mapstructure:"slow_query_threshold_ms" default:"1000"`
 }
 
 // RedisConfig contains Redis connection configuration
@@ -48,6 +62,27 @@ type RedisConfig struct {
 	Database     int    `mapstructure:"database" default:"0"`
 	PoolSize     int    `mapstructure:"pool_size" default:"10"`
 	MinIdleConns int    `mapstructure:"min_idle_conns" default:"5"`
+	// ScanBatchSize is the COUNT hint for SCAN in pattern deletes (default 100)
+	ScanBatchSize int `mapstructure:"scan_batch_size" default:"100"`
+	// Breaker tunes the circuit breaker; zero values keep the defaults
+	Breaker BreakerConfig `mapstructure:"breaker"`
+	// Compression gzips large values; disabled by default
+	Compression CompressionConfig `mapstructure:"compression"`
+}
+
+// CompressionConfig contains cache value compression settings
+type CompressionConfig struct {
+	Enabled   bool `mapstructure:"enabled" default:"false"`  // Compress values of at least Threshold bytes
+	Threshold int  `mapstructure:"threshold" default:"1024"` // Smallest encoded value that is compressed
+}
+
+// BreakerConfig contains circuit breaker settings for the Redis client
+type BreakerConfig struct {
+	MaxRequests  uint32  `mapstructure:"max_requests" default:"3"`    // Requests allowed through while half-open
+	Interval     int     `mapstructure:"interval" default:"10"`       // Seconds after which closed-state counts reset
+	Timeout      int     `mapstructure:"timeout" default:"60"`        // Seconds the breaker stays open before probing
+	FailureRatio float64 `mapstructure:"failure_ratio" default:"0.6"` // Failure ratio that trips the breaker
+	MinRequests  uint32  `mapstructure:"min_requests" default:"3"`    // Requests needed in an interval before it can trip
 }
 
 // AuthConfig contains authentication configuration
@@ -55,6 +90,9 @@ type AuthConfig struct {
 	JWTSecret     string `mapstructure:"jwt_secret"`
 	JWTExpiration int    `mapstructure:"jwt_expiration" default:"3600"`
 	Issuer        string `mapstructure:"issuer" default:"pyairtable"`
+	// Token lifetimes in seconds
+	PasswordResetExpiration     int `mapstructure:"password_reset_expiration" default:"3600"`
+	EmailVerificationExpiration int `mapstructure:"email_verification_expiration" default:"86400"`
 }
 
 // LoggerConfig contains logger configuration
@@ -71,6 +109,9 @@ type MetricsConfig struct {
 	Path    string `mapstructure:"path" default:"/metrics"`
 }
 
+// RedactedValue replaces secrets in Redacted
+const RedactedValue = "[REDACTED]"
+
 // Load loads configuration from various sources
 func Load(configPath string) (*Config, error) {
 	v := viper.New()
@@ -116,6 +157,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.read_timeout", 30)
 	v.SetDefault("server.write_timeout", 30)
+	v.SetDefault("server.handler_timeout", 25)
 	v.SetDefault("server.environment", "development")
 	
 	// Database defaults
@@ -127,6 +169,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_open_conns", 25)
 	v.SetDefault("database.max_idle_conns", 25)
 	v.SetDefault("database.max_lifetime", 300)
+	v.SetDefault("database.retry_attempts", 0)
+	v.SetDefault("database.retry_backoff", 1)
+	v.SetDefault("database.slow_query_threshold_ms", 1000)
 	
 	// Redis defaults
 	v.SetDefault("redis.host", "localhost")
@@ -134,10 +179,20 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("redis.database", 0)
 	v.SetDefault("redis.pool_size", 10)
 	v.SetDefault("redis.min_idle_conns", 5)
+	v.SetDefault("redis.scan_batch_size", 100)
+	v.SetDefault("redis.breaker.max_requests", 3)
+	v.SetDefault("redis.breaker.interval", 10)
+	v.SetDefault("redis.breaker.timeout", 60)
+	v.SetDefault("redis.breaker.failure_ratio", 0.6)
+	v.SetDefault("redis.breaker.min_requests", 3)
+	v.SetDefault("redis.compression.enabled", false)
+	v.SetDefault("redis.compression.threshold", 1024)
 	
 	// Auth defaults
 	v.SetDefault("auth.jwt_expiration", 3600)
 	v.SetDefault("auth.issuer", "pyairtable")
+	v.SetDefault("auth.password_reset_expiration", 3600)
+	v.SetDefault("auth.email_verification_expiration", 86400)
 	
 	// Logger defaults
 	v.SetDefault("logger.level", "info")
@@ -164,9 +219,46 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("server port must be between 1 and 65535")
 	}
 	
+	if c.Server.HandlerTimeout < 0 {
+		return fmt.Errorf("server handler timeout must not be negative")
+	}
+	if c.Server.HandlerTimeout > 0 && c.Server.WriteTimeout > 0 && c.Server.HandlerTimeout >= c.Server.WriteTimeout {
+		return fmt.Errorf("server handler timeout (%ds) must be shorter than the write timeout (%ds)", c.Server.HandlerTimeout, c.Server.WriteTimeout)
+	}
+	
+	for _, proxy := range c.Server.TrustedProxies {
+		if proxy = strings.TrimSpace(proxy); proxy != "" && !isIPOrCIDR(proxy) {
+			return fmt.Errorf("server trusted proxy %q must be an IP address or CIDR", proxy)
+		}
+	}
+	
 	return nil
 }
 
+// HandlerTimeoutDuration returns HandlerTimeout as a duration
+func (s ServerConfig) HandlerTimeoutDuration() time.Duration {
+	return time.Duration(s.HandlerTimeout) * time.Second
+}
+
+// Redacted returns a copy of the configuration with passwords and secrets masked,
+// safe to log or expose on admin endpoints
+func (c *Config) Redacted() Config {
+	redacted := *c
+	redacted.Server.TrustedProxies = append([]string(nil), c.Server.TrustedProxies...)
+	redacted.Database.Password = redact(c.Database.Password)
+	redacted.Redis.Password = redact(c.Redis.Password)
+	redacted.Auth.JWTSecret = redact(c.Auth.JWTSecret)
+	return redacted
+}
+
+// redact masks a non-empty secret, leaving empty ones visible as unset
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
 // IsDevelopment returns true if the environment is development
 func (c *Config) IsDevelopment() bool {
 	return strings.ToLower(c.Server.Environment) == "development"
@@ -175,4 +267,13 @@ func (c *Config) IsDevelopment() bool {
 // IsProduction returns true if the environment is production
 func (c *Config) IsProduction() bool {
 	return strings.ToLower(c.Server.Environment) == "production"
-}
\ No newline at end of file
+}
+
+// isIPOrCIDR reports whether value is an IP address or a CIDR network
+func isIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
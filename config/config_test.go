@@ -127,6 +127,51 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "handler timeout shorter than write timeout",
+			config: &Config{
+				Database: DatabaseConfig{Password: "testpass"},
+				Auth:     AuthConfig{JWTSecret: "testsecret"},
+				Server:   ServerConfig{Port: 8080, HandlerTimeout: 25, WriteTimeout: 30},
+			},
+			wantErr: false,
+		},
+		{
+			name: "handler timeout not shorter than write timeout",
+			config: &Config{
+				Database: DatabaseConfig{Password: "testpass"},
+				Auth:     AuthConfig{JWTSecret: "testsecret"},
+				Server:   ServerConfig{Port: 8080, HandlerTimeout: 30, WriteTimeout: 30},
+			},
+			wantErr: true,
+		},
+		{
+			name: "trusted proxies",
+			config: &Config{
+				Database: DatabaseConfig{Password: "testpass"},
+				Auth:     AuthConfig{JWTSecret: "testsecret"},
+				Server:   ServerConfig{Port: 8080, TrustedProxies: []string{"10.0.0.0/8", " 192.168.1.1"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid trusted proxy",
+			config: &Config{
+				Database: DatabaseConfig{Password: "testpass"},
+				Auth:     AuthConfig{JWTSecret: "testsecret"},
+				Server:   ServerConfig{Port: 8080, TrustedProxies: []string{"load-balancer"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative handler timeout",
+			config: &Config{
+				Database: DatabaseConfig{Password: "testpass"},
+				Auth:     AuthConfig{JWTSecret: "testsecret"},
+				Server:   ServerConfig{Port: 8080, HandlerTimeout: -1},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,4 +275,46 @@ func BenchmarkLoad(b *testing.B) {
 			b.Fatal(err)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestConfig_Redacted(t *testing.T) {
+	cfg := &Config{
+		Server:   ServerConfig{Port: 8080, TrustedProxies: []string{"10.0.0.0/8"}},
+		Database: DatabaseConfig{Host: "db", Password: "db-secret"},
+		Auth:     AuthConfig{JWTSecret: "jwt-secret"},
+	}
+
+	redacted := cfg.Redacted()
+
+	if redacted.Database.Password != RedactedValue || redacted.Auth.JWTSecret != RedactedValue {
+		t.Errorf("expected secrets to be redacted, got %q and %q", redacted.Database.Password, redacted.Auth.JWTSecret)
+	}
+	if redacted.Redis.Password != "" {
+		t.Errorf("expected an unset secret to stay empty, got %q", redacted.Redis.Password)
+	}
+	if redacted.Database.Host != "db" || redacted.Server.Port != 8080 {
+		t.Errorf("expected other settings to be kept, got %+v", redacted)
+	}
+	if cfg.Database.Password != "db-secret" {
+		t.Error("expected the original configuration to be unchanged")
+	}
+
+	redacted.Server.TrustedProxies[0] = "0.0.0.0/0"
+	if cfg.Server.TrustedProxies[0] != "10.0.0.0/8" {
+		t.Error("expected the copy not to share slices with the original")
+	}
+}
+
+func TestLoad_BreakerConfig(t *testing.T) {
+	os.Setenv("PYAIRTABLE_REDIS_BREAKER_TIMEOUT", "5")
+	defer os.Unsetenv("PYAIRTABLE_REDIS_BREAKER_TIMEOUT")
+
+	config, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := BreakerConfig{MaxRequests: 3, Interval: 10, Timeout: 5, FailureRatio: 0.6, MinRequests: 3}
+	if config.Redis.Breaker != want {
+		t.Errorf("Redis.Breaker = %+v, want %+v", config.Redis.Breaker, want)
+	}
+}
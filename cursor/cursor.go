@@ -0,0 +1,131 @@
+// Package cursor provides a tamper-evident codec for opaque pagination cursors
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// Version is the cursor format version written by Encode
+const Version = "v1"
+
+// Cursor format: <version>.<base64url(json payload)>[.<base64url(hmac-sha256)>]
+const separator = "."
+
+var encoding = base64.RawURLEncoding
+
+// Codec encodes and decodes cursors, signing them when a key is configured
+type Codec struct {
+	key []byte
+}
+
+// NewCodec creates a cursor codec. With a non-empty key cursors are HMAC-signed and
+// Decode rejects cursors without a valid signature; with an empty key they are only encoded.
+func NewCodec(key []byte) *Codec {
+	return &Codec{key: key}
+}
+
+// Encode serializes the cursor fields into an opaque, URL-safe string.
+// Field values must be JSON-serializable; an empty string is returned otherwise.
+func (c *Codec) Encode(fields map[string]interface{}) string {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+
+	cursor := Version + separator + encoding.EncodeToString(payload)
+	if len(c.key) > 0 {
+		cursor += separator + encoding.EncodeToString(c.sign(cursor))
+	}
+
+	return cursor
+}
+
+// Decode parses a cursor produced by Encode. Malformed, unsigned (when a key is
+// configured), tampered and unknown-version cursors are rejected with an INVALID_INPUT error.
+// Numbers are decoded as json.Number to keep IDs exact.
+func (c *Codec) Decode(cursor string) (map[string]interface{}, error) {
+	parts := strings.Split(cursor, separator)
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, invalidCursor("malformed cursor")
+	}
+
+	version, encodedPayload := parts[0], parts[1]
+	if version != Version {
+		return nil, invalidCursor(fmt.Sprintf("unsupported cursor version %q", version))
+	}
+
+	if len(c.key) > 0 {
+		if len(parts) != 3 {
+			return nil, invalidCursor("missing cursor signature")
+		}
+
+		signature, err := encoding.DecodeString(parts[2])
+		if err != nil {
+			return nil, invalidCursor("malformed cursor signature")
+		}
+
+		expected := c.sign(version + separator + encodedPayload)
+		if !hmac.Equal(signature, expected) {
+			return nil, invalidCursor("invalid cursor signature")
+		}
+	}
+
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, invalidCursor("malformed cursor payload")
+	}
+
+	var fields map[string]interface{}
+	if err := utils.FromJSONUseNumber(payload, &fields); err != nil || fields == nil {
+		return nil, invalidCursor("malformed cursor payload")
+	}
+
+	return fields, nil
+}
+
+// sign computes the HMAC-SHA256 of the signed portion of a cursor
+func (c *Codec) sign(data string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// invalidCursor creates the error returned for cursors that can't be decoded
+func invalidCursor(reason string) error {
+	return errors.NewInvalidInputError("cursor", reason)
+}
+
+var (
+	defaultCodec = NewCodec(nil)
+	defaultMu    sync.RWMutex
+)
+
+// SetKey configures the signing key used by the package-level Encode and Decode
+func SetKey(key []byte) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCodec = NewCodec(key)
+}
+
+// Encode encodes cursor fields with the default codec (see SetKey)
+func Encode(fields map[string]interface{}) string {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCodec.Encode(fields)
+}
+
+// Decode decodes a cursor with the default codec (see SetKey)
+func Decode(cursor string) (map[string]interface{}, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCodec.Decode(cursor)
+}
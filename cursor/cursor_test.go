@@ -0,0 +1,144 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+	}{
+		{name: "signed", key: []byte("secret")},
+		{name: "unsigned", key: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := NewCodec(tt.key)
+			cursor := codec.Encode(map[string]interface{}{
+				"id":         int64(9007199254740993),
+				"created_at": "2024-03-04T10:30:00Z",
+			})
+
+			if strings.ContainsAny(cursor, "+/=") {
+				t.Errorf("cursor %q is not URL-safe", cursor)
+			}
+
+			fields, err := codec.Decode(cursor)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got := fields["id"]; got != json.Number("9007199254740993") {
+				t.Errorf("id = %#v, want exact json.Number", got)
+			}
+			if got := fields["created_at"]; got != "2024-03-04T10:30:00Z" {
+				t.Errorf("created_at = %#v", got)
+			}
+		})
+	}
+}
+
+func TestCodec_RejectsTamperedCursors(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	cursor := codec.Encode(map[string]interface{}{"id": 10, "workspace_id": 1})
+	parts := strings.Split(cursor, ".")
+
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"id":10,"workspace_id":2}`))
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "forged payload", cursor: parts[0] + "." + forgedPayload + "." + parts[2]},
+		{name: "signature removed", cursor: parts[0] + "." + parts[1]},
+		{name: "signature from other key", cursor: NewCodec([]byte("other")).Encode(map[string]interface{}{"id": 10})},
+		{name: "garbage signature", cursor: parts[0] + "." + parts[1] + ".!!!"},
+		{name: "empty", cursor: ""},
+		{name: "not a cursor", cursor: "hello"},
+		{name: "too many parts", cursor: cursor + ".extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := codec.Decode(tt.cursor)
+			if err == nil {
+				t.Fatalf("Decode() = %v, want error", fields)
+			}
+			if !errors.Is(err, errors.ErrCodeInvalidInput) {
+				t.Errorf("expected INVALID_INPUT error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCodec_RejectsMalformedPayload(t *testing.T) {
+	codec := NewCodec(nil)
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "invalid base64", cursor: "v1.***"},
+		{name: "not json", cursor: "v1." + base64.RawURLEncoding.EncodeToString([]byte("nope"))},
+		{name: "json array", cursor: "v1." + base64.RawURLEncoding.EncodeToString([]byte("[1,2]"))},
+		{name: "json null", cursor: "v1." + base64.RawURLEncoding.EncodeToString([]byte("null"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.cursor); err == nil {
+				t.Error("Decode() expected error")
+			}
+		})
+	}
+}
+
+func TestCodec_Versioning(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	cursor := codec.Encode(map[string]interface{}{"id": 1})
+
+	if !strings.HasPrefix(cursor, Version+".") {
+		t.Fatalf("cursor %q does not carry version %q", cursor, Version)
+	}
+
+	// A cursor from a future format version must not be accepted, even if re-signed
+	payload := strings.Split(cursor, ".")[1]
+	future := "v2." + payload
+	future += "." + base64.RawURLEncoding.EncodeToString(codec.sign(future))
+
+	_, err := codec.Decode(future)
+	if err == nil {
+		t.Fatal("expected unknown version to be rejected")
+	}
+	if !strings.Contains(err.Error(), "unsupported cursor version") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDefaultCodec(t *testing.T) {
+	SetKey([]byte("package-key"))
+	defer SetKey(nil)
+
+	cursor := Encode(map[string]interface{}{"page": "next"})
+	if _, err := NewCodec(nil).Decode(cursor); err != nil {
+		t.Fatalf("signed cursor payload should still be readable: %v", err)
+	}
+
+	fields, err := Decode(cursor)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if fields["page"] != "next" {
+		t.Errorf("page = %#v, want next", fields["page"])
+	}
+
+	if _, err := NewCodec([]byte("other")).Decode(cursor); err == nil {
+		t.Error("expected cursor signed with another key to be rejected")
+	}
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+)
+
+// CachedRepository adds read-through caching of GetByID to a KeyedRepository.
+//
+// Writes invalidate rather than update the cache: the entry is deleted before the
+// database write and again after it, so a reader that misses never sees an entry
+// older than the write. That still leaves one race: a reader that loaded the old
+// row just before the write can store it after the second delete. WithDoubleDelete
+// closes that window by deleting once more after a delay longer than a read takes.
+// Cache failures are tolerated; the database stays the source of truth.
+type CachedRepository[T any, K Key] struct {
+	repo              *KeyedRepository[T, K]
+	cache             *cache.Client
+	ttl               time.Duration
+	keyPrefix         string
+	doubleDeleteDelay time.Duration
+}
+
+// NewCachedRepository caches the entities of repo in client for ttl, under keys
+// prefixed with the lowercased model name, e.g. "workspace:42"
+func NewCachedRepository[T any, K Key](repo *KeyedRepository[T, K], client *cache.Client, ttl time.Duration) *CachedRepository[T, K] {
+	return &CachedRepository[T, K]{
+		repo:      repo,
+		cache:     client,
+		ttl:       ttl,
+		keyPrefix: strings.ToLower(resourceName[T]()) + ":",
+	}
+}
+
+// WithDoubleDelete returns a copy of the repository that deletes the cache entry a
+// third time, delay after each write, removing entries stored by racing readers
+func (r *CachedRepository[T, K]) WithDoubleDelete(delay time.Duration) *CachedRepository[T, K] {
+	clone := *r
+	clone.doubleDeleteDelay = delay
+	return &clone
+}
+
+// CacheKey returns the cache key of the entity with primary key id
+func (r *CachedRepository[T, K]) CacheKey(id K) string {
+	return fmt.Sprintf("%s%v", r.keyPrefix, id)
+}
+
+// GetByID returns the entity from the cache, loading and caching it on a miss
+func (r *CachedRepository[T, K]) GetByID(ctx context.Context, id K) (*T, error) {
+	key := r.CacheKey(id)
+
+	var entity T
+	if err := r.cache.Get(ctx, key, &entity); err == nil {
+		return &entity, nil
+	}
+
+	loaded, err := r.repo.GetByIDCtx(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.cache.Set(ctx, key, loaded, r.ttl)
+	return loaded, nil
+}
+
+// Create creates the entity; nothing is cached until it is first read
+func (r *CachedRepository[T, K]) Create(entity *T) error {
+	return r.repo.Create(entity)
+}
+
+// Update saves the entity with primary key id and invalidates its cache entry
+func (r *CachedRepository[T, K]) Update(ctx context.Context, id K, entity *T) error {
+	return r.invalidating(ctx, id, func() error { return r.repo.UpdateCtx(ctx, entity) })
+}
+
+// Delete deletes the entity with primary key id and invalidates its cache entry
+func (r *CachedRepository[T, K]) Delete(ctx context.Context, id K) error {
+	return r.invalidating(ctx, id, func() error { return r.repo.DeleteCtx(ctx, id) })
+}
+
+// invalidating runs write between deletes of the entity's cache entry
+func (r *CachedRepository[T, K]) invalidating(ctx context.Context, id K, write func() error) error {
+	key := r.CacheKey(id)
+
+	_ = r.cache.Delete(ctx, key)
+	err := write()
+	_ = r.cache.Delete(ctx, key)
+
+	if r.doubleDeleteDelay > 0 {
+		// The request context may be done by then
+		time.AfterFunc(r.doubleDeleteDelay, func() {
+			_ = r.cache.Delete(context.Background(), key)
+		})
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/alicebob/miniredis/v2"
+	"gorm.io/gorm"
+)
+
+type Widget struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+func newCachedRepository(t *testing.T) (*database.CachedRepository[Widget, uint], *sharedtesting.TestDB, *miniredis.Miniredis) {
+	t.Helper()
+
+	testDB := sharedtesting.NewTestDB(t)
+	t.Cleanup(testDB.Cleanup)
+	if err := testDB.Migrate(&Widget{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	client, server := sharedtesting.NewTestCache(t)
+
+	repo := database.NewRepository[Widget](testDB.DB)
+	return database.NewCachedRepository(repo.KeyedRepository, client, time.Minute), testDB, server
+}
+
+func TestCachedRepository(t *testing.T) {
+	repo, _, server := newCachedRepository(t)
+	ctx := context.Background()
+
+	widget := &Widget{Name: "original"}
+	if err := repo.Create(widget); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, widget.ID); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if !server.Exists(repo.CacheKey(widget.ID)) || repo.CacheKey(widget.ID) != "widget:1" {
+		t.Fatalf("expected the widget to be cached under %q", repo.CacheKey(widget.ID))
+	}
+
+	widget.Name = "updated"
+	if err := repo.Update(ctx, widget.ID, widget); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if server.Exists(repo.CacheKey(widget.ID)) {
+		t.Error("expected Update to invalidate the cache entry")
+	}
+	if got, err := repo.GetByID(ctx, widget.ID); err != nil || got.Name != "updated" {
+		t.Errorf("GetByID() = %+v, %v, want the updated widget", got, err)
+	}
+
+	if err := repo.Delete(ctx, widget.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, widget.ID); err == nil {
+		t.Error("expected GetByID to miss after Delete")
+	}
+}
+
+func TestCachedRepository_ReadWriteRace(t *testing.T) {
+	tests := []struct {
+		name      string
+		delay     time.Duration
+		wantStale bool
+	}{
+		{name: "without double delete the racing read sticks", wantStale: true},
+		{name: "double delete removes the racing read", delay: 20 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, testDB, _ := newCachedRepository(t)
+			if tt.delay > 0 {
+				repo = repo.WithDoubleDelete(tt.delay)
+			}
+			ctx := context.Background()
+
+			widget := &Widget{Name: "v1"}
+			if err := repo.Create(widget); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			// The write lands after the read loaded v1 but before it is cached
+			raced := false
+			err := testDB.Callback().Query().After("gorm:query").Register("test:race", func(tx *gorm.DB) {
+				if raced {
+					return
+				}
+				raced = true
+				if err := repo.Update(ctx, widget.ID, &Widget{ID: widget.ID, Name: "v2"}); err != nil {
+					t.Errorf("Update() error = %v", err)
+				}
+			})
+			if err != nil {
+				t.Fatalf("failed to register callback: %v", err)
+			}
+
+			if got, err := repo.GetByID(ctx, widget.ID); err != nil || got.Name != "v1" {
+				t.Fatalf("racing GetByID() = %+v, %v, want v1", got, err)
+			}
+
+			time.Sleep(3 * tt.delay)
+			got, err := repo.GetByID(ctx, widget.ID)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if stale := got.Name == "v1"; stale != tt.wantStale {
+				t.Errorf("GetByID() after the race = %q, want stale %v", got.Name, tt.wantStale)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"gorm.io/gorm"
+)
+
+func TestConnect(t *testing.T) {
+	errRefused := stderrors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		attempts   int
+		backoff    int
+		failures   int
+		wantErr    bool
+		wantSleeps []time.Duration
+	}{
+		{name: "fails fast without retries", failures: 1, wantErr: true},
+		{name: "first attempt succeeds", attempts: 3},
+		{name: "succeeds after retries", attempts: 5, backoff: 2, failures: 3, wantSleeps: []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}},
+		{name: "gives up", attempts: 6, backoff: 2, failures: 10, wantErr: true,
+			wantSleeps: []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}},
+		{name: "unset backoff uses the minimum", attempts: 2, failures: 2, wantSleeps: []time.Duration{time.Second, 2 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, logs := logger.NewTest()
+
+			calls := 0
+			open := func() (*gorm.DB, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, errRefused
+				}
+				return &gorm.DB{}, nil
+			}
+			var sleeps []time.Duration
+			sleep := func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			cfg := &config.DatabaseConfig{RetryAttempts: tt.attempts, RetryBackoff: tt.backoff}
+			db, err := connect(cfg, log, open, sleep)
+
+			if tt.wantErr {
+				if !stderrors.Is(err, errRefused) {
+					t.Errorf("connect() error = %v, want the last connection error", err)
+				}
+			} else if err != nil || db == nil {
+				t.Errorf("connect() = %v, %v, want a connection", db, err)
+			}
+			if !reflect.DeepEqual(sleeps, tt.wantSleeps) {
+				t.Errorf("backoff = %v, want %v", sleeps, tt.wantSleeps)
+			}
+			if logs.Len() != len(tt.wantSleeps) {
+				t.Errorf("logged %d retries, want %d", logs.Len(), len(tt.wantSleeps))
+			}
+		})
+	}
+}
+
+func TestNewQueryLogger(t *testing.T) {
+	log, _ := logger.NewTest()
+
+	tests := []struct {
+		thresholdMs int
+		want        time.Duration
+	}{
+		{0, DefaultSlowQueryThreshold},
+		{250, 250 * time.Millisecond},
+		{-1, 0},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.DatabaseConfig{SlowQueryThresholdMs: tt.thresholdMs}
+		if got := newQueryLogger(cfg, log).SlowThreshold; got != tt.want {
+			t.Errorf("SlowQueryThresholdMs %d: threshold = %v, want %v", tt.thresholdMs, got, tt.want)
+		}
+	}
+}
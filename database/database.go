@@ -2,15 +2,18 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"reflect"
 	"time"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 // DB holds the database connection
@@ -18,8 +21,14 @@ type DB struct {
 	*gorm.DB
 }
 
-// New creates a new database connection
+// New creates a new database connection logging to logger.Default()
 func New(cfg *config.DatabaseConfig) (*DB, error) {
+	return NewWithLogger(cfg, logger.Default())
+}
+
+// NewWithLogger creates a new database connection whose queries and connection
+// retries are logged to log through a GormLogger
+func NewWithLogger(cfg *config.DatabaseConfig, log *logger.Logger) (*DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host,
@@ -31,20 +40,15 @@ func New(cfg *config.DatabaseConfig) (*DB, error) {
 	)
 
 	gormConfig := &gorm.Config{
-		Logger: logger.New(
-			log.New(os.Stdout, "\r\n", log.LstdFlags),
-			logger.Config{
-				SlowThreshold:             time.Second,
-				LogLevel:                  logger.Warn,
-				IgnoreRecordNotFoundError: true,
-				Colorful:                  false,
-			},
-		),
-	}
-
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+		Logger: newQueryLogger(cfg, log),
+	}
+
+	// gorm.Open pings the database, so a server that isn't up yet fails here
+	db, err := connect(cfg, log, func() (*gorm.DB, error) {
+		return gorm.Open(postgres.Open(dsn), gormConfig)
+	}, time.Sleep)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
 	sqlDB, err := db.DB()
@@ -60,6 +64,49 @@ func New(cfg *config.DatabaseConfig) (*DB, error) {
 	return &DB{DB: db}, nil
 }
 
+// newQueryLogger creates the GORM logger for cfg's slow query threshold
+func newQueryLogger(cfg *config.DatabaseConfig, log *logger.Logger) *GormLogger {
+	gormLogger := NewGormLogger(log)
+	if cfg.SlowQueryThresholdMs != 0 {
+		gormLogger.SlowThreshold = max(time.Duration(cfg.SlowQueryThresholdMs)*time.Millisecond, 0)
+	}
+	return gormLogger
+}
+
+// Bounds of the delay between connection attempts
+const (
+	minRetryBackoff = time.Second
+	maxRetryBackoff = 30 * time.Second
+)
+
+// connect calls open until it succeeds, retrying up to cfg.RetryAttempts times
+// with exponential backoff starting at cfg.RetryBackoff seconds (at least 1s, so
+// an unset backoff doesn't retry in a tight loop)
+func connect(cfg *config.DatabaseConfig, log *logger.Logger, open func() (*gorm.DB, error), sleep func(time.Duration)) (*gorm.DB, error) {
+	backoff := max(time.Duration(cfg.RetryBackoff)*time.Second, minRetryBackoff)
+	for attempt := 1; ; attempt++ {
+		db, err := open()
+		if err == nil {
+			return db, nil
+		}
+		if attempt > cfg.RetryAttempts {
+			if cfg.RetryAttempts > 0 {
+				return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
+			}
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+
+		log.Warn("Database connection failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", cfg.RetryAttempts+1),
+			zap.Duration("retry_in", backoff),
+			zap.Error(err),
+		)
+		sleep(backoff)
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
 // Health checks the database connection health
 func (db *DB) Health() error {
 	sqlDB, err := db.DB.DB()
@@ -112,25 +159,51 @@ func (db *DB) Migrate(models ...interface{}) error {
 	return db.AutoMigrate(models...)
 }
 
-// Repository provides generic repository operations
-type Repository[T any] struct {
+// Key constrains the primary key types a KeyedRepository can look records up by
+type Key interface {
+	~uint | ~uint32 | ~uint64 | ~int | ~int64 | ~string
+}
+
+// KeyedRepository provides generic repository operations for models whose primary key
+// is of type K, e.g. KeyedRepository[Document, string] for models embedding ULIDModel
+type KeyedRepository[T any, K Key] struct {
 	db *DB
 }
 
+// NewKeyedRepository creates a new repository for the given type and primary key type
+func NewKeyedRepository[T any, K Key](db *DB) *KeyedRepository[T, K] {
+	return &KeyedRepository[T, K]{db: db}
+}
+
+// Repository provides generic repository operations for models keyed by a uint ID
+type Repository[T any] struct {
+	*KeyedRepository[T, uint]
+}
+
 // NewRepository creates a new repository for the given type
 func NewRepository[T any](db *DB) *Repository[T] {
-	return &Repository[T]{db: db}
+	return &Repository[T]{KeyedRepository: NewKeyedRepository[T, uint](db)}
 }
 
 // Create creates a new record
-func (r *Repository[T]) Create(entity *T) error {
-	return r.db.Create(entity).Error
+func (r *KeyedRepository[T, K]) Create(entity *T) error {
+	return r.CreateCtx(context.Background(), entity)
+}
+
+// CreateCtx creates a new record, bound to ctx
+func (r *KeyedRepository[T, K]) CreateCtx(ctx context.Context, entity *T) error {
+	return r.translateWriteError(r.db.WithContext(ctx).Create(entity).Error)
+}
+
+// GetByID retrieves a record by primary key
+func (r *KeyedRepository[T, K]) GetByID(id K) (*T, error) {
+	return r.GetByIDCtx(context.Background(), id)
 }
 
-// GetByID retrieves a record by ID
-func (r *Repository[T]) GetByID(id uint) (*T, error) {
+// GetByIDCtx retrieves a record by primary key, bound to ctx
+func (r *KeyedRepository[T, K]) GetByIDCtx(ctx context.Context, id K) (*T, error) {
 	var entity T
-	err := r.db.First(&entity, id).Error
+	err := r.db.WithContext(ctx).Where(primaryKeyEquals(id)).First(&entity).Error
 	if err != nil {
 		return nil, err
 	}
@@ -138,44 +211,149 @@ func (r *Repository[T]) GetByID(id uint) (*T, error) {
 }
 
 // Update updates a record
-func (r *Repository[T]) Update(entity *T) error {
-	return r.db.Save(entity).Error
+func (r *KeyedRepository[T, K]) Update(entity *T) error {
+	return r.UpdateCtx(context.Background(), entity)
 }
 
-// Delete deletes a record by ID
-func (r *Repository[T]) Delete(id uint) error {
+// UpdateCtx updates a record, bound to ctx
+func (r *KeyedRepository[T, K]) UpdateCtx(ctx context.Context, entity *T) error {
+	return r.translateWriteError(r.db.WithContext(ctx).Save(entity).Error)
+}
+
+// Delete deletes a record by primary key
+func (r *KeyedRepository[T, K]) Delete(id K) error {
+	return r.DeleteCtx(context.Background(), id)
+}
+
+// DeleteCtx deletes a record by primary key, bound to ctx
+func (r *KeyedRepository[T, K]) DeleteCtx(ctx context.Context, id K) error {
 	var entity T
-	return r.db.Delete(&entity, id).Error
+	return r.translateWriteError(r.db.WithContext(ctx).Where(primaryKeyEquals(id)).Delete(&entity).Error)
 }
 
 // List retrieves records with pagination
-func (r *Repository[T]) List(offset, limit int) ([]T, error) {
+func (r *KeyedRepository[T, K]) List(offset, limit int) ([]T, error) {
+	return r.ListCtx(context.Background(), offset, limit)
+}
+
+// ListCtx retrieves records with pagination, bound to ctx
+func (r *KeyedRepository[T, K]) ListCtx(ctx context.Context, offset, limit int) ([]T, error) {
 	var entities []T
-	err := r.db.Offset(offset).Limit(limit).Find(&entities).Error
+	err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Find(&entities).Error
 	return entities, err
 }
 
 // Count returns the total count of records
-func (r *Repository[T]) Count() (int64, error) {
+func (r *KeyedRepository[T, K]) Count() (int64, error) {
+	return r.CountCtx(context.Background())
+}
+
+// CountCtx returns the total count of records, bound to ctx
+func (r *KeyedRepository[T, K]) CountCtx(ctx context.Context) (int64, error) {
 	var count int64
 	var entity T
-	err := r.db.Model(&entity).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&entity).Count(&count).Error
 	return count, err
 }
 
 // FindWhere finds records matching the given condition
-func (r *Repository[T]) FindWhere(condition string, args ...interface{}) ([]T, error) {
+func (r *KeyedRepository[T, K]) FindWhere(condition string, args ...interface{}) ([]T, error) {
+	return r.FindWhereCtx(context.Background(), condition, args...)
+}
+
+// FindWhereCtx finds records matching the given condition, bound to ctx
+func (r *KeyedRepository[T, K]) FindWhereCtx(ctx context.Context, condition string, args ...interface{}) ([]T, error) {
 	var entities []T
-	err := r.db.Where(condition, args...).Find(&entities).Error
+	err := r.db.WithContext(ctx).Where(condition, args...).Find(&entities).Error
 	return entities, err
 }
 
 // FirstWhere finds the first record matching the given condition
-func (r *Repository[T]) FirstWhere(condition string, args ...interface{}) (*T, error) {
+func (r *KeyedRepository[T, K]) FirstWhere(condition string, args ...interface{}) (*T, error) {
+	return r.FirstWhereCtx(context.Background(), condition, args...)
+}
+
+// FirstWhereCtx finds the first record matching the given condition, bound to ctx
+func (r *KeyedRepository[T, K]) FirstWhereCtx(ctx context.Context, condition string, args ...interface{}) (*T, error) {
 	var entity T
-	err := r.db.Where(condition, args...).First(&entity).Error
+	err := r.db.WithContext(ctx).Where(condition, args...).First(&entity).Error
 	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
-}
\ No newline at end of file
+}
+
+// Exists reports whether any record matches the given condition (any record when the
+// condition is empty). It selects a constant with LIMIT 1 instead of counting or loading rows.
+func (r *KeyedRepository[T, K]) Exists(condition string, args ...interface{}) (bool, error) {
+	return r.ExistsCtx(context.Background(), condition, args...)
+}
+
+// ExistsCtx is Exists bound to ctx
+func (r *KeyedRepository[T, K]) ExistsCtx(ctx context.Context, condition string, args ...interface{}) (bool, error) {
+	var entity T
+	var found int
+
+	query := r.db.WithContext(ctx).Model(&entity).Select("1")
+	if condition != "" {
+		query = query.Where(condition, args...)
+	}
+
+	result := query.Limit(1).Scan(&found)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Pluck fetches a single column of the records matching the condition into a typed slice,
+// e.g. Pluck[models.User, uint](repo, "id", "status = ?", models.StatusActive).
+// An empty condition plucks the column of all records. The column name must not come
+// from user input.
+func Pluck[T any, V any](r *Repository[T], column string, condition string, args ...interface{}) ([]V, error) {
+	var entity T
+	values := make([]V, 0)
+
+	query := r.db.Model(&entity)
+	if condition != "" {
+		query = query.Where(condition, args...)
+	}
+
+	if err := query.Pluck(column, &values).Error; err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// primaryKeyEquals matches the model's primary key column. Passing string keys to
+// First or Delete directly would make GORM treat them as raw SQL.
+func primaryKeyEquals(id interface{}) clause.Eq {
+	return clause.Eq{Column: clause.PrimaryColumn, Value: id}
+}
+
+// auditColumns are set on create and never rewritten by an update
+var auditColumns = []string{"created_at", "created_by"}
+
+// primaryKeyOf returns the entity's primary key, rejecting a zero key: an update
+// without one would match every row its other conditions allow
+func primaryKeyOf(ctx context.Context, s *schema.Schema, entity interface{}) (interface{}, error) {
+	field := s.PrioritizedPrimaryField
+	if field == nil {
+		return nil, fmt.Errorf("%s has no primary key", s.Name)
+	}
+
+	id, zero := field.ValueOf(ctx, reflect.ValueOf(entity))
+	if zero {
+		return nil, fmt.Errorf("%s has no primary key value", s.Name)
+	}
+	return id, nil
+}
+
+// translateWriteError maps constraint violations to structured errors (see TranslateError)
+func (r *KeyedRepository[T, K]) translateWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return translateError(translateDialectError(r.db.DB, err), resourceName[T]())
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Postgres error codes for constraint violations
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+)
+
+// pgKeyColumns extracts the column list from a Postgres error detail such as
+// "Key (workspace_id, slug)=(1, tasks) already exists."
+var pgKeyColumns = regexp.MustCompile(`^Key \(([^)]+)\)`)
+
+// TranslateError maps database constraint violations to structured errors so they
+// don't surface to clients as 500s:
+//   - unique violation (23505) -> ALREADY_EXISTS (409)
+//   - foreign key violation (23503) -> INVALID_REFERENCE (400), or CONFLICT (409)
+//     when the row is still referenced by others
+//   - not null violation (23502) -> MISSING_FIELD (400)
+//
+// The constraint, table and column involved are added to the error details.
+// Errors that aren't constraint violations are returned unchanged.
+func TranslateError(err error) error {
+	return translateError(err, "")
+}
+
+// translateError is TranslateError with an explicit resource name for messages
+func translateError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) {
+		return translatePgError(pgErr, resource)
+	}
+
+	switch {
+	case stderrors.Is(err, gorm.ErrDuplicatedKey):
+		return errors.NewAlreadyExistsError(resourceOrDefault(resource)).WithCause(err)
+	case stderrors.Is(err, gorm.ErrForeignKeyViolated):
+		return errors.NewInvalidReferenceError("unknown").WithCause(err)
+	}
+
+	return err
+}
+
+// translatePgError builds the structured error for a Postgres constraint violation
+func translatePgError(pgErr *pgconn.PgError, resource string) error {
+	if resource == "" {
+		resource = pgErr.TableName
+	}
+
+	details := map[string]interface{}{}
+	if pgErr.ConstraintName != "" {
+		details["constraint"] = pgErr.ConstraintName
+	}
+	if pgErr.TableName != "" {
+		details["table"] = pgErr.TableName
+	}
+
+	column := pgErr.ColumnName
+	if column == "" {
+		if match := pgKeyColumns.FindStringSubmatch(pgErr.Detail); match != nil {
+			column = match[1]
+		}
+	}
+
+	var appErr *errors.Error
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		appErr = errors.NewAlreadyExistsError(resourceOrDefault(resource))
+		if column != "" {
+			details["fields"] = strings.Split(column, ", ")
+		}
+	case pgForeignKeyViolation:
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			appErr = errors.NewConflictError(fmt.Sprintf("%s is still referenced by other records", resourceOrDefault(resource)))
+		} else {
+			appErr = errors.NewInvalidReferenceError(column)
+		}
+	case pgNotNullViolation:
+		appErr = errors.NewMissingFieldError(column)
+	default:
+		return pgErr
+	}
+
+	for key, value := range appErr.Details {
+		details[key] = value
+	}
+
+	return appErr.WithDetails(details).WithCause(pgErr)
+}
+
+// resourceOrDefault returns a generic resource name when none is known
+func resourceOrDefault(resource string) string {
+	if resource == "" {
+		return "Resource"
+	}
+	return resource
+}
+
+// translateDialectError converts driver-specific errors (e.g. SQLite) into gorm's
+// sentinel errors using the dialector, keeping the original error message
+func translateDialectError(db *gorm.DB, err error) error {
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) {
+		return err
+	}
+
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	if !ok {
+		return err
+	}
+
+	if translated := translator.Translate(err); translated != err {
+		return fmt.Errorf("%w: %v", translated, err)
+	}
+
+	return err
+}
+
+// resourceName returns the model type name used in error messages
+func resourceName[T any]() string {
+	var entity T
+	return reflect.TypeOf(entity).Name()
+}
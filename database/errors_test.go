@@ -0,0 +1,135 @@
+package database_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError_Postgres(t *testing.T) {
+	tests := []struct {
+		name        string
+		pgErr       *pgconn.PgError
+		wantCode    string
+		wantHTTP    int
+		wantDetails map[string]interface{}
+	}{
+		{
+			name: "unique violation",
+			pgErr: &pgconn.PgError{
+				Code:           "23505",
+				TableName:      "tables",
+				ConstraintName: "idx_table_workspace_slug",
+				Detail:         "Key (workspace_id, slug)=(1, tasks) already exists.",
+			},
+			wantCode:    errors.ErrCodeAlreadyExists,
+			wantHTTP:    http.StatusConflict,
+			wantDetails: map[string]interface{}{"constraint": "idx_table_workspace_slug", "table": "tables"},
+		},
+		{
+			name: "foreign key violation on insert",
+			pgErr: &pgconn.PgError{
+				Code:           "23503",
+				TableName:      "fields",
+				ConstraintName: "fk_tables_fields",
+				Detail:         `Key (table_id)=(99) is not present in table "tables".`,
+			},
+			wantCode:    errors.ErrCodeInvalidReference,
+			wantHTTP:    http.StatusBadRequest,
+			wantDetails: map[string]interface{}{"constraint": "fk_tables_fields", "field": "table_id"},
+		},
+		{
+			name: "foreign key violation on delete",
+			pgErr: &pgconn.PgError{
+				Code:           "23503",
+				TableName:      "fields",
+				ConstraintName: "fk_tables_fields",
+				Detail:         `Key (id)=(1) is still referenced from table "fields".`,
+			},
+			wantCode: errors.ErrCodeConflict,
+			wantHTTP: http.StatusConflict,
+		},
+		{
+			name: "not null violation",
+			pgErr: &pgconn.PgError{
+				Code:       "23502",
+				TableName:  "users",
+				ColumnName: "email",
+			},
+			wantCode:    errors.ErrCodeMissingField,
+			wantHTTP:    http.StatusBadRequest,
+			wantDetails: map[string]interface{}{"field": "email", "table": "users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := database.TranslateError(fmt.Errorf("insert failed: %w", tt.pgErr))
+
+			appErr, ok := err.(*errors.Error)
+			if !ok {
+				t.Fatalf("expected *errors.Error, got %T (%v)", err, err)
+			}
+			if appErr.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", appErr.Code, tt.wantCode)
+			}
+			if appErr.HTTPCode != tt.wantHTTP {
+				t.Errorf("HTTPCode = %d, want %d", appErr.HTTPCode, tt.wantHTTP)
+			}
+			for key, want := range tt.wantDetails {
+				if got := appErr.Details[key]; got != want {
+					t.Errorf("Details[%q] = %v, want %v", key, got, want)
+				}
+			}
+			if appErr.Cause != tt.pgErr {
+				t.Errorf("expected original Postgres error as cause, got %v", appErr.Cause)
+			}
+		})
+	}
+}
+
+func TestTranslateError_PassThrough(t *testing.T) {
+	if err := database.TranslateError(nil); err != nil {
+		t.Errorf("TranslateError(nil) = %v, want nil", err)
+	}
+
+	other := fmt.Errorf("connection refused")
+	if err := database.TranslateError(other); err != other {
+		t.Errorf("TranslateError() = %v, want unchanged error", err)
+	}
+
+	syntax := &pgconn.PgError{Code: "42601", Message: "syntax error"}
+	if err := database.TranslateError(syntax); err != syntax {
+		t.Errorf("TranslateError() = %v, want unchanged Postgres error", err)
+	}
+}
+
+func TestRepository_CreateUniqueViolation(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	repo := database.NewRepository[models.User](testDB.DB)
+	fixtures := sharedtesting.NewTestFixtures()
+
+	if err := repo.Create(fixtures.CreateTestUser(func(u *models.User) { u.ID = 0 })); err != nil {
+		t.Fatalf("first Create() error = %v", err)
+	}
+
+	err := repo.Create(fixtures.CreateTestUser(func(u *models.User) { u.ID = 0 }))
+	if !errors.Is(err, errors.ErrCodeAlreadyExists) {
+		t.Fatalf("expected AlreadyExists error, got %v", err)
+	}
+	if code := errors.GetHTTPCode(err); code != http.StatusConflict {
+		t.Errorf("expected HTTP 409, got %d", code)
+	}
+}
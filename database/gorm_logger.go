@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DefaultSlowQueryThreshold is the duration above which queries are logged as slow
+const DefaultSlowQueryThreshold = time.Second
+
+// GormLogger implements GORM's logger.Interface on top of logger.Logger, so query
+// logs are structured and carry the request ID and user of the query's context.
+// Failed queries are logged at Error, slow ones at Warn and every other query at
+// Debug, so the zap level decides what is written; LogMode can still silence
+// GORM below a level.
+type GormLogger struct {
+	log                       *logger.Logger
+	level                     gormlogger.LogLevel
+	SlowThreshold             time.Duration // 0 disables slow query logging
+	IgnoreRecordNotFoundError bool
+}
+
+// NewGormLogger creates a GORM logger passing every query to log at GORM's Info
+// level, with the DefaultSlowQueryThreshold and record-not-found errors ignored
+func NewGormLogger(log *logger.Logger) *GormLogger {
+	return &GormLogger{
+		log:                       log,
+		level:                     gormlogger.Info,
+		SlowThreshold:             DefaultSlowQueryThreshold,
+		IgnoreRecordNotFoundError: true,
+	}
+}
+
+// LogMode returns a copy of the logger at level
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs a GORM info message
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.log.WithContext(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn logs a GORM warning
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.log.WithContext(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error logs a GORM error
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.log.WithContext(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs a finished query with its SQL, duration and affected rows (-1 when
+// unknown)
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !(l.IgnoreRecordNotFoundError && stderrors.Is(err, gorm.ErrRecordNotFound))
+	slow := l.SlowThreshold > 0 && elapsed > l.SlowThreshold
+
+	switch {
+	case failed && l.level >= gormlogger.Error:
+		l.log.WithContext(ctx).Error("Database query failed", append(queryFields(fc, elapsed), zap.Error(err))...)
+	case slow && l.level >= gormlogger.Warn:
+		l.log.WithContext(ctx).Warn("Slow database query",
+			append(queryFields(fc, elapsed), zap.Int64("threshold_ms", l.SlowThreshold.Milliseconds()))...)
+	case l.level >= gormlogger.Info && l.log.Core().Enabled(zap.DebugLevel):
+		l.log.WithContext(ctx).Debug("Database query", queryFields(fc, elapsed)...)
+	}
+}
+
+// queryFields returns the fields logged for every query, named as in
+// logger.LogDatabaseQuery
+func queryFields(fc func() (string, int64), elapsed time.Duration) []zap.Field {
+	sql, rows := fc()
+	return []zap.Field{
+		zap.String("query", sql),
+		zap.Int64("duration_ms", elapsed.Milliseconds()),
+		zap.Int64("affected_rows", rows),
+	}
+}
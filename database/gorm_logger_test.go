@@ -0,0 +1,112 @@
+package database_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func openLoggedDB(t *testing.T, gormLogger gormlogger.Interface) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.Session(&gorm.Session{Logger: gormlogger.Discard}).AutoMigrate(&Widget{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestGormLogger(t *testing.T) {
+	log, logs := logger.NewTest()
+	db := openLoggedDB(t, database.NewGormLogger(log))
+	ctx := logger.ContextWithRequestID(context.Background(), "req_123")
+
+	if err := db.WithContext(ctx).Create(&Widget{Name: "first"}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != zapcore.DebugLevel || entry.Message != "Database query" {
+		t.Errorf("entry = %s %q, want a debug database query", entry.Level, entry.Message)
+	}
+
+	fields := entry.ContextMap()
+	if fields["request_id"] != "req_123" {
+		t.Errorf("request_id = %v, want req_123", fields["request_id"])
+	}
+	if query, _ := fields["query"].(string); !strings.HasPrefix(query, "INSERT INTO `widgets`") {
+		t.Errorf("query = %v, want the insert", fields["query"])
+	}
+	if fields["affected_rows"] != int64(1) {
+		t.Errorf("affected_rows = %v, want 1", fields["affected_rows"])
+	}
+	if _, ok := fields["duration_ms"].(int64); !ok {
+		t.Errorf("duration_ms = %v, want an integer", fields["duration_ms"])
+	}
+}
+
+func TestGormLogger_Levels(t *testing.T) {
+	log, logs := logger.NewTest()
+	if err := log.SetLevel("info"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	gormLogger := database.NewGormLogger(log)
+	db := openLoggedDB(t, gormLogger)
+
+	// With zap at Info fast queries and missing records aren't logged
+	var widget Widget
+	_ = db.First(&widget, 42).Error
+	if logs.Len() != 0 {
+		t.Fatalf("got %d entries at Info for a fast query, want none", logs.Len())
+	}
+
+	if err := db.Exec("SELECT * FROM missing_table").Error; err == nil {
+		t.Fatal("expected the query to fail")
+	}
+	failed := logs.TakeAll()
+	if len(failed) != 1 || failed[0].Level != zapcore.ErrorLevel || failed[0].ContextMap()["error"] == nil {
+		t.Errorf("entries = %+v, want one error entry for the failed query", failed)
+	}
+
+	// Every query counts as slow with a tiny threshold
+	gormLogger.SlowThreshold = time.Nanosecond
+	if err := db.Find(&[]Widget{}).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	slow := logs.TakeAll()
+	if len(slow) != 1 || slow[0].Level != zapcore.WarnLevel || slow[0].Message != "Slow database query" {
+		t.Errorf("entries = %+v, want one slow query warning", slow)
+	}
+
+	// Lowering GORM's level still silences it
+	gormLogger.SlowThreshold = 0
+	if err := log.SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	warnOnly := openLoggedDB(t, gormLogger.LogMode(gormlogger.Warn))
+	_ = warnOnly.Find(&[]Widget{}).Error
+	if logs.Len() != 0 {
+		t.Errorf("got %d entries for a fast query at LogMode(Warn), want none", logs.Len())
+	}
+
+	quiet := openLoggedDB(t, gormLogger.LogMode(gormlogger.Silent))
+	_ = quiet.Exec("SELECT * FROM missing_table").Error
+	if logs.Len() != 0 {
+		t.Errorf("got %d entries in Silent mode, want none", logs.Len())
+	}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"gorm.io/gorm"
+)
+
+// compositeUniqueIndexes lists the multi-column unique indexes declared on shared models
+var compositeUniqueIndexes = []struct {
+	model interface{}
+	name  string
+}{
+	{&models.WorkspaceMember{}, "idx_workspace_member_user"},
+	{&models.Table{}, "idx_table_workspace_slug"},
+	{&models.Field{}, "idx_field_table_name"},
+}
+
+// MigrateCompositeIndexes creates the composite unique indexes (workspace member,
+// table slug per workspace, field name per table) on existing tables that predate them.
+// Duplicate rows must be cleaned up beforehand or index creation fails.
+func (db *DB) MigrateCompositeIndexes() error {
+	migrator := db.Migrator()
+
+	for _, index := range compositeUniqueIndexes {
+		if migrator.HasIndex(index.model, index.name) {
+			continue
+		}
+		if err := migrator.CreateIndex(index.model, index.name); err != nil {
+			return fmt.Errorf("failed to create index %s: %w", index.name, err)
+		}
+	}
+
+	return nil
+}
+
+// softDeleteWhere limits unique indexes to rows that aren't soft deleted
+const softDeleteWhere = "deleted_at IS NULL"
+
+// softDeleteUniqueIndexes lists the unique indexes on shared soft-deleted models.
+// They only cover live rows, so a value held by a soft-deleted row can be reused.
+var softDeleteUniqueIndexes = []struct {
+	model   interface{}
+	name    string
+	columns []string
+}{
+	{&models.User{}, "idx_users_email", []string{"email"}},
+	{&models.Tenant{}, "idx_tenants_slug", []string{"slug"}},
+	{&models.Workspace{}, "idx_workspace_tenant_slug", []string{"tenant_id", "slug"}},
+	{&models.Table{}, "idx_table_workspace_slug", []string{"workspace_id", "slug"}},
+}
+
+// MigrateSoftDeleteUniqueIndexes creates the partial unique indexes (user email,
+// tenant slug, workspace slug per tenant, table slug per workspace) that ignore
+// soft-deleted rows, replacing full unique indexes created by older versions.
+// Run it after AutoMigrate.
+func (db *DB) MigrateSoftDeleteUniqueIndexes() error {
+	for _, index := range softDeleteUniqueIndexes {
+		if err := db.CreateSoftDeleteUniqueIndex(index.model, index.name, index.columns...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSoftDeleteUniqueIndex creates a unique index on columns of model's table
+// limited to rows where deleted_at IS NULL, so creating a record with a value held
+// only by a soft-deleted row succeeds. An existing full unique index with the same
+// name is replaced. Partial indexes are supported by Postgres and SQLite.
+func (db *DB) CreateSoftDeleteUniqueIndex(model interface{}, name string, columns ...string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("index %s needs at least one column", name)
+	}
+
+	stmt := &gorm.Statement{DB: db.DB}
+	if err := stmt.Parse(model); err != nil {
+		return fmt.Errorf("failed to parse model for index %s: %w", name, err)
+	}
+
+	definition, err := db.indexDefinition(name)
+	if err != nil {
+		return fmt.Errorf("failed to inspect index %s: %w", name, err)
+	}
+	if strings.Contains(strings.ToLower(definition), strings.ToLower(softDeleteWhere)) {
+		return nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = stmt.Quote(column)
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if definition != "" {
+			if err := tx.Exec("DROP INDEX " + stmt.Quote(name)).Error; err != nil {
+				return fmt.Errorf("failed to drop index %s: %w", name, err)
+			}
+		}
+
+		sql := fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s) WHERE %s",
+			stmt.Quote(name), stmt.Quote(stmt.Schema.Table), strings.Join(quoted, ", "), softDeleteWhere)
+		if err := tx.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create index %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// indexDefinition returns the CREATE INDEX statement of the named index, or an
+// empty string when it doesn't exist
+func (db *DB) indexDefinition(name string) (string, error) {
+	var query string
+	switch db.Dialector.Name() {
+	case "postgres":
+		query = "SELECT indexdef FROM pg_indexes WHERE schemaname = current_schema() AND indexname = ?"
+	case "sqlite":
+		query = "SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?"
+	default:
+		return "", fmt.Errorf("partial indexes are not supported by %s", db.Dialector.Name())
+	}
+
+	var definitions []string
+	if err := db.DB.Raw(query, name).Scan(&definitions).Error; err != nil {
+		return "", err
+	}
+	if len(definitions) == 0 {
+		return "", nil
+	}
+	return definitions[0], nil
+}
+
+// hashedTokenModels lists the models whose token column stores the SHA-256 hash
+// of the token sent to the user
+var hashedTokenModels = []interface{}{
+	&models.PasswordResetToken{},
+	&models.EmailVerificationToken{},
+	&models.WorkspaceInvitation{},
+}
+
+// MigrateTokenHashes replaces the raw reset, email verification and invitation
+// tokens stored by older versions with their SHA-256 hash, so links already sent
+// keep working. Rows are picked by their token_hashed flag, which AutoMigrate adds
+// as false to existing rows and the model constructors set, never by the format of
+// the token, so it is safe to run more than once. Run it after AutoMigrate.
+func (db *DB) MigrateTokenHashes() error {
+	for _, model := range hashedTokenModels {
+		var rows []struct {
+			ID    uint
+			Token string
+		}
+		err := db.Unscoped().Model(model).Select("id", "token").Where("token_hashed = ?", false).
+			FindInBatches(&rows, 500, func(tx *gorm.DB, batch int) error {
+				for _, row := range rows {
+					err := db.Unscoped().Model(model).Where("id = ? AND token_hashed = ?", row.ID, false).
+						UpdateColumns(map[string]interface{}{
+							"token":        utils.HashSHA256(row.Token),
+							"token_hashed": true,
+						}).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to hash tokens of %T: %w", model, err)
+		}
+	}
+	return nil
+}
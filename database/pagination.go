@@ -0,0 +1,85 @@
+package database
+
+import (
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Paginate counts the records matched by db, fetches the page described by req,
+// maps each record with mapper (e.g. model to DTO) and assembles the PaginationResponse.
+// The db may already carry scopes such as Where clauses; they apply to both the count
+// and the page query. A page size above the configured maximum returns the 400 error
+// from req.Validate.
+func Paginate[T any, U any](db *gorm.DB, req *models.PaginationRequest, mapper func(T) U) (*models.PaginationResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var model T
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Model(&model).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count records: %w", err)
+	}
+
+	var entities []T
+	err := db.Session(&gorm.Session{}).
+		Model(&model).
+		Order(orderByColumn(req.GetSort(), req.GetOrder())).
+		Offset(req.GetOffset()).
+		Limit(req.GetPageSize()).
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+
+	items := make([]U, 0, len(entities))
+	for _, entity := range entities {
+		items = append(items, mapper(entity))
+	}
+
+	return models.NewPaginationResponse(items, req, total), nil
+}
+
+// Paginate returns the page of records described by req, restricted to the columns
+// in opts: its Filters must name opts.FilterFields and are applied with
+// models.ApplyFilters, its Search is matched against opts.SearchFields with
+// models.ApplySearch, and its Sort must name one of opts.SortFields (the default
+// sort needs no listing). A search without search fields is rejected, and
+// disallowed columns or invalid filters return a VALIDATION_FAILED error detailing
+// each one.
+func (r *KeyedRepository[T, K]) Paginate(req *models.PaginationRequest, opts models.ListOptions) (*models.PaginationResponse, error) {
+	var invalid models.ValidationErrors
+	query, err := models.ApplyFilters(r.db.DB, req.FilterRequests(), opts.FilterFields)
+	if err != nil && !stderrors.As(err, &invalid) {
+		return nil, err
+	}
+	if req.Sort != "" && !slices.Contains(opts.SortFields, req.Sort) {
+		invalid.Add("sort", fmt.Sprintf("cannot sort by %q", req.Sort))
+	}
+	if len(invalid) > 0 {
+		return nil, errors.NewValidationError("Invalid list query", invalid.Fields())
+	}
+
+	query, err = models.ApplySearch(query, req.Search, opts.SearchFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return Paginate(query, req, func(entity T) T { return entity })
+}
+
+// orderByColumn builds a quoted ORDER BY clause so sort fields can't inject SQL
+func orderByColumn(column, order string) clause.OrderByColumn {
+	return clause.OrderByColumn{
+		Column: clause.Column{Name: column},
+		Desc:   strings.EqualFold(order, "desc"),
+	}
+}
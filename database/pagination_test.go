@@ -0,0 +1,211 @@
+package database_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+type userDTO struct {
+	ID    uint
+	Email string
+	Name  string
+}
+
+func toUserDTO(u models.User) userDTO {
+	return userDTO{ID: u.ID, Email: u.Email, Name: u.GetFullName()}
+}
+
+func TestPaginate(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	fixtures := sharedtesting.NewTestFixtures()
+	for _, user := range fixtures.CreateMultipleUsers(5) {
+		if err := testDB.Create(user).Error; err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+
+	req := &models.PaginationRequest{Page: 2, PageSize: 2, Sort: "id", Order: "asc"}
+	resp, err := database.Paginate(testDB.DB.DB, req, toUserDTO)
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+
+	want := models.Pagination{Page: 2, PageSize: 2, Total: 5, TotalPages: 3, HasNext: true, HasPrev: true}
+	if resp.Pagination != want {
+		t.Errorf("Pagination = %+v, want %+v", resp.Pagination, want)
+	}
+
+	items, ok := resp.Data.([]userDTO)
+	if !ok {
+		t.Fatalf("expected []userDTO data, got %T", resp.Data)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	for i, item := range items {
+		id := uint(i + 3)
+		if item.ID != id || item.Email != fmt.Sprintf("user%d@example.com", id) {
+			t.Errorf("item %d = %+v, want user %d", i, item, id)
+		}
+		if item.Name != fmt.Sprintf("User%d User", id) {
+			t.Errorf("item %d name = %q, want mapped full name", i, item.Name)
+		}
+	}
+}
+
+func TestPaginate_ScopedQuery(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	fixtures := sharedtesting.NewTestFixtures()
+	for _, user := range fixtures.CreateMultipleUsers(4) {
+		if user.ID%2 == 0 {
+			user.Status = models.StatusInactive
+		}
+		if err := testDB.Create(user).Error; err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+
+	req := &models.PaginationRequest{Page: 1, PageSize: 10, Sort: "id", Order: "desc"}
+	scoped := testDB.DB.DB.Where("status = ?", models.StatusActive)
+	resp, err := database.Paginate(scoped, req, toUserDTO)
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+
+	if resp.Pagination.Total != 2 || resp.Pagination.HasNext {
+		t.Errorf("unexpected pagination for scoped query: %+v", resp.Pagination)
+	}
+	items := resp.Data.([]userDTO)
+	if len(items) != 2 || items[0].ID != 3 || items[1].ID != 1 {
+		t.Errorf("expected active users 3 and 1 in descending order, got %+v", items)
+	}
+}
+
+func TestPaginate_RejectsOversizedPage(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	req := &models.PaginationRequest{Page: 1, PageSize: models.DefaultMaxPageSize + 1}
+	_, err := database.Paginate(testDB.DB.DB, req, toUserDTO)
+	if !errors.Is(err, errors.ErrCodeInvalidInput) {
+		t.Errorf("expected invalid input error for oversized page, got %v", err)
+	}
+}
+
+func TestRepository_Paginate(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	fixtures := sharedtesting.NewTestFixtures()
+	for _, user := range fixtures.CreateMultipleUsers(12) {
+		if user.ID%3 == 0 {
+			user.Status = models.StatusInactive
+		}
+		if err := testDB.Create(user).Error; err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+	repo := database.NewRepository[models.User](testDB.DB)
+	opts := models.ListOptions{
+		SearchFields: []string{"email", "first_name"},
+		FilterFields: []string{"id", "status"},
+		SortFields:   []string{"id"},
+	}
+
+	tests := []struct {
+		name      string
+		req       models.PaginationRequest
+		wantTotal int64
+		wantIDs   []uint
+	}{
+		{
+			name:      "page of all records",
+			req:       models.PaginationRequest{Page: 2, PageSize: 5, Sort: "id", Order: "asc"},
+			wantTotal: 12,
+			wantIDs:   []uint{6, 7, 8, 9, 10},
+		},
+		{
+			name:      "equality filter",
+			req:       models.PaginationRequest{Page: 1, PageSize: 10, Sort: "id", Order: "asc", Filters: models.JSON{"status": "inactive"}},
+			wantTotal: 4,
+			wantIDs:   []uint{3, 6, 9, 12},
+		},
+		{
+			name: "operator filter and list filter",
+			req: models.PaginationRequest{Page: 1, PageSize: 10, Sort: "id", Order: "desc", Filters: models.JSON{
+				"id":     map[string]interface{}{"operator": "gt", "value": float64(8)},
+				"status": []interface{}{"active"},
+			}},
+			wantTotal: 2,
+			wantIDs:   []uint{11, 10},
+		},
+		{
+			name:      "search ignores case",
+			req:       models.PaginationRequest{Page: 1, PageSize: 10, Sort: "id", Order: "asc", Search: "USER1"},
+			wantTotal: 4,
+			wantIDs:   []uint{1, 10, 11, 12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := repo.Paginate(&tt.req, opts)
+			if err != nil {
+				t.Fatalf("Paginate() error = %v", err)
+			}
+			if resp.Pagination.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", resp.Pagination.Total, tt.wantTotal)
+			}
+			users := resp.Data.([]models.User)
+			ids := make([]uint, len(users))
+			for i, user := range users {
+				ids[i] = user.ID
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	_, err := repo.Paginate(&models.PaginationRequest{Filters: models.JSON{"status": map[string]interface{}{"operator": "regex", "value": "."}}}, opts)
+	if !errors.Is(err, errors.ErrCodeValidationFailed) {
+		t.Errorf("expected a validation error for an unknown operator, got %v", err)
+	}
+	if _, err := repo.Paginate(&models.PaginationRequest{Search: "user"}, models.ListOptions{}); !errors.Is(err, errors.ErrCodeInvalidInput) {
+		t.Errorf("expected search without columns to be rejected, got %v", err)
+	}
+
+	for _, req := range []models.PaginationRequest{
+		{Filters: models.JSON{"password_hash": map[string]interface{}{"operator": "starts_with", "value": "$2a$"}}},
+		{Sort: "password_hash"},
+	} {
+		if _, err := repo.Paginate(&req, opts); !errors.Is(err, errors.ErrCodeValidationFailed) {
+			t.Errorf("Paginate(%+v) error = %v, want VALIDATION_FAILED for a column not in opts", req, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+)
+
+// DefaultPoolMetricsInterval is the collection interval used when none is configured
+const DefaultPoolMetricsInterval = 15 * time.Second
+
+// PoolStatsSource provides connection pool statistics; *sql.DB implements it
+type PoolStatsSource interface {
+	Stats() sql.DBStats
+}
+
+// PoolCollector periodically records connection pool usage and waits so
+// pool exhaustion (a climbing wait count) can be alerted on
+type PoolCollector struct {
+	source   PoolStatsSource
+	registry *metrics.Registry
+	database string
+	interval time.Duration
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPoolCollector creates a collector recording the stats of source under the
+// given database label every interval
+func NewPoolCollector(source PoolStatsSource, registry *metrics.Registry, database string, interval time.Duration) *PoolCollector {
+	if interval <= 0 {
+		interval = DefaultPoolMetricsInterval
+	}
+
+	return &PoolCollector{
+		source:   source,
+		registry: registry,
+		database: database,
+		interval: interval,
+	}
+}
+
+// PoolCollector creates a collector for this connection's pool
+func (db *DB) PoolCollector(registry *metrics.Registry, database string, interval time.Duration) (*PoolCollector, error) {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	return NewPoolCollector(sqlDB, registry, database, interval), nil
+}
+
+// Start begins collecting in the background
+func (p *PoolCollector) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+
+	go p.run(p.stop, p.done)
+}
+
+// Stop stops background collection
+func (p *PoolCollector) Stop() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Collect reads the pool stats once and updates the gauges
+func (p *PoolCollector) Collect() {
+	stats := p.source.Stats()
+	p.registry.RecordDatabaseConnections(p.database, stats.InUse, stats.Idle)
+	p.registry.RecordDatabaseWaits(p.database, stats.WaitCount, stats.WaitDuration)
+}
+
+// run collects immediately and then every interval until stopped
+func (p *PoolCollector) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	p.Collect()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.Collect()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package database_test
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakePoolStats struct {
+	mu    sync.Mutex
+	stats sql.DBStats
+}
+
+func (f *fakePoolStats) Stats() sql.DBStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+func (f *fakePoolStats) set(stats sql.DBStats) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stats = stats
+}
+
+func TestPoolCollector_Collect(t *testing.T) {
+	registry := metrics.New("test")
+	source := &fakePoolStats{}
+	collector := database.NewPoolCollector(source, registry, "primary", time.Minute)
+
+	tests := []struct {
+		name  string
+		stats sql.DBStats
+	}{
+		{name: "idle pool", stats: sql.DBStats{Idle: 5}},
+		{name: "exhausted pool", stats: sql.DBStats{InUse: 25, Idle: 0, WaitCount: 42, WaitDuration: 1500 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source.set(tt.stats)
+			collector.Collect()
+
+			checks := []struct {
+				metric string
+				got    float64
+				want   float64
+			}{
+				{"active", testutil.ToFloat64(registry.DatabaseConnectionsActive.WithLabelValues("primary")), float64(tt.stats.InUse)},
+				{"idle", testutil.ToFloat64(registry.DatabaseConnectionsIdle.WithLabelValues("primary")), float64(tt.stats.Idle)},
+				{"wait_count", testutil.ToFloat64(registry.DatabaseConnectionsWaitCount.WithLabelValues("primary")), float64(tt.stats.WaitCount)},
+				{"wait_duration", testutil.ToFloat64(registry.DatabaseConnectionsWaitDuration.WithLabelValues("primary")), tt.stats.WaitDuration.Seconds()},
+			}
+			for _, c := range checks {
+				if c.got != c.want {
+					t.Errorf("%s = %v, want %v", c.metric, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolCollector_StartUpdatesPeriodically(t *testing.T) {
+	registry := metrics.New("test")
+	source := &fakePoolStats{stats: sql.DBStats{InUse: 1}}
+	collector := database.NewPoolCollector(source, registry, "primary", 10*time.Millisecond)
+
+	collector.Start()
+	defer collector.Stop()
+
+	source.set(sql.DBStats{InUse: 7, WaitCount: 3})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if testutil.ToFloat64(registry.DatabaseConnectionsWaitCount.WithLabelValues("primary")) == 3 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("expected the collector to pick up new pool stats")
+}
+
+func TestDB_PoolCollector(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	registry := metrics.New("test")
+	collector, err := testDB.DB.PoolCollector(registry, "test", 0)
+	if err != nil {
+		t.Fatalf("PoolCollector() error = %v", err)
+	}
+	collector.Collect()
+
+	if got := testutil.CollectAndCount(registry.DatabaseConnectionsIdle); got != 1 {
+		t.Errorf("expected idle gauge for the test database, got %d series", got)
+	}
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// GetByIDWithPreloads retrieves a record by primary key along with the named
+// relations, e.g. GetByIDWithPreloads(id, "Members", "Tables.Fields")
+func (r *KeyedRepository[T, K]) GetByIDWithPreloads(id K, preloads ...string) (*T, error) {
+	query, err := r.preload(preloads)
+	if err != nil {
+		return nil, err
+	}
+
+	var entity T
+	if err := query.Where(primaryKeyEquals(id)).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// ListWithPreloads retrieves records with pagination along with the named relations
+func (r *KeyedRepository[T, K]) ListWithPreloads(offset, limit int, preloads ...string) ([]T, error) {
+	query, err := r.preload(preloads)
+	if err != nil {
+		return nil, err
+	}
+
+	var entities []T
+	err = query.Offset(offset).Limit(limit).Find(&entities).Error
+	return entities, err
+}
+
+// preload checks each name against T's relationships and adds it to a new query.
+// clause.Associations preloads every direct relation.
+func (r *KeyedRepository[T, K]) preload(preloads []string) (*gorm.DB, error) {
+	var entity T
+	stmt := &gorm.Statement{DB: r.db.DB}
+	if err := stmt.Parse(&entity); err != nil {
+		return nil, err
+	}
+
+	query := r.db.DB
+	for _, name := range preloads {
+		if name != clause.Associations && !hasRelationship(stmt.Schema, name) {
+			return nil, errors.NewInvalidInputError("preload", "unknown relation "+name+" of "+stmt.Schema.Name)
+		}
+		query = query.Preload(name)
+	}
+	return query, nil
+}
+
+// hasRelationship reports whether path, e.g. "Tables.Fields", names a chain of
+// relationships starting at s
+func hasRelationship(s *schema.Schema, path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		rel, ok := s.Relationships.Relations[name]
+		if !ok {
+			return false
+		}
+		s = rel.FieldSchema
+	}
+	return true
+}
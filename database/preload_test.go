@@ -0,0 +1,62 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+func TestRepository_Preloads(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.Workspace{}, &models.Table{}, &models.Field{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	workspace := &models.Workspace{Name: "Product", Slug: "product"}
+	workspace.TenantID = 1
+	if err := testDB.Create(workspace).Error; err != nil {
+		t.Fatalf("failed to seed workspace: %v", err)
+	}
+	table := &models.Table{WorkspaceID: workspace.ID, Name: "Roadmap", Slug: "roadmap"}
+	table.TenantID = 1
+	if err := testDB.Create(table).Error; err != nil {
+		t.Fatalf("failed to seed table: %v", err)
+	}
+	field := &models.Field{TableID: table.ID, Name: "Title", Type: models.FieldTypeText}
+	if err := testDB.Create(field).Error; err != nil {
+		t.Fatalf("failed to seed field: %v", err)
+	}
+
+	repo := database.NewRepository[models.Workspace](testDB.DB)
+
+	got, err := repo.GetByIDWithPreloads(workspace.ID, "Tables.Fields")
+	if err != nil {
+		t.Fatalf("GetByIDWithPreloads() error = %v", err)
+	}
+	if len(got.Tables) != 1 || len(got.Tables[0].Fields) != 1 || got.Tables[0].Fields[0].Name != "Title" {
+		t.Errorf("GetByIDWithPreloads() tables = %+v, want the table with its field", got.Tables)
+	}
+
+	list, err := repo.ListWithPreloads(0, 10, "Tables")
+	if err != nil {
+		t.Fatalf("ListWithPreloads() error = %v", err)
+	}
+	if len(list) != 1 || len(list[0].Tables) != 1 {
+		t.Errorf("ListWithPreloads() = %+v, want the workspace with its table", list)
+	}
+
+	for _, name := range []string{"Owner", "Tables.Owner", "Tables."} {
+		_, err := repo.GetByIDWithPreloads(workspace.ID, name)
+		if !errors.Is(err, errors.ErrCodeInvalidInput) {
+			t.Errorf("GetByIDWithPreloads(%q) error = %v, want INVALID_INPUT", name, err)
+		}
+		if _, err := repo.ListWithPreloads(0, 10, name); !errors.Is(err, errors.ErrCodeInvalidInput) {
+			t.Errorf("ListWithPreloads(%q) error = %v, want INVALID_INPUT", name, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"gorm.io/gorm"
+)
+
+// Query statuses used as metric labels
+const (
+	QueryStatusSuccess = "success"
+	QueryStatusError   = "error"
+)
+
+const queryStartKey = "metrics:query_start"
+
+// queryMetrics is a GORM plugin timing queries, creates, updates and deletes
+type queryMetrics struct {
+	registry atomic.Pointer[metrics.Registry]
+}
+
+// Name implements gorm.Plugin
+func (m *queryMetrics) Name() string {
+	return "shared:query_metrics"
+}
+
+// Initialize implements gorm.Plugin, registering the callbacks around each operation
+func (m *queryMetrics) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return stderrors.Join(
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", m.start),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", m.record("query")),
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", m.start),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", m.record("create")),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", m.start),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", m.record("update")),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", m.start),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", m.record("delete")),
+	)
+}
+
+// start remembers when the statement began
+func (m *queryMetrics) start(tx *gorm.DB) {
+	if m.registry.Load() != nil {
+		tx.InstanceSet(queryStartKey, time.Now())
+	}
+}
+
+// record returns a callback recording the statement's duration and status
+func (m *queryMetrics) record(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		registry := m.registry.Load()
+		if registry == nil {
+			return
+		}
+		value, ok := tx.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		status := QueryStatusSuccess
+		if tx.Error != nil && !stderrors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			status = QueryStatusError
+		}
+		registry.RecordDatabaseQuery(operation, tx.Statement.Table, status, time.Since(start))
+	}
+}
+
+// WithMetrics records the duration of every query, create, update and delete
+// on the connection into registry, labelled by operation and table, and starts
+// a PoolCollector recording the pool's stats every DefaultPoolMetricsInterval
+// under the current database's name. Stop the collector on shutdown. Calling it
+// again switches the registry; a nil registry stops recording queries.
+func (db *DB) WithMetrics(registry *metrics.Registry) (*PoolCollector, error) {
+	plugin, ok := db.Config.Plugins[(&queryMetrics{}).Name()].(*queryMetrics)
+	if !ok {
+		plugin = &queryMetrics{}
+		if err := db.Use(plugin); err != nil {
+			return nil, fmt.Errorf("failed to register query metrics: %w", err)
+		}
+	}
+	plugin.registry.Store(registry)
+
+	if registry == nil {
+		return nil, nil
+	}
+	collector, err := db.PoolCollector(registry, db.Migrator().CurrentDatabase(), DefaultPoolMetricsInterval)
+	if err != nil {
+		return nil, err
+	}
+	collector.Start()
+	return collector, nil
+}
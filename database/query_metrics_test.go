@@ -0,0 +1,90 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWithMetrics(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&Widget{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	repo := database.NewRepository[Widget](testDB.DB)
+
+	// No registry yet: statements must run without recording anything
+	if err := repo.Create(&Widget{Name: "before"}); err != nil {
+		t.Fatalf("Create() without metrics error = %v", err)
+	}
+
+	registry := metrics.New("test")
+	collector, err := testDB.DB.WithMetrics(registry)
+	if err != nil {
+		t.Fatalf("WithMetrics() error = %v", err)
+	}
+	defer collector.Stop()
+
+	widget := &Widget{Name: "original"}
+	if err := repo.Create(widget); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	widget.Name = "updated"
+	if err := repo.Update(widget); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := repo.GetByID(widget.ID); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if _, err := repo.GetByID(999); err == nil {
+		t.Fatal("GetByID() of a missing row should fail")
+	}
+	if err := repo.Delete(widget.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := testDB.Table("missing").Create(map[string]interface{}{"name": "x"}).Error; err == nil {
+		t.Fatal("insert into a missing table should fail")
+	}
+
+	tests := []struct {
+		operation, table, status string
+		want                     float64
+	}{
+		{"create", "widgets", database.QueryStatusSuccess, 1},
+		{"update", "widgets", database.QueryStatusSuccess, 1},
+		{"query", "widgets", database.QueryStatusSuccess, 2},
+		{"delete", "widgets", database.QueryStatusSuccess, 1},
+		{"create", "missing", database.QueryStatusError, 1},
+	}
+	for _, tt := range tests {
+		got := testutil.ToFloat64(registry.DatabaseQueriesTotal.WithLabelValues(tt.operation, tt.table, tt.status))
+		if got != tt.want {
+			t.Errorf("%s %s %s queries = %v, want %v", tt.operation, tt.table, tt.status, got, tt.want)
+		}
+	}
+	if count := testutil.CollectAndCount(registry.DatabaseQueryDuration); count != 5 {
+		t.Errorf("duration series = %d, want one per operation and table", count)
+	}
+
+	// The pool collector records once on start
+	collector.Stop()
+	if count := testutil.CollectAndCount(registry.DatabaseConnectionsIdle); count != 1 {
+		t.Errorf("idle connection series = %d, want 1", count)
+	}
+
+	// A nil registry stops recording
+	if _, err := testDB.DB.WithMetrics(nil); err != nil {
+		t.Fatalf("WithMetrics(nil) error = %v", err)
+	}
+	if err := repo.Create(&Widget{Name: "after"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := testutil.ToFloat64(registry.DatabaseQueriesTotal.WithLabelValues("create", "widgets", database.QueryStatusSuccess)); got != 1 {
+		t.Errorf("create queries = %v after removing the registry, want 1", got)
+	}
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"gorm.io/gorm"
+)
+
+// Defaults for RouterConfig
+const (
+	DefaultReadYourWritesWindow = 5 * time.Second
+	DefaultMaxReplicaLag        = 10 * time.Second
+	DefaultReplicaLagInterval   = 15 * time.Second
+)
+
+// DefaultReplicaLagQuery returns how many seconds a Postgres standby's replay is behind
+// the primary. It reports 0 on a primary or an idle standby that has replayed everything.
+const DefaultReplicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// Replica is a named read replica connection
+type Replica struct {
+	Name string
+	DB   *DB
+}
+
+// RouterConfig configures a Router. Zero values use the defaults.
+type RouterConfig struct {
+	ReadYourWritesWindow time.Duration     // How long reads go to the primary after a write in the same request
+	MaxLag               time.Duration     // Replicas lagging more than this are skipped
+	LagQuery             string            // Query returning a replica's lag in seconds (default DefaultReplicaLagQuery)
+	LagInterval          time.Duration     // How often Start measures the lag
+	Metrics              *metrics.Registry // Records database_replica_lag_seconds when set
+}
+
+// replicaState tracks the last measured lag of a replica
+type replicaState struct {
+	Replica
+	lag       atomic.Int64 // nanoseconds
+	available atomic.Bool
+}
+
+// Router sends writes to the primary and spreads reads across replicas that are
+// within MaxLag. Reads in a context prepared with WithReadYourWrites go to the
+// primary for ReadYourWritesWindow after a write through the same context, so
+// users see what they just created.
+type Router struct {
+	primary  *DB
+	replicas []*replicaState
+	cfg      RouterConfig
+	next     atomic.Uint32
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRouter creates a router over a primary and its read replicas. Replicas are
+// considered available until the first lag check says otherwise.
+func NewRouter(primary *DB, replicas []Replica, cfg RouterConfig) *Router {
+	if cfg.ReadYourWritesWindow <= 0 {
+		cfg.ReadYourWritesWindow = DefaultReadYourWritesWindow
+	}
+	if cfg.MaxLag <= 0 {
+		cfg.MaxLag = DefaultMaxReplicaLag
+	}
+	if cfg.LagQuery == "" {
+		cfg.LagQuery = DefaultReplicaLagQuery
+	}
+	if cfg.LagInterval <= 0 {
+		cfg.LagInterval = DefaultReplicaLagInterval
+	}
+
+	states := make([]*replicaState, 0, len(replicas))
+	for _, replica := range replicas {
+		state := &replicaState{Replica: replica}
+		state.available.Store(true)
+		states = append(states, state)
+	}
+
+	return &Router{primary: primary, replicas: states, cfg: cfg}
+}
+
+// Primary returns the primary connection
+func (r *Router) Primary() *DB {
+	return r.primary
+}
+
+// Writer returns the primary bound to ctx and records the write so later reads in
+// the same request stay on the primary
+func (r *Router) Writer(ctx context.Context) *gorm.DB {
+	if tracker, ok := ctx.Value(writeTrackerKey{}).(*writeTracker); ok {
+		tracker.lastWrite.Store(time.Now().UnixNano())
+	}
+	return r.primary.WithContext(ctx)
+}
+
+// Reader returns a connection for reads bound to ctx: the primary right after a
+// write in the same request or when no replica is within MaxLag, otherwise the
+// next available replica
+func (r *Router) Reader(ctx context.Context) *gorm.DB {
+	if r.recentlyWrote(ctx) {
+		return r.primary.WithContext(ctx)
+	}
+
+	if replica := r.pickReplica(); replica != nil {
+		return replica.DB.WithContext(ctx)
+	}
+	return r.primary.WithContext(ctx)
+}
+
+// Lag returns the last measured lag of the named replica and whether it is
+// currently used for reads
+func (r *Router) Lag(name string) (time.Duration, bool) {
+	for _, replica := range r.replicas {
+		if replica.Name == name {
+			return time.Duration(replica.lag.Load()), replica.available.Load()
+		}
+	}
+	return 0, false
+}
+
+// CheckLag measures the lag of every replica once and updates the metric. Replicas
+// whose query fails are skipped for reads until a later check succeeds.
+func (r *Router) CheckLag(ctx context.Context) {
+	for _, replica := range r.replicas {
+		var seconds float64
+		err := replica.DB.WithContext(ctx).Raw(r.cfg.LagQuery).Scan(&seconds).Error
+		if err != nil {
+			replica.available.Store(false)
+			continue
+		}
+
+		lag := time.Duration(seconds * float64(time.Second))
+		replica.lag.Store(int64(lag))
+		replica.available.Store(lag <= r.cfg.MaxLag)
+
+		if r.cfg.Metrics != nil {
+			r.cfg.Metrics.RecordReplicaLag(replica.Name, lag)
+		}
+	}
+}
+
+// Start begins checking replica lag in the background
+func (r *Router) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go r.run(r.stop, r.done)
+}
+
+// Stop stops background lag checks
+func (r *Router) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// run checks immediately and then every LagInterval until stopped
+func (r *Router) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	r.CheckLag(ctx)
+
+	ticker := time.NewTicker(r.cfg.LagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.CheckLag(ctx)
+		}
+	}
+}
+
+// pickReplica returns the next available replica round-robin, or nil
+func (r *Router) pickReplica() *replicaState {
+	n := len(r.replicas)
+	if n == 0 {
+		return nil
+	}
+
+	// Reduce on the unsigned counter so the index can't go negative once it
+	// passes MaxInt32 on 32-bit platforms
+	start := int(r.next.Add(1) % uint32(n))
+	for i := 0; i < n; i++ {
+		replica := r.replicas[(start+i)%n]
+		if replica.available.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// recentlyWrote reports whether ctx saw a write within the read-your-writes window
+func (r *Router) recentlyWrote(ctx context.Context) bool {
+	tracker, ok := ctx.Value(writeTrackerKey{}).(*writeTracker)
+	if !ok {
+		return false
+	}
+	last := tracker.lastWrite.Load()
+	return last != 0 && time.Since(time.Unix(0, last)) < r.cfg.ReadYourWritesWindow
+}
+
+type writeTrackerKey struct{}
+
+// writeTracker remembers the time of the last write in a request
+type writeTracker struct {
+	lastWrite atomic.Int64
+}
+
+// WithReadYourWrites prepares a request context so that reads through a Router
+// follow writes made with the same context to the primary. Call it once per
+// request, e.g. in middleware.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writeTrackerKey{}).(*writeTracker); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, writeTrackerKey{}, &writeTracker{})
+}
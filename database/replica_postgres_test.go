@@ -0,0 +1,138 @@
+//go:build postgres
+
+package database_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openPostgres connects to the database in PYAIRTABLE_TEST_POSTGRES_DSN (key=value
+// form) under the given application name, so tests can tell connections apart
+func openPostgres(t *testing.T, applicationName string) *database.DB {
+	t.Helper()
+
+	dsn := os.Getenv("PYAIRTABLE_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("PYAIRTABLE_TEST_POSTGRES_DSN not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn+" application_name="+applicationName), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to Postgres: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return &database.DB{DB: db}
+}
+
+// servedBy returns the application name of the connection a query ran on
+func servedBy(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+
+	var name string
+	if err := db.Raw("SELECT current_setting('application_name')").Scan(&name).Error; err != nil {
+		t.Fatalf("failed to read application name: %v", err)
+	}
+	return name
+}
+
+func TestRouter_ReadYourWrites(t *testing.T) {
+	primary := openPostgres(t, "primary")
+	replica := openPostgres(t, "replica")
+
+	router := database.NewRouter(primary, []database.Replica{{Name: "replica-1", DB: replica}}, database.RouterConfig{
+		ReadYourWritesWindow: 200 * time.Millisecond,
+	})
+
+	ctx := database.WithReadYourWrites(context.Background())
+	if got := servedBy(t, router.Reader(ctx)); got != "replica" {
+		t.Fatalf("read before any write served by %q, want replica", got)
+	}
+
+	if got := servedBy(t, router.Writer(ctx)); got != "primary" {
+		t.Fatalf("write served by %q, want primary", got)
+	}
+	if got := servedBy(t, router.Reader(ctx)); got != "primary" {
+		t.Errorf("read after a write served by %q, want primary", got)
+	}
+
+	other := database.WithReadYourWrites(context.Background())
+	if got := servedBy(t, router.Reader(other)); got != "replica" {
+		t.Errorf("read in another request served by %q, want replica", got)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if got := servedBy(t, router.Reader(ctx)); got != "replica" {
+		t.Errorf("read after the window served by %q, want replica", got)
+	}
+}
+
+func TestRouter_CheckLag(t *testing.T) {
+	primary := openPostgres(t, "primary")
+	replica := openPostgres(t, "replica")
+
+	t.Run("default query", func(t *testing.T) {
+		registry := metrics.New("test")
+		router := database.NewRouter(primary, []database.Replica{{Name: "replica-1", DB: replica}}, database.RouterConfig{
+			Metrics: registry,
+		})
+
+		router.CheckLag(context.Background())
+
+		lag, available := router.Lag("replica-1")
+		if !available || lag < 0 || lag > database.DefaultMaxReplicaLag {
+			t.Errorf("Lag() = %v, %v; want an available replica", lag, available)
+		}
+		if got := testutil.ToFloat64(registry.DatabaseReplicaLag.WithLabelValues("replica-1")); got != lag.Seconds() {
+			t.Errorf("database_replica_lag_seconds = %v, want %v", got, lag.Seconds())
+		}
+	})
+
+	t.Run("lagging replica", func(t *testing.T) {
+		registry := metrics.New("test")
+		router := database.NewRouter(primary, []database.Replica{{Name: "replica-1", DB: replica}}, database.RouterConfig{
+			MaxLag:   10 * time.Second,
+			LagQuery: "SELECT 42.5",
+			Metrics:  registry,
+		})
+
+		router.CheckLag(context.Background())
+
+		if got := testutil.ToFloat64(registry.DatabaseReplicaLag.WithLabelValues("replica-1")); got != 42.5 {
+			t.Errorf("database_replica_lag_seconds = %v, want 42.5", got)
+		}
+		if _, available := router.Lag("replica-1"); available {
+			t.Error("expected a replica over MaxLag to be skipped")
+		}
+		if got := servedBy(t, router.Reader(context.Background())); got != "primary" {
+			t.Errorf("read with a lagging replica served by %q, want primary", got)
+		}
+	})
+
+	t.Run("failing lag query", func(t *testing.T) {
+		router := database.NewRouter(primary, []database.Replica{{Name: "replica-1", DB: replica}}, database.RouterConfig{
+			LagQuery: "SELECT no_such_column",
+		})
+
+		router.CheckLag(context.Background())
+
+		if _, available := router.Lag("replica-1"); available {
+			t.Error("expected a replica whose lag can't be measured to be skipped")
+		}
+	})
+}
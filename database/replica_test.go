@@ -0,0 +1,24 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRouter_PickReplicaAcrossCounterWrap(t *testing.T) {
+	router := NewRouter(nil, []Replica{{Name: "a"}, {Name: "b"}, {Name: "c"}}, RouterConfig{})
+	router.replicas[1].available.Store(false)
+	router.next.Store(math.MaxUint32 - 3)
+
+	seen := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		replica := router.pickReplica()
+		if replica == nil {
+			t.Fatal("pickReplica() = nil, want an available replica")
+		}
+		seen[replica.Name]++
+	}
+	if seen["b"] != 0 || seen["a"] == 0 || seen["c"] == 0 {
+		t.Errorf("picked %v, want only the available replicas a and c", seen)
+	}
+}
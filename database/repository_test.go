@@ -0,0 +1,346 @@
+package database_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"gorm.io/gorm"
+)
+
+func TestRepository_CompositeUniqueConstraints(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.WorkspaceMember{}, &models.Table{}, &models.Field{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	t.Run("duplicate workspace member", func(t *testing.T) {
+		repo := database.NewRepository[models.WorkspaceMember](testDB.DB)
+
+		if err := repo.Create(&models.WorkspaceMember{WorkspaceID: 1, UserID: 1, Role: models.WorkspaceRoleEditor}); err != nil {
+			t.Fatalf("first Create() error = %v", err)
+		}
+
+		err := repo.Create(&models.WorkspaceMember{WorkspaceID: 1, UserID: 1, Role: models.WorkspaceRoleViewer})
+		if !errors.Is(err, errors.ErrCodeAlreadyExists) {
+			t.Fatalf("expected AlreadyExists error, got %v", err)
+		}
+		if code := errors.GetHTTPCode(err); code != 409 {
+			t.Errorf("expected HTTP 409, got %d", code)
+		}
+
+		if err := repo.Create(&models.WorkspaceMember{WorkspaceID: 2, UserID: 1, Role: models.WorkspaceRoleViewer}); err != nil {
+			t.Errorf("same user in another workspace should be allowed, got %v", err)
+		}
+	})
+
+	t.Run("duplicate table slug", func(t *testing.T) {
+		repo := database.NewRepository[models.Table](testDB.DB)
+		fixtures := sharedtesting.NewTestFixtures()
+
+		first := fixtures.CreateTestTable(func(tbl *models.Table) { tbl.ID = 0 })
+		if err := repo.Create(first); err != nil {
+			t.Fatalf("first Create() error = %v", err)
+		}
+
+		duplicate := fixtures.CreateTestTable(func(tbl *models.Table) { tbl.ID = 0 })
+		if err := repo.Create(duplicate); !errors.Is(err, errors.ErrCodeAlreadyExists) {
+			t.Fatalf("expected AlreadyExists error, got %v", err)
+		}
+
+		otherWorkspace := fixtures.CreateTestTable(func(tbl *models.Table) {
+			tbl.ID = 0
+			tbl.WorkspaceID = 2
+		})
+		if err := repo.Create(otherWorkspace); err != nil {
+			t.Errorf("same slug in another workspace should be allowed, got %v", err)
+		}
+	})
+
+	t.Run("duplicate field name", func(t *testing.T) {
+		repo := database.NewRepository[models.Field](testDB.DB)
+
+		if err := repo.Create(&models.Field{TableID: 1, Name: "Status", Type: models.FieldTypeText}); err != nil {
+			t.Fatalf("first Create() error = %v", err)
+		}
+
+		err := repo.Create(&models.Field{TableID: 1, Name: "Status", Type: models.FieldTypeSelect})
+		if !errors.Is(err, errors.ErrCodeAlreadyExists) {
+			t.Fatalf("expected AlreadyExists error, got %v", err)
+		}
+	})
+}
+
+func TestMigrateCompositeIndexes(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.WorkspaceMember{}, &models.Table{}, &models.Field{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	migrator := testDB.Migrator()
+	if err := migrator.DropIndex(&models.Table{}, "idx_table_workspace_slug"); err != nil {
+		t.Fatalf("failed to drop index: %v", err)
+	}
+
+	if err := testDB.MigrateCompositeIndexes(); err != nil {
+		t.Fatalf("MigrateCompositeIndexes() error = %v", err)
+	}
+
+	if !migrator.HasIndex(&models.Table{}, "idx_table_workspace_slug") {
+		t.Error("expected table slug index to be recreated")
+	}
+}
+
+func TestRepository_Exists(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	repo := database.NewRepository[models.User](testDB.DB)
+	fixtures := sharedtesting.NewTestFixtures()
+	for _, user := range fixtures.CreateMultipleUsers(2) {
+		if err := repo.Create(user); err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		args      []interface{}
+		want      bool
+	}{
+		{name: "matching email", condition: "email = ?", args: []interface{}{"user1@example.com"}, want: true},
+		{name: "unknown email", condition: "email = ?", args: []interface{}{"nobody@example.com"}, want: false},
+		{name: "multiple matches", condition: "status = ?", args: []interface{}{models.StatusActive}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Exists(tt.condition, tt.args...)
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Exists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("soft deleted records are ignored", func(t *testing.T) {
+		if err := repo.Delete(1); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if got, err := repo.Exists("email = ?", "user1@example.com"); err != nil || got {
+			t.Errorf("Exists() = %v, %v; want false for deleted user", got, err)
+		}
+	})
+}
+
+type traceKey struct{}
+
+func TestRepository_Context(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&Widget{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// Record the trace value each statement runs with, as a tracing plugin would
+	var traces []interface{}
+	record := func(tx *gorm.DB) { traces = append(traces, tx.Statement.Context.Value(traceKey{})) }
+	callbacks := testDB.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("test:trace", record),
+		callbacks.Query().Before("gorm:query").Register("test:trace", record),
+		callbacks.Update().Before("gorm:update").Register("test:trace", record),
+		callbacks.Delete().Before("gorm:delete").Register("test:trace", record),
+	} {
+		if err != nil {
+			t.Fatalf("failed to register callback: %v", err)
+		}
+	}
+
+	repo := database.NewRepository[Widget](testDB.DB)
+	ctx := context.WithValue(context.Background(), traceKey{}, "req-1")
+
+	widget := &Widget{Name: "original"}
+	if err := repo.CreateCtx(ctx, widget); err != nil {
+		t.Fatalf("CreateCtx() error = %v", err)
+	}
+	widget.Name = "updated"
+	if err := repo.UpdateCtx(ctx, widget); err != nil {
+		t.Fatalf("UpdateCtx() error = %v", err)
+	}
+	if got, err := repo.GetByIDCtx(ctx, widget.ID); err != nil || got.Name != "updated" {
+		t.Fatalf("GetByIDCtx() = %+v, %v", got, err)
+	}
+	if list, err := repo.ListCtx(ctx, 0, 10); err != nil || len(list) != 1 {
+		t.Fatalf("ListCtx() = %+v, %v", list, err)
+	}
+	if err := repo.DeleteCtx(ctx, widget.ID); err != nil {
+		t.Fatalf("DeleteCtx() error = %v", err)
+	}
+
+	if len(traces) != 5 {
+		t.Fatalf("traced %d statements, want 5", len(traces))
+	}
+	for i, trace := range traces {
+		if trace != "req-1" {
+			t.Errorf("statement %d ran with trace %v, want the request context", i, trace)
+		}
+	}
+
+	traces = nil
+	if _, err := repo.GetByID(widget.ID); err == nil {
+		t.Error("GetByID() found a deleted widget")
+	}
+	if len(traces) != 1 || traces[0] != nil {
+		t.Errorf("GetByID() traces = %v, want one statement without the request context", traces)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := repo.CreateCtx(cancelled, &Widget{Name: "late"}); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("CreateCtx() with a cancelled context error = %v, want context.Canceled", err)
+	}
+	if _, err := repo.CountCtx(cancelled); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("CountCtx() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	repo := database.NewRepository[models.User](testDB.DB)
+	fixtures := sharedtesting.NewTestFixtures()
+	for _, user := range fixtures.CreateMultipleUsers(3) {
+		if user.ID == 2 {
+			user.Status = models.StatusInactive
+		}
+		if err := repo.Create(user); err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+
+	ids, err := database.Pluck[models.User, uint](repo, "id", "status = ?", models.StatusActive)
+	if err != nil {
+		t.Fatalf("Pluck() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("Pluck() ids = %v, want [1 3]", ids)
+	}
+
+	emails, err := database.Pluck[models.User, string](repo, "email", "")
+	if err != nil {
+		t.Fatalf("Pluck() error = %v", err)
+	}
+	if len(emails) != 3 || emails[0] != "user1@example.com" {
+		t.Errorf("Pluck() emails = %v", emails)
+	}
+
+	none, err := database.Pluck[models.User, uint](repo, "id", "email = ?", "nobody@example.com")
+	if err != nil {
+		t.Fatalf("Pluck() error = %v", err)
+	}
+	if none == nil || len(none) != 0 {
+		t.Errorf("Pluck() = %#v, want empty slice", none)
+	}
+}
+
+type document struct {
+	models.ULIDModel
+	Title string
+}
+
+func TestKeyedRepository_ULID(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&document{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	repo := database.NewKeyedRepository[document, string](testDB.DB)
+
+	const count = 50
+	ids := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		doc := &document{Title: fmt.Sprintf("doc %d", i)}
+		if err := repo.Create(doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if len(doc.ID) != 26 {
+			t.Fatalf("expected a 26 character ULID, got %q", doc.ID)
+		}
+		if seen[doc.ID] {
+			t.Fatalf("duplicate ID %q", doc.ID)
+		}
+		seen[doc.ID] = true
+		ids = append(ids, doc.ID)
+	}
+
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("expected IDs to sort in creation order, got %v", ids)
+	}
+
+	got, err := repo.GetByID(ids[10])
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.ID != ids[10] || got.Title != "doc 10" {
+		t.Errorf("GetByID() = %+v, want doc 10", got)
+	}
+
+	if _, err := repo.GetByID("1 OR 1=1"); err == nil {
+		t.Error("expected GetByID() to treat the key as a value, not SQL")
+	}
+
+	if err := repo.Delete(ids[10]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(ids[10]); err == nil {
+		t.Error("expected deleted document to be gone")
+	}
+	if total, err := repo.Count(); err != nil || total != count-1 {
+		t.Errorf("Count() = %d, %v; want %d", total, err, count-1)
+	}
+}
+
+func TestULIDModel_KeepsExplicitID(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&document{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	repo := database.NewKeyedRepository[document, string](testDB.DB)
+	doc := &document{ULIDModel: models.ULIDModel{ID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"}, Title: "imported"}
+	if err := repo.Create(doc); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if doc.ID != "01ARZ3NDEKTSV4RRFFQ69G5FAV" {
+		t.Errorf("expected explicit ID to be kept, got %q", doc.ID)
+	}
+}
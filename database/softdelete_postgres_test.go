@@ -0,0 +1,29 @@
+//go:build postgres
+
+package database_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+)
+
+func TestSoftDeleteUniqueIndexes_Postgres(t *testing.T) {
+	db := openPostgres(t, "softdelete")
+
+	if err := db.AutoMigrate(&models.Workspace{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := db.MigrateSoftDeleteUniqueIndexes(); err != nil {
+		t.Fatalf("MigrateSoftDeleteUniqueIndexes() error = %v", err)
+	}
+
+	// A tenant of its own keeps reruns against the same database independent
+	tenantID := uint(time.Now().UnixNano() % 1_000_000_000)
+	t.Cleanup(func() {
+		db.Unscoped().Where("tenant_id = ?", tenantID).Delete(&models.Workspace{})
+	})
+
+	assertSlugReusableAfterSoftDelete(t, db, tenantID)
+}
@@ -0,0 +1,115 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+// assertSlugReusableAfterSoftDelete checks that a workspace slug is unique among live
+// workspaces of a tenant but can be reused once the holder is soft deleted
+func assertSlugReusableAfterSoftDelete(t *testing.T, db *database.DB, tenantID uint) {
+	t.Helper()
+
+	repo := database.NewRepository[models.Workspace](db)
+	newWorkspace := func() *models.Workspace {
+		workspace := &models.Workspace{Name: "Projects", Slug: "projects"}
+		workspace.TenantID = tenantID
+		return workspace
+	}
+
+	first := newWorkspace()
+	if err := repo.Create(first); err != nil {
+		t.Fatalf("first Create() error = %v", err)
+	}
+	if err := repo.Create(newWorkspace()); !errors.Is(err, errors.ErrCodeAlreadyExists) {
+		t.Fatalf("expected a live duplicate slug to be rejected, got %v", err)
+	}
+
+	if err := repo.Delete(first.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	second := newWorkspace()
+	if err := repo.Create(second); err != nil {
+		t.Fatalf("expected the slug of a soft-deleted workspace to be reusable, got %v", err)
+	}
+
+	// Restoring the deleted workspace would now duplicate a live slug
+	if err := db.Unscoped().Model(first).Update("deleted_at", nil).Error; err == nil {
+		t.Error("expected restoring a duplicate to be rejected")
+	}
+}
+
+func TestSoftDeleteUniqueIndexes(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.Workspace{}, &models.Table{}, &models.User{}, &models.Tenant{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := testDB.MigrateSoftDeleteUniqueIndexes(); err != nil {
+		t.Fatalf("MigrateSoftDeleteUniqueIndexes() error = %v", err)
+	}
+	// Running it again is a no-op
+	if err := testDB.MigrateSoftDeleteUniqueIndexes(); err != nil {
+		t.Fatalf("second MigrateSoftDeleteUniqueIndexes() error = %v", err)
+	}
+
+	assertSlugReusableAfterSoftDelete(t, testDB.DB, 1)
+}
+
+func TestWorkspaceSlugIndex_FromAutoMigrate(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.Workspace{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// The slug only has to be unique within a tenant
+	other := &models.Workspace{Name: "Projects", Slug: "projects"}
+	other.TenantID = 2
+	if err := database.NewRepository[models.Workspace](testDB.DB).Create(other); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	assertSlugReusableAfterSoftDelete(t, testDB.DB, 1)
+}
+
+func TestCreateSoftDeleteUniqueIndex_ReplacesFullIndex(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// An index created by an older version covers soft-deleted rows too
+	if err := testDB.Exec("DROP INDEX idx_users_email").Error; err != nil {
+		t.Fatalf("failed to drop index: %v", err)
+	}
+	if err := testDB.Exec("CREATE UNIQUE INDEX idx_users_email ON users (email)").Error; err != nil {
+		t.Fatalf("failed to create legacy index: %v", err)
+	}
+
+	if err := testDB.CreateSoftDeleteUniqueIndex(&models.User{}, "idx_users_email", "email"); err != nil {
+		t.Fatalf("CreateSoftDeleteUniqueIndex() error = %v", err)
+	}
+
+	repo := database.NewRepository[models.User](testDB.DB)
+	user := &models.User{Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace", PasswordHash: "x"}
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Delete(user.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	again := &models.User{Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace", PasswordHash: "x"}
+	if err := repo.Create(again); err != nil {
+		t.Errorf("expected the email of a soft-deleted user to be reusable, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TenantRepository provides the Repository operations on a model embedding
+// models.TenantModel, confined to one tenant: every read and delete is filtered
+// by tenant_id, Create sets TenantID and Update only touches the tenant's rows.
+// Use AllTenants for admin or cross-tenant queries.
+type TenantRepository[T any] struct {
+	repo     *KeyedRepository[T, uint]
+	db       *DB
+	tenantID uint
+}
+
+// NewTenantRepository creates a repository scoped to tenantID
+func NewTenantRepository[T any](db *DB, tenantID uint) *TenantRepository[T] {
+	// The session keeps the tenant condition from leaking between queries
+	scoped := db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "tenant_id"}, Value: tenantID}).
+		Session(&gorm.Session{})
+
+	return &TenantRepository[T]{
+		repo:     NewKeyedRepository[T, uint](&DB{DB: scoped}),
+		db:       db,
+		tenantID: tenantID,
+	}
+}
+
+// TenantID returns the tenant the repository is scoped to
+func (r *TenantRepository[T]) TenantID() uint {
+	return r.tenantID
+}
+
+// AllTenants returns an unscoped repository over every tenant's records
+func (r *TenantRepository[T]) AllTenants() *Repository[T] {
+	return NewRepository[T](r.db)
+}
+
+// Create sets the entity's TenantID and creates it
+func (r *TenantRepository[T]) Create(entity *T) error {
+	return r.CreateCtx(context.Background(), entity)
+}
+
+// CreateCtx is Create bound to ctx
+func (r *TenantRepository[T]) CreateCtx(ctx context.Context, entity *T) error {
+	if err := r.setTenantID(ctx, entity); err != nil {
+		return err
+	}
+	return r.repo.CreateCtx(ctx, entity)
+}
+
+// GetByID retrieves the tenant's record by primary key
+func (r *TenantRepository[T]) GetByID(id uint) (*T, error) {
+	return r.repo.GetByIDCtx(context.Background(), id)
+}
+
+// GetByIDCtx is GetByID bound to ctx
+func (r *TenantRepository[T]) GetByIDCtx(ctx context.Context, id uint) (*T, error) {
+	return r.repo.GetByIDCtx(ctx, id)
+}
+
+// GetByIDWithPreloads retrieves the tenant's record by primary key along with the
+// named relations
+func (r *TenantRepository[T]) GetByIDWithPreloads(id uint, preloads ...string) (*T, error) {
+	return r.repo.GetByIDWithPreloads(id, preloads...)
+}
+
+// Update saves all fields of the entity but created_at and created_by. The entity
+// must have a primary key and belong to the tenant; saving another tenant's record
+// returns NOT_FOUND. Unlike Repository.Update it never inserts, so the entity must
+// already exist.
+func (r *TenantRepository[T]) Update(entity *T) error {
+	return r.UpdateCtx(context.Background(), entity)
+}
+
+// UpdateCtx is Update bound to ctx
+func (r *TenantRepository[T]) UpdateCtx(ctx context.Context, entity *T) error {
+	if err := r.setTenantID(ctx, entity); err != nil {
+		return err
+	}
+	id, err := r.primaryKey(ctx, entity)
+	if err != nil {
+		return err
+	}
+
+	result := r.repo.db.WithContext(ctx).
+		Model(entity).
+		Where(primaryKeyEquals(id)).
+		Select("*").
+		Omit(auditColumns...).
+		Updates(entity)
+	if result.Error != nil {
+		return r.repo.translateWriteError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError(resourceName[T]())
+	}
+	return nil
+}
+
+// Delete deletes the tenant's record by primary key
+func (r *TenantRepository[T]) Delete(id uint) error {
+	return r.repo.DeleteCtx(context.Background(), id)
+}
+
+// DeleteCtx is Delete bound to ctx
+func (r *TenantRepository[T]) DeleteCtx(ctx context.Context, id uint) error {
+	return r.repo.DeleteCtx(ctx, id)
+}
+
+// List retrieves the tenant's records with pagination
+func (r *TenantRepository[T]) List(offset, limit int) ([]T, error) {
+	return r.repo.ListCtx(context.Background(), offset, limit)
+}
+
+// ListCtx is List bound to ctx
+func (r *TenantRepository[T]) ListCtx(ctx context.Context, offset, limit int) ([]T, error) {
+	return r.repo.ListCtx(ctx, offset, limit)
+}
+
+// Count returns the number of the tenant's records
+func (r *TenantRepository[T]) Count() (int64, error) {
+	return r.repo.CountCtx(context.Background())
+}
+
+// CountCtx is Count bound to ctx
+func (r *TenantRepository[T]) CountCtx(ctx context.Context) (int64, error) {
+	return r.repo.CountCtx(ctx)
+}
+
+// FindWhere finds the tenant's records matching the given condition
+func (r *TenantRepository[T]) FindWhere(condition string, args ...interface{}) ([]T, error) {
+	return r.repo.FindWhereCtx(context.Background(), condition, args...)
+}
+
+// FirstWhere finds the tenant's first record matching the given condition
+func (r *TenantRepository[T]) FirstWhere(condition string, args ...interface{}) (*T, error) {
+	return r.repo.FirstWhereCtx(context.Background(), condition, args...)
+}
+
+// Exists reports whether any of the tenant's records matches the given condition
+func (r *TenantRepository[T]) Exists(condition string, args ...interface{}) (bool, error) {
+	return r.repo.ExistsCtx(context.Background(), condition, args...)
+}
+
+// Paginate returns the page of the tenant's records described by req, restricted
+// to the columns in opts (see Repository.Paginate)
+func (r *TenantRepository[T]) Paginate(req *models.PaginationRequest, opts models.ListOptions) (*models.PaginationResponse, error) {
+	return r.repo.Paginate(req, opts)
+}
+
+// setTenantID sets the entity's tenant_id field to the repository's tenant
+func (r *TenantRepository[T]) setTenantID(ctx context.Context, entity *T) error {
+	stmt := &gorm.Statement{DB: r.db.DB}
+	if err := stmt.Parse(entity); err != nil {
+		return err
+	}
+
+	field := stmt.Schema.LookUpField("tenant_id")
+	if field == nil {
+		return fmt.Errorf("%s has no tenant_id field", stmt.Schema.Name)
+	}
+	return field.Set(ctx, reflect.ValueOf(entity), r.tenantID)
+}
+
+// primaryKey returns the entity's non-zero primary key
+func (r *TenantRepository[T]) primaryKey(ctx context.Context, entity *T) (interface{}, error) {
+	stmt := &gorm.Statement{DB: r.db.DB}
+	if err := stmt.Parse(entity); err != nil {
+		return nil, err
+	}
+	return primaryKeyOf(ctx, stmt.Schema, entity)
+}
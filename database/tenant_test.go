@@ -0,0 +1,131 @@
+package database_test
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"gorm.io/gorm"
+)
+
+func TestTenantRepository(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.Workspace{}, &Widget{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	acme := database.NewTenantRepository[models.Workspace](testDB.DB, 1)
+	globex := database.NewTenantRepository[models.Workspace](testDB.DB, 2)
+
+	own := &models.Workspace{Name: "Roadmap", Slug: "roadmap"}
+	own.TenantID = 2 // overwritten by the scoped Create
+	if err := acme.Create(own); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if own.TenantID != 1 {
+		t.Errorf("TenantID = %d, want the repository's tenant", own.TenantID)
+	}
+	for _, name := range []string{"Budget", "Hiring"} {
+		if err := globex.Create(&models.Workspace{Name: name, Slug: name}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	foreign, err := globex.FirstWhere("name = ?", "Budget")
+	if err != nil {
+		t.Fatalf("FirstWhere() error = %v", err)
+	}
+
+	t.Run("reads are scoped", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if count, err := acme.Count(); err != nil || count != 1 {
+				t.Errorf("Count() = %d, %v, want 1", count, err)
+			}
+		}
+		if list, err := globex.List(0, 10); err != nil || len(list) != 2 {
+			t.Errorf("List() = %d workspaces, %v, want 2", len(list), err)
+		}
+		if _, err := acme.GetByID(foreign.ID); !stderrors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetByID() of another tenant's workspace error = %v, want not found", err)
+		}
+		if found, err := acme.FindWhere("name = ?", "Budget"); err != nil || len(found) != 0 {
+			t.Errorf("FindWhere() = %v, %v, want nothing", found, err)
+		}
+		if exists, err := acme.Exists("name = ?", "Hiring"); err != nil || exists {
+			t.Errorf("Exists() = %v, %v, want false", exists, err)
+		}
+		page, err := acme.Paginate(&models.PaginationRequest{Page: 1, PageSize: 10}, models.ListOptions{})
+		if err != nil || page.Pagination.Total != 1 {
+			t.Errorf("Paginate() = %+v, %v, want 1 workspace", page, err)
+		}
+	})
+
+	t.Run("writes are scoped", func(t *testing.T) {
+		hijack := *foreign
+		hijack.Name = "Hijacked"
+		if err := acme.Update(&hijack); !errors.Is(err, errors.ErrCodeNotFound) {
+			t.Errorf("Update() of another tenant's workspace error = %v, want NOT_FOUND", err)
+		}
+		if err := acme.Delete(foreign.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if got, err := globex.GetByID(foreign.ID); err != nil || got.Name != "Budget" {
+			t.Errorf("GetByID() = %+v, %v, want the workspace untouched", got, err)
+		}
+
+		own.Name = "Roadmap 2025"
+		if err := acme.Update(own); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if got, err := acme.GetByID(own.ID); err != nil || got.Name != "Roadmap 2025" {
+			t.Errorf("GetByID() = %+v, %v, want the updated workspace", got, err)
+		}
+	})
+
+	t.Run("update needs a primary key", func(t *testing.T) {
+		if err := globex.Update(&models.Workspace{Name: "Wiped"}); err == nil {
+			t.Error("expected Update without a primary key to fail")
+		}
+		if found, err := globex.FindWhere("name = ?", "Wiped"); err != nil || len(found) != 0 {
+			t.Errorf("FindWhere() = %d workspaces, %v, want none overwritten", len(found), err)
+		}
+	})
+
+	t.Run("update keeps audit columns", func(t *testing.T) {
+		stored, err := acme.GetByID(own.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+
+		edit := *stored
+		edit.CreatedAt = stored.CreatedAt.AddDate(-1, 0, 0)
+		edit.CreatedBy = 99
+		if err := acme.Update(&edit); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		got, err := acme.GetByID(own.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if !got.CreatedAt.Equal(stored.CreatedAt) || got.CreatedBy != stored.CreatedBy {
+			t.Errorf("created = %v by %d, want %v by %d", got.CreatedAt, got.CreatedBy, stored.CreatedAt, stored.CreatedBy)
+		}
+	})
+
+	t.Run("all tenants", func(t *testing.T) {
+		if count, err := acme.AllTenants().Count(); err != nil || count != 3 {
+			t.Errorf("AllTenants().Count() = %d, %v, want 3", count, err)
+		}
+	})
+
+	t.Run("model without tenant", func(t *testing.T) {
+		widgets := database.NewTenantRepository[Widget](testDB.DB, 1)
+		if err := widgets.Create(&Widget{Name: "orphan"}); err == nil {
+			t.Error("expected Create to fail for a model without tenant_id")
+		}
+	})
+}
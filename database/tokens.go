@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reset, email verification and invitation tokens are all stored as the SHA-256
+// hash of the raw token sent to the user (see models.NewPasswordResetToken,
+// NewEmailVerificationToken and NewWorkspaceInvitation), so the Consume methods
+// take the raw token and look up its hash. Rows written by older versions with
+// raw tokens are converted by MigrateTokenHashes.
+
+// ConsumeResetToken marks the password reset token as used and returns its user.
+// token is the raw token sent to the user. The check and the update are a single
+// UPDATE ... WHERE used_at IS NULL, so of concurrent requests with the same token
+// exactly one succeeds; the others, and unknown or expired tokens, get TOKEN_INVALID.
+func (db *DB) ConsumeResetToken(ctx context.Context, token string) (*models.User, error) {
+	var consumed models.PasswordResetToken
+	if err := consumeToken(db.WithContext(ctx), &consumed, "token", utils.HashSHA256(token), "used_at"); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := db.WithContext(ctx).First(&user, consumed.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ConsumeEmailVerificationToken marks the email verification token as used and
+// the user's email as verified in one transaction, returning the user. Like
+// ConsumeResetToken, a token can only be consumed once.
+func (db *DB) ConsumeEmailVerificationToken(ctx context.Context, token string) (*models.User, error) {
+	var user models.User
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var consumed models.EmailVerificationToken
+		if err := consumeToken(tx, &consumed, "token", utils.HashSHA256(token), "used_at"); err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, consumed.UserID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+		return tx.Model(&user).Select("email_verified", "email_verified_at").Updates(&user).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ConsumeInvitation marks the workspace invitation with the given token as
+// accepted and returns it. Like ConsumeResetToken, an invitation can only be
+// accepted once.
+func (db *DB) ConsumeInvitation(ctx context.Context, token string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	if err := consumeToken(db.WithContext(ctx), &invitation, "token", utils.HashSHA256(token), "accepted_at"); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// consumeToken sets usedColumn to now on the unexpired row of model's table whose
+// tokenColumn matches value and whose usedColumn is still NULL, loading the row
+// into model with RETURNING
+func consumeToken(tx *gorm.DB, model interface{}, tokenColumn, value, usedColumn string) error {
+	now := time.Now()
+	result := tx.Model(model).
+		Clauses(clause.Returning{}).
+		Where(clause.Eq{Column: clause.Column{Name: tokenColumn}, Value: value}).
+		Where(clause.Eq{Column: clause.Column{Name: usedColumn}, Value: nil}).
+		Where(clause.Gt{Column: clause.Column{Name: "expires_at"}, Value: now}).
+		Update(usedColumn, now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewTokenInvalidError()
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTokenDB opens a file database so concurrent requests use separate connections
+func newTokenDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "tokens.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(&models.User{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.WorkspaceInvitation{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	user := sharedtesting.NewTestFixtures().CreateTestUser(func(u *models.User) { u.EmailVerified = false })
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	return &database.DB{DB: db}
+}
+
+// consumeConcurrently runs consume from several goroutines and returns how many succeeded
+func consumeConcurrently(t *testing.T, consume func() error) int {
+	t.Helper()
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- consume()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, errors.ErrCodeTokenInvalid):
+			t.Errorf("consume error = %v, want TOKEN_INVALID", err)
+		}
+	}
+	return succeeded
+}
+
+func TestConsumeResetToken(t *testing.T) {
+	db := newTokenDB(t)
+	ctx := context.Background()
+
+	for _, token := range []*models.PasswordResetToken{
+		models.NewPasswordResetToken(1, "valid", time.Hour),
+		models.NewPasswordResetToken(1, "expired", -time.Minute),
+	} {
+		if err := db.Create(token).Error; err != nil {
+			t.Fatalf("failed to seed token: %v", err)
+		}
+	}
+
+	succeeded := consumeConcurrently(t, func() error {
+		user, err := db.ConsumeResetToken(ctx, "valid")
+		if err == nil && user.ID != 1 {
+			t.Errorf("ConsumeResetToken() user = %d, want 1", user.ID)
+		}
+		return err
+	})
+	if succeeded != 1 {
+		t.Errorf("%d concurrent consumptions succeeded, want exactly 1", succeeded)
+	}
+
+	var stored models.PasswordResetToken
+	if err := db.Where("user_id = ? AND expires_at > ?", 1, time.Now()).First(&stored).Error; err != nil || !stored.IsUsed() {
+		t.Errorf("stored token = %+v, %v, want it marked used", stored, err)
+	}
+
+	for _, token := range []string{"expired", "unknown"} {
+		if _, err := db.ConsumeResetToken(ctx, token); !errors.Is(err, errors.ErrCodeTokenInvalid) {
+			t.Errorf("ConsumeResetToken(%q) error = %v, want TOKEN_INVALID", token, err)
+		}
+	}
+}
+
+func TestConsumeEmailVerificationToken(t *testing.T) {
+	db := newTokenDB(t)
+	ctx := context.Background()
+
+	if err := db.Create(models.NewEmailVerificationToken(1, "verify", time.Hour)).Error; err != nil {
+		t.Fatalf("failed to seed token: %v", err)
+	}
+
+	succeeded := consumeConcurrently(t, func() error {
+		_, err := db.ConsumeEmailVerificationToken(ctx, "verify")
+		return err
+	})
+	if succeeded != 1 {
+		t.Errorf("%d concurrent consumptions succeeded, want exactly 1", succeeded)
+	}
+
+	var user models.User
+	if err := db.First(&user, 1).Error; err != nil || !user.IsEmailVerified() || user.EmailVerifiedAt == nil {
+		t.Errorf("user = %+v, %v, want the email verified", user, err)
+	}
+}
+
+func TestConsumeInvitation(t *testing.T) {
+	db := newTokenDB(t)
+	ctx := context.Background()
+
+	invitation := models.NewWorkspaceInvitation(3, "new@example.com", models.WorkspaceRoleEditor, 1, "invite", time.Hour)
+	if err := db.Create(invitation).Error; err != nil {
+		t.Fatalf("failed to seed invitation: %v", err)
+	}
+
+	succeeded := consumeConcurrently(t, func() error {
+		accepted, err := db.ConsumeInvitation(ctx, "invite")
+		if err == nil && (accepted.WorkspaceID != 3 || !accepted.IsAccepted()) {
+			t.Errorf("ConsumeInvitation() = %+v, want the accepted invitation", accepted)
+		}
+		return err
+	})
+	if succeeded != 1 {
+		t.Errorf("%d concurrent acceptances succeeded, want exactly 1", succeeded)
+	}
+}
+
+func TestMigrateTokenHashes(t *testing.T) {
+	db := newTokenDB(t)
+	ctx := context.Background()
+
+	// Rows written before tokens were hashed hold the raw token, which some
+	// services generated as hex that looks just like a digest
+	hexToken := strings.Repeat("ab", 32)
+	legacy := []interface{}{
+		&models.PasswordResetToken{UserID: 1, Token: "legacy-reset", ExpiresAt: time.Now().Add(time.Hour)},
+		&models.PasswordResetToken{UserID: 1, Token: hexToken, ExpiresAt: time.Now().Add(time.Hour)},
+		&models.EmailVerificationToken{UserID: 1, Token: "legacy-verify", ExpiresAt: time.Now().Add(time.Hour)},
+		&models.WorkspaceInvitation{WorkspaceID: 3, Email: "new@example.com", Role: models.WorkspaceRoleEditor, Token: "legacy-invite", InvitedBy: 1, ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	for _, row := range legacy {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", row, err)
+		}
+	}
+	if err := db.Create(models.NewPasswordResetToken(1, "current", time.Hour)).Error; err != nil {
+		t.Fatalf("failed to seed reset token: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := db.MigrateTokenHashes(); err != nil {
+			t.Fatalf("MigrateTokenHashes() error = %v", err)
+		}
+	}
+
+	if _, err := db.ConsumeResetToken(ctx, "legacy-reset"); err != nil {
+		t.Errorf("ConsumeResetToken() of a migrated token error = %v", err)
+	}
+	if _, err := db.ConsumeResetToken(ctx, hexToken); err != nil {
+		t.Errorf("ConsumeResetToken() of a migrated hex token error = %v", err)
+	}
+	if _, err := db.ConsumeResetToken(ctx, "current"); err != nil {
+		t.Errorf("ConsumeResetToken() of an already hashed token error = %v", err)
+	}
+	if _, err := db.ConsumeEmailVerificationToken(ctx, "legacy-verify"); err != nil {
+		t.Errorf("ConsumeEmailVerificationToken() of a migrated token error = %v", err)
+	}
+	if _, err := db.ConsumeInvitation(ctx, "legacy-invite"); err != nil {
+		t.Errorf("ConsumeInvitation() of a migrated token error = %v", err)
+	}
+}
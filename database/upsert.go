@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"gorm.io/gorm/clause"
+)
+
+// Upsert inserts the record or, when it conflicts on conflictColumns, updates
+// updateColumns of the existing row, in a single INSERT ... ON CONFLICT statement.
+// With no updateColumns a conflicting insert is skipped (DO NOTHING) without an
+// error. conflictColumns must match a unique index; partial indexes such as the
+// soft-delete ones from MigrateSoftDeleteUniqueIndexes aren't matched on Postgres.
+func (r *KeyedRepository[T, K]) Upsert(entity *T, conflictColumns []string, updateColumns []string) error {
+	return r.UpsertCtx(context.Background(), entity, conflictColumns, updateColumns)
+}
+
+// UpsertCtx is Upsert bound to ctx
+func (r *KeyedRepository[T, K]) UpsertCtx(ctx context.Context, entity *T, conflictColumns []string, updateColumns []string) error {
+	return r.translateWriteError(r.db.WithContext(ctx).Clauses(onConflict(conflictColumns, updateColumns)).Create(entity).Error)
+}
+
+// InsertOnly inserts the record unless it conflicts on conflictColumns, in which
+// case it returns an ALREADY_EXISTS error. Unlike Create, conflicts on other unique
+// indexes still surface as errors from the database.
+func (r *KeyedRepository[T, K]) InsertOnly(entity *T, conflictColumns []string) error {
+	return r.InsertOnlyCtx(context.Background(), entity, conflictColumns)
+}
+
+// InsertOnlyCtx is InsertOnly bound to ctx
+func (r *KeyedRepository[T, K]) InsertOnlyCtx(ctx context.Context, entity *T, conflictColumns []string) error {
+	result := r.db.WithContext(ctx).Clauses(onConflict(conflictColumns, nil)).Create(entity)
+	if result.Error != nil {
+		return r.translateWriteError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAlreadyExistsError(resourceName[T]())
+	}
+	return nil
+}
+
+// onConflict builds the ON CONFLICT clause, doing nothing without updateColumns
+func onConflict(conflictColumns []string, updateColumns []string) clause.OnConflict {
+	conflict := clause.OnConflict{
+		Columns:   make([]clause.Column, len(conflictColumns)),
+		DoNothing: len(updateColumns) == 0,
+	}
+	for i, column := range conflictColumns {
+		conflict.Columns[i] = clause.Column{Name: column}
+	}
+	if len(updateColumns) > 0 {
+		conflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	}
+	return conflict
+}
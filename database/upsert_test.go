@@ -0,0 +1,68 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+func TestRepository_Upsert(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&models.WorkspaceMember{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	repo := database.NewRepository[models.WorkspaceMember](testDB.DB)
+	conflict := []string{"workspace_id", "user_id"}
+
+	member := func(role models.WorkspaceRole) *models.WorkspaceMember {
+		return &models.WorkspaceMember{WorkspaceID: 1, UserID: 1, Role: role}
+	}
+	role := func() models.WorkspaceRole {
+		t.Helper()
+		got, err := repo.FirstWhere("workspace_id = ? AND user_id = ?", 1, 1)
+		if err != nil {
+			t.Fatalf("FirstWhere() error = %v", err)
+		}
+		return got.Role
+	}
+
+	if err := repo.Upsert(member(models.WorkspaceRoleViewer), conflict, []string{"role"}); err != nil {
+		t.Fatalf("inserting Upsert() error = %v", err)
+	}
+	if got := role(); got != models.WorkspaceRoleViewer {
+		t.Errorf("role after insert = %q, want viewer", got)
+	}
+
+	if err := repo.Upsert(member(models.WorkspaceRoleEditor), conflict, []string{"role"}); err != nil {
+		t.Fatalf("updating Upsert() error = %v", err)
+	}
+	if got := role(); got != models.WorkspaceRoleEditor {
+		t.Errorf("role after update = %q, want editor", got)
+	}
+
+	if err := repo.Upsert(member(models.WorkspaceRoleViewer), conflict, nil); err != nil {
+		t.Fatalf("Upsert() without update columns error = %v", err)
+	}
+	if got := role(); got != models.WorkspaceRoleEditor {
+		t.Errorf("role after DO NOTHING = %q, want editor unchanged", got)
+	}
+
+	if count, err := repo.Count(); err != nil || count != 1 {
+		t.Errorf("Count() = %d, %v, want a single member", count, err)
+	}
+
+	err := repo.InsertOnly(member(models.WorkspaceRoleViewer), conflict)
+	if !errors.Is(err, errors.ErrCodeAlreadyExists) {
+		t.Errorf("InsertOnly() on a conflict error = %v, want ALREADY_EXISTS", err)
+	}
+	other := &models.WorkspaceMember{WorkspaceID: 1, UserID: 2, Role: models.WorkspaceRoleViewer}
+	if err := repo.InsertOnly(other, conflict); err != nil || other.ID == 0 {
+		t.Errorf("InsertOnly() without a conflict = %v, id %d", err, other.ID)
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpdateWithVersion updates the record only if its version column still equals
+// expectedVersion, the version it was loaded at, and sets the version to
+// expectedVersion+1. It returns a VERSION_CONFLICT error when another update (or a
+// delete) got there first. The entity needs a primary key and the model a version
+// column, e.g. by embedding models.AuditableModel. Like TenantRepository.Update it
+// leaves created_at and created_by alone.
+func (r *KeyedRepository[T, K]) UpdateWithVersion(entity *T, expectedVersion int64) error {
+	return r.UpdateWithVersionCtx(context.Background(), entity, expectedVersion)
+}
+
+// UpdateWithVersionCtx is UpdateWithVersion bound to ctx
+func (r *KeyedRepository[T, K]) UpdateWithVersionCtx(ctx context.Context, entity *T, expectedVersion int64) error {
+	stmt := &gorm.Statement{DB: r.db.DB}
+	if err := stmt.Parse(entity); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField("version")
+	if field == nil {
+		return fmt.Errorf("%s has no version field", stmt.Schema.Name)
+	}
+	id, err := primaryKeyOf(ctx, stmt.Schema, entity)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claiming the next version first locks the row for the rest of the update.
+		// The version is set here rather than by a model hook, so the lock holds for
+		// any model with a version column.
+		result := tx.Model(new(T)).
+			Where(primaryKeyEquals(id)).
+			Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: expectedVersion}).
+			UpdateColumn(field.DBName, expectedVersion+1)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.NewVersionConflictError(resourceName[T](), expectedVersion)
+		}
+
+		return tx.Model(entity).
+			Where(primaryKeyEquals(id)).
+			Select("*").
+			Omit(append([]string{field.DBName}, auditColumns...)...).
+			Updates(entity).Error
+	})
+
+	// Hooks may have changed the version in memory; store what the row holds, or
+	// leave the entity at the version it was loaded at
+	version := expectedVersion
+	if err == nil {
+		version = expectedVersion + 1
+	}
+	if setErr := field.Set(ctx, reflect.ValueOf(entity), version); setErr != nil {
+		return setErr
+	}
+	return r.translateWriteError(err)
+}
@@ -0,0 +1,136 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+type Document struct {
+	models.AuditableModel
+	Title string
+}
+
+func TestRepository_UpdateWithVersion(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&Document{}, &Widget{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	repo := database.NewRepository[Document](testDB.DB)
+
+	if err := repo.Create(&Document{Title: "draft"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Two users load the same version
+	alice, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	bob, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	loaded := alice.Version
+
+	alice.Title = "alice's edit"
+	if err := repo.UpdateWithVersion(alice, loaded); err != nil {
+		t.Fatalf("first UpdateWithVersion() error = %v", err)
+	}
+	if alice.Version != loaded+1 {
+		t.Errorf("version after update = %d, want %d", alice.Version, loaded+1)
+	}
+
+	bob.Title = "bob's edit"
+	err = repo.UpdateWithVersion(bob, loaded)
+	if !errors.Is(err, errors.ErrCodeVersionConflict) {
+		t.Fatalf("stale UpdateWithVersion() error = %v, want VERSION_CONFLICT", err)
+	}
+	if code := errors.GetHTTPCode(err); code != 409 {
+		t.Errorf("HTTP code = %d, want 409", code)
+	}
+	if bob.Version != loaded {
+		t.Errorf("version after conflict = %d, want %d", bob.Version, loaded)
+	}
+
+	stored, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Title != "alice's edit" || stored.Version != loaded+1 {
+		t.Errorf("stored = %q v%d, want alice's edit at v%d", stored.Title, stored.Version, loaded+1)
+	}
+
+	// Reloading picks up the new version
+	if err := repo.UpdateWithVersion(bob, stored.Version); err != nil {
+		t.Errorf("UpdateWithVersion() after reload error = %v", err)
+	}
+
+	missing := &Document{Title: "gone"}
+	missing.ID = 42
+	if err := repo.UpdateWithVersion(missing, 1); !errors.Is(err, errors.ErrCodeVersionConflict) {
+		t.Errorf("UpdateWithVersion() of a missing record error = %v, want VERSION_CONFLICT", err)
+	}
+
+	if err := database.NewRepository[Widget](testDB.DB).UpdateWithVersion(&Widget{ID: 1}, 1); err == nil {
+		t.Error("expected an error for a model without a version column")
+	}
+}
+
+// Ticket has a version column but no hook incrementing it
+type Ticket struct {
+	ID      uint `gorm:"primaryKey"`
+	Title   string
+	Version int64 `gorm:"default:1"`
+}
+
+func TestRepository_UpdateWithVersion_Guards(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+
+	if err := testDB.Migrate(&Ticket{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	repo := database.NewRepository[Ticket](testDB.DB)
+
+	for _, title := range []string{"first", "second"} {
+		if err := repo.Create(&Ticket{Title: title}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if err := repo.UpdateWithVersion(&Ticket{Title: "wiped"}, 1); err == nil {
+		t.Error("expected UpdateWithVersion without a primary key to fail")
+	}
+	if wiped, err := repo.FindWhere("title = ?", "wiped"); err != nil || len(wiped) != 0 {
+		t.Errorf("FindWhere() = %d tickets, %v, want none overwritten", len(wiped), err)
+	}
+
+	ticket, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	ticket.Title = "edited"
+	if err := repo.UpdateWithVersion(ticket, 1); err != nil {
+		t.Fatalf("UpdateWithVersion() error = %v", err)
+	}
+	if ticket.Version != 2 {
+		t.Errorf("version after update = %d, want 2", ticket.Version)
+	}
+	if err := repo.UpdateWithVersion(ticket, 1); !errors.Is(err, errors.ErrCodeVersionConflict) {
+		t.Errorf("stale UpdateWithVersion() error = %v, want VERSION_CONFLICT", err)
+	}
+
+	stored, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Title != "edited" || stored.Version != 2 {
+		t.Errorf("stored = %q v%d, want edited at v2", stored.Title, stored.Version)
+	}
+}
@@ -4,6 +4,8 @@ package errors
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
 )
 
 // Error represents a structured error with code, message, and details
@@ -56,11 +58,16 @@ const (
 	ErrCodeValidationFailed   = "VALIDATION_FAILED"
 	ErrCodeInvalidInput       = "INVALID_INPUT"
 	ErrCodeMissingField       = "MISSING_FIELD"
+	ErrCodeInvalidReference   = "INVALID_REFERENCE"
+	ErrCodePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	ErrCodeUnsupportedMedia   = "UNSUPPORTED_MEDIA_TYPE"
+	ErrCodeRangeNotSatisfiable = "RANGE_NOT_SATISFIABLE"
 	
 	// Resource errors
 	ErrCodeNotFound           = "NOT_FOUND"
 	ErrCodeAlreadyExists      = "ALREADY_EXISTS"
 	ErrCodeConflict           = "CONFLICT"
+	ErrCodeVersionConflict    = "VERSION_CONFLICT"
 	
 	// Business logic errors
 	ErrCodeBusinessRule       = "BUSINESS_RULE_VIOLATION"
@@ -168,6 +175,56 @@ func NewMissingFieldError(field string) *Error {
 	}
 }
 
+// NewInvalidReferenceError creates an error for a reference to a missing related resource
+func NewInvalidReferenceError(field string) *Error {
+	return &Error{
+		Code:     ErrCodeInvalidReference,
+		Message:  fmt.Sprintf("Field '%s' references a resource that does not exist", field),
+		HTTPCode: http.StatusBadRequest,
+		Details: map[string]interface{}{
+			"field": field,
+		},
+	}
+}
+
+// NewPayloadTooLargeError creates an error for request content over a size or count limit
+func NewPayloadTooLargeError(message string, limit int64) *Error {
+	return &Error{
+		Code:     ErrCodePayloadTooLarge,
+		Message:  message,
+		HTTPCode: http.StatusRequestEntityTooLarge,
+		Details: map[string]interface{}{
+			"limit": limit,
+		},
+	}
+}
+
+// NewUnsupportedMediaTypeError creates an error for content of a type that isn't accepted
+func NewUnsupportedMediaTypeError(contentType string, allowed []string) *Error {
+	return &Error{
+		Code:     ErrCodeUnsupportedMedia,
+		Message:  fmt.Sprintf("Content type '%s' is not supported", contentType),
+		HTTPCode: http.StatusUnsupportedMediaType,
+		Details: map[string]interface{}{
+			"content_type": contentType,
+			"allowed":      allowed,
+		},
+	}
+}
+
+// NewRangeNotSatisfiableError creates an error for a Range header that selects no
+// bytes of content of the given size
+func NewRangeNotSatisfiableError(size int64) *Error {
+	return &Error{
+		Code:     ErrCodeRangeNotSatisfiable,
+		Message:  "Requested range is not satisfiable",
+		HTTPCode: http.StatusRequestedRangeNotSatisfiable,
+		Details: map[string]interface{}{
+			"size": size,
+		},
+	}
+}
+
 // NewNotFoundError creates a not found error
 func NewNotFoundError(resource string) *Error {
 	return &Error{
@@ -201,6 +258,20 @@ func NewConflictError(message string) *Error {
 	}
 }
 
+// NewVersionConflictError creates an error for an optimistic locking update whose
+// record was changed by someone else since it was loaded at expectedVersion
+func NewVersionConflictError(resource string, expectedVersion int64) *Error {
+	return &Error{
+		Code:     ErrCodeVersionConflict,
+		Message:  fmt.Sprintf("%s was modified concurrently", resource),
+		HTTPCode: http.StatusConflict,
+		Details: map[string]interface{}{
+			"resource":         resource,
+			"expected_version": expectedVersion,
+		},
+	}
+}
+
 // NewBusinessRuleError creates a business rule violation error
 func NewBusinessRuleError(rule, message string) *Error {
 	return &Error{
@@ -301,7 +372,7 @@ func NewCacheError(operation string, cause error) *Error {
 type ErrorResponse struct {
 	Error     *Error `json:"error"`
 	RequestID string `json:"request_id,omitempty"`
-	Timestamp string `json:"timestamp"`
+	Timestamp utils.Timestamp `json:"timestamp"`
 }
 
 // NewErrorResponse creates a new error response
@@ -309,7 +380,7 @@ func NewErrorResponse(err *Error, requestID string) *ErrorResponse {
 	return &ErrorResponse{
 		Error:     err,
 		RequestID: requestID,
-		Timestamp: fmt.Sprintf("%d", UnixNow()),
+		Timestamp: utils.TimestampNow(),
 	}
 }
 
@@ -39,14 +39,22 @@ type Checker struct {
 	mutex    sync.RWMutex
 	timeout  time.Duration
 	metadata map[string]interface{}
+
+	// maxConcurrency caps how many checks run at once (0 means unlimited)
+	maxConcurrency int
+
+	// Status change subscribers used by StreamHandler
+	subscribers map[chan HealthResponse]struct{}
+	lastStatus  Status
 }
 
 // NewChecker creates a new health checker
 func NewChecker() *Checker {
 	return &Checker{
-		checks:   make(map[string]Check),
-		timeout:  30 * time.Second,
-		metadata: make(map[string]interface{}),
+		checks:      make(map[string]Check),
+		timeout:     30 * time.Second,
+		metadata:    make(map[string]interface{}),
+		subscribers: make(map[chan HealthResponse]struct{}),
 	}
 }
 
@@ -55,6 +63,14 @@ func (hc *Checker) SetTimeout(timeout time.Duration) {
 	hc.timeout = timeout
 }
 
+// SetMaxConcurrency limits how many checks run in parallel. Zero or a negative
+// value means unlimited, which is the default.
+func (hc *Checker) SetMaxConcurrency(n int) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hc.maxConcurrency = n
+}
+
 // SetMetadata sets metadata for the health checker
 func (hc *Checker) SetMetadata(key string, value interface{}) {
 	hc.mutex.Lock()
@@ -87,19 +103,43 @@ func (hc *Checker) CheckHealth(ctx context.Context) HealthResponse {
 	for key, value := range hc.metadata {
 		metadata[key] = value
 	}
+	maxConcurrency := hc.maxConcurrency
 	hc.mutex.RUnlock()
 
 	results := make(map[string]CheckResult)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
+	// Bound parallelism with a semaphore when a limit is configured
+	var semaphore chan struct{}
+	if maxConcurrency > 0 {
+		semaphore = make(chan struct{}, maxConcurrency)
+	}
+
 	// Run checks concurrently
 	for name, check := range checks {
 		wg.Add(1)
 		go func(name string, check Check) {
 			defer wg.Done()
 			
-			// Create context with timeout
+			if semaphore != nil {
+				select {
+				case semaphore <- struct{}{}:
+					defer func() { <-semaphore }()
+				case <-ctx.Done():
+					mu.Lock()
+					results[name] = CheckResult{
+						Status:    StatusDown,
+						Message:   "Health check was not run",
+						Details:   map[string]interface{}{"error": ctx.Err().Error()},
+						Timestamp: time.Now(),
+					}
+					mu.Unlock()
+					return
+				}
+			}
+			
+			// Create context with timeout (started once the check is allowed to run)
 			checkCtx, cancel := context.WithTimeout(ctx, hc.timeout)
 			defer cancel()
 			
@@ -127,13 +167,17 @@ func (hc *Checker) CheckHealth(ctx context.Context) HealthResponse {
 		}
 	}
 
-	return HealthResponse{
+	response := HealthResponse{
 		Status:    overallStatus,
 		Timestamp: time.Now(),
 		Checks:    results,
 		Metadata:  metadata,
 		System:    getSystemInfo(),
 	}
+
+	hc.publishStatus(response)
+
+	return response
 }
 
 // HealthResponse represents the overall health response
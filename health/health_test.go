@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckHealth_MaxConcurrency(t *testing.T) {
+	const (
+		checkCount     = 20
+		maxConcurrency = 3
+	)
+
+	var running, peak int64
+	slowCheck := func(ctx context.Context) CheckResult {
+		current := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+
+		for {
+			old := atomic.LoadInt64(&peak)
+			if current <= old || atomic.CompareAndSwapInt64(&peak, old, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		return CheckResult{Status: StatusUp}
+	}
+
+	checker := NewChecker()
+	checker.SetMaxConcurrency(maxConcurrency)
+	for i := 0; i < checkCount; i++ {
+		checker.AddCheck(fmt.Sprintf("check-%d", i), slowCheck)
+	}
+
+	response := checker.CheckHealth(context.Background())
+
+	if len(response.Checks) != checkCount {
+		t.Fatalf("expected %d results, got %d", checkCount, len(response.Checks))
+	}
+	if response.Status != StatusUp {
+		t.Errorf("expected status %q, got %q", StatusUp, response.Status)
+	}
+	if got := atomic.LoadInt64(&peak); got > maxConcurrency {
+		t.Errorf("expected at most %d concurrent checks, observed %d", maxConcurrency, got)
+	}
+}
+
+func TestCheckHealth_MaxConcurrencyRespectsTimeout(t *testing.T) {
+	checker := NewChecker()
+	checker.SetTimeout(20 * time.Millisecond)
+	checker.SetMaxConcurrency(1)
+
+	for i := 0; i < 3; i++ {
+		checker.AddCheck(fmt.Sprintf("hanging-%d", i), func(ctx context.Context) CheckResult {
+			<-ctx.Done()
+			return CheckResult{Status: StatusDown, Message: ctx.Err().Error()}
+		})
+	}
+
+	start := time.Now()
+	response := checker.CheckHealth(context.Background())
+	elapsed := time.Since(start)
+
+	if response.Status != StatusDown {
+		t.Errorf("expected status %q, got %q", StatusDown, response.Status)
+	}
+	// Each queued check gets its own full timeout once it starts running
+	if elapsed < 60*time.Millisecond || elapsed > time.Second {
+		t.Errorf("expected checks to run sequentially with per-check timeouts, took %v", elapsed)
+	}
+}
+
+func TestCheckHealth_UnlimitedByDefault(t *testing.T) {
+	const checkCount = 10
+
+	var running int64
+	release := make(chan struct{})
+
+	checker := NewChecker()
+	for i := 0; i < checkCount; i++ {
+		checker.AddCheck(fmt.Sprintf("check-%d", i), func(ctx context.Context) CheckResult {
+			if current := atomic.AddInt64(&running, 1); current == checkCount {
+				close(release)
+			}
+			<-release
+			return CheckResult{Status: StatusUp}
+		})
+	}
+
+	done := make(chan HealthResponse)
+	go func() { done <- checker.CheckHealth(context.Background()) }()
+
+	// Checks only finish once all of them are running at the same time
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("checks did not all run in parallel")
+	}
+}
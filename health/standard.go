@@ -0,0 +1,95 @@
+package health
+
+import "context"
+
+// Standard check names, shared across services so dashboards can rely on them
+const (
+	CheckDatabase   = "database"
+	CheckCache      = "cache"
+	CheckMemory     = "memory"
+	CheckGoroutines = "goroutines"
+)
+
+// Default thresholds of the standard checks
+const (
+	DefaultMemoryWarningBytes  uint64 = 512 << 20 // 512MB
+	DefaultMemoryCriticalBytes uint64 = 1 << 30   // 1GB
+	DefaultGoroutineWarning           = 1000
+	DefaultGoroutineCritical          = 10000
+)
+
+// StandardCheckNames overrides the names the standard checks are registered under;
+// empty fields keep the standard names
+type StandardCheckNames struct {
+	Database   string
+	Cache      string
+	Memory     string
+	Goroutines string
+}
+
+// StandardOptions configures RegisterStandardChecks. Zero thresholds use the defaults.
+type StandardOptions struct {
+	Database interface{ Health() error }                // Skipped when nil
+	Cache    interface{ Health(context.Context) error } // Skipped when nil
+
+	MemoryWarningBytes  uint64
+	MemoryCriticalBytes uint64
+	GoroutineWarning    int
+	GoroutineCritical   int
+
+	Names StandardCheckNames
+}
+
+// RegisterStandardChecks adds the database, cache, memory and goroutine checks to
+// checker with consistent names and thresholds. The database and cache checks are
+// only added when a handle is given. It returns the names of the registered checks.
+func RegisterStandardChecks(checker *Checker, opts StandardOptions) []string {
+	opts = opts.withDefaults()
+
+	var names []string
+	add := func(name string, check Check) {
+		checker.AddCheck(name, check)
+		names = append(names, name)
+	}
+
+	if opts.Database != nil {
+		add(opts.Names.Database, DatabaseCheck(opts.Database))
+	}
+	if opts.Cache != nil {
+		add(opts.Names.Cache, CacheCheck(opts.Cache))
+	}
+	add(opts.Names.Memory, MemoryCheck(opts.MemoryWarningBytes, opts.MemoryCriticalBytes))
+	add(opts.Names.Goroutines, GoroutineCheck(opts.GoroutineWarning, opts.GoroutineCritical))
+
+	return names
+}
+
+// withDefaults fills unset thresholds and names
+func (o StandardOptions) withDefaults() StandardOptions {
+	if o.MemoryWarningBytes == 0 {
+		o.MemoryWarningBytes = DefaultMemoryWarningBytes
+	}
+	if o.MemoryCriticalBytes == 0 {
+		o.MemoryCriticalBytes = DefaultMemoryCriticalBytes
+	}
+	if o.GoroutineWarning == 0 {
+		o.GoroutineWarning = DefaultGoroutineWarning
+	}
+	if o.GoroutineCritical == 0 {
+		o.GoroutineCritical = DefaultGoroutineCritical
+	}
+
+	if o.Names.Database == "" {
+		o.Names.Database = CheckDatabase
+	}
+	if o.Names.Cache == "" {
+		o.Names.Cache = CheckCache
+	}
+	if o.Names.Memory == "" {
+		o.Names.Memory = CheckMemory
+	}
+	if o.Names.Goroutines == "" {
+		o.Names.Goroutines = CheckGoroutines
+	}
+	return o
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type fakeDatabase struct{ err error }
+
+func (f fakeDatabase) Health() error { return f.err }
+
+type fakeCache struct{ err error }
+
+func (f fakeCache) Health(ctx context.Context) error { return f.err }
+
+func TestRegisterStandardChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       StandardOptions
+		wantNames  []string
+		wantStatus Status
+	}{
+		{
+			name:       "all checks",
+			opts:       StandardOptions{Database: fakeDatabase{}, Cache: fakeCache{}},
+			wantNames:  []string{CheckCache, CheckDatabase, CheckGoroutines, CheckMemory},
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "without handles",
+			opts:       StandardOptions{},
+			wantNames:  []string{CheckGoroutines, CheckMemory},
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "renamed checks",
+			opts:       StandardOptions{Database: fakeDatabase{}, Names: StandardCheckNames{Database: "postgres", Memory: "heap"}},
+			wantNames:  []string{CheckGoroutines, "heap", "postgres"},
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "failing cache",
+			opts:       StandardOptions{Cache: fakeCache{err: errors.New("connection refused")}},
+			wantNames:  []string{CheckCache, CheckGoroutines, CheckMemory},
+			wantStatus: StatusDown,
+		},
+		{
+			name:       "goroutine threshold",
+			opts:       StandardOptions{GoroutineWarning: 1, GoroutineCritical: 1 << 20},
+			wantNames:  []string{CheckGoroutines, CheckMemory},
+			wantStatus: StatusWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker()
+			registered := RegisterStandardChecks(checker, tt.opts)
+			sort.Strings(registered)
+			if !reflect.DeepEqual(registered, tt.wantNames) {
+				t.Errorf("RegisterStandardChecks() = %v, want %v", registered, tt.wantNames)
+			}
+
+			response := checker.CheckHealth(context.Background())
+			var ran []string
+			for name := range response.Checks {
+				ran = append(ran, name)
+			}
+			sort.Strings(ran)
+			if !reflect.DeepEqual(ran, tt.wantNames) {
+				t.Errorf("checks run = %v, want %v", ran, tt.wantNames)
+			}
+			if response.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", response.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultStreamInterval is the interval used by StreamHandler when none is given
+const DefaultStreamInterval = 5 * time.Second
+
+// StreamHandler returns a Gin handler that streams health updates as Server-Sent Events.
+// A HealthResponse is pushed immediately on connect, then every interval, and as soon as
+// any health check run observes a change in the overall status. The stream ends when the
+// client disconnects (request context is cancelled).
+func (hc *Checker) StreamHandler(interval time.Duration) gin.HandlerFunc {
+	if interval <= 0 {
+		interval = DefaultStreamInterval
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		updates := hc.subscribe()
+		defer hc.unsubscribe(updates)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastSent time.Time
+		send := func(response HealthResponse) {
+			c.SSEvent("health", response)
+			c.Writer.Flush()
+			lastSent = response.Timestamp
+		}
+
+		send(hc.CheckHealth(ctx))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				send(hc.CheckHealth(ctx))
+			case response := <-updates:
+				// Skip the notification raised by our own periodic check
+				if response.Timestamp.Equal(lastSent) {
+					continue
+				}
+				send(response)
+			}
+		}
+	}
+}
+
+// subscribe registers a channel that receives responses whose overall status changed
+func (hc *Checker) subscribe() chan HealthResponse {
+	ch := make(chan HealthResponse, 1)
+
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hc.subscribers[ch] = struct{}{}
+
+	return ch
+}
+
+// unsubscribe removes a status change subscription
+func (hc *Checker) unsubscribe(ch chan HealthResponse) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	delete(hc.subscribers, ch)
+}
+
+// subscriberCount returns the number of active stream subscriptions
+func (hc *Checker) subscriberCount() int {
+	hc.mutex.RLock()
+	defer hc.mutex.RUnlock()
+	return len(hc.subscribers)
+}
+
+// publishStatus notifies subscribers when the overall status differs from the last run
+func (hc *Checker) publishStatus(response HealthResponse) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	if hc.lastStatus == response.Status {
+		return
+	}
+	hc.lastStatus = response.Status
+
+	for ch := range hc.subscribers {
+		// Replace any pending update so slow readers always see the latest status
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- response:
+		default:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var down atomic.Bool
+	checker := NewChecker()
+	checker.AddCheck("dependency", func(ctx context.Context) CheckResult {
+		if down.Load() {
+			return CheckResult{Status: StatusDown}
+		}
+		return CheckResult{Status: StatusUp}
+	})
+
+	router := gin.New()
+	router.GET("/health/stream", checker.StreamHandler(20*time.Millisecond))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/health/stream", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("expected text/event-stream content type, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() HealthResponse {
+		t.Helper()
+		var event HealthResponse
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %v", err)
+			}
+			if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					t.Fatalf("failed to decode event: %v", err)
+				}
+				return event
+			}
+		}
+	}
+
+	if event := readEvent(); event.Status != StatusUp {
+		t.Errorf("expected first event status %q, got %q", StatusUp, event.Status)
+	}
+	if event := readEvent(); event.Status != StatusUp {
+		t.Errorf("expected second event status %q, got %q", StatusUp, event.Status)
+	}
+
+	down.Store(true)
+	for i := 0; ; i++ {
+		if event := readEvent(); event.Status == StatusDown {
+			break
+		}
+		if i > 10 {
+			t.Fatal("status change was never streamed")
+		}
+	}
+
+	if got := checker.subscriberCount(); got != 1 {
+		t.Errorf("expected 1 active subscriber, got %d", got)
+	}
+
+	cancel()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for checker.subscriberCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream goroutine did not exit after client disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPublishStatusOnlyOnChange(t *testing.T) {
+	checker := NewChecker()
+	updates := checker.subscribe()
+	defer checker.unsubscribe(updates)
+
+	checker.publishStatus(HealthResponse{Status: StatusUp})
+	select {
+	case <-updates:
+	default:
+		t.Fatal("expected notification for initial status")
+	}
+
+	checker.publishStatus(HealthResponse{Status: StatusUp})
+	select {
+	case <-updates:
+		t.Fatal("unexpected notification for unchanged status")
+	default:
+	}
+
+	checker.publishStatus(HealthResponse{Status: StatusWarning})
+	select {
+	case response := <-updates:
+		if response.Status != StatusWarning {
+			t.Errorf("expected %q, got %q", StatusWarning, response.Status)
+		}
+	default:
+		t.Fatal("expected notification for status change")
+	}
+}
@@ -0,0 +1,361 @@
+// Package importer streams tabular data into table records
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"gorm.io/gorm"
+)
+
+// Defaults for import options
+const (
+	DefaultBatchSize = 500
+	DefaultMaxErrors = 100
+)
+
+// RecordWriter persists a batch of imported records
+type RecordWriter interface {
+	WriteRecords(ctx context.Context, records []models.Record) error
+}
+
+// dryRunner is implemented by writers that can rehearse an import against the
+// store inside a transaction that is always rolled back
+type dryRunner interface {
+	dryRun(ctx context.Context, fn func(RecordWriter) error) error
+}
+
+// errDryRunRollback rolls back the transaction of a dry run
+var errDryRunRollback = stderrors.New("importer: dry run rollback")
+
+// dbWriter writes records with a single insert per batch
+type dbWriter struct {
+	db        *database.DB
+	savepoint bool // wrap each batch in a savepoint so a failed batch doesn't abort the transaction
+}
+
+// NewDBWriter returns a RecordWriter inserting records into the database
+func NewDBWriter(db *database.DB) RecordWriter {
+	return &dbWriter{db: db}
+}
+
+// WriteRecords inserts the batch of records
+func (w *dbWriter) WriteRecords(ctx context.Context, records []models.Record) error {
+	if w.savepoint {
+		return database.TranslateError(w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&records).Error
+		}))
+	}
+	return database.TranslateError(w.db.WithContext(ctx).Create(&records).Error)
+}
+
+// dryRun runs fn with a writer bound to a transaction that is rolled back afterwards,
+// so inserts hit the database constraints without persisting anything
+func (w *dbWriter) dryRun(ctx context.Context, fn func(RecordWriter) error) error {
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(&dbWriter{db: &database.DB{DB: tx}, savepoint: true}); err != nil {
+			return err
+		}
+		return errDryRunRollback
+	})
+	if stderrors.Is(err, errDryRunRollback) {
+		return nil
+	}
+	return err
+}
+
+// discardWriter accepts every batch without storing it
+type discardWriter struct{}
+
+// WriteRecords drops the batch
+func (discardWriter) WriteRecords(ctx context.Context, records []models.Record) error {
+	return nil
+}
+
+// Options configures an import
+type Options struct {
+	TableID    uint                      // Table receiving the records
+	Fields     []models.Field            // Fields of the table; values are coerced to their types
+	Mapping    map[string]string         // Source column -> field name; defaults to columns matching field names
+	BatchSize  int                       // Records per insert (default 500)
+	MaxErrors  int                       // Row errors kept in the result (default 100); all failures are still counted
+	OnProgress func(models.ImportResult) // Called with the running totals after each batch
+	DryRun     bool                      // Validate and count without persisting anything
+}
+
+// Importer streams rows through value coercion and batched inserts
+type Importer struct {
+	writer  RecordWriter
+	metrics *metrics.Registry // set by WithMetrics
+}
+
+// New creates an importer writing records through writer
+func New(writer RecordWriter) *Importer {
+	return &Importer{writer: writer}
+}
+
+// WithMetrics returns a copy of the importer that records the rows and duration
+// of every import that isn't a dry run into registry
+func (i *Importer) WithMetrics(registry *metrics.Registry) *Importer {
+	instrumented := *i
+	instrumented.metrics = registry
+	return &instrumented
+}
+
+// ImportCSV streams a CSV document from r into records without buffering the whole
+// input. The first row must be a header naming the columns. Rows with values that
+// can't be coerced are reported in the result and skipped. On context cancellation
+// the result so far is returned together with the context error.
+//
+// With DryRun set the result describes what a real import would do, but nothing is
+// persisted: the database writer inserts inside a transaction that is rolled back,
+// and other writers are never called.
+func (i *Importer) ImportCSV(ctx context.Context, r io.Reader, opts Options) (*models.ImportResult, error) {
+	opts = withDefaults(opts)
+
+	if !opts.DryRun {
+		start := time.Now()
+		result, err := importCSV(ctx, i.writer, r, opts)
+		if i.metrics != nil && result != nil {
+			i.metrics.RecordImport(result.Summary, time.Since(start))
+		}
+		return result, err
+	}
+
+	runner, ok := i.writer.(dryRunner)
+	if !ok {
+		return importCSV(ctx, discardWriter{}, r, opts)
+	}
+
+	var result *models.ImportResult
+	err := runner.dryRun(ctx, func(writer RecordWriter) error {
+		var err error
+		result, err = importCSV(ctx, writer, r, opts)
+		return err
+	})
+	return result, err
+}
+
+// importCSV runs a CSV import through writer
+func importCSV(ctx context.Context, writer RecordWriter, r io.Reader, opts Options) (*models.ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("empty CSV input")
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns, err := resolveColumns(header, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	run := newImportRun(writer, opts)
+
+	for row := 1; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return run.result, err
+		}
+
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return run.result, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			run.result.TotalRows++
+			run.fail(row, "", err.Error())
+			run.countFailed(1)
+			continue
+		}
+
+		run.result.TotalRows++
+		run.addRow(ctx, row, columns, values)
+
+		if len(run.batch) >= opts.BatchSize {
+			if err := run.flush(ctx); err != nil {
+				return run.result, err
+			}
+		}
+	}
+
+	if err := run.flush(ctx); err != nil {
+		return run.result, err
+	}
+
+	return run.result, nil
+}
+
+// column maps a source column position to its target field
+type column struct {
+	index int
+	field models.Field
+}
+
+// resolveColumns matches header columns to fields using the mapping
+func resolveColumns(header []string, opts Options) ([]column, error) {
+	fields := make(map[string]models.Field, len(opts.Fields))
+	for _, field := range opts.Fields {
+		fields[field.Name] = field
+	}
+
+	var columns []column
+	for index, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+
+		fieldName := name
+		if len(opts.Mapping) > 0 {
+			mapped, ok := opts.Mapping[name]
+			if !ok {
+				continue
+			}
+			fieldName = mapped
+		}
+
+		field, ok := fields[fieldName]
+		if !ok {
+			if len(opts.Mapping) > 0 {
+				return nil, fmt.Errorf("column %q is mapped to unknown field %q", name, fieldName)
+			}
+			continue
+		}
+
+		columns = append(columns, column{index: index, field: field})
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no CSV columns match the table fields")
+	}
+
+	return columns, nil
+}
+
+// withDefaults fills unset options
+func withDefaults(opts Options) Options {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.MaxErrors <= 0 {
+		opts.MaxErrors = DefaultMaxErrors
+	}
+	return opts
+}
+
+// importRun holds the state of a single import
+type importRun struct {
+	writer   RecordWriter
+	opts     Options
+	result   *models.ImportResult
+	batch    []models.Record
+	firstRow int
+}
+
+// newImportRun creates the state for an import
+func newImportRun(writer RecordWriter, opts Options) *importRun {
+	return &importRun{
+		writer: writer,
+		opts:   opts,
+		result: &models.ImportResult{Errors: []models.ImportError{}},
+		batch:  make([]models.Record, 0, opts.BatchSize),
+	}
+}
+
+// addRow coerces a row into a record and queues it for insertion
+func (r *importRun) addRow(ctx context.Context, row int, columns []column, values []string) {
+	data := make(models.JSON, len(columns))
+	failed := false
+
+	for _, col := range columns {
+		raw := ""
+		if col.index < len(values) {
+			raw = values[col.index]
+		}
+
+		value, err := models.CoerceValue(raw, col.field.Type, col.field.Options)
+		if err != nil {
+			r.fail(row, col.field.Name, err.Error())
+			failed = true
+			continue
+		}
+		if value == nil && col.field.Required {
+			r.fail(row, col.field.Name, "value is required")
+			failed = true
+			continue
+		}
+		if value != nil {
+			data[col.field.Name] = value
+		}
+	}
+
+	if failed {
+		r.countFailed(1)
+		return
+	}
+
+	if len(r.batch) == 0 {
+		r.firstRow = row
+	}
+	r.batch = append(r.batch, models.Record{TableID: r.opts.TableID, Data: data})
+}
+
+// flush writes the queued batch and reports progress
+func (r *importRun) flush(ctx context.Context) error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+
+	err := r.writer.WriteRecords(ctx, r.batch)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.fail(r.firstRow, "", fmt.Sprintf("failed to insert rows %d-%d: %v", r.firstRow, r.firstRow+len(r.batch)-1, err))
+		r.countFailed(len(r.batch))
+	} else {
+		r.result.SuccessCount += len(r.batch)
+		r.result.Summary.Created += len(r.batch)
+	}
+
+	r.batch = r.batch[:0]
+
+	if r.opts.OnProgress != nil {
+		r.opts.OnProgress(r.snapshot())
+	}
+
+	return nil
+}
+
+// fail records a row error, keeping at most MaxErrors of them
+func (r *importRun) fail(row int, field, message string) {
+	if len(r.result.Errors) < r.opts.MaxErrors {
+		r.result.Errors = append(r.result.Errors, models.ImportError{Row: row, Field: field, Message: message})
+	}
+}
+
+// countFailed counts rows that were not imported
+func (r *importRun) countFailed(rows int) {
+	r.result.FailedCount += rows
+	r.result.Summary.Failed += rows
+}
+
+// snapshot copies the running result for progress callbacks
+func (r *importRun) snapshot() models.ImportResult {
+	result := *r.result
+	result.Errors = append([]models.ImportError(nil), r.result.Errors...)
+	return result
+}
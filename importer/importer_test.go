@@ -0,0 +1,314 @@
+package importer_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/importer"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var testFields = []models.Field{
+	{Name: "Name", Type: models.FieldTypeText, Required: true},
+	{Name: "Age", Type: models.FieldTypeNumber},
+	{Name: "Email", Type: models.FieldTypeEmail},
+}
+
+func TestImportCSV_Database(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+	if err := testDB.Migrate(&models.Record{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"Name,Age,Email,Ignored",
+		"Ada,36,ada@example.com,x",
+		"Grace,not-a-number,grace@example.com,x",
+		",40,missing@example.com,x",
+		"Linus,54,,x",
+		"Ken,79,KEN@example.com,x",
+	}, "\n")
+
+	var progress []models.ImportResult
+	result, err := importer.New(importer.NewDBWriter(testDB.DB)).ImportCSV(context.Background(), strings.NewReader(input), importer.Options{
+		TableID:    7,
+		Fields:     testFields,
+		BatchSize:  2,
+		OnProgress: func(r models.ImportResult) { progress = append(progress, r) },
+	})
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+
+	if result.TotalRows != 5 || result.SuccessCount != 3 || result.FailedCount != 2 || result.Summary.Created != 3 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Row != 2 || result.Errors[0].Field != "Age" || result.Errors[1].Field != "Name" {
+		t.Errorf("unexpected errors: %+v", result.Errors)
+	}
+	if len(progress) != 2 || progress[0].SuccessCount != 2 || progress[1].SuccessCount != 3 {
+		t.Errorf("expected incremental progress per batch, got %+v", progress)
+	}
+
+	var records []models.Record
+	if err := testDB.Order("id").Find(&records).Error; err != nil {
+		t.Fatalf("failed to load records: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if age, _ := records[0].Data.GetInt("Age"); records[0].TableID != 7 || records[0].Data["Name"] != "Ada" || age != 36 {
+		t.Errorf("unexpected first record: %+v", records[0].Data)
+	}
+	if _, ok := records[1].Data["Email"]; ok {
+		t.Errorf("expected empty email to be omitted, got %+v", records[1].Data)
+	}
+	if records[2].Data["Email"] != "ken@example.com" {
+		t.Errorf("expected coerced email, got %+v", records[2].Data)
+	}
+}
+
+func TestImportCSV_DryRun(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+	if err := testDB.Migrate(&models.Record{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"Name,Age,Email",
+		"Ada,36,ada@example.com",
+		"Grace,not-a-number,grace@example.com",
+		",40,missing@example.com",
+		"Linus,54,",
+		"Ken,79,ken@example.com",
+	}, "\n")
+
+	imp := importer.New(importer.NewDBWriter(testDB.DB))
+	opts := importer.Options{TableID: 7, Fields: testFields, BatchSize: 2}
+
+	dryOpts := opts
+	dryOpts.DryRun = true
+	preview, err := imp.ImportCSV(context.Background(), strings.NewReader(input), dryOpts)
+	if err != nil {
+		t.Fatalf("dry run ImportCSV() error = %v", err)
+	}
+
+	var count int64
+	if err := testDB.Model(&models.Record{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count records: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected dry run to persist nothing, found %d records", count)
+	}
+
+	result, err := imp.ImportCSV(context.Background(), strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if preview.TotalRows != result.TotalRows || preview.SuccessCount != result.SuccessCount ||
+		preview.FailedCount != result.FailedCount || preview.Summary != result.Summary {
+		t.Errorf("dry run result %+v differs from real run %+v", preview, result)
+	}
+	if len(preview.Errors) != len(result.Errors) {
+		t.Errorf("dry run errors %+v differ from real run %+v", preview.Errors, result.Errors)
+	}
+
+	if err := testDB.Model(&models.Record{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count records: %v", err)
+	}
+	if count != int64(result.SuccessCount) {
+		t.Errorf("expected %d records after the real run, found %d", result.SuccessCount, count)
+	}
+}
+
+func TestImportCSV_DryRunSkipsCustomWriter(t *testing.T) {
+	writer := &countingWriter{}
+	input := "Name,Age\nAda,36\nGrace,x\n"
+
+	result, err := importer.New(writer).ImportCSV(context.Background(), strings.NewReader(input), importer.Options{
+		Fields: testFields,
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if result.SuccessCount != 1 || result.FailedCount != 1 {
+		t.Errorf("unexpected dry run result: %+v", result)
+	}
+	if writer.count != 0 {
+		t.Errorf("expected dry run not to call the writer, wrote %d records", writer.count)
+	}
+}
+
+func TestImportCSV_Mapping(t *testing.T) {
+	writer := &countingWriter{}
+	input := "Full Name,Years\nAda,36\n"
+
+	result, err := importer.New(writer).ImportCSV(context.Background(), strings.NewReader(input), importer.Options{
+		Fields:  testFields,
+		Mapping: map[string]string{"Full Name": "Name", "Years": "Age"},
+	})
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if result.SuccessCount != 1 || writer.last["Name"] != "Ada" || writer.last["Age"] != 36.0 {
+		t.Errorf("unexpected mapped import: %+v, %+v", result, writer.last)
+	}
+
+	_, err = importer.New(writer).ImportCSV(context.Background(), strings.NewReader(input), importer.Options{
+		Fields:  testFields,
+		Mapping: map[string]string{"Full Name": "Unknown"},
+	})
+	if err == nil {
+		t.Error("expected mapping to an unknown field to fail")
+	}
+}
+
+func TestImportCSV_StreamsLargeInputWithBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large import in short mode")
+	}
+
+	const rows = 200000
+	input := newCSVGenerator(rows)
+	writer := &countingWriter{}
+
+	runtime.GC()
+	var baseline runtime.MemStats
+	runtime.ReadMemStats(&baseline)
+
+	var peak uint64
+	batches := 0
+	result, err := importer.New(writer).ImportCSV(context.Background(), input, importer.Options{
+		Fields:    testFields,
+		BatchSize: 1000,
+		OnProgress: func(models.ImportResult) {
+			batches++
+			if batches%50 != 0 {
+				return
+			}
+			runtime.GC()
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > peak {
+				peak = stats.HeapAlloc
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+
+	if result.TotalRows != rows || result.SuccessCount != rows || writer.count != rows {
+		t.Errorf("unexpected counts: result %+v, written %d", result, writer.count)
+	}
+
+	const limit = 16 << 20
+	if input.bytes < 2*limit {
+		t.Fatalf("input of %d bytes too small to prove streaming", input.bytes)
+	}
+	if peak > baseline.HeapAlloc && peak-baseline.HeapAlloc > limit {
+		t.Errorf("heap grew by %d bytes importing %d bytes of CSV; expected streaming", peak-baseline.HeapAlloc, input.bytes)
+	}
+}
+
+func TestImportCSV_ContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result, err := importer.New(&countingWriter{}).ImportCSV(ctx, newCSVGenerator(10000), importer.Options{
+		Fields:     testFields,
+		BatchSize:  100,
+		OnProgress: func(models.ImportResult) { cancel() },
+	})
+	if err != context.Canceled {
+		t.Fatalf("ImportCSV() error = %v, want context.Canceled", err)
+	}
+	if result == nil || result.SuccessCount != 100 {
+		t.Errorf("expected partial result after first batch, got %+v", result)
+	}
+}
+
+// countingWriter counts written records without keeping them
+type countingWriter struct {
+	count int
+	last  models.JSON
+}
+
+func (w *countingWriter) WriteRecords(ctx context.Context, records []models.Record) error {
+	w.count += len(records)
+	w.last = records[len(records)-1].Data
+	return nil
+}
+
+// csvGenerator produces a CSV document row by row without holding it in memory
+type csvGenerator struct {
+	rows    int
+	next    int
+	pending []byte
+	bytes   int
+}
+
+func newCSVGenerator(rows int) *csvGenerator {
+	return &csvGenerator{rows: rows, pending: []byte("Name,Age,Email,Notes\n")}
+}
+
+func (g *csvGenerator) Read(p []byte) (int, error) {
+	for len(g.pending) == 0 {
+		if g.next == g.rows {
+			return 0, io.EOF
+		}
+		g.next++
+		g.pending = []byte(fmt.Sprintf("User %d,%d,user%d@example.com,%s\n", g.next, g.next%90, g.next, strings.Repeat("n", 160)))
+	}
+	n := copy(p, g.pending)
+	g.pending = g.pending[n:]
+	g.bytes += n
+	return n, nil
+}
+
+func TestImportCSV_RecordsMetrics(t *testing.T) {
+	registry := metrics.New("test")
+	imp := importer.New(&countingWriter{}).WithMetrics(registry)
+	input := "Name,Age\nAda,36\nGrace,x\nLinus,54\n"
+
+	result, err := imp.ImportCSV(context.Background(), strings.NewReader(input), importer.Options{Fields: testFields})
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if result.Summary != (models.ImportSummary{Created: 2, Failed: 1}) {
+		t.Fatalf("Summary = %+v", result.Summary)
+	}
+
+	rows := map[string]float64{
+		metrics.ImportResultCreated: 2,
+		metrics.ImportResultUpdated: 0,
+		metrics.ImportResultSkipped: 0,
+		metrics.ImportResultFailed:  1,
+	}
+	for label, want := range rows {
+		if got := testutil.ToFloat64(registry.ImportRowsTotal.WithLabelValues(label)); got != want {
+			t.Errorf("import_rows_total{result=%q} = %v, want %v", label, got, want)
+		}
+	}
+	if got := testutil.CollectAndCount(registry.ImportDuration); got != 1 {
+		t.Errorf("expected an import duration observation, got %d", got)
+	}
+
+	// Dry runs describe an import without performing it, so they aren't recorded
+	if _, err := imp.ImportCSV(context.Background(), strings.NewReader(input), importer.Options{Fields: testFields, DryRun: true}); err != nil {
+		t.Fatalf("ImportCSV() dry run error = %v", err)
+	}
+	if got := testutil.ToFloat64(registry.ImportRowsTotal.WithLabelValues(metrics.ImportResultCreated)); got != 2 {
+		t.Errorf("dry run recorded metrics: created = %v", got)
+	}
+}
@@ -0,0 +1,26 @@
+package logger
+
+import "context"
+
+// ContextKey is the type of context keys shared by the logger and HTTP middleware
+type ContextKey string
+
+// RequestIDKey is the context key holding the request ID. The request logging middleware
+// sets it; loggers, error responses and metrics read it so they all report the same ID.
+const RequestIDKey ContextKey = "request_id"
+
+// RequestIDHeader is the HTTP header carrying the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(RequestIDKey).(string); ok {
+		return requestID
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"net/http"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// levelRequest is the body of a log level update
+type levelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// LevelHandler returns a handler reporting (GET) and changing (PUT) the log level,
+// e.g. PUT {"level": "debug"} while investigating an incident. It performs no
+// authorization itself; mount it behind admin-only middleware such as
+// middleware.RequireRole("admin").
+func (l *Logger) LevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet {
+			c.JSON(http.StatusOK, gin.H{"level": l.Level()})
+			return
+		}
+
+		var req levelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, errors.NewMissingFieldError("level"))
+			return
+		}
+
+		previous := l.Level()
+		if err := l.SetLevel(req.Level); err != nil {
+			respondError(c, errors.NewInvalidInputError("level", err.Error()))
+			return
+		}
+
+		l.Warn("Log level changed", zap.String("from", previous), zap.String("to", l.Level()))
+		c.JSON(http.StatusOK, gin.H{"level": l.Level()})
+	}
+}
+
+// respondError writes err as an ErrorResponse with its own status. The logger can't
+// use response.RespondError because the response package depends on it.
+func respondError(c *gin.Context, err *errors.Error) {
+	requestID := RequestIDFromContext(c.Request.Context())
+	c.AbortWithStatusJSON(errors.GetHTTPCode(err), errors.NewErrorResponse(err, requestID))
+}
@@ -6,16 +6,20 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Logger wraps zap.Logger with additional functionality
 type Logger struct {
 	*zap.Logger
 	config *config.LoggerConfig
+	level  *zap.AtomicLevel
 }
 
 // New creates a new logger instance
@@ -27,7 +31,8 @@ func New(cfg *config.LoggerConfig) (*Logger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid log level %s: %w", cfg.Level, err)
 	}
-	zapConfig.Level = zap.NewAtomicLevelAt(level)
+	atomicLevel := zap.NewAtomicLevelAt(level)
+	zapConfig.Level = atomicLevel
 	
 	// Set encoding format
 	switch strings.ToLower(cfg.Format) {
@@ -69,6 +74,7 @@ func New(cfg *config.LoggerConfig) (*Logger, error) {
 	return &Logger{
 		Logger: logger,
 		config: cfg,
+		level:  &atomicLevel,
 	}, nil
 }
 
@@ -92,13 +98,73 @@ func NewProduction() (*Logger, error) {
 	return New(cfg)
 }
 
+// Environment names understood by NewForEnvironment
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentStaging     = "staging"
+	EnvironmentTest        = "test"
+	EnvironmentProduction  = "production"
+)
+
+// ConfigForEnvironment returns the logger defaults for env: console output at debug
+// level for development, JSON at debug level for staging and JSON at info level for
+// production. Unknown or empty environments get the production defaults. The test
+// environment has no config since it logs in memory (see NewTest).
+func ConfigForEnvironment(env string) *config.LoggerConfig {
+	switch normalizeEnvironment(env) {
+	case EnvironmentDevelopment:
+		return &config.LoggerConfig{Level: "debug", Format: "console", OutputPath: "stdout"}
+	case EnvironmentStaging:
+		return &config.LoggerConfig{Level: "debug", Format: "json", OutputPath: "stdout"}
+	default:
+		return &config.LoggerConfig{Level: "info", Format: "json", OutputPath: "stdout"}
+	}
+}
+
+// NewForEnvironment creates a logger with the defaults for env. The test environment
+// gets an in-memory logger so test output stays clean.
+func NewForEnvironment(env string) (*Logger, error) {
+	if normalizeEnvironment(env) == EnvironmentTest {
+		log, _ := NewTest()
+		return log, nil
+	}
+	return New(ConfigForEnvironment(env))
+}
+
+// NewTest creates a logger that keeps entries in memory at debug level and returns
+// them for assertions. SetLevel works as for other loggers.
+func NewTest() (*Logger, *observer.ObservedLogs) {
+	atomicLevel := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	core, logs := observer.New(atomicLevel)
+
+	return &Logger{
+		Logger: zap.New(core),
+		config: &config.LoggerConfig{Level: "debug"},
+		level:  &atomicLevel,
+	}, logs
+}
+
+// normalizeEnvironment maps environment names and common aliases to the Environment constants
+func normalizeEnvironment(env string) string {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "development", "dev", "local":
+		return EnvironmentDevelopment
+	case "staging", "stage":
+		return EnvironmentStaging
+	case "test", "testing":
+		return EnvironmentTest
+	default:
+		return EnvironmentProduction
+	}
+}
+
 // WithContext returns a logger with context fields
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	logger := l.Logger
 	
 	// Add request ID if available
-	if requestID := ctx.Value("request_id"); requestID != nil {
-		logger = logger.With(zap.String("request_id", requestID.(string)))
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		logger = logger.With(zap.String("request_id", requestID))
 	}
 	
 	// Add user ID if available
@@ -114,6 +180,7 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	return &Logger{
 		Logger: logger,
 		config: l.config,
+		level:  l.level,
 	}
 }
 
@@ -127,6 +194,7 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(zapFields...),
 		config: l.config,
+		level:  l.level,
 	}
 }
 
@@ -135,6 +203,7 @@ func (l *Logger) WithError(err error) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(zap.Error(err)),
 		config: l.config,
+		level:  l.level,
 	}
 }
 
@@ -235,6 +304,40 @@ func (l *Logger) LogPerformanceMetric(operation string, duration int64, metadata
 	l.Info("Performance metric", fields...)
 }
 
+// Level returns the current log level
+func (l *Logger) Level() string {
+	if l.level == nil {
+		return l.levelFromCore().String()
+	}
+	return l.level.Level().String()
+}
+
+// SetLevel changes the log level at runtime. Loggers derived with WithContext,
+// WithFields or WithError share the level. An invalid level returns an error and
+// leaves the current level unchanged.
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %s: %w", level, err)
+	}
+	if l.level == nil {
+		return fmt.Errorf("log level of this logger cannot be changed")
+	}
+
+	l.level.SetLevel(parsed)
+	return nil
+}
+
+// levelFromCore returns the lowest level enabled by the underlying core
+func (l *Logger) levelFromCore() zapcore.Level {
+	for level := zapcore.DebugLevel; level < zapcore.FatalLevel; level++ {
+		if l.Core().Enabled(level) {
+			return level
+		}
+	}
+	return zapcore.FatalLevel
+}
+
 // Sync flushes any buffered log entries
 func (l *Logger) Sync() error {
 	return l.Logger.Sync()
@@ -245,56 +348,63 @@ func (l *Logger) Close() error {
 	return l.Sync()
 }
 
-// Global logger instance
-var defaultLogger *Logger
+// Global logger instance, created on first use unless set with SetDefault
+var (
+	defaultLogger atomic.Pointer[Logger]
+	defaultOnce   sync.Once
+)
 
-// init initializes the default logger
-func init() {
-	var err error
-	if os.Getenv("ENVIRONMENT") == "development" {
-		defaultLogger, err = NewDevelopment()
-	} else {
-		defaultLogger, err = NewProduction()
-	}
-	
-	if err != nil {
-		panic(fmt.Sprintf("Failed to initialize default logger: %v", err))
+// Default returns the global logger. Unless SetDefault was called first, it is
+// created on first use from the ENVIRONMENT variable via NewForEnvironment.
+func Default() *Logger {
+	if log := defaultLogger.Load(); log != nil {
+		return log
 	}
+
+	defaultOnce.Do(func() {
+		log, err := NewForEnvironment(os.Getenv("ENVIRONMENT"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to initialize default logger: %v\n", err)
+			log = &Logger{Logger: zap.NewNop()}
+		}
+		defaultLogger.CompareAndSwap(nil, log)
+	})
+	return defaultLogger.Load()
 }
 
 // Global logging functions
 
 // Debug logs a debug message
 func Debug(msg string, fields ...zap.Field) {
-	defaultLogger.Debug(msg, fields...)
+	Default().Debug(msg, fields...)
 }
 
 // Info logs an info message
 func Info(msg string, fields ...zap.Field) {
-	defaultLogger.Info(msg, fields...)
+	Default().Info(msg, fields...)
 }
 
 // Warn logs a warning message
 func Warn(msg string, fields ...zap.Field) {
-	defaultLogger.Warn(msg, fields...)
+	Default().Warn(msg, fields...)
 }
 
 // Error logs an error message
 func Error(msg string, fields ...zap.Field) {
-	defaultLogger.Error(msg, fields...)
+	Default().Error(msg, fields...)
 }
 
 // Fatal logs a fatal message and exits
 func Fatal(msg string, fields ...zap.Field) {
-	defaultLogger.Fatal(msg, fields...)
+	Default().Fatal(msg, fields...)
 }
 
 // With returns a logger with additional fields
 func With(fields ...zap.Field) *zap.Logger {
-	return defaultLogger.With(fields...)
+	return Default().With(fields...)
 }
 
 // SetDefault sets the default logger
 func SetDefault(logger *Logger) {
-	defaultLogger = logger
+	defaultLogger.Store(logger)
 }
\ No newline at end of file
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestLogger(t *testing.T, level string) *Logger {
+	t.Helper()
+
+	log, err := New(&config.LoggerConfig{Level: level, Format: "json", OutputPath: "stdout"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return log
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	log := newTestLogger(t, "info")
+	derived := log.WithFields(map[string]interface{}{"component": "test"})
+
+	if log.Core().Enabled(zap.DebugLevel) {
+		t.Fatal("expected debug logs to be disabled at info level")
+	}
+
+	if err := log.SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if !log.Core().Enabled(zap.DebugLevel) {
+		t.Error("expected debug logs to pass after SetLevel(debug)")
+	}
+	if !derived.Core().Enabled(zap.DebugLevel) {
+		t.Error("expected derived loggers to share the level")
+	}
+	if got := log.Level(); got != "debug" {
+		t.Errorf("Level() = %q, want debug", got)
+	}
+
+	if err := log.SetLevel("verbose"); err == nil {
+		t.Error("expected invalid level to be rejected")
+	}
+	if got := log.Level(); got != "debug" {
+		t.Errorf("invalid level changed the level to %q", got)
+	}
+}
+
+func TestLogger_SetLevelWithoutAtomicLevel(t *testing.T) {
+	log := &Logger{Logger: zap.NewNop()}
+
+	if err := log.SetLevel("debug"); err == nil {
+		t.Error("expected an error for a logger without an adjustable level")
+	}
+}
+
+func TestLogger_LevelHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := newTestLogger(t, "info")
+	router := gin.New()
+	router.GET("/admin/log-level", log.LevelHandler())
+	router.PUT("/admin/log-level", log.LevelHandler())
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantLevel  string
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusOK, wantLevel: "info"},
+		{name: "set debug", method: http.MethodPut, body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantLevel: "debug"},
+		{name: "invalid level", method: http.MethodPut, body: `{"level":"loud"}`, wantStatus: http.StatusBadRequest, wantLevel: "debug"},
+		{name: "missing level", method: http.MethodPut, body: `{}`, wantStatus: http.StatusBadRequest, wantLevel: "debug"},
+		{name: "set warn", method: http.MethodPut, body: `{"level":"WARN"}`, wantStatus: http.StatusOK, wantLevel: "warn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/admin/log-level", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := log.Level(); got != tt.wantLevel {
+				t.Errorf("Level() = %q, want %q", got, tt.wantLevel)
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(w.Body.String(), `"level":"`+tt.wantLevel+`"`) {
+				t.Errorf("unexpected body: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestConfigForEnvironment(t *testing.T) {
+	tests := []struct {
+		env        string
+		wantLevel  string
+		wantFormat string
+	}{
+		{env: "development", wantLevel: "debug", wantFormat: "console"},
+		{env: "Dev", wantLevel: "debug", wantFormat: "console"},
+		{env: "local", wantLevel: "debug", wantFormat: "console"},
+		{env: "staging", wantLevel: "debug", wantFormat: "json"},
+		{env: "production", wantLevel: "info", wantFormat: "json"},
+		{env: "", wantLevel: "info", wantFormat: "json"},
+		{env: "unknown", wantLevel: "info", wantFormat: "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			cfg := ConfigForEnvironment(tt.env)
+			if cfg.Level != tt.wantLevel || cfg.Format != tt.wantFormat {
+				t.Errorf("ConfigForEnvironment(%q) = %+v, want level %s and format %s", tt.env, cfg, tt.wantLevel, tt.wantFormat)
+			}
+
+			log, err := NewForEnvironment(tt.env)
+			if err != nil {
+				t.Fatalf("NewForEnvironment(%q) error = %v", tt.env, err)
+			}
+			if got := log.Level(); got != tt.wantLevel {
+				t.Errorf("NewForEnvironment(%q).Level() = %q, want %q", tt.env, got, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestNewForEnvironment_Test(t *testing.T) {
+	for _, env := range []string{"test", "TESTING"} {
+		log, err := NewForEnvironment(env)
+		if err != nil {
+			t.Fatalf("NewForEnvironment(%q) error = %v", env, err)
+		}
+		if !log.Core().Enabled(zapcore.DebugLevel) {
+			t.Errorf("expected the %q logger to accept debug entries", env)
+		}
+		// Entries stay in memory instead of reaching stdout
+		if log.config.OutputPath != "" {
+			t.Errorf("expected no output path for the %q logger, got %q", env, log.config.OutputPath)
+		}
+	}
+}
+
+func TestNewTest(t *testing.T) {
+	log, logs := NewTest()
+
+	log.Debug("debug entry", zap.String("key", "value"))
+	log.WithFields(map[string]interface{}{"component": "test"}).Info("info entry")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 observed entries, got %d", len(entries))
+	}
+	if entries[0].ContextMap()["key"] != "value" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].ContextMap()["component"] != "test" {
+		t.Errorf("expected derived loggers to write to the same core, got %+v", entries[1])
+	}
+
+	if err := log.SetLevel("warn"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	log.Info("filtered")
+	if logs.Len() != 2 {
+		t.Errorf("expected info entries to be dropped at warn level, got %d entries", logs.Len())
+	}
+}
+
+func TestDefault_UsesSetDefault(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+
+	log, logs := NewTest()
+	SetDefault(log)
+
+	Info("through the global logger")
+	if logs.FilterMessage("through the global logger").Len() != 1 {
+		t.Error("expected the global functions to use the logger set with SetDefault")
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EndpointBuckets gives a group of endpoints request duration buckets matching
+// their latency profile, e.g. 5ms to 50ms for cache-backed reads
+type EndpointBuckets struct {
+	Name      string    // Exported as the group label
+	Endpoints []string  // Route patterns as matched by Gin, e.g. "/records/:id"
+	Buckets   []float64 // Upper bounds in seconds, in increasing order
+}
+
+// WithEndpointBuckets returns a copy of the registry that records the request
+// duration of the given endpoints into an http_endpoint_request_duration_seconds
+// histogram with the group's buckets instead of http_request_duration_seconds.
+// Other endpoints keep the default buckets. An error is returned for a group
+// without a name or buckets, unsorted buckets, or an endpoint in several groups.
+func (r *Registry) WithEndpointBuckets(groups ...EndpointBuckets) (*Registry, error) {
+	durations := make(map[string]*prometheus.HistogramVec, len(r.endpointDurations))
+	for endpoint, histogram := range r.endpointDurations {
+		durations[endpoint] = histogram
+	}
+
+	configured := make(map[string]string)
+	for _, group := range groups {
+		if group.Name == "" {
+			return nil, fmt.Errorf("endpoint bucket group needs a name")
+		}
+		if !increasing(group.Buckets) {
+			return nil, fmt.Errorf("endpoint bucket group %q needs buckets in increasing order", group.Name)
+		}
+		for _, endpoint := range group.Endpoints {
+			if other, ok := configured[endpoint]; ok {
+				return nil, fmt.Errorf("endpoint %s is in bucket groups %q and %q", endpoint, other, group.Name)
+			}
+			configured[endpoint] = group.Name
+		}
+	}
+
+	for _, group := range groups {
+		histogram := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   r.namespace,
+				Name:        "http_endpoint_request_duration_seconds",
+				Help:        "HTTP request duration in seconds of endpoints with their own buckets",
+				Buckets:     group.Buckets,
+				ConstLabels: prometheus.Labels{"group": group.Name},
+			},
+			[]string{"method", "endpoint"},
+		)
+		if err := register(r.registerer, &histogram); err != nil {
+			return nil, err
+		}
+		for _, endpoint := range group.Endpoints {
+			durations[endpoint] = histogram
+		}
+	}
+
+	custom := *r
+	custom.endpointDurations = durations
+	return &custom, nil
+}
+
+// increasing reports whether buckets is non-empty and strictly increasing
+func increasing(buckets []float64) bool {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return false
+		}
+	}
+	return len(buckets) > 0
+}
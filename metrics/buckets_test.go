@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// histogramsByEndpoint gathers the named histogram's series keyed by endpoint label
+func histogramsByEndpoint(t *testing.T, gatherer prometheus.Gatherer, name string) map[string]*dto.Histogram {
+	t.Helper()
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	histograms := make(map[string]*dto.Histogram)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "endpoint" {
+					histograms[label.GetValue()] = metric.GetHistogram()
+				}
+			}
+		}
+	}
+	return histograms
+}
+
+func upperBounds(histogram *dto.Histogram) []float64 {
+	var bounds []float64
+	for _, bucket := range histogram.GetBucket() {
+		bounds = append(bounds, bucket.GetUpperBound())
+	}
+	return bounds
+}
+
+func TestWithEndpointBuckets_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	shared := prometheus.NewRegistry()
+	base, err := NewWith("svc", shared)
+	if err != nil {
+		t.Fatalf("NewWith() error = %v", err)
+	}
+	cached := []float64{0.005, 0.01, 0.025, 0.05}
+	reports := []float64{0.1, 0.5, 1, 5}
+	registry, err := base.WithEndpointBuckets(
+		EndpointBuckets{Name: "cached", Endpoints: []string{"/records/:id"}, Buckets: cached},
+		EndpointBuckets{Name: "reports", Endpoints: []string{"/reports"}, Buckets: reports},
+	)
+	if err != nil {
+		t.Fatalf("WithEndpointBuckets() error = %v", err)
+	}
+
+	router := gin.New()
+	router.Use(registry.Middleware())
+	for _, path := range []string{"/records/:id", "/reports", "/health"} {
+		router.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	for _, path := range []string{"/records/1", "/records/2", "/reports", "/health"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	custom := histogramsByEndpoint(t, shared, "svc_http_endpoint_request_duration_seconds")
+	for endpoint, want := range map[string]struct {
+		buckets []float64
+		count   uint64
+	}{
+		"/records/:id": {cached, 2},
+		"/reports":     {reports, 1},
+	} {
+		histogram, ok := custom[endpoint]
+		if !ok {
+			t.Errorf("no custom histogram for %s", endpoint)
+			continue
+		}
+		if got := upperBounds(histogram); !reflect.DeepEqual(got, want.buckets) {
+			t.Errorf("%s buckets = %v, want %v", endpoint, got, want.buckets)
+		}
+		if histogram.GetSampleCount() != want.count {
+			t.Errorf("%s observations = %d, want %d", endpoint, histogram.GetSampleCount(), want.count)
+		}
+	}
+
+	defaults := histogramsByEndpoint(t, shared, "svc_http_request_duration_seconds")
+	if len(defaults) != 1 || defaults["/health"] == nil {
+		t.Errorf("default histogram endpoints = %v, want only /health", defaults)
+	} else if got := upperBounds(defaults["/health"]); !reflect.DeepEqual(got, prometheus.DefBuckets) {
+		t.Errorf("/health buckets = %v, want the defaults", got)
+	}
+
+	// The original registry is unaffected
+	base.RecordHTTPRequest(http.MethodGet, "/reports", http.StatusOK, time.Second, 0, 0)
+	if defaults := histogramsByEndpoint(t, shared, "svc_http_request_duration_seconds"); defaults["/reports"] == nil {
+		t.Error("original registry should record /reports with the default buckets")
+	}
+}
+
+func TestWithEndpointBuckets_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []EndpointBuckets
+	}{
+		{"missing name", []EndpointBuckets{{Endpoints: []string{"/a"}, Buckets: []float64{1}}}},
+		{"no buckets", []EndpointBuckets{{Name: "a", Endpoints: []string{"/a"}}}},
+		{"unsorted buckets", []EndpointBuckets{{Name: "a", Endpoints: []string{"/a"}, Buckets: []float64{1, 0.5}}}},
+		{"endpoint in two groups", []EndpointBuckets{
+			{Name: "a", Endpoints: []string{"/a"}, Buckets: []float64{1}},
+			{Name: "b", Endpoints: []string{"/a"}, Buckets: []float64{2}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New("svc").WithEndpointBuckets(tt.groups...); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
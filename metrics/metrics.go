@@ -2,10 +2,14 @@
 package metrics
 
 import (
+	stderrors "errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -13,7 +17,12 @@ import (
 
 // Registry holds all metrics
 type Registry struct {
-	registry *prometheus.Registry
+	namespace  string
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+
+	// Request duration histograms of endpoints with their own buckets, by route
+	endpointDurations map[string]*prometheus.HistogramVec
 	
 	// HTTP metrics
 	HTTPRequestsTotal     *prometheus.CounterVec
@@ -22,15 +31,19 @@ type Registry struct {
 	HTTPResponseSize      *prometheus.HistogramVec
 	
 	// Database metrics
-	DatabaseConnectionsActive *prometheus.GaugeVec
-	DatabaseConnectionsIdle   *prometheus.GaugeVec
-	DatabaseQueryDuration     *prometheus.HistogramVec
-	DatabaseQueriesTotal      *prometheus.CounterVec
+	DatabaseConnectionsActive       *prometheus.GaugeVec
+	DatabaseConnectionsIdle         *prometheus.GaugeVec
+	DatabaseConnectionsWaitCount    *prometheus.GaugeVec
+	DatabaseConnectionsWaitDuration *prometheus.GaugeVec
+	DatabaseReplicaLag              *prometheus.GaugeVec
+	DatabaseQueryDuration           *prometheus.HistogramVec
+	DatabaseQueriesTotal            *prometheus.CounterVec
 	
 	// Cache metrics
 	CacheOperationsTotal   *prometheus.CounterVec
 	CacheOperationDuration *prometheus.HistogramVec
 	CacheHitRatio          *prometheus.GaugeVec
+	CacheStaleServesTotal  prometheus.Counter
 	
 	// Business metrics
 	UsersTotal        *prometheus.GaugeVec
@@ -41,15 +54,45 @@ type Registry struct {
 	// System metrics
 	CPUUsage    *prometheus.GaugeVec
 	MemoryUsage *prometheus.GaugeVec
-	GoroutineCount *prometheus.Gauge
+	GoroutineCount prometheus.Gauge
+	
+	// Scheduler metrics
+	SchedulerJobRunsTotal   *prometheus.CounterVec
+	SchedulerJobDuration    *prometheus.HistogramVec
+
+	// Import metrics
+	ImportRowsTotal *prometheus.CounterVec
+	ImportDuration  prometheus.Histogram
 }
 
 // New creates a new metrics registry
 func New(namespace string) *Registry {
 	registry := prometheus.NewRegistry()
-	
+
+	// A fresh registry has nothing to conflict with
+	r, err := NewWith(namespace, registry)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// NewWith creates a metrics registry that registers its metrics with registerer,
+// which may be shared, e.g. prometheus.DefaultRegisterer. Metrics the registerer
+// already has (say from an earlier NewWith) are reused rather than panicking; an
+// error is returned only when a metric of the same name is registered with a
+// different help text or labels. Handler serves registerer if it is also a
+// Gatherer, and prometheus.DefaultGatherer otherwise.
+func NewWith(namespace string, registerer prometheus.Registerer) (*Registry, error) {
+	gatherer, ok := registerer.(prometheus.Gatherer)
+	if !ok {
+		gatherer = prometheus.DefaultGatherer
+	}
+
 	r := &Registry{
-		registry: registry,
+		namespace:  namespace,
+		registerer: registerer,
+		gatherer:   gatherer,
 		
 		// HTTP metrics
 		HTTPRequestsTotal: prometheus.NewCounterVec(
@@ -110,6 +153,33 @@ func New(namespace string) *Registry {
 			[]string{"database"},
 		),
 		
+		DatabaseConnectionsWaitCount: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "database_connections_wait_count",
+				Help:      "Total number of times a query waited for a free database connection",
+			},
+			[]string{"database"},
+		),
+		
+		DatabaseConnectionsWaitDuration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "database_connections_wait_duration_seconds",
+				Help:      "Total time spent waiting for a free database connection in seconds",
+			},
+			[]string{"database"},
+		),
+
+		DatabaseReplicaLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "database_replica_lag_seconds",
+				Help:      "Replication lag of read replicas in seconds",
+			},
+			[]string{"replica"},
+		),
+		
 		DatabaseQueryDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
@@ -157,6 +227,14 @@ func New(namespace string) *Registry {
 			},
 			[]string{"cache_type"},
 		),
+
+		CacheStaleServesTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "cache_stale_serves_total",
+				Help:      "Total number of cache reads served from stale local copies while Redis was unavailable",
+			},
+		),
 		
 		// Business metrics
 		UsersTotal: prometheus.NewGaugeVec(
@@ -221,58 +299,150 @@ func New(namespace string) *Registry {
 				Help:      "Total number of goroutines",
 			},
 		),
+		
+		// Scheduler metrics
+		SchedulerJobRunsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "scheduler_job_runs_total",
+				Help:      "Total number of scheduled job runs",
+			},
+			[]string{"job", "status"},
+		),
+		
+		SchedulerJobDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "scheduler_job_duration_seconds",
+				Help:      "Scheduled job duration in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"job"},
+		),
+
+		// Import metrics
+		ImportRowsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "import_rows_total",
+				Help:      "Total number of imported rows by result",
+			},
+			[]string{"result"},
+		),
+
+		ImportDuration: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "import_duration_seconds",
+				Help:      "Import duration in seconds",
+				Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12), // 100ms to ~3.5m
+			},
+		),
 	}
 	
 	// Register all metrics
-	r.registerMetrics()
-	
-	return r
+	if err := r.registerMetrics(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
-// registerMetrics registers all metrics with the registry
-func (r *Registry) registerMetrics() {
-	// HTTP metrics
-	r.registry.MustRegister(r.HTTPRequestsTotal)
-	r.registry.MustRegister(r.HTTPRequestDuration)
-	r.registry.MustRegister(r.HTTPRequestSize)
-	r.registry.MustRegister(r.HTTPResponseSize)
-	
-	// Database metrics
-	r.registry.MustRegister(r.DatabaseConnectionsActive)
-	r.registry.MustRegister(r.DatabaseConnectionsIdle)
-	r.registry.MustRegister(r.DatabaseQueryDuration)
-	r.registry.MustRegister(r.DatabaseQueriesTotal)
-	
-	// Cache metrics
-	r.registry.MustRegister(r.CacheOperationsTotal)
-	r.registry.MustRegister(r.CacheOperationDuration)
-	r.registry.MustRegister(r.CacheHitRatio)
-	
-	// Business metrics
-	r.registry.MustRegister(r.UsersTotal)
-	r.registry.MustRegister(r.WorkspacesTotal)
-	r.registry.MustRegister(r.RecordsTotal)
-	r.registry.MustRegister(r.APICallsTotal)
-	
-	// System metrics
-	r.registry.MustRegister(r.CPUUsage)
-	r.registry.MustRegister(r.MemoryUsage)
-	r.registry.MustRegister(r.GoroutineCount)
+// registerMetrics registers all metrics with the registerer, swapping in the
+// collectors it already has for metrics registered before
+func (r *Registry) registerMetrics() error {
+	return stderrors.Join(
+		// HTTP metrics
+		register(r.registerer, &r.HTTPRequestsTotal),
+		register(r.registerer, &r.HTTPRequestDuration),
+		register(r.registerer, &r.HTTPRequestSize),
+		register(r.registerer, &r.HTTPResponseSize),
+
+		// Database metrics
+		register(r.registerer, &r.DatabaseConnectionsActive),
+		register(r.registerer, &r.DatabaseConnectionsIdle),
+		register(r.registerer, &r.DatabaseConnectionsWaitCount),
+		register(r.registerer, &r.DatabaseConnectionsWaitDuration),
+		register(r.registerer, &r.DatabaseReplicaLag),
+		register(r.registerer, &r.DatabaseQueryDuration),
+		register(r.registerer, &r.DatabaseQueriesTotal),
+
+		// Cache metrics
+		register(r.registerer, &r.CacheOperationsTotal),
+		register(r.registerer, &r.CacheOperationDuration),
+		register(r.registerer, &r.CacheHitRatio),
+		register(r.registerer, &r.CacheStaleServesTotal),
+
+		// Business metrics
+		register(r.registerer, &r.UsersTotal),
+		register(r.registerer, &r.WorkspacesTotal),
+		register(r.registerer, &r.RecordsTotal),
+		register(r.registerer, &r.APICallsTotal),
+
+		// System metrics
+		register(r.registerer, &r.CPUUsage),
+		register(r.registerer, &r.MemoryUsage),
+		register(r.registerer, &r.GoroutineCount),
+
+		// Scheduler metrics
+		register(r.registerer, &r.SchedulerJobRunsTotal),
+		register(r.registerer, &r.SchedulerJobDuration),
+
+		// Import metrics
+		register(r.registerer, &r.ImportRowsTotal),
+		register(r.registerer, &r.ImportDuration),
+	)
+}
+
+// register registers *collector, replacing it with the equivalent collector the
+// registerer already has
+func register[C prometheus.Collector](registerer prometheus.Registerer, collector *C) error {
+	err := registerer.Register(*collector)
+
+	var already prometheus.AlreadyRegisteredError
+	if !stderrors.As(err, &already) {
+		return err
+	}
+	existing, ok := already.ExistingCollector.(C)
+	if !ok {
+		return fmt.Errorf("metric already registered as %T: %w", already.ExistingCollector, err)
+	}
+	*collector = existing
+	return nil
 }
 
 // Handler returns the Prometheus metrics handler
 func (r *Registry) Handler() http.Handler {
-	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+	return promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{
+		// OpenMetrics is required to expose request ID exemplars
+		EnableOpenMetrics: true,
+	})
 }
 
 // HTTP Metrics helpers
 
 // RecordHTTPRequest records HTTP request metrics
 func (r *Registry) RecordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration, requestSize, responseSize int64) {
+	r.recordHTTPRequest(method, endpoint, statusCode, duration, requestSize, responseSize, "")
+}
+
+// recordHTTPRequest records HTTP request metrics, attaching the request ID as an
+// exemplar on the duration histogram so slow buckets can be traced to log lines
+func (r *Registry) recordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration, requestSize, responseSize int64, requestID string) {
 	status := strconv.Itoa(statusCode)
 	
 	r.HTTPRequestsTotal.WithLabelValues(method, endpoint, status).Inc()
-	r.HTTPRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+	
+	durations := r.HTTPRequestDuration
+	if custom, ok := r.endpointDurations[endpoint]; ok {
+		durations = custom
+	}
+	observer := durations.WithLabelValues(method, endpoint)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && requestID != "" {
+		exemplarObserver.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"request_id": requestID})
+	} else {
+		observer.Observe(duration.Seconds())
+	}
 	r.HTTPRequestSize.WithLabelValues(method, endpoint).Observe(float64(requestSize))
 	r.HTTPResponseSize.WithLabelValues(method, endpoint).Observe(float64(responseSize))
 }
@@ -285,6 +455,17 @@ func (r *Registry) RecordDatabaseConnections(database string, active, idle int)
 	r.DatabaseConnectionsIdle.WithLabelValues(database).Set(float64(idle))
 }
 
+// RecordDatabaseWaits records the cumulative count and duration of waits for a free connection
+func (r *Registry) RecordDatabaseWaits(database string, waitCount int64, waitDuration time.Duration) {
+	r.DatabaseConnectionsWaitCount.WithLabelValues(database).Set(float64(waitCount))
+	r.DatabaseConnectionsWaitDuration.WithLabelValues(database).Set(waitDuration.Seconds())
+}
+
+// RecordReplicaLag records the replication lag of a read replica
+func (r *Registry) RecordReplicaLag(replica string, lag time.Duration) {
+	r.DatabaseReplicaLag.WithLabelValues(replica).Set(lag.Seconds())
+}
+
 // RecordDatabaseQuery records database query metrics
 func (r *Registry) RecordDatabaseQuery(operation, table, status string, duration time.Duration) {
 	r.DatabaseQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
@@ -299,6 +480,11 @@ func (r *Registry) RecordCacheOperation(operation, result string, duration time.
 	r.CacheOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
 }
 
+// RecordCacheStaleServe counts a read served from a stale local copy
+func (r *Registry) RecordCacheStaleServe() {
+	r.CacheStaleServesTotal.Inc()
+}
+
 // RecordCacheHitRatio records cache hit ratio
 func (r *Registry) RecordCacheHitRatio(cacheType string, ratio float64) {
 	r.CacheHitRatio.WithLabelValues(cacheType).Set(ratio)
@@ -343,6 +529,35 @@ func (r *Registry) RecordGoroutineCount(count int) {
 	r.GoroutineCount.Set(float64(count))
 }
 
+// Scheduler Metrics helpers
+
+// RecordSchedulerJob records a scheduled job run; skipped runs don't record a duration
+func (r *Registry) RecordSchedulerJob(job, status string, duration time.Duration) {
+	r.SchedulerJobRunsTotal.WithLabelValues(job, status).Inc()
+	if status != "skipped" {
+		r.SchedulerJobDuration.WithLabelValues(job).Observe(duration.Seconds())
+	}
+}
+
+// Import Metrics helpers
+
+// Import row results used as metric labels
+const (
+	ImportResultCreated = "created"
+	ImportResultUpdated = "updated"
+	ImportResultSkipped = "skipped"
+	ImportResultFailed  = "failed"
+)
+
+// RecordImport records the rows of a finished import by result and its duration
+func (r *Registry) RecordImport(summary models.ImportSummary, duration time.Duration) {
+	r.ImportRowsTotal.WithLabelValues(ImportResultCreated).Add(float64(summary.Created))
+	r.ImportRowsTotal.WithLabelValues(ImportResultUpdated).Add(float64(summary.Updated))
+	r.ImportRowsTotal.WithLabelValues(ImportResultSkipped).Add(float64(summary.Skipped))
+	r.ImportRowsTotal.WithLabelValues(ImportResultFailed).Add(float64(summary.Failed))
+	r.ImportDuration.Observe(duration.Seconds())
+}
+
 // Middleware returns a Gin middleware for recording HTTP metrics
 func (r *Registry) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -366,7 +581,8 @@ func (r *Registry) Middleware() gin.HandlerFunc {
 			responseSize = 0
 		}
 		
-		r.RecordHTTPRequest(method, endpoint, statusCode, duration, requestSize, responseSize)
+		requestID := logger.RequestIDFromContext(c.Request.Context())
+		r.recordHTTPRequest(method, endpoint, statusCode, duration, requestSize, responseSize, requestID)
 	}
 }
 
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewWith_SharedRegisterer(t *testing.T) {
+	shared := prometheus.NewRegistry()
+
+	first, err := NewWith("svc", shared)
+	if err != nil {
+		t.Fatalf("first NewWith() error = %v", err)
+	}
+	second, err := NewWith("svc", shared)
+	if err != nil {
+		t.Fatalf("second NewWith() error = %v", err)
+	}
+
+	first.RecordAPICall("/records", "GET", "200")
+	second.RecordAPICall("/records", "GET", "200")
+	second.RecordSchedulerJob("cleanup", "success", time.Second)
+
+	if got := testutil.ToFloat64(first.APICallsTotal.WithLabelValues("/records", "GET", "200")); got != 2 {
+		t.Errorf("api calls = %v, want both registries counting into one collector", got)
+	}
+	if count := testutil.CollectAndCount(shared, "svc_scheduler_job_runs_total"); count != 1 {
+		t.Errorf("scheduler job series = %d, want 1", count)
+	}
+
+	if _, err := NewWith("other", shared); err != nil {
+		t.Errorf("NewWith() with another namespace error = %v", err)
+	}
+}
+
+func TestNewWith_Conflict(t *testing.T) {
+	shared := prometheus.NewRegistry()
+	shared.MustRegister(prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: "svc", Name: "api_calls_total", Help: "Something else"},
+		[]string{"route"},
+	))
+
+	if _, err := NewWith("svc", shared); err == nil {
+		t.Error("expected an error for a metric registered with different labels")
+	}
+}
+
+func TestNew_Independent(t *testing.T) {
+	first := New("svc")
+	second := New("svc")
+
+	first.RecordAPICall("/records", "GET", "200")
+	if got := testutil.ToFloat64(second.APICallsTotal.WithLabelValues("/records", "GET", "200")); got != 0 {
+		t.Errorf("api calls = %v, want registries from New to be independent", got)
+	}
+}
@@ -0,0 +1,282 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// APIKeyLookupFunc returns the API key with the given key hash. The key's User
+// should be loaded, since the caller's tenant comes from it.
+type APIKeyLookupFunc func(ctx context.Context, keyHash string) (*models.APIKey, error)
+
+// APIKeyConfig holds API key authentication configuration
+type APIKeyConfig struct {
+	Header    string              // Header carrying the key (default "X-API-Key")
+	Lookup    APIKeyLookupFunc    // Resolves a key hash to the stored key
+	Usage     *APIKeyUsageTracker // Optional asynchronous usage tracking
+	SkipPaths []string
+}
+
+// APIKeyLookupFromDB returns a lookup that reads API keys and their users from the database
+func APIKeyLookupFromDB(db *database.DB) APIKeyLookupFunc {
+	return func(ctx context.Context, keyHash string) (*models.APIKey, error) {
+		var key models.APIKey
+		if err := db.WithContext(ctx).Preload("User").Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+			return nil, err
+		}
+		return &key, nil
+	}
+}
+
+// APIKey returns an API key authentication middleware. Usage statistics are
+// recorded through the tracker without blocking the request. Like JWT it stores
+// claims, so RequireScope, RequirePermission and EnforceResourceTenant work for
+// key callers: the scopes are the key's and the tenant is its user's. Keys carry
+// no roles, so RequireRole rejects them.
+func APIKey(config APIKeyConfig) gin.HandlerFunc {
+	if config.Header == "" {
+		config.Header = "X-API-Key"
+	}
+
+	return func(c *gin.Context) {
+		for _, path := range config.SkipPaths {
+			if c.Request.URL.Path == path {
+				c.Next()
+				return
+			}
+		}
+
+		rawKey := c.GetHeader(config.Header)
+		if rawKey == "" {
+			response.RespondError(c, errors.NewUnauthorizedError("Missing API key"))
+			return
+		}
+
+		key, err := config.Lookup(c.Request.Context(), utils.HashSHA256(rawKey))
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, errors.ErrCodeNotFound) {
+				response.RespondError(c, errors.NewInvalidCredentialsError())
+			} else {
+				response.RespondError(c, errors.NewInternalError("Failed to validate API key").WithCause(err))
+			}
+			return
+		}
+
+		if !key.IsValid() {
+			response.RespondError(c, errors.NewInvalidCredentialsError())
+			return
+		}
+
+		if !key.IsIPAllowed(ClientIP(c)) {
+			response.RespondError(c, errors.NewForbiddenError("IP address not allowed for this API key"))
+			return
+		}
+
+		if config.Usage != nil {
+			config.Usage.Record(key.ID)
+		}
+
+		claims := apiKeyClaims(key)
+		ctx := context.WithValue(c.Request.Context(), "api_key", key)
+		ctx = context.WithValue(ctx, "claims", claims)
+		ctx = context.WithValue(ctx, "user_id", claims.UserID)
+		ctx = context.WithValue(ctx, "tenant_id", claims.TenantID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// apiKeyClaims returns the claims of an API key caller. The tenant is left empty
+// when the lookup didn't load the key's user, so tenant checks fail closed.
+func apiKeyClaims(key *models.APIKey) *JWTClaims {
+	claims := &JWTClaims{
+		UserID: strconv.FormatUint(uint64(key.UserID), 10),
+		Scopes: key.Scopes,
+	}
+	if key.User.ID == key.UserID && key.User.ID != 0 {
+		claims.TenantID = strconv.FormatUint(uint64(key.User.TenantID), 10)
+		claims.Email = key.User.Email
+	}
+	return claims
+}
+
+// GetAPIKeyFromContext extracts the authenticated API key from context
+func GetAPIKeyFromContext(c *gin.Context) *models.APIKey {
+	if key, ok := c.Request.Context().Value("api_key").(*models.APIKey); ok {
+		return key
+	}
+	return nil
+}
+
+// UsageTrackerConfig holds API key usage tracker configuration
+type UsageTrackerConfig struct {
+	FlushInterval time.Duration // Flush buffered usage periodically (default 10s)
+	FlushSize     int           // Flush early once this many uses are buffered (default 1000)
+}
+
+// apiKeyUsage is the usage of one key buffered since the last flush
+type apiKeyUsage struct {
+	count    int
+	lastUsed time.Time
+}
+
+// APIKeyUsageTracker buffers API key usage in memory and writes it to the database
+// in batches, keeping the write off the request path. Buffered usage is lost if the
+// process crashes; call Flush on graceful shutdown.
+type APIKeyUsageTracker struct {
+	db     *database.DB
+	config UsageTrackerConfig
+
+	mu      sync.Mutex
+	pending map[uint]*apiKeyUsage
+	size    int
+
+	flushMu sync.Mutex
+	trigger chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewAPIKeyUsageTracker creates a usage tracker and starts its background flusher
+func NewAPIKeyUsageTracker(db *database.DB, config UsageTrackerConfig) *APIKeyUsageTracker {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if config.FlushSize <= 0 {
+		config.FlushSize = 1000
+	}
+
+	t := &APIKeyUsageTracker{
+		db:      db,
+		config:  config,
+		pending: make(map[uint]*apiKeyUsage),
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Record buffers one use of the API key
+func (t *APIKeyUsageTracker) Record(keyID uint) {
+	t.mu.Lock()
+	usage, ok := t.pending[keyID]
+	if !ok {
+		usage = &apiKeyUsage{}
+		t.pending[keyID] = usage
+	}
+	usage.count++
+	usage.lastUsed = time.Now()
+	t.size++
+	full := t.size >= t.config.FlushSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes all buffered usage to the database
+func (t *APIKeyUsageTracker) Flush(ctx context.Context) error {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[uint]*apiKeyUsage)
+	t.size = 0
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for keyID, usage := range batch {
+			err := tx.Model(&models.APIKey{}).
+				Where("id = ?", keyID).
+				Updates(map[string]interface{}{
+					"usage_count":  gorm.Expr("usage_count + ?", usage.count),
+					"last_used_at": usage.lastUsed,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.requeue(batch)
+		return errors.NewDatabaseError("flush API key usage", err)
+	}
+
+	return nil
+}
+
+// Close stops the background flusher and flushes remaining usage
+func (t *APIKeyUsageTracker) Close(ctx context.Context) error {
+	select {
+	case <-t.stop:
+	default:
+		close(t.stop)
+	}
+	<-t.done
+
+	return t.Flush(ctx)
+}
+
+// run flushes buffered usage periodically or when the buffer fills up
+func (t *APIKeyUsageTracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		case <-t.trigger:
+		}
+		// Failed batches are requeued and retried on the next flush
+		_ = t.Flush(context.Background())
+	}
+}
+
+// requeue merges a batch that failed to flush back into the buffer
+func (t *APIKeyUsageTracker) requeue(batch map[uint]*apiKeyUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for keyID, usage := range batch {
+		existing, ok := t.pending[keyID]
+		if !ok {
+			t.pending[keyID] = usage
+		} else {
+			existing.count += usage.count
+			if usage.lastUsed.After(existing.lastUsed) {
+				existing.lastUsed = usage.lastUsed
+			}
+		}
+		t.size += usage.count
+	}
+}
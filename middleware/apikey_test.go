@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestAPIKey_BatchedUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+	if err := testDB.Migrate(&models.APIKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	keys := map[string]*models.APIKey{
+		"key-one": {UserID: 1, Name: "one", KeyHash: utils.HashSHA256("key-one"), Prefix: "key", IsActive: true},
+		"key-two": {UserID: 2, Name: "two", KeyHash: utils.HashSHA256("key-two"), Prefix: "key", IsActive: true},
+	}
+	for _, key := range keys {
+		if err := testDB.Create(key).Error; err != nil {
+			t.Fatalf("failed to seed API key: %v", err)
+		}
+	}
+
+	// Long interval and large batch so nothing is written until Flush
+	tracker := NewAPIKeyUsageTracker(testDB.DB, UsageTrackerConfig{FlushInterval: time.Hour, FlushSize: 10000})
+	defer tracker.Close(context.Background())
+
+	router := gin.New()
+	router.Use(APIKey(APIKeyConfig{
+		Lookup: APIKeyLookupFromDB(testDB.DB),
+		Usage:  tracker,
+	}))
+	router.GET("/records", func(c *gin.Context) {
+		if GetAPIKeyFromContext(c) == nil {
+			t.Error("expected API key in context")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	burst := map[string]int{"key-one": 40, "key-two": 25}
+
+	var wg sync.WaitGroup
+	for rawKey, count := range burst {
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(rawKey string) {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodGet, "/records", nil)
+				req.Header.Set("X-API-Key", rawKey)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Errorf("status = %d, want 200", w.Code)
+				}
+			}(rawKey)
+		}
+	}
+	wg.Wait()
+
+	// Usage is buffered, not written on the request path
+	var before models.APIKey
+	if err := testDB.First(&before, keys["key-one"].ID).Error; err != nil {
+		t.Fatalf("failed to load API key: %v", err)
+	}
+	if before.UsageCount != 0 || before.LastUsedAt != nil {
+		t.Errorf("expected no usage written before flush, got count %d", before.UsageCount)
+	}
+
+	if err := tracker.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	for rawKey, want := range burst {
+		var stored models.APIKey
+		if err := testDB.First(&stored, keys[rawKey].ID).Error; err != nil {
+			t.Fatalf("failed to load API key: %v", err)
+		}
+		if stored.UsageCount != want {
+			t.Errorf("%s usage count = %d, want %d", rawKey, stored.UsageCount, want)
+		}
+		if stored.LastUsedAt == nil {
+			t.Errorf("%s last used at not set", rawKey)
+		}
+	}
+
+	// A second flush with nothing buffered leaves counts unchanged
+	if err := tracker.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	var stored models.APIKey
+	testDB.First(&stored, keys["key-one"].ID)
+	if stored.UsageCount != burst["key-one"] {
+		t.Errorf("usage count changed after empty flush: %d", stored.UsageCount)
+	}
+}
+
+func TestAPIKeyUsageTracker_FlushesWhenBatchFull(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	defer testDB.Cleanup()
+	if err := testDB.Migrate(&models.APIKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	key := &models.APIKey{UserID: 1, Name: "one", KeyHash: "hash", Prefix: "key", IsActive: true}
+	if err := testDB.Create(key).Error; err != nil {
+		t.Fatalf("failed to seed API key: %v", err)
+	}
+
+	tracker := NewAPIKeyUsageTracker(testDB.DB, UsageTrackerConfig{FlushInterval: time.Hour, FlushSize: 5})
+	defer tracker.Close(context.Background())
+
+	for i := 0; i < 5; i++ {
+		tracker.Record(key.ID)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var stored models.APIKey
+		if err := testDB.First(&stored, key.ID).Error; err == nil && stored.UsageCount == 5 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected a full batch to be flushed without waiting for the interval")
+}
+
+func TestAPIKey_Rejections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	expired := time.Now().Add(-time.Hour)
+	keys := map[string]*models.APIKey{
+		utils.HashSHA256("expired"):    {IsActive: true, ExpiresAt: &expired},
+		utils.HashSHA256("restricted"): {IsActive: true, IPWhitelist: []string{"10.0.0.1"}},
+	}
+	lookup := func(ctx context.Context, keyHash string) (*models.APIKey, error) {
+		if key, ok := keys[keyHash]; ok {
+			return key, nil
+		}
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	router := gin.New()
+	router.Use(APIKey(APIKeyConfig{Lookup: lookup}))
+	router.GET("/records", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{name: "missing key", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", key: "unknown", wantStatus: http.StatusUnauthorized},
+		{name: "expired key", key: "expired", wantStatus: http.StatusUnauthorized},
+		{name: "ip not allowed", key: "restricted", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/records", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAPIKey_WorksWithClaimGuards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := &models.APIKey{
+		UserID:   5,
+		KeyHash:  utils.HashSHA256("key-scoped"),
+		IsActive: true,
+		Scopes:   []string{"records:read"},
+		User:     models.User{TenantModel: models.TenantModel{TenantID: 7}},
+	}
+	key.User.ID = 5
+	unloaded := &models.APIKey{UserID: 5, KeyHash: utils.HashSHA256("key-unloaded"), IsActive: true, Scopes: []string{"records:read"}}
+	lookup := func(ctx context.Context, keyHash string) (*models.APIKey, error) {
+		for _, candidate := range []*models.APIKey{key, unloaded} {
+			if candidate.KeyHash == keyHash {
+				return candidate, nil
+			}
+		}
+		return nil, gorm.ErrRecordNotFound
+	}
+	resolver := NewPermissionResolver("user", func(c *gin.Context, claims *JWTClaims) ([]string, error) {
+		if claims.UserID == "5" {
+			return []string{"records:delete"}, nil
+		}
+		return nil, nil
+	})
+	workspaceTenants := map[string]string{"1": "7", "2": "8"}
+
+	router := gin.New()
+	router.Use(APIKey(APIKeyConfig{Lookup: lookup}))
+	router.DELETE("/workspaces/:workspaceID/records",
+		RequireScope("records:read"),
+		RequirePermission(resolver, "records:delete"),
+		EnforceResourceTenant(func(c *gin.Context) (string, error) {
+			return workspaceTenants[c.Param("workspaceID")], nil
+		}),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.DELETE("/admin", RequireRole("admin"), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name       string
+		rawKey     string
+		path       string
+		wantStatus int
+	}{
+		{name: "own tenant", rawKey: "key-scoped", path: "/workspaces/1/records", wantStatus: http.StatusNoContent},
+		{name: "other tenant", rawKey: "key-scoped", path: "/workspaces/2/records", wantStatus: http.StatusNotFound},
+		{name: "user not loaded fails closed", rawKey: "key-unloaded", path: "/workspaces/1/records", wantStatus: http.StatusUnauthorized},
+		{name: "keys carry no roles", rawKey: "key-scoped", path: "/admin", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			req.Header.Set("X-API-Key", tt.rawKey)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -3,11 +3,11 @@ package middleware
 
 import (
 	"context"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -45,16 +45,14 @@ func JWT(config AuthConfig) gin.HandlerFunc {
 		// Extract token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, errors.NewUnauthorizedError("Missing authorization header"))
-			c.Abort()
+			response.RespondError(c, errors.NewUnauthorizedError("Missing authorization header"))
 			return
 		}
 
 		// Check Bearer prefix
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, errors.NewUnauthorizedError("Invalid authorization header format"))
-			c.Abort()
+			response.RespondError(c, errors.NewUnauthorizedError("Invalid authorization header format"))
 			return
 		}
 
@@ -69,37 +67,32 @@ func JWT(config AuthConfig) gin.HandlerFunc {
 		})
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, errors.NewTokenInvalidError().WithCause(err))
-			c.Abort()
+			response.RespondError(c, errors.NewTokenInvalidError().WithCause(err))
 			return
 		}
 
 		claims, ok := token.Claims.(*JWTClaims)
 		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, errors.NewTokenInvalidError())
-			c.Abort()
+			response.RespondError(c, errors.NewTokenInvalidError())
 			return
 		}
 
 		// Check token expiration
 		if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-			c.JSON(http.StatusUnauthorized, errors.NewTokenExpiredError())
-			c.Abort()
+			response.RespondError(c, errors.NewTokenExpiredError())
 			return
 		}
 
 		// Check issuer
 		if config.Issuer != "" && claims.Issuer != config.Issuer {
-			c.JSON(http.StatusUnauthorized, errors.NewTokenInvalidError())
-			c.Abort()
+			response.RespondError(c, errors.NewTokenInvalidError())
 			return
 		}
 
 		// Check required roles
 		if len(config.RequiredRoles) > 0 {
 			if !hasAnyRole(claims.Roles, config.RequiredRoles) {
-				c.JSON(http.StatusForbidden, errors.NewForbiddenError("Insufficient role permissions"))
-				c.Abort()
+				response.RespondError(c, errors.NewForbiddenError("Insufficient role permissions"))
 				return
 			}
 		}
@@ -107,8 +100,7 @@ func JWT(config AuthConfig) gin.HandlerFunc {
 		// Check required scopes
 		if len(config.RequiredScopes) > 0 {
 			if !hasAnyScope(claims.Scopes, config.RequiredScopes) {
-				c.JSON(http.StatusForbidden, errors.NewInsufficientScopeError(strings.Join(config.RequiredScopes, ", ")))
-				c.Abort()
+				response.RespondError(c, errors.NewInsufficientScopeError(strings.Join(config.RequiredScopes, ", ")))
 				return
 			}
 		}
@@ -128,14 +120,12 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims := GetClaimsFromContext(c)
 		if claims == nil {
-			c.JSON(http.StatusUnauthorized, errors.NewUnauthorizedError("Missing authentication"))
-			c.Abort()
+			response.RespondError(c, errors.NewUnauthorizedError("Missing authentication"))
 			return
 		}
 
 		if !hasAnyRole(claims.Roles, roles) {
-			c.JSON(http.StatusForbidden, errors.NewForbiddenError("Insufficient role permissions"))
-			c.Abort()
+			response.RespondError(c, errors.NewForbiddenError("Insufficient role permissions"))
 			return
 		}
 
@@ -148,14 +138,12 @@ func RequireScope(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims := GetClaimsFromContext(c)
 		if claims == nil {
-			c.JSON(http.StatusUnauthorized, errors.NewUnauthorizedError("Missing authentication"))
-			c.Abort()
+			response.RespondError(c, errors.NewUnauthorizedError("Missing authentication"))
 			return
 		}
 
 		if !hasAnyScope(claims.Scopes, scopes) {
-			c.JSON(http.StatusForbidden, errors.NewInsufficientScopeError(strings.Join(scopes, ", ")))
-			c.Abort()
+			response.RespondError(c, errors.NewInsufficientScopeError(strings.Join(scopes, ", ")))
 			return
 		}
 
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// BindJSON decodes the request body into v like c.ShouldBindJSON, but first
+// rejects bodies larger than models.MaxJSONBytes (413) or nested deeper than
+// models.MaxJSONDepth (400), so untrusted payloads can't exhaust memory or the
+// stack. Binding tags are then validated, failures returning VALIDATION_FAILED.
+func BindJSON(c *gin.Context, v interface{}) error {
+	if c.Request.Body == nil {
+		return errors.NewMissingFieldError("body")
+	}
+
+	reader := io.Reader(c.Request.Body)
+	if models.MaxJSONBytes > 0 {
+		// One byte over the limit is enough for DecodeLimited to reject it
+		reader = io.LimitReader(reader, models.MaxJSONBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return errors.NewInvalidInputError("body", "failed to read request body").WithCause(err)
+	}
+
+	if err := utils.DecodeLimited(data, v, models.MaxJSONDepth, models.MaxJSONBytes); err != nil {
+		var limitErr *utils.JSONLimitError
+		switch {
+		case stderrors.As(err, &limitErr) && stderrors.Is(err, utils.ErrJSONTooLarge):
+			return errors.NewPayloadTooLargeError("Request body is too large", limitErr.Limit)
+		case stderrors.As(err, &limitErr):
+			return errors.NewInvalidInputError("body", fmt.Sprintf("nested more than %d levels deep", limitErr.Limit))
+		default:
+			return errors.NewInvalidInputError("body", "malformed JSON").WithCause(err)
+		}
+	}
+
+	if err := binding.Validator.ValidateStruct(v); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !stderrors.As(err, &fieldErrs) {
+			return errors.NewInvalidInputError("body", err.Error())
+		}
+		details := make(map[string]interface{}, len(fieldErrs))
+		for _, fieldErr := range fieldErrs {
+			details[fieldErr.Field()] = fmt.Sprintf("failed the %q check", fieldErr.Tag())
+		}
+		return errors.NewValidationError("Invalid request body", details)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+type bindTarget struct {
+	Name string      `json:"name" binding:"required"`
+	Data models.JSON `json:"data"`
+}
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deep := strings.Repeat(`{"a":`, models.MaxJSONDepth+1) + `1` + strings.Repeat(`}`, models.MaxJSONDepth+1)
+	large := `{"name": "x", "data": {"notes": "` + strings.Repeat("x", int(models.MaxJSONBytes)) + `"}}`
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{name: "too deep", body: `{"name": "x", "data": ` + deep + `}`, wantCode: errors.ErrCodeInvalidInput},
+		{name: "too large", body: large, wantCode: errors.ErrCodePayloadTooLarge},
+		{name: "malformed", body: `{"name": `, wantCode: errors.ErrCodeInvalidInput},
+		{name: "fails binding tags", body: `{"data": {}}`, wantCode: errors.ErrCodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+
+			var target bindTarget
+			if err := BindJSON(c, &target); !errors.Is(err, tt.wantCode) {
+				t.Errorf("BindJSON() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "Launch", "data": {"id": 1234567890123456789}}`))
+	var target bindTarget
+	if err := BindJSON(c, &target); err != nil {
+		t.Fatalf("BindJSON() error = %v", err)
+	}
+	if target.Name != "Launch" || target.Data["id"] != json.Number("1234567890123456789") {
+		t.Errorf("bound %+v, want the name and the exact id", target)
+	}
+}
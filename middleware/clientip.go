@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustProxies returns middleware that lets the given proxies report the client
+// IP via X-Forwarded-For or X-Real-IP for requests on the engine it's used on.
+// Entries are CIDRs or single IP addresses; it panics on an invalid one. Without
+// it nothing is trusted, so only the connection's remote address is used. Use it
+// before any middleware that calls ClientIP.
+func TrustProxies(proxies []string) gin.HandlerFunc {
+	networks, err := parseTrustedProxies(proxies)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return func(c *gin.Context) {
+		c.Set(string(TrustedProxiesKey), networks)
+		c.Next()
+	}
+}
+
+// parseTrustedProxies parses CIDRs and single IP addresses, skipping empty entries
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ClientIP returns the client address for rate limiting, allow lists and logs.
+// Forwarded headers are only honored when the request arrives from a proxy
+// trusted by TrustProxies, so clients can't spoof X-Forwarded-For to change
+// their identity.
+func ClientIP(c *gin.Context) string {
+	value, _ := c.Get(string(TrustedProxiesKey))
+	networks, _ := value.([]*net.IPNet)
+	return clientIP(c.Request, networks)
+}
+
+// clientIP walks X-Forwarded-For from the nearest hop outwards and returns the
+// first address not belonging to a trusted proxy
+func clientIP(r *http.Request, networks []*net.IPNet) string {
+	remote := remoteIP(r)
+	if remote == nil {
+		return ""
+	}
+	if !isTrustedProxy(remote, networks) {
+		return remote.String()
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip
+		if !isTrustedProxy(ip, networks) {
+			break
+		}
+	}
+	if len(hops) > 0 {
+		return client.String()
+	}
+
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return realIP.String()
+	}
+	return remote.String()
+}
+
+// remoteIP parses the address of the directly connected peer
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		host = strings.TrimSpace(r.RemoteAddr)
+	}
+	return net.ParseIP(host)
+}
+
+func isTrustedProxy(ip net.IP, networks []*net.IPNet) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
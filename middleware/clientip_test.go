@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	trust := TrustProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "spoofed XFF from untrusted source is ignored", remoteAddr: "203.0.113.7:5000", forwarded: []string{"1.2.3.4"}, want: "203.0.113.7"},
+		{name: "spoofed X-Real-IP from untrusted source is ignored", remoteAddr: "203.0.113.7:5000", realIP: "1.2.3.4", want: "203.0.113.7"},
+		{name: "XFF from trusted proxy is honored", remoteAddr: "10.1.2.3:443", forwarded: []string{"198.51.100.9"}, want: "198.51.100.9"},
+		{name: "single trusted proxy IP", remoteAddr: "192.168.1.5:443", forwarded: []string{"198.51.100.9"}, want: "198.51.100.9"},
+		{name: "client-supplied prefix is skipped", remoteAddr: "10.1.2.3:443", forwarded: []string{"1.2.3.4, 198.51.100.9"}, want: "198.51.100.9"},
+		{name: "chain of trusted proxies", remoteAddr: "10.1.2.3:443", forwarded: []string{"198.51.100.9, 10.0.0.2", "10.0.0.1"}, want: "198.51.100.9"},
+		{name: "invalid hop stops the walk", remoteAddr: "10.1.2.3:443", forwarded: []string{"198.51.100.9, garbage"}, want: "10.1.2.3"},
+		{name: "X-Real-IP from trusted proxy", remoteAddr: "10.1.2.3:443", realIP: "198.51.100.9", want: "198.51.100.9"},
+		{name: "ipv6 remote", remoteAddr: "[2001:db8::1]:443", forwarded: []string{"1.2.3.4"}, want: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = req
+			trust(c)
+
+			if got := ClientIP(c); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIP_TrustsNoOneByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.9")
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+
+	if got := ClientIP(c); got != "10.1.2.3" {
+		t.Errorf("ClientIP() = %q, want the remote address", got)
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	for _, proxy := range []string{"not-an-ip", "10.0.0.0/33"} {
+		if _, err := parseTrustedProxies([]string{proxy}); err == nil {
+			t.Errorf("parseTrustedProxies(%q) expected error", proxy)
+		}
+	}
+}
+
+func TestTokenBucketRateLimit_IgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(TrustProxies([]string{"10.0.0.0/8"}))
+	router.Use(TokenBucketRateLimit(RateLimitConfig{RequestsPerSecond: 1, BurstSize: 1}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(remoteAddr, forwarded string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwarded)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("203.0.113.7:5000", "1.1.1.1"); code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", code)
+	}
+	if code := send("203.0.113.7:5000", "2.2.2.2"); code != http.StatusTooManyRequests {
+		t.Errorf("rotating a spoofed XFF should not evade the limit, got %d", code)
+	}
+
+	if code := send("10.0.0.1:443", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("proxied client status = %d, want 200", code)
+	}
+	if code := send("10.0.0.1:443", "198.51.100.2"); code != http.StatusOK {
+		t.Errorf("distinct clients behind a trusted proxy should have separate limits, got %d", code)
+	}
+}
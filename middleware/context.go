@@ -2,29 +2,33 @@ package middleware
 
 import (
 	"context"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
 )
 
 // Context keys for storing values in request context
 type contextKey string
 
 const (
-	RequestIDKey contextKey = "request_id"
+	RequestIDKey            = logger.RequestIDKey
 	UserIDKey    contextKey = "user_id"
 	TenantIDKey  contextKey = "tenant_id"
 	ClaimsKey    contextKey = "claims"
+
+	// PermissionsKey holds the gin context's cache of resolved permissions
+	PermissionsKey contextKey = "effective_permissions"
+	// TrustedProxiesKey holds the gin context's proxies trusted by TrustProxies
+	TrustedProxiesKey contextKey = "trusted_proxies"
 )
 
 // AddRequestIDToContext adds request ID to context
 func AddRequestIDToContext(ctx context.Context, requestID string) context.Context {
-	return context.WithValue(ctx, RequestIDKey, requestID)
+	return logger.ContextWithRequestID(ctx, requestID)
 }
 
 // GetRequestIDFromContext retrieves request ID from context
 func GetRequestIDFromContext(ctx context.Context) string {
-	if requestID, ok := ctx.Value(RequestIDKey).(string); ok {
-		return requestID
-	}
-	return ""
+	return logger.RequestIDFromContext(ctx)
 }
 
 // AddUserIDToContext adds user ID to context
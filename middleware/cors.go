@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig holds cross-origin resource sharing configuration
+type CORSConfig struct {
+	AllowOrigins     []string // Allowed origins; "*" allows any
+	AllowMethods     []string // Defaults to GET, POST, PUT, PATCH, DELETE, OPTIONS
+	AllowHeaders     []string // Defaults to Authorization, Content-Type, X-API-Key, X-Request-ID
+	ExposeHeaders    []string // Defaults to X-Request-ID
+	AllowCredentials bool
+	MaxAge           time.Duration // How long preflight results may be cached
+}
+
+// Validate rejects AllowCredentials combined with a "*" origin, which would let
+// any site make credentialed requests
+func (config CORSConfig) Validate() error {
+	if config.AllowCredentials && isAllowedOrigin("*", config.AllowOrigins) {
+		return errors.New(`cors: AllowCredentials requires explicit origins, not "*"`)
+	}
+	return nil
+}
+
+// CORS returns a middleware that sets CORS headers for allowed origins and answers
+// preflight requests without passing them to later middleware. It panics if the
+// config fails Validate.
+func CORS(config CORSConfig) gin.HandlerFunc {
+	if err := config.Validate(); err != nil {
+		panic(err.Error())
+	}
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(config.AllowHeaders) == 0 {
+		config.AllowHeaders = []string{"Authorization", "Content-Type", "X-API-Key", RequestIDHeader}
+	}
+	if len(config.ExposeHeaders) == 0 {
+		config.ExposeHeaders = []string{RequestIDHeader}
+	}
+
+	allowMethods := strings.Join(config.AllowMethods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !isAllowedOrigin(origin, config.AllowOrigins) {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Expose-Headers", exposeHeaders)
+		if config.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		// Preflight request
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			if config.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isAllowedOrigin checks an origin against the allowed list
+func isAllowedOrigin(origin string, allowed []string) bool {
+	for _, allowedOrigin := range allowed {
+		if allowedOrigin == "*" || strings.EqualFold(allowedOrigin, origin) {
+			return true
+		}
+	}
+	return false
+}
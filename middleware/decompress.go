@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxDecompressedSize caps decompressed request bodies when no limit is configured
+const DefaultMaxDecompressedSize = 10 << 20 // 10MB
+
+// DecompressConfig configures DecompressRequest
+type DecompressConfig struct {
+	MaxDecompressedSize int64 // Largest allowed body after decompression
+}
+
+// DecompressRequest returns middleware that decodes gzip and deflate request bodies
+// based on Content-Encoding so handlers read plain content. The body is decompressed
+// up front, never beyond MaxDecompressedSize, so zip bombs get 413, malformed data
+// gets 400 and unknown encodings get 415 before the handler runs.
+func DecompressRequest(config DecompressConfig) gin.HandlerFunc {
+	if config.MaxDecompressedSize <= 0 {
+		config.MaxDecompressedSize = DefaultMaxDecompressedSize
+	}
+
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		// Not pooled: the new body aliases the buffer, and handlers or middleware
+		// such as Timeout may still read it after this middleware returns
+		var buf bytes.Buffer
+		body, err := decompress(&buf, c.Request.Body, encoding, config.MaxDecompressedSize)
+		if err != nil {
+			response.RespondError(c, err)
+			return
+		}
+		c.Request.Body.Close()
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Request.Header.Del("Content-Encoding")
+
+		c.Next()
+	}
+}
+
+// decompress reads at most limit decoded bytes from body into buf
+func decompress(buf *bytes.Buffer, body io.Reader, encoding string, limit int64) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(body)
+	case "deflate":
+		reader, err = zlib.NewReader(body)
+	default:
+		return nil, errors.NewUnsupportedMediaTypeError(encoding, []string{"gzip", "deflate"})
+	}
+	if err != nil {
+		return nil, errors.NewInvalidInputError("body", fmt.Sprintf("malformed %s encoding", encoding))
+	}
+	defer reader.Close()
+
+	if _, err := buf.ReadFrom(io.LimitReader(reader, limit+1)); err != nil {
+		return nil, errors.NewInvalidInputError("body", fmt.Sprintf("malformed %s encoding", encoding))
+	}
+	if int64(buf.Len()) > limit {
+		return nil, errors.NewPayloadTooLargeError("Decompressed request body is too large", limit)
+	}
+
+	return buf.Bytes(), nil
+}
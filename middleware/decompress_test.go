@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func deflateBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("deflate write: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompressRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payload := []byte(`{"name":"Projects"}`)
+	// 64MB of zeros compresses to ~64KB
+	bomb := gzipBytes(t, make([]byte, 64<<20))
+	validGzip := gzipBytes(t, payload)
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+		wantCode   string
+		wantBody   string
+	}{
+		{name: "gzip body", encoding: "gzip", body: validGzip, wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "deflate body", encoding: "deflate", body: deflateBytes(t, payload), wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "uncompressed body", body: payload, wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "malformed gzip header", encoding: "gzip", body: []byte("definitely not gzip"), wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeInvalidInput},
+		{name: "truncated gzip stream", encoding: "gzip", body: validGzip[:len(validGzip)-6], wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeInvalidInput},
+		{name: "zip bomb", encoding: "gzip", body: bomb, wantStatus: http.StatusRequestEntityTooLarge, wantCode: errors.ErrCodePayloadTooLarge},
+		{name: "unsupported encoding", encoding: "br", body: payload, wantStatus: http.StatusUnsupportedMediaType, wantCode: errors.ErrCodeUnsupportedMedia},
+	}
+
+	router := gin.New()
+	router.Use(DecompressRequest(DecompressConfig{MaxDecompressedSize: 1 << 20}))
+	router.POST("/", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if c.GetHeader("Content-Encoding") != "" {
+			c.Status(http.StatusTeapot)
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %.200s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("handler read %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if tt.wantCode != "" {
+				var body errors.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == nil || body.Error.Code != tt.wantCode {
+					t.Errorf("expected error code %s, got %s", tt.wantCode, strings.TrimSpace(w.Body.String()))
+				}
+			}
+		})
+	}
+}
+
+func TestDecompressRequest_BodyOutlivesMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Handlers like those behind Timeout may read the body after the middleware returned
+	var kept []io.Reader
+	router := gin.New()
+	router.Use(DecompressRequest(DecompressConfig{}))
+	router.POST("/", func(c *gin.Context) {
+		kept = append(kept, c.Request.Body)
+		c.Status(http.StatusNoContent)
+	})
+
+	payloads := []string{`{"name":"first"}`, `{"name":"second"}`}
+	for _, payload := range payloads {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipBytes(t, []byte(payload))))
+		req.Header.Set("Content-Encoding", "gzip")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	for i, body := range kept {
+		data, err := io.ReadAll(body)
+		if err != nil || string(data) != payloads[i] {
+			t.Errorf("body %d = %q (%v), want %q", i, data, err, payloads[i])
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler returns middleware that renders the last error added with c.Error as an
+// ErrorResponse carrying the request ID. Errors that aren't *errors.Error become a
+// generic internal error so details don't leak to clients. Place it after RequestLogging.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		response.RespondError(c, c.Errors.Last().Err)
+	}
+}
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureOverrideHeader carries signed per-request feature overrides
+const FeatureOverrideHeader = "X-Feature-Override"
+
+// FeatureOverridesKey stores the accepted overrides in the request context
+const FeatureOverridesKey contextKey = "feature_overrides"
+
+// DefaultFeatureOverrideMaxTTL is the longest override lifetime accepted when
+// FeatureOverrideConfig.MaxTTL is unset
+const DefaultFeatureOverrideMaxTTL = time.Hour
+
+// FeatureOverrideConfig configures FeatureOverride
+type FeatureOverrideConfig struct {
+	Secret         []byte        // HMAC key shared with QA tooling; overrides are ignored when empty
+	AdminRoles     []string      // Roles allowed to override
+	AllowAnyCaller bool          // Accept overrides without an admin role, e.g. in development and staging
+	MaxTTL         time.Duration // Longest accepted override lifetime (default DefaultFeatureOverrideMaxTTL)
+}
+
+// FeatureOverride returns middleware that lets QA and canary traffic flip features
+// for a single request without touching tenant settings. The header has the form
+// "new_grid=true,bulk_export=false;tenant=<id>;user=<id>;iat=<unix seconds>;exp=<unix
+// seconds>;sig=<hex HMAC-SHA256>", as built by SignFeatureOverride. Overrides are
+// only accepted from callers with one of AdminRoles unless AllowAnyCaller is set,
+// and only for the tenant and user they were signed for. Unsigned, forged,
+// expired, too long-lived, mismatched or malformed headers are ignored; the request
+// itself always proceeds. Register it after JWT so the caller is known.
+func FeatureOverride(config FeatureOverrideConfig) gin.HandlerFunc {
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = DefaultFeatureOverrideMaxTTL
+	}
+
+	return func(c *gin.Context) {
+		header := c.GetHeader(FeatureOverrideHeader)
+		claims := GetClaimsFromContext(c)
+		if header == "" || len(config.Secret) == 0 || claims == nil || !featureOverrideAllowed(claims, config) {
+			c.Next()
+			return
+		}
+
+		override, ok := parseFeatureOverride(header, config.Secret)
+		if ok && override.accepts(claims, config.MaxTTL) {
+			ctx := context.WithValue(c.Request.Context(), FeatureOverridesKey, override.features)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+}
+
+// featureOverrideAllowed applies the admin role restriction
+func featureOverrideAllowed(claims *JWTClaims, config FeatureOverrideConfig) bool {
+	if config.AllowAnyCaller {
+		return true
+	}
+	return len(config.AdminRoles) > 0 && hasAnyRole(claims.Roles, config.AdminRoles)
+}
+
+// SignFeatureOverride builds an X-Feature-Override header value valid for ttl and
+// only for tenantID; an empty userID lets any user of the tenant send it. The ttl
+// must not exceed the middleware's MaxTTL.
+func SignFeatureOverride(overrides map[string]bool, tenantID, userID string, secret []byte, ttl time.Duration) string {
+	features := make([]string, 0, len(overrides))
+	for feature := range overrides {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	pairs := make([]string, len(features))
+	for i, feature := range features {
+		pairs[i] = feature + "=" + strconv.FormatBool(overrides[feature])
+	}
+
+	now := utils.TimeNow()
+	payload := strings.Join([]string{
+		strings.Join(pairs, ","),
+		"tenant=" + tenantID,
+		"user=" + userID,
+		"iat=" + strconv.FormatInt(now.Unix(), 10),
+		"exp=" + strconv.FormatInt(now.Add(ttl).Unix(), 10),
+	}, ";")
+	return payload + ";sig=" + featureOverrideSignature(payload, secret)
+}
+
+// featureOverride is a verified X-Feature-Override header
+type featureOverride struct {
+	features  map[string]bool
+	tenantID  string
+	userID    string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+// accepts reports whether the override is current, no longer-lived than maxTTL
+// and signed for the caller
+func (o *featureOverride) accepts(claims *JWTClaims, maxTTL time.Duration) bool {
+	now := utils.TimeNow()
+	switch {
+	case !now.Before(o.expiresAt), o.expiresAt.Sub(o.issuedAt) > maxTTL:
+		return false
+	case o.tenantID != claims.TenantID:
+		return false
+	case o.userID != "" && o.userID != claims.UserID:
+		return false
+	}
+	return true
+}
+
+// parseFeatureOverride verifies and decodes a header built by SignFeatureOverride
+func parseFeatureOverride(header string, secret []byte) (*featureOverride, bool) {
+	payload, signature, ok := strings.Cut(header, ";sig=")
+	if !ok || !hmac.Equal([]byte(signature), []byte(featureOverrideSignature(payload, secret))) {
+		return nil, false
+	}
+
+	parts := strings.Split(payload, ";")
+	if len(parts) != 5 {
+		return nil, false
+	}
+	fields := make(map[string]string, 4)
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, false
+		}
+		fields[key] = value
+	}
+	issuedAt, err := strconv.ParseInt(fields["iat"], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	expires, err := strconv.ParseInt(fields["exp"], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	tenantID, hasTenant := fields["tenant"]
+	userID, hasUser := fields["user"]
+	if !hasTenant || !hasUser {
+		return nil, false
+	}
+
+	override := &featureOverride{
+		features:  make(map[string]bool),
+		tenantID:  tenantID,
+		userID:    userID,
+		issuedAt:  time.Unix(issuedAt, 0),
+		expiresAt: time.Unix(expires, 0),
+	}
+	for _, pair := range strings.Split(parts[0], ",") {
+		feature, rawValue, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || feature == "" {
+			return nil, false
+		}
+		value, err := strconv.ParseBool(rawValue)
+		if err != nil {
+			return nil, false
+		}
+		override.features[feature] = value
+	}
+
+	return override, true
+}
+
+func featureOverrideSignature(payload string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetFeatureOverridesFromContext returns the overrides accepted by FeatureOverride
+func GetFeatureOverridesFromContext(ctx context.Context) map[string]bool {
+	if overrides, ok := ctx.Value(FeatureOverridesKey).(map[string]bool); ok {
+		return overrides
+	}
+	return nil
+}
+
+// IsFeatureEnabled reports whether feature is on for the request, consulting
+// request overrides before the tenant's settings
+func IsFeatureEnabled(ctx context.Context, settings models.FeatureSettings, feature string) bool {
+	if enabled, ok := GetFeatureOverridesFromContext(ctx)[feature]; ok {
+		return enabled
+	}
+	return settings.IsEnabled(feature)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+var featureSecret = []byte("feature-secret")
+
+// tenantFeatures has new_grid off and bulk_export on
+var tenantFeatures = models.FeatureSettings{
+	EnabledFeatures: []string{"bulk_export"},
+	FeatureFlags:    map[string]bool{"new_grid": false},
+}
+
+func featureEngine(config FeatureOverrideConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(JWT(AuthConfig{JWTSecret: "jwt-secret"}), FeatureOverride(config))
+	engine.GET("/features", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		c.String(http.StatusOK, "%t,%t",
+			IsFeatureEnabled(ctx, tenantFeatures, "new_grid"),
+			IsFeatureEnabled(ctx, tenantFeatures, "bulk_export"))
+	})
+	return engine
+}
+
+func requestFeatures(t *testing.T, engine *gin.Engine, override string, roles []string) string {
+	t.Helper()
+
+	token, err := CreateToken(&JWTClaims{UserID: "usr_1", TenantID: "tnt_1", Roles: roles}, "jwt-secret")
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/features", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if override != "" {
+		req.Header.Set(FeatureOverrideHeader, override)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestFeatureOverride(t *testing.T) {
+	features := map[string]bool{"new_grid": true, "bulk_export": false}
+	valid := SignFeatureOverride(features, "tnt_1", "usr_1", featureSecret, time.Minute)
+	anyUser := SignFeatureOverride(features, "tnt_1", "", featureSecret, time.Minute)
+	otherTenant := SignFeatureOverride(features, "tnt_2", "", featureSecret, time.Minute)
+	otherUser := SignFeatureOverride(features, "tnt_1", "usr_2", featureSecret, time.Minute)
+	longLived := SignFeatureOverride(features, "tnt_1", "usr_1", featureSecret, 48*time.Hour)
+	expired := SignFeatureOverride(map[string]bool{"new_grid": true}, "tnt_1", "usr_1", featureSecret, -time.Minute)
+	forged := SignFeatureOverride(map[string]bool{"new_grid": true}, "tnt_1", "usr_1", []byte("wrong-secret"), time.Minute)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	unsigned := "new_grid=true;tenant=tnt_1;user=usr_1;iat=" + now + ";exp=" + strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+
+	anyCaller := FeatureOverrideConfig{Secret: featureSecret, AllowAnyCaller: true}
+	admins := FeatureOverrideConfig{Secret: featureSecret, AdminRoles: []string{"admin"}}
+
+	tests := []struct {
+		name     string
+		config   FeatureOverrideConfig
+		override string
+		roles    []string
+		want     string
+	}{
+		{name: "no header uses tenant settings", config: anyCaller, want: "false,true"},
+		{name: "valid override takes precedence", config: anyCaller, override: valid, want: "true,false"},
+		{name: "override for any user of the tenant", config: anyCaller, override: anyUser, want: "true,false"},
+		{name: "another tenant's override is ignored", config: anyCaller, override: otherTenant, want: "false,true"},
+		{name: "another user's override is ignored", config: anyCaller, override: otherUser, want: "false,true"},
+		{name: "override beyond the max TTL is ignored", config: anyCaller, override: longLived, want: "false,true"},
+		{name: "forged override is ignored", config: anyCaller, override: forged, want: "false,true"},
+		{name: "unsigned override is ignored", config: anyCaller, override: unsigned, want: "false,true"},
+		{name: "expired override is ignored", config: anyCaller, override: expired, want: "false,true"},
+		{name: "no secret disables overrides", config: FeatureOverrideConfig{AllowAnyCaller: true}, override: valid, want: "false,true"},
+		{name: "restricted by default", config: FeatureOverrideConfig{Secret: featureSecret}, override: valid, roles: []string{"admin"}, want: "false,true"},
+		{name: "non-admin callers are ignored", config: admins, override: valid, roles: []string{"user"}, want: "false,true"},
+		{name: "admin callers are accepted", config: admins, override: valid, roles: []string{"admin"}, want: "true,false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := featureEngine(tt.config)
+			if got := requestFeatures(t, engine, tt.override, tt.roles); got != tt.want {
+				t.Errorf("features = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
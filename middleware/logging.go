@@ -3,22 +3,40 @@ package middleware
 import (
 	"bytes"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-// responseWriter wraps gin.ResponseWriter to capture response body
-type responseWriter struct {
-	gin.ResponseWriter
-	body *bytes.Buffer
+// RequestIDHeader is the header carrying the request ID
+const RequestIDHeader = logger.RequestIDHeader
+
+// maxLoggedBodySize is how much of a request body is logged at debug level
+const maxLoggedBodySize = 1000
+
+// RequestID returns a middleware that assigns the request ID without logging, for
+// stacks that don't use RequestLogging
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assignRequestID(c)
+		c.Next()
+	}
 }
 
-func (w *responseWriter) Write(data []byte) (int, error) {
-	w.body.Write(data)
-	return w.ResponseWriter.Write(data)
+// assignRequestID accepts the caller's request ID or generates one, echoes it
+// back and adds it to the request context
+func assignRequestID(c *gin.Context) string {
+	requestID := c.GetHeader(RequestIDHeader)
+	if !isValidRequestID(requestID) {
+		requestID = generateRequestID()
+	}
+	c.Header(RequestIDHeader, requestID)
+	c.Request = c.Request.WithContext(AddRequestIDToContext(c.Request.Context(), requestID))
+	return requestID
 }
 
 // RequestLogging returns a middleware that logs HTTP requests
@@ -26,33 +44,16 @@ func RequestLogging(log *logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		
-		// Capture request body if needed (for POST/PUT requests)
+		// Peek at the start of POST/PUT/PATCH bodies for debug logs only; the rest
+		// reaches the handler unread, so body size limits further down still apply
 		var requestBody []byte
 		if c.Request.Method == "POST" || c.Request.Method == "PUT" || c.Request.Method == "PATCH" {
-			if c.Request.Body != nil {
-				requestBody, _ = io.ReadAll(c.Request.Body)
-				c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+			if c.Request.Body != nil && log.Core().Enabled(zap.DebugLevel) {
+				requestBody = peekBody(c.Request, maxLoggedBodySize+1)
 			}
 		}
 		
-		// Wrap response writer to capture response
-		writer := &responseWriter{
-			ResponseWriter: c.Writer,
-			body:          bytes.NewBufferString(""),
-		}
-		c.Writer = writer
-		
-		// Generate request ID if not present
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-			c.Header("X-Request-ID", requestID)
-		}
-		
-		// Add request ID to context
-		c.Request = c.Request.WithContext(
-			AddRequestIDToContext(c.Request.Context(), requestID),
-		)
+		requestID := assignRequestID(c)
 		
 		// Process request
 		c.Next()
@@ -60,17 +61,18 @@ func RequestLogging(log *logger.Logger) gin.HandlerFunc {
 		// Calculate duration
 		duration := time.Since(start)
 		
-		// Get response size
-		responseSize := writer.body.Len()
+		// Get response size; gin counts written bytes, so streamed responses aren't held
+		responseSize := max(c.Writer.Size(), 0)
 		
 		// Log request details
 		fields := []zap.Field{
 			zap.String("request_id", requestID),
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
+			zap.String("route", routeTemplate(c)),
 			zap.String("query", c.Request.URL.RawQuery),
 			zap.String("user_agent", c.Request.UserAgent()),
-			zap.String("client_ip", c.ClientIP()),
+			zap.String("client_ip", ClientIP(c)),
 			zap.Int("status_code", c.Writer.Status()),
 			zap.Duration("duration", duration),
 			zap.Int64("request_size", c.Request.ContentLength),
@@ -89,8 +91,8 @@ func RequestLogging(log *logger.Logger) gin.HandlerFunc {
 		// Add request body for debug level (truncated)
 		if log.Core().Enabled(zap.DebugLevel) && len(requestBody) > 0 {
 			body := string(requestBody)
-			if len(body) > 1000 {
-				body = body[:1000] + "... [truncated]"
+			if len(body) > maxLoggedBodySize {
+				body = body[:maxLoggedBodySize] + "... [truncated]"
 			}
 			fields = append(fields, zap.String("request_body", body))
 		}
@@ -139,7 +141,7 @@ func SecurityLogging(log *logger.Logger) gin.HandlerFunc {
 			log.LogSecurityEvent(
 				"unauthorized_access_attempt",
 				GetUserIDFromContext(c),
-				c.ClientIP(),
+				ClientIP(c),
 				"medium",
 				map[string]interface{}{
 					"path":       c.Request.URL.Path,
@@ -151,7 +153,7 @@ func SecurityLogging(log *logger.Logger) gin.HandlerFunc {
 			log.LogSecurityEvent(
 				"forbidden_access_attempt",
 				GetUserIDFromContext(c),
-				c.ClientIP(),
+				ClientIP(c),
 				"medium",
 				map[string]interface{}{
 					"path":       c.Request.URL.Path,
@@ -163,7 +165,7 @@ func SecurityLogging(log *logger.Logger) gin.HandlerFunc {
 			log.LogSecurityEvent(
 				"rate_limit_exceeded",
 				GetUserIDFromContext(c),
-				c.ClientIP(),
+				ClientIP(c),
 				"low",
 				map[string]interface{}{
 					"path":       c.Request.URL.Path,
@@ -175,9 +177,43 @@ func SecurityLogging(log *logger.Logger) gin.HandlerFunc {
 	}
 }
 
+// UnmatchedRoute is logged as the route of requests that matched no route
+const UnmatchedRoute = "unmatched"
+
+// routeTemplate returns the matched route template (e.g. /users/:id) for grouping logs
+func routeTemplate(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return UnmatchedRoute
+}
+
 // generateRequestID generates a unique request ID
 func generateRequestID() string {
-	// In a real implementation, you'd use a proper UUID library
-	// For now, using a simple timestamp-based ID
-	return "req_" + string(rune(time.Now().UnixNano()))
-}
\ No newline at end of file
+	return "req_" + uuid.NewString()
+}
+
+// isValidRequestID reports whether a caller-supplied request ID is safe to propagate
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+	for _, r := range requestID {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// peekBody reads up to n bytes of the request body and puts them back in front of
+// the unread rest
+func peekBody(r *http.Request, n int64) []byte {
+	body := r.Body
+	prefix, _ := io.ReadAll(io.LimitReader(body, n))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), body), body}
+	return prefix
+}
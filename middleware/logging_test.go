@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLogging_ResponseSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		wantSize int64
+	}{
+		{name: "body", handler: func(c *gin.Context) { c.String(http.StatusOK, "hello") }, wantSize: 5},
+		{name: "no body", handler: func(c *gin.Context) { c.Status(http.StatusNoContent) }, wantSize: 0},
+		{name: "streamed", handler: func(c *gin.Context) {
+			for i := 0; i < 3; i++ {
+				c.Writer.WriteString("data: tick\n\n")
+				c.Writer.Flush()
+			}
+		}, wantSize: 36},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			router := gin.New()
+			router.Use(RequestLogging(&logger.Logger{Logger: zap.New(core)}))
+			router.GET("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := logs.FilterMessage("HTTP request").All()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 request log entry, got %d", len(entries))
+			}
+			if got := entries[0].ContextMap()["response_size"]; got != tt.wantSize {
+				t.Errorf("response_size = %v, want %d", got, tt.wantSize)
+			}
+		})
+	}
+}
+
+// countingReader records how many bytes were read from it
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestRequestLogging_RequestBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payload := strings.Repeat("x", 64<<10)
+
+	tests := []struct {
+		name         string
+		level        zapcore.Level
+		wantReadFrom int
+		wantLogged   bool
+	}{
+		{name: "not read above debug", level: zap.InfoLevel, wantReadFrom: 0},
+		{name: "only the logged prefix read at debug", level: zap.DebugLevel, wantReadFrom: maxLoggedBodySize + 1, wantLogged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(tt.level)
+			body := &countingReader{r: strings.NewReader(payload)}
+
+			var readBeforeHandler int
+			var received string
+			router := gin.New()
+			router.Use(RequestLogging(&logger.Logger{Logger: zap.New(core)}))
+			router.POST("/", func(c *gin.Context) {
+				readBeforeHandler = body.read
+				data, _ := io.ReadAll(c.Request.Body)
+				received = string(data)
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if readBeforeHandler != tt.wantReadFrom {
+				t.Errorf("read %d bytes before the handler, want %d", readBeforeHandler, tt.wantReadFrom)
+			}
+			if received != payload {
+				t.Errorf("handler received %d bytes, want the whole %d byte body", len(received), len(payload))
+			}
+
+			entries := logs.FilterMessage("HTTP request").All()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 request log entry, got %d", len(entries))
+			}
+			logged, ok := entries[0].ContextMap()["request_body"].(string)
+			if ok != tt.wantLogged {
+				t.Fatalf("request_body logged = %v, want %v", ok, tt.wantLogged)
+			}
+			if ok && logged != payload[:maxLoggedBodySize]+"... [truncated]" {
+				t.Errorf("request_body = %.50q..., want the truncated prefix", logged)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers read by NonceGuard
+const (
+	NonceHeader          = "X-Nonce"
+	NonceTimestampHeader = "X-Timestamp"
+)
+
+// maxNonceLength bounds the Redis keys clients can create
+const maxNonceLength = 128
+
+// NonceGuard returns middleware that rejects replayed requests. Each request must
+// carry a unique X-Nonce and an X-Timestamp in Unix seconds within ttl of the
+// server's clock. Nonces are remembered in Redis for as long as their timestamp
+// could still be accepted, and a reused nonce is rejected with 409. Unlike
+// idempotency keys, which let a client safely retry, a nonce can never be reused.
+// Requests are rejected with 503 when Redis is unavailable.
+func NonceGuard(client *cache.Client, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		nonce := c.GetHeader(NonceHeader)
+		if nonce == "" {
+			response.RespondError(c, errors.NewMissingFieldError(NonceHeader))
+			return
+		}
+		if len(nonce) > maxNonceLength {
+			response.RespondError(c, errors.NewInvalidInputError(NonceHeader, "Nonce is too long"))
+			return
+		}
+
+		rawTimestamp := c.GetHeader(NonceTimestampHeader)
+		if rawTimestamp == "" {
+			response.RespondError(c, errors.NewMissingFieldError(NonceTimestampHeader))
+			return
+		}
+		timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+		if err != nil {
+			response.RespondError(c, errors.NewInvalidInputError(NonceTimestampHeader, "Timestamp must be in Unix seconds"))
+			return
+		}
+
+		skew := utils.TimeNow().Sub(time.Unix(timestamp, 0))
+		if skew > ttl || skew < -ttl {
+			response.RespondError(c, errors.NewInvalidInputError(NonceTimestampHeader, "Timestamp is outside the allowed window"))
+			return
+		}
+
+		// A timestamp up to ttl ahead stays acceptable for 2*ttl
+		fresh, err := client.SetNX(c.Request.Context(), "nonce:"+nonce, timestamp, 2*ttl)
+		if err != nil {
+			response.RespondError(c, errors.NewServiceUnavailableError("cache"))
+			return
+		}
+		if !fresh {
+			response.RespondError(c, errors.NewConflictError("Nonce has already been used"))
+			return
+		}
+
+		c.Next()
+	}
+}
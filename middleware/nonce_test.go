@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNonceGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	client, server := sharedtesting.NewTestCache(t)
+	router := gin.New()
+	router.Use(NonceGuard(client, 5*time.Minute))
+	router.POST("/transfer", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	now := time.Now().Unix()
+	tests := []struct {
+		name       string
+		nonce      string
+		timestamp  string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "first request", nonce: "n-1", timestamp: strconv.FormatInt(now, 10), wantStatus: http.StatusOK},
+		{name: "replayed nonce", nonce: "n-1", timestamp: strconv.FormatInt(now, 10), wantStatus: http.StatusConflict, wantCode: errors.ErrCodeConflict},
+		{name: "new nonce", nonce: "n-2", timestamp: strconv.FormatInt(now-60, 10), wantStatus: http.StatusOK},
+		{name: "expired timestamp", nonce: "n-3", timestamp: strconv.FormatInt(now-600, 10), wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeInvalidInput},
+		{name: "future timestamp", nonce: "n-4", timestamp: strconv.FormatInt(now+600, 10), wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeInvalidInput},
+		{name: "malformed timestamp", nonce: "n-5", timestamp: "yesterday", wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeInvalidInput},
+		{name: "missing nonce", timestamp: strconv.FormatInt(now, 10), wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeMissingField},
+		{name: "missing timestamp", nonce: "n-6", wantStatus: http.StatusBadRequest, wantCode: errors.ErrCodeMissingField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/transfer", nil)
+			if tt.nonce != "" {
+				req.Header.Set(NonceHeader, tt.nonce)
+			}
+			if tt.timestamp != "" {
+				req.Header.Set(NonceTimestampHeader, tt.timestamp)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body errors.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == nil || body.Error.Code != tt.wantCode {
+				t.Errorf("unexpected body: %s", w.Body.String())
+			}
+		})
+	}
+
+	if ttl := server.TTL("nonce:n-1"); ttl != 10*time.Minute {
+		t.Errorf("expected nonces to be kept for twice the window, TTL = %v", ttl)
+	}
+	if server.Exists("nonce:n-3") {
+		t.Error("expected rejected requests not to consume their nonce")
+	}
+
+	t.Run("cache unavailable", func(t *testing.T) {
+		server.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/transfer", nil)
+		req.Header.Set(NonceHeader, "n-7")
+		req.Header.Set(NonceTimestampHeader, strconv.FormatInt(now, 10))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", w.Code)
+		}
+	})
+}
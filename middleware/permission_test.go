@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// permissionUsers are the users the test resolver "loads", keyed by user ID
+var permissionUsers = map[string]*models.User{
+	"usr_editor": {
+		Permissions: []models.Permission{{Name: "records:export"}},
+		Roles: []models.Role{
+			{Name: "editor", Permissions: []models.Permission{{Name: "records:read"}, {Name: "records:write"}}},
+			{Name: "viewer", Permissions: []models.Permission{{Name: "records:read"}}},
+		},
+	},
+	"usr_viewer": {
+		Roles: []models.Role{{Name: "viewer", Permissions: []models.Permission{{Name: "records:read"}}}},
+	},
+}
+
+func permissionEngine(resolve PermissionResolver) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(JWT(AuthConfig{JWTSecret: "jwt-secret"}))
+	engine.POST("/records",
+		RequirePermission(resolve, "records:read"),
+		RequirePermission(resolve, "records:write", "records:admin"),
+		func(c *gin.Context) {
+			granted, err := EffectivePermissions(c, resolve)
+			if err != nil || !granted["records:export"] {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			c.Status(http.StatusCreated)
+		})
+	return engine
+}
+
+func requestPermission(t *testing.T, engine *gin.Engine, userID string) int {
+	t.Helper()
+
+	token, err := CreateToken(&JWTClaims{UserID: userID}, "jwt-secret")
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/records", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRequirePermission(t *testing.T) {
+	resolved := 0
+	engine := permissionEngine(NewPermissionResolver("user", func(c *gin.Context, claims *JWTClaims) ([]string, error) {
+		resolved++
+		user, ok := permissionUsers[claims.UserID]
+		if !ok {
+			return nil, errors.NewNotFoundError("User")
+		}
+		return user.PermissionNames(), nil
+	}))
+
+	tests := []struct {
+		name         string
+		userID       string
+		wantStatus   int
+		wantResolved int
+	}{
+		{name: "all checks pass", userID: "usr_editor", wantStatus: http.StatusCreated, wantResolved: 1},
+		{name: "second check fails", userID: "usr_viewer", wantStatus: http.StatusForbidden, wantResolved: 1},
+		{name: "resolver error", userID: "usr_unknown", wantStatus: http.StatusNotFound, wantResolved: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved = 0
+			if status := requestPermission(t, engine, tt.userID); status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if resolved != tt.wantResolved {
+				t.Errorf("permissions resolved %d times, want %d", resolved, tt.wantResolved)
+			}
+		})
+	}
+
+	t.Run("each request resolves again", func(t *testing.T) {
+		resolved = 0
+		requestPermission(t, engine, "usr_editor")
+		requestPermission(t, engine, "usr_editor")
+		if resolved != 2 {
+			t.Errorf("permissions resolved %d times over two requests, want 2", resolved)
+		}
+	})
+}
+
+func TestRequirePermission_CachesPerResolverKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantWide := NewPermissionResolver("tenant", func(c *gin.Context, claims *JWTClaims) ([]string, error) {
+		return []string{"records:read"}, nil
+	})
+	// resolverFor builds every resolver from one closure, so only the key tells them apart
+	workspaceGrants := map[string][]string{"ws1": {"records:delete"}, "ws2": {"records:read"}}
+	resolverFor := func(workspaceID string) PermissionResolver {
+		return NewPermissionResolver("workspace:"+workspaceID, func(c *gin.Context, claims *JWTClaims) ([]string, error) {
+			return workspaceGrants[workspaceID], nil
+		})
+	}
+
+	tests := []struct {
+		name       string
+		checks     []gin.HandlerFunc
+		wantStatus int
+	}{
+		{
+			name: "second resolver isn't served the first one's permissions",
+			checks: []gin.HandlerFunc{
+				RequirePermission(tenantWide, "records:read"),
+				RequirePermission(resolverFor("ws1"), "records:read"),
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "resolvers from the same factory don't share grants",
+			checks: []gin.HandlerFunc{
+				RequirePermission(resolverFor("ws1"), "records:delete"),
+				RequirePermission(resolverFor("ws2"), "records:delete"),
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "each resolver grants its own permissions",
+			checks: []gin.HandlerFunc{
+				RequirePermission(resolverFor("ws1"), "records:delete"),
+				RequirePermission(resolverFor("ws2"), "records:read"),
+			},
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(JWT(AuthConfig{JWTSecret: "jwt-secret"}))
+			handlers := append(tt.checks, func(c *gin.Context) { c.Status(http.StatusCreated) })
+			engine.POST("/records", handlers...)
+
+			if status := requestPermission(t, engine, "usr_editor"); status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewPermissionResolver_RequiresKey(t *testing.T) {
+	resolve := func(c *gin.Context, claims *JWTClaims) ([]string, error) { return nil, nil }
+
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{name: "empty key", fn: func() { NewPermissionResolver("", resolve) }},
+		{name: "nil func", fn: func() { NewPermissionResolver("user", nil) }},
+		{name: "zero resolver", fn: func() { RequirePermission(PermissionResolver{}, "records:read") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
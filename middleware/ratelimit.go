@@ -2,13 +2,12 @@ package middleware
 
 import (
 	"context"
-	"fmt"
-	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/cache"
 	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -27,7 +26,7 @@ type RateLimitConfig struct {
 
 // DefaultKeyFunc generates rate limit key based on client IP
 func DefaultKeyFunc(c *gin.Context) string {
-	return c.ClientIP()
+	return ClientIP(c)
 }
 
 // UserKeyFunc generates rate limit key based on user ID
@@ -36,7 +35,7 @@ func UserKeyFunc(c *gin.Context) string {
 	if userID != "" {
 		return "user:" + userID
 	}
-	return c.ClientIP()
+	return ClientIP(c)
 }
 
 // TenantKeyFunc generates rate limit key based on tenant ID
@@ -45,7 +44,7 @@ func TenantKeyFunc(c *gin.Context) string {
 	if tenantID != "" {
 		return "tenant:" + tenantID
 	}
-	return c.ClientIP()
+	return ClientIP(c)
 }
 
 // TokenBucketRateLimit implements token bucket rate limiting
@@ -81,8 +80,7 @@ func TokenBucketRateLimit(config RateLimitConfig) gin.HandlerFunc {
 			c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
 			
 			retryAfter := int(limiter.Reserve().Delay().Seconds()) + 1
-			c.JSON(http.StatusTooManyRequests, errors.NewRateLimitedError(retryAfter))
-			c.Abort()
+			response.RespondError(c, errors.NewRateLimitedError(retryAfter))
 			return
 		}
 		
@@ -117,25 +115,16 @@ func SlidingWindowRateLimit(config RateLimitConfig) gin.HandlerFunc {
 		}
 		
 		key := "ratelimit:" + config.KeyFunc(c)
-		ctx := context.Background()
-		now := time.Now()
-		window := now.Truncate(config.WindowSize)
 		
-		// Get current count for this window
-		countKey := fmt.Sprintf("%s:%d", key, window.Unix())
-		
-		// Increment counter
-		count, err := config.RedisClient.IncrementBy(ctx, countKey, 1)
+		// Count the request in the window by the Redis server's clock, so replicas
+		// whose clocks drift or are stepped by NTP still agree on window boundaries
+		counted, err := config.RedisClient.IncrementWindow(context.Background(), key, config.WindowSize)
 		if err != nil {
 			// If Redis is unavailable, allow the request but log the error
 			c.Next()
 			return
 		}
-		
-		// Set expiration for the key
-		if count == 1 {
-			config.RedisClient.Expire(ctx, countKey, config.WindowSize)
-		}
+		count, now, window := counted.Count, counted.Now, counted.Start
 		
 		// Check if limit exceeded
 		if int(count) > config.MaxRequests {
@@ -148,8 +137,7 @@ func SlidingWindowRateLimit(config RateLimitConfig) gin.HandlerFunc {
 			c.Header("X-RateLimit-Window", config.WindowSize.String())
 			
 			retryAfter := int(window.Add(config.WindowSize).Sub(now).Seconds()) + 1
-			c.JSON(http.StatusTooManyRequests, errors.NewRateLimitedError(retryAfter))
-			c.Abort()
+			response.RespondError(c, errors.NewRateLimitedError(retryAfter))
 			return
 		}
 		
@@ -190,25 +178,16 @@ func FixedWindowRateLimit(config RateLimitConfig) gin.HandlerFunc {
 		}
 		
 		key := "ratelimit:" + config.KeyFunc(c)
-		ctx := context.Background()
-		now := time.Now()
-		window := now.Truncate(config.WindowSize)
 		
-		// Create window-specific key
-		windowKey := fmt.Sprintf("%s:%d", key, window.Unix())
-		
-		// Try to increment counter
-		count, err := config.RedisClient.IncrementBy(ctx, windowKey, 1)
+		// Count the request in the window by the Redis server's clock, so replicas
+		// whose clocks drift or are stepped by NTP still agree on window boundaries
+		counted, err := config.RedisClient.IncrementWindow(context.Background(), key, config.WindowSize)
 		if err != nil {
 			// If Redis is unavailable, allow the request but log the error
 			c.Next()
 			return
 		}
-		
-		// Set expiration for new windows
-		if count == 1 {
-			config.RedisClient.Expire(ctx, windowKey, config.WindowSize)
-		}
+		count, now, window := counted.Count, counted.Now, counted.Start
 		
 		// Check if limit exceeded
 		if int(count) > config.MaxRequests {
@@ -221,8 +200,7 @@ func FixedWindowRateLimit(config RateLimitConfig) gin.HandlerFunc {
 			c.Header("X-RateLimit-Window", config.WindowSize.String())
 			
 			retryAfter := int(window.Add(config.WindowSize).Sub(now).Seconds()) + 1
-			c.JSON(http.StatusTooManyRequests, errors.NewRateLimitedError(retryAfter))
-			c.Abort()
+			response.RespondError(c, errors.NewRateLimitedError(retryAfter))
 			return
 		}
 		
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"math"
+	"time"
+)
+
+// StrictRateLimitConfig suits sensitive endpoints such as login and password
+// reset: a few requests per second per client IP, 30 per minute.
+func StrictRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: 1,
+		BurstSize:         5,
+		WindowSize:        time.Minute,
+		MaxRequests:       30,
+		KeyFunc:           DefaultKeyFunc,
+	}
+}
+
+// StandardRateLimitConfig suits authenticated API traffic, limited per user
+func StandardRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: 10,
+		BurstSize:         20,
+		WindowSize:        time.Minute,
+		MaxRequests:       600,
+		KeyFunc:           UserKeyFunc,
+	}
+}
+
+// LenientRateLimitConfig suits high-volume internal or tenant-wide traffic
+func LenientRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: 50,
+		BurstSize:         100,
+		WindowSize:        time.Minute,
+		MaxRequests:       3000,
+		KeyFunc:           TenantKeyFunc,
+	}
+}
+
+// PublicAPIRateLimitConfig suits unauthenticated public endpoints, limited per client IP
+func PublicAPIRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: 5,
+		BurstSize:         10,
+		WindowSize:        time.Minute,
+		MaxRequests:       300,
+		KeyFunc:           DefaultKeyFunc,
+	}
+}
+
+// ScaleRateLimit multiplies the rate, burst and window limit of config by factor,
+// e.g. ScaleRateLimit(StandardRateLimitConfig(), 5) for enterprise tenants.
+// Scaled limits are rounded up and never drop below 1; a non-positive factor
+// returns config unchanged.
+func ScaleRateLimit(config RateLimitConfig, factor float64) RateLimitConfig {
+	if factor <= 0 {
+		return config
+	}
+
+	config.RequestsPerSecond = scaleLimit(config.RequestsPerSecond, factor)
+	config.BurstSize = scaleLimit(config.BurstSize, factor)
+	config.MaxRequests = scaleLimit(config.MaxRequests, factor)
+	return config
+}
+
+func scaleLimit(limit int, factor float64) int {
+	if limit <= 0 {
+		return limit
+	}
+	scaled := int(math.Ceil(float64(limit) * factor))
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
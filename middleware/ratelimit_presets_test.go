@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"testing"
+)
+
+func TestRateLimitPresets(t *testing.T) {
+	presets := map[string]RateLimitConfig{
+		"strict":     StrictRateLimitConfig(),
+		"standard":   StandardRateLimitConfig(),
+		"lenient":    LenientRateLimitConfig(),
+		"public api": PublicAPIRateLimitConfig(),
+	}
+
+	for name, config := range presets {
+		t.Run(name, func(t *testing.T) {
+			assertConsistentRateLimit(t, config)
+		})
+
+		t.Run(name+" scaled", func(t *testing.T) {
+			for _, factor := range []float64{0.1, 0.5, 2, 10} {
+				assertConsistentRateLimit(t, ScaleRateLimit(config, factor))
+			}
+		})
+	}
+}
+
+func assertConsistentRateLimit(t *testing.T, config RateLimitConfig) {
+	t.Helper()
+
+	if config.RequestsPerSecond <= 0 {
+		t.Errorf("RequestsPerSecond = %d, want > 0", config.RequestsPerSecond)
+	}
+	if config.BurstSize < config.RequestsPerSecond {
+		t.Errorf("BurstSize %d < RequestsPerSecond %d", config.BurstSize, config.RequestsPerSecond)
+	}
+	if config.WindowSize <= 0 {
+		t.Errorf("WindowSize = %v, want > 0", config.WindowSize)
+	}
+	if config.MaxRequests <= 0 {
+		t.Errorf("MaxRequests = %d, want > 0", config.MaxRequests)
+	}
+	if sustained := int(config.WindowSize.Seconds()) * config.RequestsPerSecond; config.MaxRequests > sustained {
+		t.Errorf("MaxRequests %d exceeds the sustained rate over the window (%d)", config.MaxRequests, sustained)
+	}
+	if config.KeyFunc == nil {
+		t.Error("expected KeyFunc to be set")
+	}
+}
+
+func TestScaleRateLimit(t *testing.T) {
+	base := StandardRateLimitConfig()
+
+	tests := []struct {
+		name      string
+		factor    float64
+		wantRPS   int
+		wantBurst int
+		wantMax   int
+	}{
+		{name: "enterprise", factor: 5, wantRPS: 50, wantBurst: 100, wantMax: 3000},
+		{name: "fractional rounds up", factor: 0.25, wantRPS: 3, wantBurst: 5, wantMax: 150},
+		{name: "zero factor unchanged", factor: 0, wantRPS: 10, wantBurst: 20, wantMax: 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScaleRateLimit(base, tt.factor)
+			if got.RequestsPerSecond != tt.wantRPS || got.BurstSize != tt.wantBurst || got.MaxRequests != tt.wantMax {
+				t.Errorf("ScaleRateLimit() = rps %d burst %d max %d, want %d %d %d",
+					got.RequestsPerSecond, got.BurstSize, got.MaxRequests, tt.wantRPS, tt.wantBurst, tt.wantMax)
+			}
+			if got.WindowSize != base.WindowSize {
+				t.Errorf("WindowSize changed to %v", got.WindowSize)
+			}
+		})
+	}
+}
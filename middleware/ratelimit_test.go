@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// connectReplica connects another client to server, as a second service replica would
+func connectReplica(t *testing.T, server *miniredis.Miniredis) *cache.Client {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(server.Addr())
+	portNum, _ := strconv.Atoi(port)
+	client, err := cache.New(&config.RedisConfig{Host: host, Port: portNum})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestWindowRateLimit_ClockSkew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiters := []struct {
+		name string
+		new  func(RateLimitConfig) gin.HandlerFunc
+	}{
+		{"fixed window", FixedWindowRateLimit},
+		{"sliding window", SlidingWindowRateLimit},
+	}
+
+	for _, limiter := range limiters {
+		t.Run(limiter.name, func(t *testing.T) {
+			first, server := sharedtesting.NewTestCache(t)
+			second := connectReplica(t, server)
+
+			serverNow := time.Date(2024, 1, 1, 12, 0, 50, 0, time.UTC)
+			server.SetTime(serverNow)
+
+			// Windows follow the Redis clock, whatever the replicas' own clocks say
+			replica := func(client *cache.Client) *gin.Engine {
+				router := gin.New()
+				router.Use(limiter.new(RateLimitConfig{
+					MaxRequests: 2,
+					WindowSize:  time.Minute,
+					RedisClient: client,
+					KeyFunc:     func(*gin.Context) string { return "client-1" },
+				}))
+				router.GET("/records", func(c *gin.Context) { c.Status(http.StatusOK) })
+				return router
+			}
+			replicas := []*gin.Engine{replica(first), replica(second)}
+
+			reset := strconv.FormatInt(time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC).Unix(), 10)
+			requests := []struct {
+				replica    int
+				wantStatus int
+				wantReset  string
+			}{
+				{0, http.StatusOK, reset},
+				{1, http.StatusOK, reset},
+				{0, http.StatusTooManyRequests, reset},
+				{1, http.StatusTooManyRequests, reset},
+			}
+			for i, req := range requests {
+				w := httptest.NewRecorder()
+				replicas[req.replica].ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/records", nil))
+				if w.Code != req.wantStatus {
+					t.Errorf("request %d: status = %d, want %d", i, w.Code, req.wantStatus)
+				}
+				if got := w.Header().Get("X-RateLimit-Reset"); got != req.wantReset {
+					t.Errorf("request %d: reset = %s, want %s", i, got, req.wantReset)
+				}
+			}
+
+			// Only the Redis clock moving on starts a new window
+			server.SetTime(serverNow.Add(15 * time.Second))
+			w := httptest.NewRecorder()
+			replicas[1].ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/records", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("status in the next window = %d, want %d", w.Code, http.StatusOK)
+			}
+		})
+	}
+}
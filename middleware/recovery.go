@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Recovery returns a middleware that recovers from panics, logs them with the
+// stack trace and responds with a generic internal error
+func Recovery(log *logger.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		if log != nil {
+			log.Error("Panic recovered",
+				zap.String("request_id", GetRequestIDFromContext(c.Request.Context())),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("panic", fmt.Sprintf("%v", recovered)),
+				zap.Stack("stacktrace"),
+			)
+		}
+
+		response.RespondError(c, errors.NewInternalError("Internal server error"))
+	})
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestID_ConsistentAcrossHeaderLogsAndErrorResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		incomingID string
+		wantID     string
+	}{
+		{name: "generated", incomingID: ""},
+		{name: "propagated from caller", incomingID: "trace-abc-123", wantID: "trace-abc-123"},
+		{name: "unsafe caller value replaced", incomingID: "bad id\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			log := &logger.Logger{Logger: zap.New(core)}
+
+			router := gin.New()
+			router.Use(RequestLogging(log), ErrorHandler())
+			router.GET("/workspaces/:id", func(c *gin.Context) {
+				c.Error(errors.NewNotFoundError("Workspace"))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/workspaces/42", nil)
+			if tt.incomingID != "" {
+				req.Header.Set(RequestIDHeader, tt.incomingID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", w.Code)
+			}
+
+			headerID := w.Header().Get(RequestIDHeader)
+			if headerID == "" {
+				t.Fatal("expected X-Request-ID response header")
+			}
+			if tt.wantID != "" && headerID != tt.wantID {
+				t.Errorf("header request ID = %q, want %q", headerID, tt.wantID)
+			}
+			if tt.wantID == "" && !strings.HasPrefix(headerID, "req_") {
+				t.Errorf("expected generated request ID, got %q", headerID)
+			}
+
+			var body errors.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if body.RequestID != headerID {
+				t.Errorf("response request_id = %q, header = %q", body.RequestID, headerID)
+			}
+			if body.Error == nil || body.Error.Code != errors.ErrCodeNotFound {
+				t.Errorf("unexpected error body: %s", w.Body.String())
+			}
+
+			entries := logs.FilterMessage("HTTP request").All()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 request log entry, got %d", len(entries))
+			}
+			if logged := entries[0].ContextMap()["request_id"]; logged != headerID {
+				t.Errorf("logged request_id = %v, header = %q", logged, headerID)
+			}
+		})
+	}
+}
+
+func TestErrorHandler_HidesUnexpectedErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		c.Error(http.ErrHandlerTimeout)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if strings.Contains(w.Body.String(), http.ErrHandlerTimeout.Error()) {
+		t.Errorf("internal error details leaked: %s", w.Body.String())
+	}
+}
+
+func TestRequestLogging_RouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zap.InfoLevel)
+	router := gin.New()
+	router.Use(RequestLogging(&logger.Logger{Logger: zap.New(core)}))
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		path      string
+		wantRoute string
+	}{
+		{path: "/users/42", wantRoute: "/users/:id"},
+		{path: "/missing/7", wantRoute: UnmatchedRoute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			entries := logs.TakeAll()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 log entry, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["route"] != tt.wantRoute {
+				t.Errorf("route = %v, want %q", fields["route"], tt.wantRoute)
+			}
+			if fields["path"] != tt.path {
+				t.Errorf("path = %v, want %q", fields["path"], tt.path)
+			}
+		})
+	}
+}
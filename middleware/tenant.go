@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ResourceTenantLoader returns the tenant ID owning the resource addressed by the request.
+// It should return a NOT_FOUND error (or gorm.ErrRecordNotFound) when the resource doesn't exist.
+type ResourceTenantLoader func(c *gin.Context) (string, error)
+
+// TenantLookupFunc returns the tenant ID owning the resource with the given ID
+type TenantLookupFunc func(ctx context.Context, resourceID string) (string, error)
+
+// ParamTenantLoader builds a ResourceTenantLoader that looks up the resource named by
+// the given path parameter, e.g. ParamTenantLoader("workspaceID", lookupWorkspaceTenant)
+func ParamTenantLoader(param string, lookup TenantLookupFunc) ResourceTenantLoader {
+	return func(c *gin.Context) (string, error) {
+		resourceID := c.Param(param)
+		if resourceID == "" {
+			return "", errors.NewNotFoundError("Resource")
+		}
+		return lookup(c.Request.Context(), resourceID)
+	}
+}
+
+// EnforceResourceTenant returns middleware that verifies the resource addressed by the
+// request belongs to the caller's tenant. Resources of other tenants are reported as
+// not found rather than forbidden so their existence isn't leaked.
+func EnforceResourceTenant(loader ResourceTenantLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := GetTenantIDFromContext(c)
+		if tenantID == "" {
+			response.RespondError(c, errors.NewUnauthorizedError("Missing tenant"))
+			return
+		}
+
+		resourceTenantID, err := loader(c)
+		if err != nil {
+			if isNotFound(err) {
+				response.RespondError(c, errors.NewNotFoundError("Resource"))
+			} else {
+				response.RespondError(c, errors.NewInternalError("Failed to load resource").WithCause(err))
+			}
+			return
+		}
+
+		if resourceTenantID != tenantID {
+			response.RespondError(c, errors.NewNotFoundError("Resource"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isNotFound reports whether err means the resource doesn't exist
+func isNotFound(err error) bool {
+	return errors.Is(err, errors.ErrCodeNotFound) || stderrors.Is(err, gorm.ErrRecordNotFound)
+}
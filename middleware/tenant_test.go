@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestEnforceResourceTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	workspaceTenants := map[string]string{
+		"1": "tenant-a",
+		"2": "tenant-b",
+	}
+	lookup := func(ctx context.Context, id string) (string, error) {
+		if id == "500" {
+			return "", fmt.Errorf("connection refused")
+		}
+		tenantID, ok := workspaceTenants[id]
+		if !ok {
+			return "", gorm.ErrRecordNotFound
+		}
+		return tenantID, nil
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if tenantID := c.GetHeader("X-Test-Tenant"); tenantID != "" {
+			ctx := context.WithValue(c.Request.Context(), "tenant_id", tenantID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	})
+	router.GET("/workspaces/:workspaceID",
+		EnforceResourceTenant(ParamTenantLoader("workspaceID", lookup)),
+		func(c *gin.Context) { c.Status(http.StatusOK) },
+	)
+
+	tests := []struct {
+		name       string
+		path       string
+		tenantID   string
+		wantStatus int
+	}{
+		{name: "own resource", path: "/workspaces/1", tenantID: "tenant-a", wantStatus: http.StatusOK},
+		{name: "other tenant's resource", path: "/workspaces/2", tenantID: "tenant-a", wantStatus: http.StatusNotFound},
+		{name: "missing resource", path: "/workspaces/999", tenantID: "tenant-a", wantStatus: http.StatusNotFound},
+		{name: "loader failure", path: "/workspaces/500", tenantID: "tenant-a", wantStatus: http.StatusInternalServerError},
+		{name: "no tenant in context", path: "/workspaces/1", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.tenantID != "" {
+				req.Header.Set("X-Test-Tenant", tt.tenantID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout returns middleware that gives each request a deadline of timeout on its
+// context. Handlers must pass c.Request.Context() to database, cache and HTTP calls
+// so they are cancelled when it expires. If the deadline passes before a response
+// was written, the request is answered with a 408 TIMEOUT error. A zero or negative
+// timeout disables the middleware.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if c.Writer.Written() || !stderrors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		response.RespondError(c, errors.NewTimeoutError("request"))
+	}
+}
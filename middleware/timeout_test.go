@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "fast handler",
+			timeout:    time.Second,
+			handler:    func(c *gin.Context) { c.Status(http.StatusOK) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "handler cancelled by the deadline",
+			timeout: 20 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+				c.Error(c.Request.Context().Err())
+			},
+			wantStatus: http.StatusRequestTimeout,
+			wantCode:   errors.ErrCodeTimeout,
+		},
+		{
+			name:    "handler ignoring the deadline",
+			timeout: 10 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				time.Sleep(30 * time.Millisecond)
+			},
+			wantStatus: http.StatusRequestTimeout,
+			wantCode:   errors.ErrCodeTimeout,
+		},
+		{
+			name:    "response written before the deadline is kept",
+			timeout: 10 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusAccepted)
+				c.Writer.WriteHeaderNow()
+				<-c.Request.Context().Done()
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:    "disabled",
+			timeout: 0,
+			handler: func(c *gin.Context) {
+				if _, ok := c.Request.Context().Deadline(); ok {
+					c.Status(http.StatusInternalServerError)
+					return
+				}
+				c.Status(http.StatusOK)
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestLogging(&logger.Logger{Logger: zap.NewNop()}), ErrorHandler(), Timeout(tt.timeout))
+			router.GET("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode == "" {
+				return
+			}
+
+			var body errors.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if body.Error == nil || body.Error.Code != tt.wantCode {
+				t.Errorf("unexpected body: %s", w.Body.String())
+			}
+			if body.RequestID == "" {
+				t.Error("expected the timeout response to carry the request ID")
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package middleware
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Upload limits used when UploadConfig leaves them unset
+const (
+	DefaultUploadMaxSize         = 32 << 20
+	DefaultUploadMaxFiles        = 10
+	DefaultUploadMemoryThreshold = 10 << 20
+)
+
+// UploadConfig holds multipart upload limits
+type UploadConfig struct {
+	MaxSize         int64    // Maximum request body size in bytes
+	MaxFileSize     int64    // Maximum size of a single file (defaults to MaxSize)
+	MaxFiles        int      // Maximum number of files
+	AllowedTypes    []string // Allowed content types, e.g. "text/csv" or "image/*"; empty allows any
+	MemoryThreshold int64    // Bytes kept in memory before files are spooled to disk
+}
+
+// ForWorkspace returns a copy of the config whose per-file limit also honors the
+// workspace's MaxFileSize setting
+func (cfg UploadConfig) ForWorkspace(settings *models.WorkspaceSettings) UploadConfig {
+	if settings != nil && settings.MaxFileSize > 0 {
+		if cfg.MaxFileSize <= 0 || settings.MaxFileSize < cfg.MaxFileSize {
+			cfg.MaxFileSize = settings.MaxFileSize
+		}
+	}
+	return cfg
+}
+
+// withDefaults fills unset limits
+func (cfg UploadConfig) withDefaults() UploadConfig {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultUploadMaxSize
+	}
+	if cfg.MaxFileSize <= 0 || cfg.MaxFileSize > cfg.MaxSize {
+		cfg.MaxFileSize = cfg.MaxSize
+	}
+	if cfg.MaxFiles <= 0 {
+		cfg.MaxFiles = DefaultUploadMaxFiles
+	}
+	if cfg.MemoryThreshold <= 0 {
+		cfg.MemoryThreshold = DefaultUploadMemoryThreshold
+	}
+	return cfg
+}
+
+// UploadedFile is a validated file from a multipart upload
+type UploadedFile struct {
+	FieldName   string
+	Filename    string
+	ContentType string // Sniffed from the file contents
+	Size        int64
+	data        []byte // Contents of files kept in memory
+	path        string // Temporary file of files spooled to disk
+}
+
+// Open returns a reader for the file contents; callers must close it
+func (f *UploadedFile) Open() (multipart.File, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return memoryFile{bytes.NewReader(f.data)}, nil
+}
+
+// Remove deletes the file's temporary copy on disk, if any
+func (f *UploadedFile) Remove() error {
+	if f.path == "" {
+		return nil
+	}
+	return os.Remove(f.path)
+}
+
+// RemoveUploads deletes the temporary copies of files returned by ParseUpload
+func RemoveUploads(files []*UploadedFile) {
+	for _, file := range files {
+		file.Remove()
+	}
+}
+
+// memoryFile adapts an in-memory file to multipart.File
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// ParseUpload streams the request's multipart parts within the configured limits
+// and returns its files. Parts are checked as they arrive: the file count first,
+// then the content type sniffed from the first bytes, then the size, so a
+// violation stops reading the body at once with a 413 or 415 error. Files stay in
+// memory until MemoryThreshold bytes are held and are spooled to temporary files
+// after that; remove them with RemoveUploads (the Upload middleware does this
+// automatically). Non-file fields are available through c.PostForm.
+func ParseUpload(c *gin.Context, cfg UploadConfig) ([]*UploadedFile, error) {
+	cfg = cfg.withDefaults()
+
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, errors.NewUnsupportedMediaTypeError(mediaType, []string{"multipart/form-data"})
+	}
+
+	if c.Request.ContentLength > cfg.MaxSize {
+		return nil, errors.NewPayloadTooLargeError("Request body is too large", cfg.MaxSize)
+	}
+
+	body := &maxBytesBody{ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxSize)}
+	c.Request.Body = body
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, errors.NewInvalidInputError("body", "malformed multipart form").WithCause(err)
+	}
+
+	var files []*UploadedFile
+	values := make(map[string][]string)
+	memoryLeft := cfg.MemoryThreshold
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			RemoveUploads(files)
+			return nil, body.readError(err, cfg.MaxSize)
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(part)
+			part.Close()
+			if err != nil {
+				RemoveUploads(files)
+				return nil, body.readError(err, cfg.MaxSize)
+			}
+			values[part.FormName()] = append(values[part.FormName()], string(value))
+			continue
+		}
+
+		if len(files) == cfg.MaxFiles {
+			part.Close()
+			RemoveUploads(files)
+			return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("Too many files, at most %d allowed", cfg.MaxFiles), int64(cfg.MaxFiles))
+		}
+
+		file, err := readUploadPart(part, cfg, &memoryLeft, body)
+		part.Close()
+		if err != nil {
+			RemoveUploads(files)
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	// Later c.PostForm calls read the fields collected above instead of the body
+	c.Request.MultipartForm = &multipart.Form{Value: values}
+	c.Request.PostForm = values
+	return files, nil
+}
+
+// readUploadPart sniffs and checks the content type of a file part, then reads
+// it into memory while memoryLeft allows and into a temporary file after that
+func readUploadPart(part *multipart.Part, cfg UploadConfig, memoryLeft *int64, body *maxBytesBody) (*UploadedFile, error) {
+	file := &UploadedFile{FieldName: part.FormName(), Filename: part.FileName()}
+	limited := io.LimitReader(part, cfg.MaxFileSize+1)
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(limited, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, body.readError(err, cfg.MaxSize)
+	}
+	head = head[:n]
+
+	file.ContentType = sniffContentType(head, part.Header.Get("Content-Type"))
+	if !isAllowedType(file.ContentType, cfg.AllowedTypes) {
+		return nil, errors.NewUnsupportedMediaTypeError(file.ContentType, cfg.AllowedTypes)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(head)
+	if _, err := io.CopyN(&buf, limited, *memoryLeft-int64(buf.Len())+1); err != nil && err != io.EOF {
+		return nil, body.readError(err, cfg.MaxSize)
+	}
+
+	file.Size = int64(buf.Len())
+	if file.Size <= *memoryLeft {
+		file.data = buf.Bytes()
+		*memoryLeft -= file.Size
+	} else {
+		tmp, err := os.CreateTemp("", "upload-*")
+		if err != nil {
+			return nil, errors.NewInternalError("Failed to store uploaded file").WithCause(err)
+		}
+		file.path = tmp.Name()
+		file.Size, err = io.Copy(tmp, io.MultiReader(&buf, limited))
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			file.Remove()
+			return nil, body.readError(err, cfg.MaxSize)
+		}
+	}
+
+	if file.Size > cfg.MaxFileSize {
+		file.Remove()
+		return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("File '%s' is too large", file.Filename), cfg.MaxFileSize)
+	}
+	return file, nil
+}
+
+// Upload returns middleware that parses multipart uploads with ParseUpload, stores the
+// files in the context (see GetUploadedFiles) and removes spooled files after the request
+func Upload(cfg UploadConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		files, err := ParseUpload(c, cfg)
+		if err != nil {
+			response.RespondError(c, err)
+			return
+		}
+		defer RemoveUploads(files)
+
+		c.Set("uploaded_files", files)
+		c.Next()
+	}
+}
+
+// GetUploadedFiles returns the files parsed by the Upload middleware
+func GetUploadedFiles(c *gin.Context) []*UploadedFile {
+	if files, ok := c.Get("uploaded_files"); ok {
+		return files.([]*UploadedFile)
+	}
+	return nil
+}
+
+// maxBytesBody records whether the size limit was hit, since the multipart
+// parser doesn't wrap the underlying read error
+type maxBytesBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *maxBytesBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// readError maps an error reading the multipart body to 413 when the size limit
+// was hit and to a malformed form otherwise
+func (b *maxBytesBody) readError(err error, limit int64) error {
+	if b.exceeded {
+		return errors.NewPayloadTooLargeError("Request body is too large", limit)
+	}
+	return errors.NewInvalidInputError("body", "malformed multipart form").WithCause(err)
+}
+
+// sniffContentType detects a file's type from its first bytes. The client's
+// declared type is only used to refine plain text, e.g. to text/csv, which the
+// bytes alone can't tell apart.
+func sniffContentType(head []byte, declared string) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if sniffed != "text/plain" {
+		return sniffed
+	}
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && strings.HasPrefix(mediaType, "text/") {
+		return mediaType
+	}
+	return sniffed
+}
+
+// isAllowedType matches a content type against an allowlist supporting "type/*" entries
+func isAllowedType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, pattern := range allowed {
+		if strings.EqualFold(pattern, contentType) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+type testUpload struct {
+	filename    string
+	contentType string
+	content     string
+}
+
+func newUploadRequest(t *testing.T, uploads ...testUpload) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, upload := range uploads {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+upload.filename+`"`)
+		if upload.contentType != "" {
+			header.Set("Content-Type", upload.contentType)
+		}
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatalf("failed to create part: %v", err)
+		}
+		part.Write([]byte(upload.content))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := UploadConfig{
+		MaxSize:         1 << 20,
+		MaxFileSize:     64,
+		MaxFiles:        2,
+		AllowedTypes:    []string{"text/csv", "image/*"},
+		MemoryThreshold: 16,
+	}
+
+	var received []string
+	router := gin.New()
+	router.POST("/upload", Upload(cfg), func(c *gin.Context) {
+		received = nil
+		for _, file := range GetUploadedFiles(c) {
+			reader, err := file.Open()
+			if err != nil {
+				t.Errorf("Open() error = %v", err)
+				continue
+			}
+			content, _ := io.ReadAll(reader)
+			reader.Close()
+			received = append(received, file.ContentType+":"+string(content))
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		uploads    []testUpload
+		wantStatus int
+		wantCode   string
+		wantFiles  []string
+	}{
+		{
+			name:       "valid upload",
+			uploads:    []testUpload{{filename: "data.csv", contentType: "text/csv", content: "a,b\n1,2\n"}},
+			wantStatus: http.StatusOK,
+			wantFiles:  []string{"text/csv:a,b\n1,2\n"},
+		},
+		{
+			name: "file spooled to disk over memory threshold",
+			uploads: []testUpload{{
+				filename:    "big.csv",
+				contentType: "text/csv",
+				content:     strings.Repeat("x", 40),
+			}},
+			wantStatus: http.StatusOK,
+			wantFiles:  []string{"text/csv:" + strings.Repeat("x", 40)},
+		},
+		{
+			name:       "sniffed image type",
+			uploads:    []testUpload{{filename: "pixel.png", content: "\x89PNG\r\n\x1a\n0000"}},
+			wantStatus: http.StatusOK,
+			wantFiles:  []string{"image/png:\x89PNG\r\n\x1a\n0000"},
+		},
+		{
+			name:       "oversize file",
+			uploads:    []testUpload{{filename: "huge.csv", contentType: "text/csv", content: strings.Repeat("x", 100)}},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   errors.ErrCodePayloadTooLarge,
+		},
+		{
+			name:       "disallowed type",
+			uploads:    []testUpload{{filename: "run.sh", contentType: "application/x-sh", content: "#!/bin/sh"}},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   errors.ErrCodeUnsupportedMedia,
+		},
+		{
+			name:       "declared type not trusted",
+			uploads:    []testUpload{{filename: "fake.png", contentType: "image/png", content: "#!/bin/sh\nrm -rf /"}},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   errors.ErrCodeUnsupportedMedia,
+		},
+		{
+			name: "too many files",
+			uploads: []testUpload{
+				{filename: "a.csv", contentType: "text/csv", content: "a"},
+				{filename: "b.csv", contentType: "text/csv", content: "b"},
+				{filename: "c.csv", contentType: "text/csv", content: "c"},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   errors.ErrCodePayloadTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newUploadRequest(t, tt.uploads...))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				var body errors.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == nil || body.Error.Code != tt.wantCode {
+					t.Errorf("expected error code %s, got %s", tt.wantCode, w.Body.String())
+				}
+			}
+			if tt.wantFiles != nil && strings.Join(received, "|") != strings.Join(tt.wantFiles, "|") {
+				t.Errorf("received files %q, want %q", received, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestUpload_FormFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("table", "contacts")
+	part, _ := writer.CreateFormFile("file", "data.csv")
+	part.Write([]byte("a,b\n"))
+	writer.Close()
+
+	var table string
+	var files int
+	router := gin.New()
+	router.POST("/upload", Upload(UploadConfig{}), func(c *gin.Context) {
+		table = c.PostForm("table")
+		files = len(GetUploadedFiles(c))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || table != "contacts" || files != 1 {
+		t.Errorf("status = %d, table = %q, files = %d, want 200, contacts and 1 file", w.Code, table, files)
+	}
+}
+
+func TestUpload_RequestTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/upload", Upload(UploadConfig{MaxSize: 128}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := newUploadRequest(t, testUpload{filename: "a.csv", contentType: "text/csv", content: strings.Repeat("x", 512)})
+	req.ContentLength = -1 // force the streaming limit rather than the Content-Length check
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
+
+func TestUpload_NotMultipart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/upload", Upload(UploadConfig{}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want 415", w.Code)
+	}
+}
+
+func TestUploadConfig_ForWorkspace(t *testing.T) {
+	base := UploadConfig{MaxSize: 1 << 20, MaxFileSize: 1 << 20}
+
+	tests := []struct {
+		name     string
+		settings *models.WorkspaceSettings
+		want     int64
+	}{
+		{name: "stricter workspace limit", settings: &models.WorkspaceSettings{MaxFileSize: 1024}, want: 1024},
+		{name: "looser workspace limit", settings: &models.WorkspaceSettings{MaxFileSize: 10 << 20}, want: 1 << 20},
+		{name: "unset workspace limit", settings: &models.WorkspaceSettings{}, want: 1 << 20},
+		{name: "no settings", settings: nil, want: 1 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.ForWorkspace(tt.settings).MaxFileSize; got != tt.want {
+				t.Errorf("MaxFileSize = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
 	"gorm.io/gorm"
 )
 
@@ -11,10 +13,34 @@ import (
 type BaseModel struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedAt time.Time      `json:"updated_at" diff:"-"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
+// ULIDModel is an alternative to BaseModel for models keyed by a ULID string
+// instead of an auto-increment ID, so row counts aren't exposed and IDs can't be guessed.
+// ULIDs sort by creation time. Models embedding it that define their own BeforeCreate
+// hook must call ULIDModel.BeforeCreate.
+type ULIDModel struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:char(26)"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" diff:"-"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate assigns a new ULID unless the ID was set explicitly
+func (u *ULIDModel) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = utils.GenerateULID()
+	}
+	return nil
+}
+
+// IsDeleted checks if the model is soft deleted
+func (u *ULIDModel) IsDeleted() bool {
+	return u.DeletedAt.Valid
+}
+
 // SoftDeletable interface for models that support soft deletion
 type SoftDeletable interface {
 	IsDeleted() bool
@@ -58,7 +84,7 @@ type AuditableModel struct {
 	BaseModel
 	CreatedBy uint   `json:"created_by" gorm:"index"`
 	UpdatedBy uint   `json:"updated_by" gorm:"index"`
-	Version   int64  `json:"version" gorm:"default:1"`
+	Version   int64  `json:"version" gorm:"default:1" diff:"-"`
 }
 
 // BeforeUpdate increments version for optimistic locking
@@ -107,4 +133,42 @@ func (s Status) IsArchived() bool {
 // IsDeleted checks if the status is deleted
 func (s Status) IsDeleted() bool {
 	return s == StatusDeleted
-}
\ No newline at end of file
+}
+
+// statusTransitions lists the statuses each status may move to. Deleted is final.
+var statusTransitions = map[Status][]Status{
+	StatusPending:  {StatusActive, StatusInactive, StatusDeleted},
+	StatusActive:   {StatusInactive, StatusArchived, StatusDeleted},
+	StatusInactive: {StatusActive, StatusArchived, StatusDeleted},
+	StatusArchived: {StatusActive, StatusDeleted},
+	StatusDeleted:  {},
+}
+
+// CanTransitionTo reports whether s may change to next. Staying on the same status
+// is always allowed, and an unset status may become any known status.
+func (s Status) CanTransitionTo(next Status) bool {
+	if _, known := statusTransitions[next]; !known {
+		return false
+	}
+	if s == "" || s == next {
+		return true
+	}
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition changes s to next, returning ValidationErrors for the status field
+// if the move isn't allowed
+func (s *Status) Transition(next Status) error {
+	if !s.CanTransitionTo(next) {
+		var errs ValidationErrors
+		errs.Add("status", fmt.Sprintf("cannot change from %q to %q", *s, next))
+		return errs
+	}
+	*s = next
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB opens a silent in-memory SQLite database with models migrated
+func openTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		want     bool
+	}{
+		{from: StatusPending, to: StatusActive, want: true},
+		{from: StatusActive, to: StatusArchived, want: true},
+		{from: StatusActive, to: StatusActive, want: true},
+		{from: StatusInactive, to: StatusActive, want: true},
+		{from: StatusArchived, to: StatusActive, want: true},
+		{from: StatusArchived, to: StatusDeleted, want: true},
+		{from: StatusArchived, to: StatusInactive, want: false},
+		{from: StatusArchived, to: StatusPending, want: false},
+		{from: StatusActive, to: StatusPending, want: false},
+		{from: StatusDeleted, to: StatusActive, want: false},
+		{from: StatusDeleted, to: StatusArchived, want: false},
+		{from: "", to: StatusPending, want: true},
+		{from: StatusActive, to: "suspended", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+
+			status := tt.from
+			err := status.Transition(tt.to)
+			if tt.want {
+				if err != nil || status != tt.to {
+					t.Errorf("Transition() = %v, status %q, want %q", err, status, tt.to)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !stderrors.As(err, &errs) || errs.Fields()["status"] == nil {
+				t.Errorf("Transition() error = %v, want ValidationErrors for status", err)
+			}
+			if status != tt.from {
+				t.Errorf("status changed to %q on a rejected transition", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// CoercionError describes a raw value that could not be converted to a field type
+type CoercionError struct {
+	FieldType FieldType
+	Value     string
+	Reason    string
+}
+
+// Error implements the error interface
+func (e *CoercionError) Error() string {
+	return fmt.Sprintf("cannot coerce %q to %s: %s", e.Value, e.FieldType, e.Reason)
+}
+
+// dateLayouts are the accepted input formats for date fields, tried in order.
+// Slash-separated dates are read month-first (US) unless options["date_format"] says otherwise.
+var dateLayouts = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/2006",
+	"01-02-2006",
+	"02.01.2006",
+	"2006/01/02",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	time.RFC3339,
+}
+
+// dateTimeLayouts are the accepted input formats for datetime fields, tried in order
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+}
+
+// currencySymbols are stripped from currency values before parsing
+var currencySymbols = []string{"$", "€", "£", "¥", "₹", "USD", "EUR", "GBP"}
+
+// CoerceValue converts a raw string cell (e.g. from a CSV/XLSX import) to the typed
+// value stored for the given field type. Empty cells coerce to nil.
+//
+// Options may carry "date_format" (a Go time layout) to disambiguate dates and
+// "choices" (a list of names or {"name": ...} objects) for select fields.
+func CoerceValue(raw string, fieldType FieldType, options JSON) (interface{}, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil, nil
+	}
+
+	fail := func(reason string) error {
+		return &CoercionError{FieldType: fieldType, Value: raw, Reason: reason}
+	}
+
+	switch fieldType {
+	case FieldTypeText, FieldTypeBarcode, FieldTypePhone:
+		return value, nil
+
+	case FieldTypeEmail:
+		if !utils.IsValidEmail(value) {
+			return nil, fail("invalid email address")
+		}
+		return strings.ToLower(value), nil
+
+	case FieldTypeURL:
+		if !utils.IsValidURL(value) {
+			return nil, fail("invalid URL")
+		}
+		return value, nil
+
+	case FieldTypeNumber:
+		n, err := parseNumber(value)
+		if err != nil {
+			return nil, fail("not a number")
+		}
+		return n, nil
+
+	case FieldTypeAutoNumber, FieldTypeRating:
+		n, err := strconv.ParseInt(strings.ReplaceAll(value, ",", ""), 10, 64)
+		if err != nil {
+			return nil, fail("not an integer")
+		}
+		return n, nil
+
+	case FieldTypeCurrency:
+		n, err := parseNumber(stripCurrency(value))
+		if err != nil {
+			return nil, fail("not a currency amount")
+		}
+		return n, nil
+
+	case FieldTypePercent:
+		// Percentages are stored as written: "45%" and "45" both become 45
+		n, err := parseNumber(strings.TrimSpace(strings.TrimSuffix(value, "%")))
+		if err != nil {
+			return nil, fail("not a percentage")
+		}
+		return n, nil
+
+	case FieldTypeDuration:
+		if d, err := time.ParseDuration(value); err == nil {
+			return d.Seconds(), nil
+		}
+		n, err := parseNumber(value)
+		if err != nil {
+			return nil, fail("not a duration")
+		}
+		return n, nil
+
+	case FieldTypeBoolean:
+		b, ok := parseBool(value)
+		if !ok {
+			return nil, fail("not a boolean")
+		}
+		return b, nil
+
+	case FieldTypeDate:
+		t, err := parseTime(value, options, dateLayouts)
+		if err != nil {
+			return nil, fail("unrecognized date format")
+		}
+		return t, nil
+
+	case FieldTypeDateTime:
+		t, err := parseTime(value, options, append(dateTimeLayouts, dateLayouts...))
+		if err != nil {
+			return nil, fail("unrecognized date/time format")
+		}
+		return t, nil
+
+	case FieldTypeSelect:
+		choice, err := matchChoice(value, options)
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+		return choice, nil
+
+	case FieldTypeMultiSelect:
+		var selected []string
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			choice, err := matchChoice(part, options)
+			if err != nil {
+				return nil, fail(err.Error())
+			}
+			selected = append(selected, choice)
+		}
+		return selected, nil
+
+	case FieldTypeAttachment, FieldTypeRelation:
+		var items []string
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				items = append(items, part)
+			}
+		}
+		return items, nil
+
+	case FieldTypeFormula, FieldTypeLookup, FieldTypeRollup:
+		return nil, fail("computed fields cannot be imported")
+
+	default:
+		return nil, fail("unsupported field type")
+	}
+}
+
+// parseNumber parses a decimal number allowing thousands separators
+func parseNumber(value string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+}
+
+// stripCurrency removes currency symbols/codes and accounting-style parentheses
+func stripCurrency(value string) string {
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	value = strings.Trim(value, "()")
+	for _, symbol := range currencySymbols {
+		value = strings.ReplaceAll(value, symbol, "")
+	}
+	value = strings.TrimSpace(value)
+	if negative {
+		value = "-" + value
+	}
+	return value
+}
+
+// parseBool parses common spreadsheet boolean representations
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "t", "yes", "y", "1", "on", "checked", "x":
+		return true, true
+	case "false", "f", "no", "n", "0", "off", "unchecked":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// parseTime parses a time using options["date_format"] first, then the given layouts
+func parseTime(value string, options JSON, layouts []string) (time.Time, error) {
+	if format, ok := options["date_format"].(string); ok && format != "" {
+		layouts = append([]string{format}, layouts...)
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+// matchChoice finds the canonical choice name for a select value (case-insensitive)
+func matchChoice(value string, options JSON) (string, error) {
+	choices, err := selectChoices(options)
+	if err != nil {
+		return "", err
+	}
+
+	for _, choice := range choices {
+		if strings.EqualFold(choice, value) {
+			return choice, nil
+		}
+	}
+
+	return "", fmt.Errorf("%q is not one of the allowed choices", value)
+}
+
+// selectChoices extracts the list of choice names from select field options
+func selectChoices(options JSON) ([]string, error) {
+	raw, ok := options["choices"]
+	if !ok {
+		return nil, fmt.Errorf("field has no choices configured")
+	}
+
+	var items []interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	default:
+		return nil, fmt.Errorf("choices must be a list")
+	}
+
+	choices := make([]string, 0, len(items))
+	for _, item := range items {
+		switch c := item.(type) {
+		case string:
+			choices = append(choices, c)
+		case map[string]interface{}:
+			name, ok := c["name"].(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("choice objects must have a name")
+			}
+			choices = append(choices, name)
+		default:
+			return nil, fmt.Errorf("invalid choice of type %T", item)
+		}
+	}
+
+	return choices, nil
+}
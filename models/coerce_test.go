@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoerceValue(t *testing.T) {
+	selectOptions := JSON{"choices": []interface{}{"Todo", map[string]interface{}{"name": "Done"}}}
+	euDates := JSON{"date_format": "02/01/2006"}
+
+	tests := []struct {
+		name      string
+		raw       string
+		fieldType FieldType
+		options   JSON
+		want      interface{}
+		wantErr   bool
+	}{
+		{name: "empty cell", raw: "  ", fieldType: FieldTypeNumber, want: nil},
+		{name: "text", raw: " hello ", fieldType: FieldTypeText, want: "hello"},
+		{name: "email", raw: "User@Example.com", fieldType: FieldTypeEmail, want: "user@example.com"},
+		{name: "invalid email", raw: "not-an-email", fieldType: FieldTypeEmail, wantErr: true},
+		{name: "url", raw: "https://example.com/a", fieldType: FieldTypeURL, want: "https://example.com/a"},
+		{name: "invalid url", raw: "example", fieldType: FieldTypeURL, wantErr: true},
+		{name: "number", raw: "1,234.5", fieldType: FieldTypeNumber, want: 1234.5},
+		{name: "invalid number", raw: "12abc", fieldType: FieldTypeNumber, wantErr: true},
+		{name: "rating", raw: "4", fieldType: FieldTypeRating, want: int64(4)},
+		{name: "currency", raw: "$1,299.99", fieldType: FieldTypeCurrency, want: 1299.99},
+		{name: "negative currency", raw: "(€12.50)", fieldType: FieldTypeCurrency, want: -12.5},
+		{name: "percent", raw: "45%", fieldType: FieldTypePercent, want: 45.0},
+		{name: "percent without symbol", raw: "12.5", fieldType: FieldTypePercent, want: 12.5},
+		{name: "duration", raw: "1h30m", fieldType: FieldTypeDuration, want: 5400.0},
+		{name: "boolean yes", raw: "Yes", fieldType: FieldTypeBoolean, want: true},
+		{name: "boolean zero", raw: "0", fieldType: FieldTypeBoolean, want: false},
+		{name: "invalid boolean", raw: "maybe", fieldType: FieldTypeBoolean, wantErr: true},
+		{name: "iso date", raw: "2024-03-04", fieldType: FieldTypeDate, want: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "ambiguous date defaults to month first", raw: "03/04/2024", fieldType: FieldTypeDate, want: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "ambiguous date with day-first format", raw: "03/04/2024", fieldType: FieldTypeDate, options: euDates, want: time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "long date", raw: "Jan 5, 2024", fieldType: FieldTypeDate, want: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid date", raw: "13/45/2024", fieldType: FieldTypeDate, wantErr: true},
+		{name: "datetime", raw: "2024-03-04 10:30", fieldType: FieldTypeDateTime, want: time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{name: "select case-insensitive", raw: "todo", fieldType: FieldTypeSelect, options: selectOptions, want: "Todo"},
+		{name: "select object choice", raw: "Done", fieldType: FieldTypeSelect, options: selectOptions, want: "Done"},
+		{name: "invalid select", raw: "Blocked", fieldType: FieldTypeSelect, options: selectOptions, wantErr: true},
+		{name: "select without choices", raw: "Todo", fieldType: FieldTypeSelect, wantErr: true},
+		{name: "multiselect", raw: "todo, Done", fieldType: FieldTypeMultiSelect, options: selectOptions, want: []string{"Todo", "Done"}},
+		{name: "invalid multiselect", raw: "Todo, Blocked", fieldType: FieldTypeMultiSelect, options: selectOptions, wantErr: true},
+		{name: "attachment list", raw: "a.png, b.png", fieldType: FieldTypeAttachment, want: []string{"a.png", "b.png"}},
+		{name: "computed field", raw: "1", fieldType: FieldTypeFormula, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceValue(tt.raw, tt.fieldType, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CoerceValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var coercionErr *CoercionError
+				if !errors.As(err, &coercionErr) {
+					t.Errorf("expected *CoercionError, got %T", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CoerceValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,16 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
-	"errors"
+	stderrors "errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cursor"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"gorm.io/gorm/clause"
 )
 
 // JSON represents a JSON field type for GORM
@@ -33,7 +41,7 @@ func (j *JSON) Scan(value interface{}) error {
 	case string:
 		bytes = []byte(v)
 	default:
-		return errors.New("cannot scan non-string value into JSON")
+		return stderrors.New("cannot scan non-string value into JSON")
 	}
 	
 	return json.Unmarshal(bytes, j)
@@ -42,7 +50,7 @@ func (j *JSON) Scan(value interface{}) error {
 // PaginationRequest represents a pagination request
 type PaginationRequest struct {
 	Page     int    `json:"page" form:"page" binding:"min=1"`
-	PageSize int    `json:"page_size" form:"page_size" binding:"min=1,max=100"`
+	PageSize int    `json:"page_size" form:"page_size" binding:"min=1,max=100"` // Binding caps at DefaultMaxPageSize; Validate applies SetPaginationLimits
 	Sort     string `json:"sort" form:"sort"`
 	Order    string `json:"order" form:"order" binding:"oneof=asc desc"`
 	Search   string `json:"search" form:"search"`
@@ -57,13 +65,15 @@ func (p *PaginationRequest) GetOffset() int {
 	return (p.Page - 1) * p.GetPageSize()
 }
 
-// GetPageSize returns the page size with default
+// GetPageSize returns the page size with the configured default and maximum
+// (see SetPaginationLimits); use Validate to reject oversized requests
 func (p *PaginationRequest) GetPageSize() int {
+	limits := GetPaginationLimits()
 	if p.PageSize <= 0 {
-		return 20 // Default page size
+		return limits.DefaultPageSize
 	}
-	if p.PageSize > 100 {
-		return 100 // Max page size
+	if limits.MaxPageSize > 0 && p.PageSize > limits.MaxPageSize {
+		return limits.MaxPageSize
 	}
 	return p.PageSize
 }
@@ -122,6 +132,198 @@ func NewPaginationResponse(data interface{}, req *PaginationRequest, total int64
 	}
 }
 
+// Cursor pagination directions
+const (
+	CursorDirectionNext = "next"
+	CursorDirectionPrev = "prev"
+)
+
+// CursorPaginationRequest represents a keyset pagination request. Unlike
+// PaginationRequest it stays fast and stable on large tables, because each page
+// continues from the sort key and ID of the last row seen instead of an offset.
+type CursorPaginationRequest struct {
+	Cursor    string `json:"cursor" form:"cursor"`
+	Limit     int    `json:"limit" form:"limit" binding:"omitempty,min=1"`
+	Direction string `json:"direction" form:"direction" binding:"omitempty,oneof=next prev"`
+	Sort      string `json:"sort" form:"sort"`
+	Order     string `json:"order" form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// Validate rejects a limit above the configured maximum with a 400 invalid input
+// error, unless the limits are in clamp mode (see PaginationRequest.Validate)
+func (p *CursorPaginationRequest) Validate() error {
+	limits := GetPaginationLimits()
+
+	if p.Limit < 0 {
+		return errors.NewInvalidInputError("limit", "must be positive")
+	}
+	if limits.MaxPageSize > 0 && p.Limit > limits.MaxPageSize && !limits.Clamp {
+		return errors.NewInvalidInputError("limit", fmt.Sprintf("must be at most %d", limits.MaxPageSize))
+	}
+	return nil
+}
+
+// GetLimit returns the page size with the configured default and maximum; use
+// Validate to reject oversized requests
+func (p *CursorPaginationRequest) GetLimit() int {
+	page := PaginationRequest{PageSize: p.Limit}
+	return page.GetPageSize()
+}
+
+// GetDirection returns the direction with default
+func (p *CursorPaginationRequest) GetDirection() string {
+	if p.Direction == "" {
+		return CursorDirectionNext
+	}
+	return p.Direction
+}
+
+// GetSort returns the sort field with default
+func (p *CursorPaginationRequest) GetSort() string {
+	if p.Sort == "" {
+		return "created_at"
+	}
+	return p.Sort
+}
+
+// GetOrder returns the sort order with default
+func (p *CursorPaginationRequest) GetOrder() string {
+	if p.Order == "" {
+		return "desc"
+	}
+	return p.Order
+}
+
+// CursorPaginationResponse represents a keyset-paginated response. A cursor is
+// empty when there is no page in that direction.
+type CursorPaginationResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+	Limit      int         `json:"limit"`
+}
+
+// NewCursorPaginationResponse creates a new keyset-paginated response
+func NewCursorPaginationResponse(data interface{}, req *CursorPaginationRequest, nextCursor, prevCursor string) *CursorPaginationResponse {
+	return &CursorPaginationResponse{
+		Data:       data,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Limit:      req.GetLimit(),
+	}
+}
+
+// KeysetCursor is a position in a keyset-paginated list: the sort key and ID of a row
+type KeysetCursor struct {
+	SortValue interface{}
+	ID        interface{}
+}
+
+// keysetTimeType marks a cursor whose sort value is a time
+const keysetTimeType = "time"
+
+// EncodeCursor encodes the sort key and ID of a row as an opaque cursor with the
+// cursor package, which signs it when a key is set with cursor.SetKey
+func EncodeCursor(sortValue, id interface{}) string {
+	fields := map[string]interface{}{"s": sortValue, "id": id}
+	if t, ok := sortValue.(time.Time); ok {
+		fields["s"] = t.UTC().Format(time.RFC3339Nano)
+		fields["st"] = keysetTimeType
+	}
+	return cursor.Encode(fields)
+}
+
+// DecodeCursor decodes a cursor made by EncodeCursor. Integer values come back as
+// int64 and times as time.Time; invalid cursors return an INVALID_INPUT error.
+func DecodeCursor(encoded string) (*KeysetCursor, error) {
+	fields, err := cursor.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := fields["id"]
+	if !ok || id == nil {
+		return nil, errors.NewInvalidInputError("cursor", "malformed cursor payload")
+	}
+
+	sortValue := fields["s"]
+	if fields["st"] == keysetTimeType {
+		raw, _ := sortValue.(string)
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, errors.NewInvalidInputError("cursor", "malformed cursor payload")
+		}
+		sortValue = t
+	}
+
+	return &KeysetCursor{SortValue: keysetValue(sortValue), ID: keysetValue(id)}, nil
+}
+
+// keysetValue converts decoded JSON numbers to int64 where exact, else float64
+func keysetValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return value
+}
+
+// columnNamePattern matches a column, optionally qualified by its table, that is
+// safe to interpolate into SQL
+var columnNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// KeysetWhere builds the WHERE clause selecting the rows after c (direction next)
+// or before it (direction prev) when sorting by sortColumn in order, using id as
+// the tie-breaker. Pass the result to db.Where(query, args...) together with
+// KeysetOrder. sortColumn usually comes from the request, so it must be one of
+// allowedColumns as well as a plain column name.
+func KeysetWhere(sortColumn, order, direction string, c *KeysetCursor, allowedColumns []string) (string, []interface{}, error) {
+	if err := checkKeysetColumn(sortColumn, allowedColumns); err != nil {
+		return "", nil, err
+	}
+
+	op := ">"
+	if (strings.EqualFold(order, "desc")) != (direction == CursorDirectionPrev) {
+		op = "<"
+	}
+
+	query := fmt.Sprintf("(%[1]s %[2]s ?) OR (%[1]s = ? AND id %[2]s ?)", sortColumn, op)
+	return query, []interface{}{c.SortValue, c.SortValue, c.ID}, nil
+}
+
+// KeysetOrder returns the ORDER BY for a keyset page, sorting by sortColumn, which
+// must be one of allowedColumns, then id. Pages in direction prev are read in
+// reverse order, so callers reverse those rows before responding.
+func KeysetOrder(sortColumn, order, direction string, allowedColumns []string) (clause.OrderBy, error) {
+	if err := checkKeysetColumn(sortColumn, allowedColumns); err != nil {
+		return clause.OrderBy{}, err
+	}
+
+	desc := (strings.EqualFold(order, "desc")) != (direction == CursorDirectionPrev)
+	return clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: sortColumn}, Desc: desc},
+		{Column: clause.Column{Name: "id"}, Desc: desc},
+	}}, nil
+}
+
+// checkKeysetColumn rejects sort columns that aren't allowed or aren't plain
+// column names
+func checkKeysetColumn(sortColumn string, allowedColumns []string) error {
+	if !containsField(allowedColumns, sortColumn) {
+		return errors.NewInvalidInputError("sort", fmt.Sprintf("cannot sort by %q", sortColumn))
+	}
+	if !columnNamePattern.MatchString(sortColumn) {
+		return errors.NewInvalidInputError("sort", "invalid sort field")
+	}
+	return nil
+}
+
 // SortRequest represents a sort request
 type SortRequest struct {
 	Field string `json:"field" binding:"required"`
@@ -146,13 +348,16 @@ func ValidOperators() []string {
 	}
 }
 
+// Timestamp is the timestamp of response envelopes; see utils.SetTimestampFormat
+type Timestamp = utils.Timestamp
+
 // APIResponse represents a standard API response
 type APIResponse struct {
 	Success   bool        `json:"success"`
 	Data      interface{} `json:"data,omitempty"`
 	Error     *APIError   `json:"error,omitempty"`
 	Meta      *APIMeta    `json:"meta,omitempty"`
-	Timestamp string      `json:"timestamp"`
+	Timestamp Timestamp   `json:"timestamp"`
 }
 
 // APIError represents an API error response
@@ -169,6 +374,14 @@ type APIMeta struct {
 	Pagination  *Pagination `json:"pagination,omitempty"`
 	RateLimit   *RateLimit  `json:"rate_limit,omitempty"`
 	Performance *Performance `json:"performance,omitempty"`
+	Warnings    []Warning    `json:"warnings,omitempty"`
+}
+
+// Warning names a degraded source whose data is missing from a partial response
+type Warning struct {
+	Source  string `json:"source"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // RateLimit represents rate limit information
@@ -191,7 +404,7 @@ func NewSuccessResponse(data interface{}) *APIResponse {
 	return &APIResponse{
 		Success:   true,
 		Data:      data,
-		Timestamp: fmt.Sprintf("%d", UnixNow()),
+		Timestamp: utils.TimestampNow(),
 	}
 }
 
@@ -204,7 +417,7 @@ func NewErrorResponse(code, message string, details map[string]interface{}) *API
 			Message: message,
 			Details: details,
 		},
-		Timestamp: fmt.Sprintf("%d", UnixNow()),
+		Timestamp: utils.TimestampNow(),
 	}
 }
 
@@ -214,7 +427,7 @@ func NewPaginatedResponse(data interface{}, pagination *Pagination) *APIResponse
 		Success:   true,
 		Data:      data,
 		Meta:      &APIMeta{Pagination: pagination},
-		Timestamp: fmt.Sprintf("%d", UnixNow()),
+		Timestamp: utils.TimestampNow(),
 	}
 }
 
@@ -239,6 +452,8 @@ type BulkResult struct {
 	ID      interface{} `json:"id,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`   // Error code of a failed item
+	Status  int         `json:"status,omitempty"` // HTTP status of a failed item
 }
 
 // SearchRequest represents a search request
@@ -294,6 +509,7 @@ type ImportSummary struct {
 	Created int `json:"created"`
 	Updated int `json:"updated"`
 	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
 }
 
 // NullString represents a nullable string
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+type keysetItem struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+
+	tests := []struct {
+		name      string
+		sortValue interface{}
+		id        interface{}
+		wantSort  interface{}
+		wantID    interface{}
+	}{
+		{name: "time sort key", sortValue: created, id: uint(42), wantSort: created, wantID: int64(42)},
+		{name: "string sort key and ULID", sortValue: "Ada", id: "01HQ3Z", wantSort: "Ada", wantID: "01HQ3Z"},
+		{name: "numeric sort key", sortValue: 9.5, id: 7, wantSort: 9.5, wantID: int64(7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := DecodeCursor(EncodeCursor(tt.sortValue, tt.id))
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if c.SortValue != tt.wantSort || c.ID != tt.wantID {
+				t.Errorf("DecodeCursor() = %#v, want %#v / %#v", c, tt.wantSort, tt.wantID)
+			}
+		})
+	}
+
+	if _, err := DecodeCursor("garbage"); err == nil {
+		t.Error("expected an invalid cursor to be rejected")
+	}
+}
+
+// keysetColumns are the keysetItem columns the tests may sort by
+var keysetColumns = []string{"created_at", "id"}
+
+func TestKeysetWhere_RejectsUnsafeColumns(t *testing.T) {
+	for _, column := range []string{"created_at; DROP TABLE users", "name)", "", "password_hash"} {
+		if _, _, err := KeysetWhere(column, "asc", CursorDirectionNext, &KeysetCursor{}, keysetColumns); err == nil {
+			t.Errorf("KeysetWhere(%q) expected an error", column)
+		}
+		if _, err := KeysetOrder(column, "asc", CursorDirectionNext, keysetColumns); err == nil {
+			t.Errorf("KeysetOrder(%q) expected an error", column)
+		}
+	}
+
+	// The column name check still applies to columns the caller allowed
+	unsafe := "created_at DESC; DROP TABLE users"
+	if _, err := KeysetOrder(unsafe, "asc", CursorDirectionNext, []string{unsafe}); err == nil {
+		t.Errorf("KeysetOrder(%q) expected an error", unsafe)
+	}
+}
+
+func TestCursorPaginationRequest_Validate(t *testing.T) {
+	if err := (&CursorPaginationRequest{Limit: DefaultMaxPageSize}).Validate(); err != nil {
+		t.Errorf("Validate() at the maximum error = %v", err)
+	}
+	if err := (&CursorPaginationRequest{Limit: DefaultMaxPageSize + 1}).Validate(); err == nil {
+		t.Error("expected an oversized limit to be rejected")
+	}
+}
+
+func TestKeysetPagination(t *testing.T) {
+	db := openTestDB(t, &keysetItem{})
+
+	// Items 2-4 share a timestamp, so the ID has to break ties
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, offset := range []int{0, 1, 1, 1, 2} {
+		item := keysetItem{ID: uint(i + 1), CreatedAt: base.Add(time.Duration(offset) * time.Hour)}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	page := func(req CursorPaginationRequest) []uint {
+		t.Helper()
+
+		order, err := KeysetOrder(req.GetSort(), req.GetOrder(), req.GetDirection(), keysetColumns)
+		if err != nil {
+			t.Fatalf("KeysetOrder() error = %v", err)
+		}
+		query := db.Model(&keysetItem{}).Order(order).Limit(req.GetLimit())
+		if req.Cursor != "" {
+			c, err := DecodeCursor(req.Cursor)
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			where, args, err := KeysetWhere(req.GetSort(), req.GetOrder(), req.GetDirection(), c, keysetColumns)
+			if err != nil {
+				t.Fatalf("KeysetWhere() error = %v", err)
+			}
+			query = query.Where(where, args...)
+		}
+
+		var items []keysetItem
+		if err := query.Find(&items).Error; err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		ids := make([]uint, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+		return ids
+	}
+	cursorAfter := func(id uint) string {
+		return EncodeCursor(base.Add(map[uint]time.Duration{1: 0, 2: time.Hour, 3: time.Hour, 4: time.Hour, 5: 2 * time.Hour}[id]), id)
+	}
+
+	tests := []struct {
+		name string
+		req  CursorPaginationRequest
+		want []uint
+	}{
+		{name: "first page desc", req: CursorPaginationRequest{Limit: 2}, want: []uint{5, 4}},
+		{name: "next page desc", req: CursorPaginationRequest{Limit: 2, Cursor: cursorAfter(4)}, want: []uint{3, 2}},
+		{name: "last page desc", req: CursorPaginationRequest{Limit: 2, Cursor: cursorAfter(2)}, want: []uint{1}},
+		{name: "previous page desc", req: CursorPaginationRequest{Limit: 2, Cursor: cursorAfter(3), Direction: CursorDirectionPrev}, want: []uint{4, 5}},
+		{name: "next page asc", req: CursorPaginationRequest{Limit: 3, Cursor: cursorAfter(2), Order: "asc"}, want: []uint{3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := page(tt.req)
+			if len(got) != len(tt.want) {
+				t.Fatalf("page = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("page = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// EncryptionKeyProvider supplies the AES keys used by EncryptedString. Keys are
+// identified by an ID stored with each value, so old values stay readable after
+// the current key is rotated.
+type EncryptionKeyProvider interface {
+	// CurrentKey returns the key new values are encrypted with
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns the key with id
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyProvider is an EncryptionKeyProvider over a fixed set of keys, e.g.
+// loaded from configuration
+type StaticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewStaticKeyProvider creates a provider encrypting with keys[currentID]. Keys
+// must be 16, 24 or 32 bytes and IDs must not contain ':'.
+func NewStaticKeyProvider(currentID string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the key set", currentID)
+	}
+
+	copied := make(map[string][]byte, len(keys))
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, encryptedSeparator) {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("key %q must be 16, 24 or 32 bytes, got %d", id, len(key))
+		}
+		copied[id] = append([]byte(nil), key...)
+	}
+
+	return &StaticKeyProvider{currentID: currentID, keys: copied}, nil
+}
+
+// CurrentKey implements EncryptionKeyProvider
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	return p.currentID, p.keys[p.currentID], nil
+}
+
+// Key implements EncryptionKeyProvider
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", id)
+	}
+	return key, nil
+}
+
+var (
+	keyProviderMu sync.RWMutex
+	keyProvider   EncryptionKeyProvider
+)
+
+// SetEncryptionKeyProvider sets the provider used by all EncryptedString values.
+// Call it at startup before any encrypted column is read or written.
+func SetEncryptionKeyProvider(provider EncryptionKeyProvider) {
+	keyProviderMu.Lock()
+	defer keyProviderMu.Unlock()
+	keyProvider = provider
+}
+
+// ErrNoEncryptionKeyProvider is returned when EncryptedString is used before
+// SetEncryptionKeyProvider
+var ErrNoEncryptionKeyProvider = errors.New("no encryption key provider configured")
+
+func currentKeyProvider() (EncryptionKeyProvider, error) {
+	keyProviderMu.RLock()
+	defer keyProviderMu.RUnlock()
+	if keyProvider == nil {
+		return nil, ErrNoEncryptionKeyProvider
+	}
+	return keyProvider, nil
+}
+
+// encryptedSeparator separates the key ID from the ciphertext in stored values
+const encryptedSeparator = ":"
+
+// EncryptedString is a string column encrypted at rest with AES-GCM. It is stored
+// as "<key id>:<base64 ciphertext>" and always written with the provider's current
+// key, so saving a record re-encrypts it after a key rotation. Empty strings are
+// stored as is.
+type EncryptedString string
+
+// GormDataType stores encrypted values as text
+func (EncryptedString) GormDataType() string {
+	return "text"
+}
+
+// Value implements the driver.Valuer interface for EncryptedString
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	provider, err := currentKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	id, key, err := provider.CurrentKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	ciphertext, err := utils.EncryptAESGCM(key, []byte(s))
+	if err != nil {
+		return nil, err
+	}
+	return id + encryptedSeparator + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Scan implements the sql.Scanner interface for EncryptedString
+func (s *EncryptedString) Scan(value interface{}) error {
+	var stored string
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return errors.New("cannot scan non-string value into EncryptedString")
+	}
+
+	if stored == "" {
+		*s = ""
+		return nil
+	}
+
+	id, encoded, ok := strings.Cut(stored, encryptedSeparator)
+	if !ok {
+		return errors.New("encrypted value is missing its key ID")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	provider, err := currentKeyProvider()
+	if err != nil {
+		return err
+	}
+	key, err := provider.Key(id)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := utils.DecryptAESGCM(key, ciphertext)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReservedFieldNames are record attributes a field may not shadow
+var ReservedFieldNames = []string{"id", "created_at", "updated_at", "deleted_at", "table_id"}
+
+// ValidateTableFields checks a table's fields before they are saved: names must be
+// present, unique ignoring case and not reserved, positions must be unique and
+// non-negative, and select fields need a non-empty list of distinct choices. All
+// problems are returned together as ValidationErrors keyed by fields[i].
+func ValidateTableFields(fields []Field) error {
+	var errs ValidationErrors
+	names := make(map[string]int, len(fields))
+	positions := make(map[int]int, len(fields))
+
+	for i, field := range fields {
+		prefix := fmt.Sprintf("fields[%d]", i)
+
+		name := strings.TrimSpace(field.Name)
+		key := strings.ToLower(name)
+		switch {
+		case name == "":
+			errs.Add(prefix+".name", "is required")
+		case isReservedFieldName(key):
+			errs.Add(prefix+".name", fmt.Sprintf("%q is reserved", name))
+		default:
+			if first, ok := names[key]; ok {
+				errs.Add(prefix+".name", fmt.Sprintf("duplicates the name of fields[%d]", first))
+			} else {
+				names[key] = i
+			}
+		}
+
+		if field.Position < 0 {
+			errs.Add(prefix+".position", "must not be negative")
+		} else if first, ok := positions[field.Position]; ok {
+			errs.Add(prefix+".position", fmt.Sprintf("duplicates the position of fields[%d]", first))
+		} else {
+			positions[field.Position] = i
+		}
+
+		if field.Type == FieldTypeSelect || field.Type == FieldTypeMultiSelect {
+			if err := validateSelectOptions(field.Options); err != nil {
+				errs.Add(prefix+".options", err.Error())
+			}
+		}
+	}
+
+	return errs.ErrOrNil()
+}
+
+func isReservedFieldName(name string) bool {
+	for _, reserved := range ReservedFieldNames {
+		if name == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSelectOptions checks the choices of a select or multiselect field
+func validateSelectOptions(options JSON) error {
+	choices, err := selectChoices(options)
+	if err != nil {
+		return err
+	}
+	if len(choices) == 0 {
+		return fmt.Errorf("at least one choice is required")
+	}
+
+	seen := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		key := strings.ToLower(strings.TrimSpace(choice))
+		if key == "" {
+			return fmt.Errorf("choices must not be empty")
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate choice %q", choice)
+		}
+		seen[key] = true
+	}
+	return nil
+}
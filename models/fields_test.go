@@ -0,0 +1,85 @@
+package models
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+func TestValidateTableFields(t *testing.T) {
+	statusOptions := JSON{"choices": []interface{}{"Todo", map[string]interface{}{"name": "Done", "color": "green"}}}
+
+	tests := []struct {
+		name       string
+		fields     []Field
+		wantFields []string
+	}{
+		{
+			name: "valid schema",
+			fields: []Field{
+				{Name: "Name", Type: FieldTypeText, Position: 0},
+				{Name: "Status", Type: FieldTypeSelect, Position: 1, Options: statusOptions},
+				{Name: "Tags", Type: FieldTypeMultiSelect, Position: 2, Options: JSON{"choices": []interface{}{"a", "b"}}},
+			},
+		},
+		{
+			name: "duplicate names ignoring case",
+			fields: []Field{
+				{Name: "Name", Type: FieldTypeText, Position: 0},
+				{Name: " name ", Type: FieldTypeText, Position: 1},
+			},
+			wantFields: []string{"fields[1].name"},
+		},
+		{
+			name: "duplicate positions",
+			fields: []Field{
+				{Name: "Name", Type: FieldTypeText, Position: 1},
+				{Name: "Notes", Type: FieldTypeText, Position: 1},
+			},
+			wantFields: []string{"fields[1].position"},
+		},
+		{
+			name: "missing and reserved names",
+			fields: []Field{
+				{Name: "", Type: FieldTypeText, Position: 0},
+				{Name: "ID", Type: FieldTypeText, Position: 1},
+			},
+			wantFields: []string{"fields[0].name", "fields[1].name"},
+		},
+		{
+			name: "invalid select options",
+			fields: []Field{
+				{Name: "Status", Type: FieldTypeSelect, Position: 0},
+				{Name: "Priority", Type: FieldTypeSelect, Position: 1, Options: JSON{"choices": []interface{}{}}},
+				{Name: "Tags", Type: FieldTypeMultiSelect, Position: 2, Options: JSON{"choices": []interface{}{"a", "A"}}},
+				{Name: "Stage", Type: FieldTypeSelect, Position: 3, Options: JSON{"choices": "a,b"}},
+			},
+			wantFields: []string{"fields[0].options", "fields[1].options", "fields[2].options", "fields[3].options"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTableFields(tt.fields)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateTableFields() error = %v", err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !stderrors.As(err, &errs) {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+			fields := errs.Fields()
+			if len(fields) != len(tt.wantFields) {
+				t.Errorf("failing fields = %v, want %v", fields, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if _, ok := fields[field]; !ok {
+					t.Errorf("expected an error for %s, got %v", field, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"gorm.io/gorm"
+)
+
+// likeEscaper escapes LIKE wildcards in values matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ApplyFilters adds a WHERE condition to db for each filter. Field names must be
+// in allowedColumns, since filters usually come from the request and any column
+// would otherwise be open to probing, and must also be plain column names. Values
+// are always bound as parameters. like and not_like take a pattern with the
+// caller's own wildcards, while contains, starts_with and ends_with match the
+// value literally. in and not_in take a non-empty list and between a list of two
+// values; is_null and is_not_null ignore the value. Disallowed fields, unknown
+// operators, unsafe field names and values of the wrong shape are returned
+// together as ValidationErrors keyed by filters[i].
+func ApplyFilters(db *gorm.DB, filters []FilterRequest, allowedColumns []string) (*gorm.DB, error) {
+	var errs ValidationErrors
+
+	for i, filter := range filters {
+		prefix := fmt.Sprintf("filters[%d]", i)
+		if !containsField(allowedColumns, filter.Field) {
+			errs.Add(prefix+".field", fmt.Sprintf("cannot filter by %q", filter.Field))
+			continue
+		}
+		if !columnNamePattern.MatchString(filter.Field) {
+			errs.Add(prefix+".field", "must be a column name")
+			continue
+		}
+
+		query, args, err := filterCondition(filter)
+		if err != nil {
+			errs.Add(prefix+"."+err.field, err.message)
+			continue
+		}
+		db = db.Where(query, args...)
+	}
+
+	if err := errs.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// ApplySearch adds a condition matching rows where any of columns contains search,
+// ignoring case. The search is matched literally and columns must be plain column
+// names. An empty search leaves db unchanged.
+func ApplySearch(db *gorm.DB, search string, columns []string) (*gorm.DB, error) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return db, nil
+	}
+	if len(columns) == 0 {
+		return nil, errors.NewInvalidInputError("search", "search is not supported here")
+	}
+
+	conditions := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	pattern := "%" + likeEscaper.Replace(strings.ToLower(search)) + "%"
+	for i, column := range columns {
+		if !columnNamePattern.MatchString(column) {
+			return nil, fmt.Errorf("invalid search column %q", column)
+		}
+		conditions[i] = "LOWER(" + column + `) LIKE ? ESCAPE '\'`
+		args[i] = pattern
+	}
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...), nil
+}
+
+// FilterRequests converts the Filters of a PaginationRequest, sorted by field. A
+// value may be an object with "operator" and "value", e.g. {"priority":
+// {"operator": "gt", "value": 2}}; a list means in and anything else eq.
+func (p *PaginationRequest) FilterRequests() []FilterRequest {
+	fields := make([]string, 0, len(p.Filters))
+	for field := range p.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	filters := make([]FilterRequest, 0, len(fields))
+	for _, field := range fields {
+		value := p.Filters[field]
+		if spec, ok := asJSONObject(value); ok {
+			if operator, ok := spec.GetString("operator"); ok {
+				filters = append(filters, FilterRequest{Field: field, Operator: operator, Value: spec["value"]})
+				continue
+			}
+		}
+		operator := "eq"
+		if _, ok := listValue(value); ok {
+			operator = "in"
+		}
+		filters = append(filters, FilterRequest{Field: field, Operator: operator, Value: value})
+	}
+	return filters
+}
+
+// filterError is a problem with one part of a filter
+type filterError struct {
+	field   string
+	message string
+}
+
+func invalidFilterValue(message string) *filterError {
+	return &filterError{field: "value", message: message}
+}
+
+// filterCondition translates a filter into a parameterized condition
+func filterCondition(f FilterRequest) (string, []interface{}, *filterError) {
+	column := f.Field
+
+	switch f.Operator {
+	case "eq", "ne":
+		if !isScalarFilterValue(f.Value) {
+			return "", nil, invalidFilterValue("must be a single value")
+		}
+		if f.Operator == "eq" {
+			return column + " = ?", []interface{}{f.Value}, nil
+		}
+		return column + " <> ?", []interface{}{f.Value}, nil
+
+	case "gt", "gte", "lt", "lte":
+		if !isComparableFilterValue(f.Value) {
+			return "", nil, invalidFilterValue("must be a number, string or time")
+		}
+		op := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[f.Operator]
+		return fmt.Sprintf("%s %s ?", column, op), []interface{}{f.Value}, nil
+
+	case "in", "not_in":
+		values, ok := filterValueList(f.Value)
+		if !ok || len(values) == 0 {
+			return "", nil, invalidFilterValue("must be a non-empty list of values")
+		}
+		if f.Operator == "in" {
+			return column + " IN ?", []interface{}{values}, nil
+		}
+		return column + " NOT IN ?", []interface{}{values}, nil
+
+	case "between":
+		values, ok := filterValueList(f.Value)
+		if !ok || len(values) != 2 || !isComparableFilterValue(values[0]) || !isComparableFilterValue(values[1]) {
+			return "", nil, invalidFilterValue("must be a list of two numbers, strings or times")
+		}
+		return column + " BETWEEN ? AND ?", values, nil
+
+	case "like", "not_like":
+		pattern, ok := f.Value.(string)
+		if !ok {
+			return "", nil, invalidFilterValue("must be a string")
+		}
+		if f.Operator == "like" {
+			return column + " LIKE ?", []interface{}{pattern}, nil
+		}
+		return column + " NOT LIKE ?", []interface{}{pattern}, nil
+
+	case "contains", "not_contains", "starts_with", "ends_with":
+		value, ok := f.Value.(string)
+		if !ok {
+			return "", nil, invalidFilterValue("must be a string")
+		}
+		escaped := likeEscaper.Replace(value)
+		switch f.Operator {
+		case "starts_with":
+			escaped += "%"
+		case "ends_with":
+			escaped = "%" + escaped
+		default:
+			escaped = "%" + escaped + "%"
+		}
+		if f.Operator == "not_contains" {
+			return column + ` NOT LIKE ? ESCAPE '\'`, []interface{}{escaped}, nil
+		}
+		return column + ` LIKE ? ESCAPE '\'`, []interface{}{escaped}, nil
+
+	case "is_null":
+		return column + " IS NULL", nil, nil
+	case "is_not_null":
+		return column + " IS NOT NULL", nil, nil
+	}
+
+	return "", nil, &filterError{field: "operator", message: fmt.Sprintf("unknown operator %q", f.Operator)}
+}
+
+// filterValueList returns the elements of a list value
+func filterValueList(value interface{}) ([]interface{}, bool) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil, false
+	}
+
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+		if !isScalarFilterValue(values[i]) {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// isScalarFilterValue reports whether value is a single bool, number, string or time
+func isScalarFilterValue(value interface{}) bool {
+	if _, ok := value.(time.Time); ok {
+		return true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isComparableFilterValue reports whether value can be ordered: any scalar but a bool
+func isComparableFilterValue(value interface{}) bool {
+	return isScalarFilterValue(value) && reflect.ValueOf(value).Kind() != reflect.Bool
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	stderrors "errors"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type filterItem struct {
+	ID       uint `gorm:"primarykey"`
+	Name     string
+	Priority int
+	Archived *bool
+}
+
+func newFilterTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := openTestDB(t, &filterItem{})
+
+	archived := true
+	items := []filterItem{
+		{ID: 1, Name: "Launch plan", Priority: 1},
+		{ID: 2, Name: "100% done", Priority: 2, Archived: &archived},
+		{ID: 3, Name: "Budget", Priority: 3},
+		{ID: 4, Name: "launch_review", Priority: 5},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	return db
+}
+
+// filterColumns are the filterItem columns the tests may filter by
+var filterColumns = []string{"id", "name", "priority", "archived"}
+
+func TestApplyFilters(t *testing.T) {
+	db := newFilterTestDB(t)
+
+	tests := []struct {
+		name    string
+		filters []FilterRequest
+		want    []uint
+	}{
+		{name: "no filters", want: []uint{1, 2, 3, 4}},
+		{name: "eq", filters: []FilterRequest{{Field: "name", Operator: "eq", Value: "Budget"}}, want: []uint{3}},
+		{name: "ne", filters: []FilterRequest{{Field: "priority", Operator: "ne", Value: float64(1)}}, want: []uint{2, 3, 4}},
+		{name: "gt and lte", filters: []FilterRequest{
+			{Field: "priority", Operator: "gt", Value: float64(1)},
+			{Field: "priority", Operator: "lte", Value: float64(3)},
+		}, want: []uint{2, 3}},
+		{name: "in", filters: []FilterRequest{{Field: "id", Operator: "in", Value: []interface{}{float64(1), float64(4)}}}, want: []uint{1, 4}},
+		{name: "not_in", filters: []FilterRequest{{Field: "id", Operator: "not_in", Value: []int{1, 4}}}, want: []uint{2, 3}},
+		{name: "between", filters: []FilterRequest{{Field: "priority", Operator: "between", Value: []interface{}{float64(2), float64(4)}}}, want: []uint{2, 3}},
+		{name: "like", filters: []FilterRequest{{Field: "name", Operator: "like", Value: "%plan"}}, want: []uint{1}},
+		{name: "contains escapes wildcards", filters: []FilterRequest{{Field: "name", Operator: "contains", Value: "0%"}}, want: []uint{2}},
+		{name: "starts_with escapes underscore", filters: []FilterRequest{{Field: "name", Operator: "starts_with", Value: "launch_"}}, want: []uint{4}},
+		{name: "ends_with", filters: []FilterRequest{{Field: "name", Operator: "ends_with", Value: "get"}}, want: []uint{3}},
+		{name: "not_contains", filters: []FilterRequest{{Field: "name", Operator: "not_contains", Value: "launch"}}, want: []uint{2, 3}},
+		{name: "is_null", filters: []FilterRequest{{Field: "archived", Operator: "is_null"}}, want: []uint{1, 3, 4}},
+		{name: "is_not_null", filters: []FilterRequest{{Field: "archived", Operator: "is_not_null"}}, want: []uint{2}},
+		{name: "injection attempt is a bound value", filters: []FilterRequest{{Field: "name", Operator: "eq", Value: "x' OR '1'='1"}}, want: []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ApplyFilters(db.Model(&filterItem{}), tt.filters, filterColumns)
+			if err != nil {
+				t.Fatalf("ApplyFilters() error = %v", err)
+			}
+
+			var ids []uint
+			if err := query.Pluck("id", &ids).Error; err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ids = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyFilters_Invalid(t *testing.T) {
+	db := newFilterTestDB(t)
+
+	filters := []FilterRequest{
+		{Field: "name", Operator: "regex", Value: ".*"},
+		{Field: "name; DROP TABLE filter_items", Operator: "eq", Value: "x"},
+		{Field: "priority", Operator: "gt", Value: true},
+		{Field: "id", Operator: "in", Value: []interface{}{}},
+		{Field: "priority", Operator: "between", Value: []interface{}{float64(1)}},
+		{Field: "name", Operator: "contains", Value: float64(3)},
+		{Field: "name", Operator: "eq", Value: map[string]interface{}{"a": 1}},
+		{Field: "password_hash", Operator: "starts_with", Value: "$2a$"},
+	}
+
+	_, err := ApplyFilters(db, filters, filterColumns)
+	var errs ValidationErrors
+	if !stderrors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	want := []string{
+		"filters[0].operator", "filters[1].field", "filters[2].value", "filters[3].value",
+		"filters[4].value", "filters[5].value", "filters[6].value", "filters[7].field",
+	}
+	fields := errs.Fields()
+	if len(fields) != len(want) {
+		t.Errorf("failing fields = %v, want %v", fields, want)
+	}
+	for _, field := range want {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("expected an error for %s, got %v", field, err)
+		}
+	}
+}
+
+func TestApplyFilters_UnsafeAllowedColumn(t *testing.T) {
+	db := newFilterTestDB(t)
+
+	// The column name check still applies to columns the caller allowed
+	unsafe := "name; DROP TABLE filter_items"
+	_, err := ApplyFilters(db, []FilterRequest{{Field: unsafe, Operator: "eq", Value: "x"}}, []string{unsafe})
+	var errs ValidationErrors
+	if !stderrors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	if _, ok := errs.Fields()["filters[0].field"]; !ok {
+		t.Errorf("errors = %v, want one for filters[0].field", errs)
+	}
+}
@@ -0,0 +1,643 @@
+package models
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FormulaError describes a formula that could not be parsed or evaluated
+type FormulaError struct {
+	Field  string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *FormulaError) Error() string {
+	return fmt.Sprintf("formula field %q: %s", e.Field, e.Reason)
+}
+
+// FormulaEngine evaluates formula fields against records. Formulas are read from
+// the field's Options["formula"] and parsed once, so an engine should be shared.
+//
+// Formulas support numbers, "text" or 'text' literals, TRUE and FALSE, field
+// references written as {Field Name}, the operators + - * / (arithmetic),
+// & (concatenation) and = != < > <= >= (comparison), parentheses, and the
+// functions CONCAT, IF, SUM, LEN, UPPER and LOWER. Blank values count as 0 in
+// arithmetic and as "" in text.
+//
+// Formulas longer than MaxFormulaLength or nested deeper than MaxFormulaDepth are
+// rejected, and only the MaxParsedFormulas most recently used syntax trees are kept.
+type FormulaEngine struct {
+	mu     sync.Mutex
+	order  *list.List // Most recently used first
+	parsed map[string]*list.Element
+}
+
+// Formula limits
+const (
+	MaxFormulaLength  = 4096 // Bytes
+	MaxFormulaDepth   = 64   // Nested parentheses, function calls and negations
+	MaxParsedFormulas = 1024 // Syntax trees cached per engine
+)
+
+// parsedFormula is a cached syntax tree
+type parsedFormula struct {
+	formula string
+	node    formulaNode
+}
+
+// NewFormulaEngine creates a formula engine
+func NewFormulaEngine() *FormulaEngine {
+	return &FormulaEngine{order: list.New(), parsed: make(map[string]*list.Element)}
+}
+
+// Evaluate computes field's formula for record. References are checked against
+// record.Table.Fields when they are loaded, and otherwise against record.Data;
+// a referenced field with no value is blank. The result is a float64, string,
+// bool or nil.
+func (e *FormulaEngine) Evaluate(field Field, record *Record) (interface{}, error) {
+	fail := func(format string, args ...interface{}) error {
+		return &FormulaError{Field: field.Name, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if field.Type != FieldTypeFormula {
+		return nil, fail("field type is %q, not formula", field.Type)
+	}
+	formula, ok := field.Options.GetString("formula")
+	if !ok || strings.TrimSpace(formula) == "" {
+		return nil, fail("no formula configured")
+	}
+
+	node, err := e.parse(formula)
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+
+	env := formulaEnv{record: record}
+	if record != nil && len(record.Table.Fields) > 0 {
+		env.fields = make(map[string]bool, len(record.Table.Fields))
+		for _, f := range record.Table.Fields {
+			env.fields[f.Name] = true
+		}
+	}
+
+	value, err := node.eval(env)
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+	return value, nil
+}
+
+// parse returns the cached syntax tree of formula, parsing it on first use and
+// evicting the least recently used tree when the cache is full
+func (e *FormulaEngine) parse(formula string) (formulaNode, error) {
+	e.mu.Lock()
+	if element, ok := e.parsed[formula]; ok {
+		e.order.MoveToFront(element)
+		e.mu.Unlock()
+		return element.Value.(*parsedFormula).node, nil
+	}
+	e.mu.Unlock()
+
+	if len(formula) > MaxFormulaLength {
+		return nil, fmt.Errorf("formula is longer than %d bytes", MaxFormulaLength)
+	}
+	p := &formulaParser{input: formula}
+	node, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.parsed[formula]; !ok {
+		e.parsed[formula] = e.order.PushFront(&parsedFormula{formula: formula, node: node})
+		if e.order.Len() > MaxParsedFormulas {
+			oldest := e.order.Back()
+			e.order.Remove(oldest)
+			delete(e.parsed, oldest.Value.(*parsedFormula).formula)
+		}
+	}
+	return node, nil
+}
+
+// formulaEnv resolves field references during evaluation
+type formulaEnv struct {
+	record *Record
+	fields map[string]bool
+}
+
+func (env formulaEnv) lookup(name string) (interface{}, error) {
+	var data JSON
+	if env.record != nil {
+		data = env.record.Data
+	}
+	value, ok := data[name]
+	if !ok && !env.fields[name] {
+		return nil, fmt.Errorf("unknown field %q", name)
+	}
+	if n, ok := jsonFloat(value); ok {
+		return n, nil
+	}
+	return value, nil
+}
+
+type formulaNode interface {
+	eval(env formulaEnv) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(formulaEnv) (interface{}, error) { return n.value, nil }
+
+type fieldNode struct{ name string }
+
+func (n fieldNode) eval(env formulaEnv) (interface{}, error) { return env.lookup(n.name) }
+
+type negateNode struct{ operand formulaNode }
+
+func (n negateNode) eval(env formulaEnv) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	number, err := formulaNumber(value, "-")
+	if err != nil {
+		return nil, err
+	}
+	return -number, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right formulaNode
+}
+
+func (n binaryNode) eval(env formulaEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&":
+		return formulaText(left) + formulaText(right), nil
+	case "=", "!=", "<", ">", "<=", ">=":
+		return formulaCompare(n.op, left, right)
+	}
+
+	a, err := formulaNumber(left, n.op)
+	if err != nil {
+		return nil, err
+	}
+	b, err := formulaNumber(right, n.op)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	default:
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	}
+}
+
+type callNode struct {
+	name string
+	args []formulaNode
+}
+
+func (n callNode) eval(env formulaEnv) (interface{}, error) {
+	// IF only evaluates the branch it returns
+	if n.name == "IF" {
+		cond, err := n.args[0].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case formulaTruthy(cond):
+			return n.args[1].eval(env)
+		case len(n.args) == 3:
+			return n.args[2].eval(env)
+		default:
+			return nil, nil
+		}
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	switch n.name {
+	case "CONCAT":
+		var b strings.Builder
+		for _, arg := range args {
+			b.WriteString(formulaText(arg))
+		}
+		return b.String(), nil
+	case "SUM":
+		var sum float64
+		for _, arg := range args {
+			number, err := formulaNumber(arg, "SUM")
+			if err != nil {
+				return nil, err
+			}
+			sum += number
+		}
+		return sum, nil
+	case "LEN":
+		return float64(utf8.RuneCountInString(formulaText(args[0]))), nil
+	case "UPPER":
+		return strings.ToUpper(formulaText(args[0])), nil
+	default: // LOWER
+		return strings.ToLower(formulaText(args[0])), nil
+	}
+}
+
+// formulaFunctions maps each function to its minimum and maximum argument count;
+// -1 means any number
+var formulaFunctions = map[string][2]int{
+	"CONCAT": {1, -1},
+	"IF":     {2, 3},
+	"SUM":    {1, -1},
+	"LEN":    {1, 1},
+	"UPPER":  {1, 1},
+	"LOWER":  {1, 1},
+}
+
+// formulaNumber converts an operand to a number; blanks and booleans count as 0/1
+func formulaNumber(value interface{}, op string) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("cannot use %s in %s, expected a number", formulaTypeName(value), op)
+	}
+}
+
+// formulaText converts a value to text for concatenation and text functions
+func formulaText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			if n, ok := jsonFloat(item); ok {
+				item = n
+			}
+			parts[i] = formulaText(item)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formulaTruthy reports whether a value counts as true in IF
+func formulaTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	default:
+		return true
+	}
+}
+
+// formulaCompare compares two numbers or two texts; a blank matches either type
+func formulaCompare(op string, left, right interface{}) (bool, error) {
+	var cmp int
+	_, leftText := left.(string)
+	_, rightText := right.(string)
+
+	if leftText || rightText {
+		_, leftNumber := left.(float64)
+		_, rightNumber := right.(float64)
+		if leftNumber || rightNumber {
+			return false, fmt.Errorf("cannot compare %s with %s", formulaTypeName(left), formulaTypeName(right))
+		}
+		cmp = strings.Compare(formulaText(left), formulaText(right))
+	} else {
+		a, err := formulaNumber(left, op)
+		if err != nil {
+			return false, err
+		}
+		b, err := formulaNumber(right, op)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func formulaTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "text"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "a list"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// formulaParser is a recursive descent parser over the formula grammar:
+//
+//	comparison = concat [("=" | "!=" | "<>" | "<" | ">" | "<=" | ">=") concat]
+//	concat     = additive {"&" additive}
+//	additive   = term {("+" | "-") term}
+//	term       = unary {("*" | "/") unary}
+//	unary      = "-" unary | primary
+//	primary    = number | string | "{" name "}" | TRUE | FALSE | name "(" args ")" | "(" comparison ")"
+type formulaParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+func (p *formulaParser) parse() (formulaNode, error) {
+	node, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	return node, nil
+}
+
+func (p *formulaParser) comparison() (formulaNode, error) {
+	left, err := p.concat()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"<=", ">=", "!=", "<>", "=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.concat()
+			if err != nil {
+				return nil, err
+			}
+			if op == "<>" {
+				op = "!="
+			}
+			return binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) concat() (formulaNode, error) {
+	return p.binary(p.additive, "&")
+}
+
+func (p *formulaParser) additive() (formulaNode, error) {
+	return p.binary(p.term, "+", "-")
+}
+
+func (p *formulaParser) term() (formulaNode, error) {
+	return p.binary(p.unary, "*", "/")
+}
+
+// binary parses a left-associative chain of operands joined by ops
+func (p *formulaParser) binary(operand func() (formulaNode, error), ops ...string) (formulaNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		matched := ""
+		for _, op := range ops {
+			if p.accept(op) {
+				matched = op
+				break
+			}
+		}
+		if matched == "" {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: matched, left: left, right: right}
+	}
+}
+
+// unary is entered once per nesting level, so it enforces MaxFormulaDepth
+func (p *formulaParser) unary() (formulaNode, error) {
+	if p.depth++; p.depth > MaxFormulaDepth {
+		return nil, fmt.Errorf("formula is nested more than %d levels deep", MaxFormulaDepth)
+	}
+	defer func() { p.depth-- }()
+
+	if p.accept("-") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	return p.primary()
+}
+
+func (p *formulaParser) primary() (formulaNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of formula")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '(':
+		p.pos++
+		node, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ) at position %d", p.pos)
+		}
+		return node, nil
+
+	case c == '"' || c == '\'':
+		end := strings.IndexByte(p.input[p.pos+1:], c)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated text at position %d", p.pos)
+		}
+		text := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return literalNode{value: text}, nil
+
+	case c == '{':
+		end := strings.IndexByte(p.input[p.pos:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated field reference at position %d", p.pos)
+		}
+		name := strings.TrimSpace(p.input[p.pos+1 : p.pos+end])
+		if name == "" {
+			return nil, fmt.Errorf("empty field reference at position %d", p.pos)
+		}
+		p.pos += end + 1
+		return fieldNode{name: name}, nil
+
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return literalNode{value: n}, nil
+
+	case unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		name := strings.ToUpper(p.input[start:p.pos])
+		switch name {
+		case "TRUE":
+			return literalNode{value: true}, nil
+		case "FALSE":
+			return literalNode{value: false}, nil
+		}
+		if _, ok := formulaFunctions[name]; !ok {
+			word := p.input[start:p.pos]
+			if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == '(' {
+				return nil, fmt.Errorf("unknown function %s at position %d", name, start)
+			}
+			return nil, fmt.Errorf("unknown name %q at position %d; fields are referenced as {%s}", word, start, word)
+		}
+		return p.call(name)
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+// call parses the argument list of function name and checks its arity
+func (p *formulaParser) call(name string) (formulaNode, error) {
+	arity := formulaFunctions[name]
+	if !p.accept("(") {
+		return nil, fmt.Errorf("expected ( after %s", name)
+	}
+
+	var args []formulaNode
+	if !p.accept(")") {
+		for {
+			arg, err := p.comparison()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				return nil, fmt.Errorf("expected , or ) in %s at position %d", name, p.pos)
+			}
+		}
+	}
+
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return nil, fmt.Errorf("%s takes %s, got %d", name, formulaArity(arity), len(args))
+	}
+	return callNode{name: name, args: args}, nil
+}
+
+func formulaArity(arity [2]int) string {
+	switch {
+	case arity[1] < 0:
+		return fmt.Sprintf("at least %d arguments", arity[0])
+	case arity[0] == arity[1] && arity[0] == 1:
+		return "1 argument"
+	case arity[0] == arity[1]:
+		return fmt.Sprintf("%d arguments", arity[0])
+	default:
+		return fmt.Sprintf("%d to %d arguments", arity[0], arity[1])
+	}
+}
+
+// accept consumes tok after any whitespace if it comes next
+func (p *formulaParser) accept(tok string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.input[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *formulaParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func formulaField(formula string) Field {
+	return Field{Name: "Result", Type: FieldTypeFormula, Options: JSON{"formula": formula}}
+}
+
+func TestFormulaEngine_Evaluate(t *testing.T) {
+	engine := NewFormulaEngine()
+	record := &Record{Data: JSON{
+		"Name":     "Launch Plan",
+		"Price":    json.Number("12.5"),
+		"Quantity": float64(4),
+		"Discount": 2,
+		"Done":     true,
+		"Notes":    nil,
+		"Tags":     []interface{}{"a", "b"},
+	}}
+
+	tests := []struct {
+		formula string
+		want    interface{}
+	}{
+		{formula: "1 + 2 * 3", want: 7.0},
+		{formula: "(1 + 2) * 3", want: 9.0},
+		{formula: "10 / 4 - -1", want: 3.5},
+		{formula: "{Price} * {Quantity} - {Discount}", want: 48.0},
+		{formula: `{Name} & " x" & {Quantity}`, want: "Launch Plan x4"},
+		{formula: `CONCAT({Name}, ': ', {Tags})`, want: "Launch Plan: a, b"},
+		{formula: "SUM({Price}, {Quantity}, 0.5)", want: 17.0},
+		{formula: "LEN({Name})", want: 11.0},
+		{formula: "upper({Name})", want: "LAUNCH PLAN"},
+		{formula: "LOWER({Name})", want: "launch plan"},
+		{formula: `IF({Quantity} > 3, "bulk", "single")`, want: "bulk"},
+		{formula: `IF({Done}, "yes")`, want: "yes"},
+		{formula: `IF({Notes}, "has notes")`, want: nil},
+		{formula: `IF(FALSE, 1 / 0, "lazy")`, want: "lazy"},
+		{formula: `{Name} = "Launch Plan"`, want: true},
+		{formula: "{Quantity} <> 4", want: false},
+		{formula: "{Notes} + 1", want: 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formula, func(t *testing.T) {
+			got, err := engine.Evaluate(formulaField(tt.formula), record)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormulaEngine_Errors(t *testing.T) {
+	engine := NewFormulaEngine()
+	record := &Record{
+		Data:  JSON{"Name": "Ada", "Price": 3},
+		Table: Table{Fields: []Field{{Name: "Name"}, {Name: "Price"}, {Name: "Notes"}}},
+	}
+
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{field: formulaField("{Missing} + 1"), want: `unknown field "Missing"`},
+		{field: formulaField(`{Name} * 2`), want: "cannot use text in *"},
+		{field: formulaField(`{Price} > "10"`), want: "cannot compare a number with text"},
+		{field: formulaField("{Price} / 0"), want: "division by zero"},
+		{field: formulaField("LEN({Name}, 2)"), want: "LEN takes 1 argument, got 2"},
+		{field: formulaField("ROUND({Price})"), want: "unknown function ROUND"},
+		{field: formulaField("Price + 1"), want: `unknown name "Price"`},
+		{field: formulaField(`"unterminated`), want: "unterminated text"},
+		{field: formulaField("(1 + 2"), want: "missing )"},
+		{field: formulaField("1 +"), want: "unexpected end of formula"},
+		{field: formulaField("1 2"), want: "unexpected"},
+		{field: formulaField(strings.Repeat("(", MaxFormulaDepth) + "1" + strings.Repeat(")", MaxFormulaDepth)), want: "nested more than"},
+		{field: formulaField(strings.Repeat("-", MaxFormulaDepth) + "1"), want: "nested more than"},
+		{field: formulaField("1" + strings.Repeat(" + 1", MaxFormulaLength/4)), want: "longer than"},
+		{field: Field{Name: "Result", Type: FieldTypeFormula}, want: "no formula configured"},
+		{field: Field{Name: "Result", Type: FieldTypeText, Options: JSON{"formula": "1"}}, want: "not formula"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			_, err := engine.Evaluate(tt.field, record)
+			var formulaErr *FormulaError
+			if !stderrors.As(err, &formulaErr) || formulaErr.Field != "Result" {
+				t.Fatalf("Evaluate() error = %v, want a FormulaError", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Evaluate() error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	// A known field with no value is blank rather than unknown
+	if got, err := engine.Evaluate(formulaField(`{Notes} & "!"`), record); err != nil || got != "!" {
+		t.Errorf("Evaluate() = %v, %v, want a blank reference", got, err)
+	}
+}
+
+func TestFormulaEngine_CacheIsBounded(t *testing.T) {
+	engine := NewFormulaEngine()
+
+	for i := 0; i < MaxParsedFormulas+10; i++ {
+		if _, err := engine.Evaluate(formulaField(fmt.Sprintf("%d + 1", i)), &Record{}); err != nil {
+			t.Fatalf("Evaluate() error = %v", err)
+		}
+	}
+	if len(engine.parsed) != MaxParsedFormulas || engine.order.Len() != MaxParsedFormulas {
+		t.Errorf("cached %d formulas, want at most %d", len(engine.parsed), MaxParsedFormulas)
+	}
+
+	// Nesting within the limit still parses
+	nested := strings.Repeat("(", MaxFormulaDepth/2) + "1" + strings.Repeat(")", MaxFormulaDepth/2)
+	if got, err := engine.Evaluate(formulaField(nested), &Record{}); err != nil || got != float64(1) {
+		t.Errorf("Evaluate() = %v, %v, want 1", got, err)
+	}
+}
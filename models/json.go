@@ -0,0 +1,112 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// Limits on JSON request bodies decoded with middleware.BindJSON, e.g. a Record
+// with its Data. A limit of 0 disables its check.
+var (
+	MaxJSONDepth       = 32
+	MaxJSONBytes int64 = 1 << 20
+)
+
+// UnmarshalJSON implements json.Unmarshaler, keeping numbers as json.Number so
+// large integers such as IDs don't lose precision by being converted to float64
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	var decoded map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	*j = decoded
+	return nil
+}
+
+// GetString returns the string stored under key
+func (j JSON) GetString(key string) (string, bool) {
+	s, ok := j[key].(string)
+	return s, ok
+}
+
+// GetInt returns the integer stored under key, whether it was decoded as
+// json.Number, float64 or a Go integer. Fractional numbers and strings don't match.
+func (j JSON) GetInt(key string) (int64, bool) {
+	return jsonInt(j[key])
+}
+
+// GetFloat returns the number stored under key, whether it was decoded as
+// json.Number, float64 or a Go integer
+func (j JSON) GetFloat(key string) (float64, bool) {
+	return jsonFloat(j[key])
+}
+
+// GetBool returns the boolean stored under key
+func (j JSON) GetBool(key string) (bool, bool) {
+	b, ok := j[key].(bool)
+	return b, ok
+}
+
+// GetJSON returns the object stored under key
+func (j JSON) GetJSON(key string) (JSON, bool) {
+	return asJSONObject(j[key])
+}
+
+// GetNested returns the value at a dot path such as "settings.theme.color",
+// descending through nested objects
+func (j JSON) GetNested(path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+
+	current := j
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		value, ok := current[key]
+		if !ok {
+			return nil, false
+		}
+		if i == len(keys)-1 {
+			return value, true
+		}
+		if current, ok = asJSONObject(value); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// asJSONObject returns value as JSON if it is a decoded object
+func asJSONObject(value interface{}) (JSON, bool) {
+	switch v := value.(type) {
+	case JSON:
+		return v, v != nil
+	case map[string]interface{}:
+		return JSON(v), v != nil
+	default:
+		return nil, false
+	}
+}
+
+// jsonInt converts a decoded JSON number to int64, rejecting strings
+func jsonInt(value interface{}) (int64, bool) {
+	if _, ok := value.(string); ok {
+		return 0, false
+	}
+	n, err := utils.NumberToInt64(value)
+	return n, err == nil
+}
+
+// jsonFloat converts a decoded JSON number to float64, rejecting strings
+func jsonFloat(value interface{}) (float64, bool) {
+	if _, ok := value.(string); ok {
+		return 0, false
+	}
+	n, err := utils.NumberToFloat64(value)
+	return n, err == nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+func TestJSONAccessors(t *testing.T) {
+	var scanned JSON
+	if err := scanned.Scan(`{"name":"Ada","count":3,"ratio":0.5,"active":true,"settings":{"theme":{"color":"blue"}}}`); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	data := JSON{
+		"name":     "Ada",
+		"count":    json.Number("3"),
+		"int":      7,
+		"ratio":    0.5,
+		"numeric":  "42",
+		"active":   true,
+		"settings": JSON{"theme": map[string]interface{}{"color": "blue"}},
+	}
+
+	for name, j := range map[string]JSON{"scanned": scanned, "literal": data} {
+		t.Run(name, func(t *testing.T) {
+			if s, ok := j.GetString("name"); !ok || s != "Ada" {
+				t.Errorf("GetString(name) = %q, %v", s, ok)
+			}
+			if n, ok := j.GetInt("count"); !ok || n != 3 {
+				t.Errorf("GetInt(count) = %d, %v", n, ok)
+			}
+			if _, ok := j.GetInt("ratio"); ok {
+				t.Error("GetInt(ratio) should not match a fractional number")
+			}
+			if f, ok := j.GetFloat("ratio"); !ok || f != 0.5 {
+				t.Errorf("GetFloat(ratio) = %v, %v", f, ok)
+			}
+			if b, ok := j.GetBool("active"); !ok || !b {
+				t.Errorf("GetBool(active) = %v, %v", b, ok)
+			}
+			if _, ok := j.GetBool("name"); ok {
+				t.Error("GetBool(name) should not match a string")
+			}
+			if settings, ok := j.GetJSON("settings"); !ok || settings["theme"] == nil {
+				t.Errorf("GetJSON(settings) = %v, %v", settings, ok)
+			}
+			if color, ok := j.GetNested("settings.theme.color"); !ok || color != "blue" {
+				t.Errorf("GetNested() = %v, %v", color, ok)
+			}
+			for _, path := range []string{"", "missing", "settings.font", "name.first", "settings.theme.color.hex"} {
+				if value, ok := j.GetNested(path); ok {
+					t.Errorf("GetNested(%q) = %v, want no match", path, value)
+				}
+			}
+		})
+	}
+
+	if n, ok := data.GetInt("int"); !ok || n != 7 {
+		t.Errorf("GetInt(int) = %d, %v", n, ok)
+	}
+	if _, ok := data.GetInt("numeric"); ok {
+		t.Error("GetInt(numeric) should not match a string")
+	}
+	if _, ok := JSON(nil).GetString("name"); ok {
+		t.Error("a nil JSON should have no values")
+	}
+}
+
+func TestJSONUnmarshalKeepsNumbers(t *testing.T) {
+	var record Record
+	if err := json.Unmarshal([]byte(`{"data": {"external_id": 1234567890123456789, "nested": {"n": 9007199254740993}}}`), &record); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if id, ok := record.Data.GetInt("external_id"); !ok || id != 1234567890123456789 {
+		t.Errorf("external_id = %d, %v, want 1234567890123456789", id, ok)
+	}
+
+	var viaUseNumber Record
+	if err := utils.FromJSONUseNumber([]byte(`{"data": {"external_id": 1234567890123456789}}`), &viaUseNumber); err != nil {
+		t.Fatalf("FromJSONUseNumber() error = %v", err)
+	}
+	if id := viaUseNumber.Data["external_id"]; id != json.Number("1234567890123456789") {
+		t.Errorf("external_id = %#v, want the exact json.Number", id)
+	}
+
+	// Internal decodes, e.g. from the database, aren't subject to request limits
+	deep := strings.Repeat(`{"a":`, MaxJSONDepth+1) + `1` + strings.Repeat(`}`, MaxJSONDepth+1)
+	var stored JSON
+	if err := stored.Scan(deep); err != nil {
+		t.Errorf("Scan() of deeply nested data error = %v", err)
+	}
+	if err := json.Unmarshal([]byte(deep), &stored); err != nil {
+		t.Errorf("Unmarshal() of deeply nested data error = %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListOptions lists the columns a list endpoint lets clients search, filter and
+// sort by. Anything else in the request is rejected.
+type ListOptions struct {
+	SearchFields []string
+	FilterFields []string
+	SortFields   []string
+}
+
+// ApplyListQuery scopes db to the search, filters and sort of req:
+//   - Query (or the embedded Search) is matched against opts.SearchFields
+//   - Filters (equality) and the embedded Filters (see FilterRequests) must name
+//     opts.FilterFields
+//   - Sort, or the embedded Sort and Order, must name opts.SortFields; without
+//     either the default GetSort/GetOrder is used
+//
+// Pagination isn't applied, so the result can be counted as is; add PageScope for
+// the page query. Invalid requests are added to the returned query's errors, so
+// they surface from Count or Find.
+func ApplyListQuery(db *gorm.DB, req *SearchRequest, opts ListOptions) *gorm.DB {
+	// A new session so errors aren't added to a shared *gorm.DB
+	db = db.Session(&gorm.Session{})
+
+	search := req.Query
+	if search == "" {
+		search = req.PaginationRequest.Search
+	}
+	query, err := ApplySearch(db, search, opts.SearchFields)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	filters := req.PaginationRequest.FilterRequests()
+	fields := make([]string, 0, len(req.Filters))
+	for field := range req.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		filters = append(filters, FilterRequest{Field: field, Operator: "eq", Value: req.Filters[field]})
+	}
+
+	var errs ValidationErrors
+	query, err = ApplyFilters(query, filters, opts.FilterFields)
+	if err != nil {
+		if invalid, ok := err.(ValidationErrors); ok {
+			errs = invalid
+		} else {
+			db.AddError(err)
+			return db
+		}
+	}
+	orders := listOrders(req, opts, &errs)
+	if err := errs.ErrOrNil(); err != nil {
+		db.AddError(err)
+		return db
+	}
+	if len(orders) > 0 {
+		query = query.Order(clause.OrderBy{Columns: orders})
+	}
+	// Reusable, so Count doesn't leak into the Find that follows
+	return query.Session(&gorm.Session{})
+}
+
+// PageScope limits a query to the page described by req
+func PageScope(req *PaginationRequest) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset(req.GetOffset()).Limit(req.GetPageSize())
+	}
+}
+
+// listOrders builds the ORDER BY columns of req, recording disallowed fields in errs
+func listOrders(req *SearchRequest, opts ListOptions, errs *ValidationErrors) []clause.OrderByColumn {
+	sorts := req.Sort
+	if len(sorts) == 0 {
+		if req.PaginationRequest.Sort != "" && !containsField(opts.SortFields, req.PaginationRequest.Sort) {
+			errs.Add("sort", fmt.Sprintf("cannot sort by %q", req.PaginationRequest.Sort))
+			return nil
+		}
+		sorts = []SortRequest{{Field: req.PaginationRequest.GetSort(), Order: req.PaginationRequest.GetOrder()}}
+	} else {
+		for i, s := range sorts {
+			if !containsField(opts.SortFields, s.Field) {
+				errs.Add(fmt.Sprintf("sort[%d].field", i), fmt.Sprintf("cannot sort by %q", s.Field))
+			}
+		}
+	}
+
+	orders := make([]clause.OrderByColumn, len(sorts))
+	for i, s := range sorts {
+		orders[i] = clause.OrderByColumn{Column: clause.Column{Name: s.Field}, Desc: strings.EqualFold(s.Order, "desc")}
+	}
+	return orders
+}
+
+func containsField(fields []string, field string) bool {
+	for _, allowed := range fields {
+		if allowed == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type listItem struct {
+	ID       uint `gorm:"primarykey"`
+	Name     string
+	Team     string
+	Priority int
+	Status   string
+}
+
+func newListTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := openTestDB(t, &listItem{})
+
+	items := []listItem{
+		{ID: 1, Name: "Launch plan", Team: "growth", Priority: 3, Status: "open"},
+		{ID: 2, Name: "Launch review", Team: "growth", Priority: 1, Status: "open"},
+		{ID: 3, Name: "Launch retro", Team: "growth", Priority: 2, Status: "closed"},
+		{ID: 4, Name: "Pre-launch QA", Team: "growth", Priority: 5, Status: "open"},
+		{ID: 5, Name: "Launch budget", Team: "finance", Priority: 4, Status: "open"},
+		{ID: 6, Name: "Launch comms", Team: "growth", Priority: 4, Status: "open"},
+		{ID: 7, Name: "Hiring", Team: "growth", Priority: 9, Status: "open"},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	return db
+}
+
+var listOptions = ListOptions{
+	SearchFields: []string{"name"},
+	FilterFields: []string{"team", "status", "priority"},
+	SortFields:   []string{"priority", "name", "id"},
+}
+
+func TestApplyListQuery(t *testing.T) {
+	db := newListTestDB(t)
+
+	req := &SearchRequest{
+		Query:   "LAUNCH",
+		Filters: map[string]string{"team": "growth"},
+		Sort:    []SortRequest{{Field: "priority", Order: "desc"}, {Field: "id", Order: "asc"}},
+		PaginationRequest: PaginationRequest{
+			Page:     2,
+			PageSize: 2,
+			Filters:  JSON{"status": "open"},
+		},
+	}
+
+	query := ApplyListQuery(db.Model(&listItem{}), req, listOptions)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if total != 4 {
+		t.Errorf("total = %d, want the 4 open growth launch items", total)
+	}
+
+	var page []listItem
+	if err := query.Scopes(PageScope(&req.PaginationRequest)).Find(&page).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	// Matches by priority desc: 4 (5), 6 (4), 1 (3), 2 (1); page 2 holds the last two
+	ids := make([]uint, len(page))
+	for i, item := range page {
+		ids[i] = item.ID
+	}
+	if fmt.Sprint(ids) != "[1 2]" {
+		t.Errorf("page ids = %v, want [1 2]", ids)
+	}
+
+	var all []listItem
+	if err := query.Find(&all).Error; err != nil || len(all) != 4 {
+		t.Errorf("reusing the query found %d items, %v, want 4", len(all), err)
+	}
+}
+
+func TestApplyListQuery_DefaultSort(t *testing.T) {
+	db := newListTestDB(t)
+
+	req := &SearchRequest{PaginationRequest: PaginationRequest{Sort: "name", Order: "asc", Filters: JSON{"team": "finance"}}}
+	var items []listItem
+	if err := ApplyListQuery(db, req, listOptions).Find(&items).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(items) != 1 || items[0].ID != 5 {
+		t.Errorf("items = %+v, want item 5", items)
+	}
+}
+
+func TestApplyListQuery_RejectsDisallowedFields(t *testing.T) {
+	db := newListTestDB(t)
+
+	tests := []struct {
+		name string
+		req  SearchRequest
+		want string
+	}{
+		{name: "filter field", req: SearchRequest{Filters: map[string]string{"name": "x"}}, want: "filters[0].field"},
+		{name: "embedded filter field", req: SearchRequest{PaginationRequest: PaginationRequest{Filters: JSON{"team": "growth", "secret": 1}}}, want: "filters[0].field"},
+		{name: "sort field", req: SearchRequest{Sort: []SortRequest{{Field: "team"}}}, want: "sort[0].field"},
+		{name: "embedded sort field", req: SearchRequest{PaginationRequest: PaginationRequest{Sort: "status"}}, want: "sort"},
+		{name: "filter operator", req: SearchRequest{PaginationRequest: PaginationRequest{Filters: JSON{"priority": map[string]interface{}{"operator": "regex"}}}}, want: "filters[0].operator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []listItem
+			err := ApplyListQuery(db, &tt.req, listOptions).Find(&items).Error
+
+			var errs ValidationErrors
+			if !stderrors.As(err, &errs) {
+				t.Fatalf("Find() error = %v, want ValidationErrors", err)
+			}
+			if _, ok := errs.Fields()[tt.want]; !ok {
+				t.Errorf("errors = %v, want one for %s", errs, tt.want)
+			}
+		})
+	}
+
+	if err := ApplyListQuery(db, &SearchRequest{Query: "x"}, ListOptions{}).Find(&[]listItem{}).Error; err == nil {
+		t.Error("expected search without search fields to fail")
+	}
+	if err := db.Find(&[]listItem{}).Error; err != nil {
+		t.Errorf("a rejected request left an error on the shared db: %v", err)
+	}
+}
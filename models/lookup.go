@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rollup aggregations, set in a rollup field's Options["aggregation"]
+const (
+	RollupSum       = "SUM"
+	RollupCount     = "COUNT"
+	RollupAvg       = "AVG"
+	RollupMin       = "MIN"
+	RollupMax       = "MAX"
+	RollupArrayJoin = "ARRAYJOIN"
+)
+
+// DefaultArrayJoinSeparator separates ARRAYJOIN values unless Options["separator"] is set
+const DefaultArrayJoinSeparator = ", "
+
+// ComputeLookup returns the values of Options["lookup_field"] across the related
+// records linked through Options["relation_field"], in record order. Blank values
+// are skipped and list values are flattened. With no values the result is nil.
+func ComputeLookup(field Field, related []Record) (interface{}, error) {
+	if field.Type != FieldTypeLookup {
+		return nil, fmt.Errorf("field %q: type is %q, not lookup", field.Name, field.Type)
+	}
+	values, err := linkedValues(field, related)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ComputeRollup aggregates Options["lookup_field"] across the related records with
+// Options["aggregation"]. Over no values SUM and COUNT are 0, ARRAYJOIN is "" and
+// AVG, MIN and MAX are nil. SUM, AVG, MIN and MAX need numeric values.
+func ComputeRollup(field Field, related []Record) (interface{}, error) {
+	if field.Type != FieldTypeRollup {
+		return nil, fmt.Errorf("field %q: type is %q, not rollup", field.Name, field.Type)
+	}
+	aggregation, _ := field.Options.GetString("aggregation")
+	aggregation = strings.ToUpper(aggregation)
+
+	values, err := linkedValues(field, related)
+	if err != nil {
+		return nil, err
+	}
+
+	switch aggregation {
+	case RollupCount:
+		return float64(len(values)), nil
+
+	case RollupArrayJoin:
+		separator, ok := field.Options.GetString("separator")
+		if !ok {
+			separator = DefaultArrayJoinSeparator
+		}
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = formulaText(value)
+		}
+		return strings.Join(parts, separator), nil
+
+	case RollupSum, RollupAvg, RollupMin, RollupMax:
+		numbers := make([]float64, len(values))
+		for i, value := range values {
+			n, ok := jsonFloat(value)
+			if !ok {
+				return nil, fmt.Errorf("field %q: cannot %s %s", field.Name, aggregation, formulaTypeName(value))
+			}
+			numbers[i] = n
+		}
+		return aggregateNumbers(aggregation, numbers), nil
+
+	default:
+		return nil, fmt.Errorf("field %q: unknown aggregation %q", field.Name, aggregation)
+	}
+}
+
+// aggregateNumbers applies a numeric rollup aggregation
+func aggregateNumbers(aggregation string, numbers []float64) interface{} {
+	var sum float64
+	for _, n := range numbers {
+		sum += n
+	}
+	if aggregation == RollupSum {
+		return sum
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	switch aggregation {
+	case RollupAvg:
+		return sum / float64(len(numbers))
+	case RollupMin:
+		min := numbers[0]
+		for _, n := range numbers[1:] {
+			if n < min {
+				min = n
+			}
+		}
+		return min
+	default: // RollupMax
+		max := numbers[0]
+		for _, n := range numbers[1:] {
+			if n > max {
+				max = n
+			}
+		}
+		return max
+	}
+}
+
+// linkedValues collects the non-blank values of the lookup field from related
+func linkedValues(field Field, related []Record) ([]interface{}, error) {
+	if _, ok := field.Options.GetString("relation_field"); !ok {
+		return nil, fmt.Errorf("field %q: no relation_field configured", field.Name)
+	}
+	name, ok := field.Options.GetString("lookup_field")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("field %q: no lookup_field configured", field.Name)
+	}
+
+	var values []interface{}
+	for i := range related {
+		value := related[i].GetFieldValue(name)
+		if items, ok := listValue(value); ok {
+			for _, item := range items {
+				if !isEmptyValue(item) {
+					values = append(values, item)
+				}
+			}
+			continue
+		}
+		if !isEmptyValue(value) {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func relatedTasks() []Record {
+	return []Record{
+		{Data: JSON{"Title": "Design", "Hours": json.Number("4"), "Tags": []interface{}{"ux", "ui"}}},
+		{Data: JSON{"Title": "Build", "Hours": float64(10), "Tags": []interface{}{"eng"}}},
+		{Data: JSON{"Title": "", "Hours": nil}},
+		{Data: JSON{"Title": "Ship", "Hours": 1}},
+	}
+}
+
+func rollupField(lookup, aggregation string) Field {
+	return Field{Name: "Rollup", Type: FieldTypeRollup, Options: JSON{
+		"relation_field": "Tasks", "lookup_field": lookup, "aggregation": aggregation,
+	}}
+}
+
+func TestComputeLookup(t *testing.T) {
+	field := func(lookup string) Field {
+		return Field{Name: "Lookup", Type: FieldTypeLookup, Options: JSON{"relation_field": "Tasks", "lookup_field": lookup}}
+	}
+
+	tests := []struct {
+		name    string
+		field   Field
+		related []Record
+		want    interface{}
+	}{
+		{name: "values in record order", field: field("Title"), related: relatedTasks(), want: []interface{}{"Design", "Build", "Ship"}},
+		{name: "lists are flattened", field: field("Tags"), related: relatedTasks(), want: []interface{}{"ux", "ui", "eng"}},
+		{name: "no related records", field: field("Title"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComputeLookup(tt.field, tt.related)
+			if err != nil {
+				t.Fatalf("ComputeLookup() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ComputeLookup() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ComputeLookup(Field{Name: "Lookup", Type: FieldTypeLookup, Options: JSON{"relation_field": "Tasks"}}, nil); err == nil {
+		t.Error("expected an error without a lookup_field")
+	}
+}
+
+func TestComputeRollup(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		related []Record
+		want    interface{}
+	}{
+		{name: "sum", field: rollupField("Hours", "SUM"), related: relatedTasks(), want: 15.0},
+		{name: "count skips blanks", field: rollupField("Hours", "count"), related: relatedTasks(), want: 3.0},
+		{name: "avg", field: rollupField("Hours", "AVG"), related: relatedTasks(), want: 5.0},
+		{name: "min", field: rollupField("Hours", "MIN"), related: relatedTasks(), want: 1.0},
+		{name: "max", field: rollupField("Hours", "MAX"), related: relatedTasks(), want: 10.0},
+		{name: "arrayjoin", field: rollupField("Title", "ARRAYJOIN"), related: relatedTasks(), want: "Design, Build, Ship"},
+		{name: "empty sum", field: rollupField("Hours", "SUM"), want: 0.0},
+		{name: "empty count", field: rollupField("Hours", "COUNT"), want: 0.0},
+		{name: "empty avg", field: rollupField("Hours", "AVG"), want: nil},
+		{name: "empty max", field: rollupField("Hours", "MAX"), want: nil},
+		{name: "empty arrayjoin", field: rollupField("Title", "ARRAYJOIN"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComputeRollup(tt.field, tt.related)
+			if err != nil {
+				t.Fatalf("ComputeRollup() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ComputeRollup() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	separator := rollupField("Title", "ARRAYJOIN")
+	separator.Options["separator"] = " | "
+	if got, _ := ComputeRollup(separator, relatedTasks()); got != "Design | Build | Ship" {
+		t.Errorf("ComputeRollup() with separator = %v", got)
+	}
+}
+
+func TestComputeRollup_Errors(t *testing.T) {
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{field: rollupField("Title", "SUM"), want: "cannot SUM text"},
+		{field: rollupField("Hours", "MEDIAN"), want: `unknown aggregation "MEDIAN"`},
+		{field: Field{Name: "Rollup", Type: FieldTypeRollup, Options: JSON{"lookup_field": "Hours", "aggregation": "SUM"}}, want: "no relation_field configured"},
+		{field: Field{Name: "Rollup", Type: FieldTypeLookup}, want: "not rollup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			_, err := ComputeRollup(tt.field, relatedTasks())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ComputeRollup() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+)
+
+// Default pagination limits
+const (
+	DefaultPageSize    = 20
+	DefaultMaxPageSize = 100
+)
+
+// PaginationLimits configures page sizes for every PaginationRequest in the service
+type PaginationLimits struct {
+	DefaultPageSize int  // Used when the request has no page size
+	MaxPageSize     int  // Largest allowed page size; 0 means unbounded, e.g. for internal exports
+	Clamp           bool // Silently cap oversized requests instead of rejecting them
+}
+
+var (
+	paginationLimitsMu sync.RWMutex
+	paginationLimits   = PaginationLimits{DefaultPageSize: DefaultPageSize, MaxPageSize: DefaultMaxPageSize}
+)
+
+// SetPaginationLimits replaces the service-wide pagination limits. A non-positive
+// DefaultPageSize falls back to DefaultPageSize and is capped at MaxPageSize.
+func SetPaginationLimits(limits PaginationLimits) {
+	if limits.DefaultPageSize <= 0 {
+		limits.DefaultPageSize = DefaultPageSize
+	}
+	if limits.MaxPageSize > 0 && limits.DefaultPageSize > limits.MaxPageSize {
+		limits.DefaultPageSize = limits.MaxPageSize
+	}
+
+	paginationLimitsMu.Lock()
+	defer paginationLimitsMu.Unlock()
+	paginationLimits = limits
+}
+
+// GetPaginationLimits returns the service-wide pagination limits
+func GetPaginationLimits() PaginationLimits {
+	paginationLimitsMu.RLock()
+	defer paginationLimitsMu.RUnlock()
+	return paginationLimits
+}
+
+// Validate rejects a page size above the configured maximum with a 400 invalid
+// input error, unless the limits are in clamp mode
+func (p *PaginationRequest) Validate() error {
+	limits := GetPaginationLimits()
+
+	if p.PageSize < 0 {
+		return errors.NewInvalidInputError("page_size", "must be positive")
+	}
+	if limits.MaxPageSize > 0 && p.PageSize > limits.MaxPageSize && !limits.Clamp {
+		return errors.NewInvalidInputError("page_size", fmt.Sprintf("must be at most %d", limits.MaxPageSize))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	sharederrors "github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestPaginationRequest_PageSizeLimits(t *testing.T) {
+	defer SetPaginationLimits(GetPaginationLimits())
+
+	tests := []struct {
+		name         string
+		limits       PaginationLimits
+		pageSize     int
+		wantPageSize int
+		wantErr      bool
+	}{
+		{name: "default when unset", limits: PaginationLimits{}, pageSize: 0, wantPageSize: 20},
+		{name: "within default limit", limits: PaginationLimits{}, pageSize: 50, wantPageSize: 50},
+		{name: "over default limit rejected", limits: PaginationLimits{MaxPageSize: 100}, pageSize: 101, wantPageSize: 100, wantErr: true},
+		{name: "over limit clamped", limits: PaginationLimits{MaxPageSize: 100, Clamp: true}, pageSize: 500, wantPageSize: 100},
+		{name: "custom max", limits: PaginationLimits{MaxPageSize: 250}, pageSize: 200, wantPageSize: 200},
+		{name: "custom default", limits: PaginationLimits{DefaultPageSize: 50, MaxPageSize: 250}, pageSize: 0, wantPageSize: 50},
+		{name: "default capped at max", limits: PaginationLimits{DefaultPageSize: 50, MaxPageSize: 10}, pageSize: 0, wantPageSize: 10},
+		{name: "unbounded export", limits: PaginationLimits{MaxPageSize: 0}, pageSize: 100000, wantPageSize: 100000},
+		{name: "negative rejected", limits: PaginationLimits{MaxPageSize: 100}, pageSize: -1, wantPageSize: 20, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPaginationLimits(tt.limits)
+			req := &PaginationRequest{PageSize: tt.pageSize}
+
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && sharederrors.GetHTTPCode(err) != http.StatusBadRequest {
+				t.Errorf("Validate() status = %d, want 400", sharederrors.GetHTTPCode(err))
+			}
+			if got := req.GetPageSize(); got != tt.wantPageSize {
+				t.Errorf("GetPageSize() = %d, want %d", got, tt.wantPageSize)
+			}
+		})
+	}
+}
+
+func TestPaginationRequest_BindingRejectsOversizedPages(t *testing.T) {
+	tests := []struct {
+		pageSize int
+		wantErr  bool
+	}{
+		{pageSize: 1},
+		{pageSize: DefaultMaxPageSize},
+		{pageSize: DefaultMaxPageSize + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		req := PaginationRequest{Page: 1, PageSize: tt.pageSize, Order: "asc"}
+		if err := binding.Validator.ValidateStruct(&req); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateStruct(page_size=%d) error = %v, wantErr %v", tt.pageSize, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetPaginationLimits_Defaults(t *testing.T) {
+	limits := GetPaginationLimits()
+	if limits.DefaultPageSize != DefaultPageSize || limits.MaxPageSize != DefaultMaxPageSize || limits.Clamp {
+		t.Errorf("unexpected default limits: %+v", limits)
+	}
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// ValidateRecord checks record data against a table's fields and returns every
+// problem found, one ImportError per failing field, or nil when data is valid.
+//
+// Required fields must be present and non-empty, values must match their field
+// type, and computed fields (formula, lookup, rollup) must not be set. A field's
+// Validation may carry "min_length"/"max_length" and "pattern" for text values and
+// "min"/"max" for numbers. Keys that aren't fields of the table are rejected.
+// Field.Unique spans records, so it is left to the store's unique checks.
+func ValidateRecord(fields []Field, data map[string]interface{}) []ImportError {
+	var errs []ImportError
+	fail := func(field, message string) {
+		errs = append(errs, ImportError{Field: field, Message: message})
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.Name] = true
+
+		value, present := data[field.Name]
+		if !present || isEmptyValue(value) {
+			if field.Required && !isComputedField(field.Type) {
+				fail(field.Name, "is required")
+			}
+			continue
+		}
+
+		if err := validateFieldValue(field, value); err != nil {
+			fail(field.Name, err.Error())
+		}
+	}
+
+	var unknown []string
+	for key := range data {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fail(key, "is not a field of this table")
+	}
+
+	return errs
+}
+
+// validateFieldValue checks a single non-empty value against its field
+func validateFieldValue(field Field, value interface{}) error {
+	switch field.Type {
+	case FieldTypeText, FieldTypeBarcode, FieldTypePhone:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		return checkTextRules(s, field.Validation)
+
+	case FieldTypeEmail:
+		s, ok := value.(string)
+		if !ok || !utils.IsValidEmail(s) {
+			return fmt.Errorf("must be a valid email address")
+		}
+		return checkTextRules(s, field.Validation)
+
+	case FieldTypeURL:
+		s, ok := value.(string)
+		if !ok || !utils.IsValidURL(s) {
+			return fmt.Errorf("must be a valid URL")
+		}
+		return checkTextRules(s, field.Validation)
+
+	case FieldTypeNumber, FieldTypeCurrency, FieldTypePercent, FieldTypeDuration:
+		n, ok := jsonFloat(value)
+		if !ok {
+			return fmt.Errorf("must be a number")
+		}
+		return checkNumberRules(n, field.Validation)
+
+	case FieldTypeAutoNumber, FieldTypeRating:
+		n, ok := jsonFloat(value)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("must be an integer")
+		}
+		return checkNumberRules(n, field.Validation)
+
+	case FieldTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be a boolean")
+		}
+
+	case FieldTypeDate, FieldTypeDateTime:
+		if _, ok := value.(time.Time); ok {
+			return nil
+		}
+		layouts := dateLayouts
+		if field.Type == FieldTypeDateTime {
+			layouts = append(dateTimeLayouts, dateLayouts...)
+		}
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a %s string", field.Type)
+		}
+		if _, err := parseTime(s, field.Options, layouts); err != nil {
+			return fmt.Errorf("unrecognized %s format", field.Type)
+		}
+
+	case FieldTypeSelect:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		return checkChoice(s, field.Options)
+
+	case FieldTypeMultiSelect:
+		items, ok := listValue(value)
+		if !ok {
+			return fmt.Errorf("must be a list of choices")
+		}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("choices must be strings")
+			}
+			if err := checkChoice(s, field.Options); err != nil {
+				return err
+			}
+		}
+
+	case FieldTypeAttachment, FieldTypeRelation:
+		if _, ok := listValue(value); !ok {
+			return fmt.Errorf("must be a list")
+		}
+
+	case FieldTypeFormula, FieldTypeLookup, FieldTypeRollup:
+		return fmt.Errorf("is computed and cannot be set")
+
+	default:
+		return fmt.Errorf("has unsupported type %q", field.Type)
+	}
+
+	return nil
+}
+
+// checkChoice reports whether value is exactly one of the configured choices
+func checkChoice(value string, options JSON) error {
+	choices, err := selectChoices(options)
+	if err != nil {
+		return err
+	}
+	for _, choice := range choices {
+		if choice == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of the allowed choices", value)
+}
+
+// checkTextRules applies the min_length, max_length and pattern rules
+func checkTextRules(value string, rules JSON) error {
+	length := utf8.RuneCountInString(value)
+	if min, ok := ruleNumber(rules, "min_length"); ok && float64(length) < min {
+		return fmt.Errorf("must be at least %v characters", min)
+	}
+	if max, ok := ruleNumber(rules, "max_length"); ok && float64(length) > max {
+		return fmt.Errorf("must be at most %v characters", max)
+	}
+	if pattern, ok := rules["pattern"].(string); ok && pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("has an invalid validation pattern")
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("does not match the required pattern")
+		}
+	}
+	return nil
+}
+
+// checkNumberRules applies the min and max rules
+func checkNumberRules(value float64, rules JSON) error {
+	if min, ok := ruleNumber(rules, "min"); ok && value < min {
+		return fmt.Errorf("must be at least %v", min)
+	}
+	if max, ok := ruleNumber(rules, "max"); ok && value > max {
+		return fmt.Errorf("must be at most %v", max)
+	}
+	return nil
+}
+
+// ruleNumber reads a numeric validation rule
+func ruleNumber(rules JSON, key string) (float64, bool) {
+	raw, ok := rules[key]
+	if !ok {
+		return 0, false
+	}
+	return jsonFloat(raw)
+}
+
+// listValue returns value as a list of items
+func listValue(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items, true
+	default:
+		return nil, false
+	}
+}
+
+// isEmptyValue reports whether value counts as missing for required fields
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case json.Number:
+		return v == ""
+	default:
+		items, ok := listValue(value)
+		return ok && len(items) == 0
+	}
+}
+
+func isComputedField(fieldType FieldType) bool {
+	return fieldType == FieldTypeFormula || fieldType == FieldTypeLookup || fieldType == FieldTypeRollup
+}
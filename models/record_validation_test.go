@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateRecord(t *testing.T) {
+	fields := []Field{
+		{Name: "Name", Type: FieldTypeText, Required: true, Validation: JSON{"min_length": 2, "max_length": float64(10)}},
+		{Name: "Code", Type: FieldTypeText, Validation: JSON{"pattern": `^[A-Z]{3}$`}},
+		{Name: "Email", Type: FieldTypeEmail},
+		{Name: "Budget", Type: FieldTypeCurrency, Validation: JSON{"min": 0}},
+		{Name: "Rating", Type: FieldTypeRating, Validation: JSON{"max": 5}},
+		{Name: "Due", Type: FieldTypeDate},
+		{Name: "Done", Type: FieldTypeBoolean},
+		{Name: "Status", Type: FieldTypeSelect, Options: JSON{"choices": []interface{}{"Todo", map[string]interface{}{"name": "Done"}}}},
+		{Name: "Tags", Type: FieldTypeMultiSelect, Options: JSON{"choices": []string{"a", "b"}}},
+		{Name: "Total", Type: FieldTypeFormula, Required: true},
+	}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want map[string]string
+	}{
+		{
+			name: "valid record",
+			data: map[string]interface{}{
+				"Name": "Launch", "Code": "ABC", "Email": "ada@example.com", "Budget": json.Number("12.5"),
+				"Rating": float64(4), "Due": "2024-03-04", "Done": true, "Status": "Done", "Tags": []interface{}{"a", "b"},
+			},
+		},
+		{
+			name: "missing required field",
+			data: map[string]interface{}{"Name": "  "},
+			want: map[string]string{"Name": "is required"},
+		},
+		{
+			name: "type mismatches",
+			data: map[string]interface{}{
+				"Name": "Launch", "Email": "not-an-email", "Budget": "12", "Rating": 4.5, "Due": "someday",
+				"Done": "yes", "Status": "todo", "Tags": []interface{}{"a", "c"},
+			},
+			want: map[string]string{
+				"Email":  "must be a valid email address",
+				"Budget": "must be a number",
+				"Rating": "must be an integer",
+				"Due":    "unrecognized date format",
+				"Done":   "must be a boolean",
+				"Status": `"todo" is not one of the allowed choices`,
+				"Tags":   `"c" is not one of the allowed choices`,
+			},
+		},
+		{
+			name: "validation rules",
+			data: map[string]interface{}{"Name": "A", "Code": "abcd", "Budget": -1, "Rating": 6},
+			want: map[string]string{
+				"Name":   "must be at least 2 characters",
+				"Code":   "does not match the required pattern",
+				"Budget": "must be at least 0",
+				"Rating": "must be at most 5",
+			},
+		},
+		{
+			name: "computed and unknown fields",
+			data: map[string]interface{}{"Name": "Launch", "Total": 3, "Owner": "ada"},
+			want: map[string]string{"Total": "is computed and cannot be set", "Owner": "is not a field of this table"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateRecord(fields, tt.data)
+
+			got := make(map[string]string, len(errs))
+			for _, e := range errs {
+				got[e.Field] = e.Message
+			}
+			if len(got) != len(errs) || len(got) != len(tt.want) {
+				t.Fatalf("ValidateRecord() = %v, want %v", errs, tt.want)
+			}
+			for field, message := range tt.want {
+				if got[field] != message {
+					t.Errorf("%s: got %q, want %q", field, got[field], message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"gorm.io/gorm"
+)
+
+// GenerateUniqueSlug returns utils.GenerateSlug(base), suffixed with -2, -3, ... if
+// needed so no row of model's table has the same slug where scopeField equals
+// scopeID, e.g. GenerateUniqueSlug(db, &Table{}, "Q3 Roadmap", "workspace_id", 7).
+// An empty scopeField checks the whole table. Soft-deleted rows don't conflict, as
+// with the partial unique indexes on slug. Concurrent creates can still pick the
+// same slug, so callers should retry when the insert fails with ALREADY_EXISTS.
+func GenerateUniqueSlug(db *gorm.DB, model interface{}, base string, scopeField string, scopeID uint) (string, error) {
+	slug := utils.GenerateSlug(base)
+	if slug == "" {
+		return "", errors.NewInvalidInputError("slug", "must contain at least one letter or digit")
+	}
+
+	query := db.Model(model).Where("slug = ? OR slug LIKE ? ESCAPE '\\'", slug, likeEscaper.Replace(slug)+"-%")
+	if scopeField != "" {
+		if !columnNamePattern.MatchString(scopeField) {
+			return "", fmt.Errorf("invalid scope field %q", scopeField)
+		}
+		query = query.Where(scopeField+" = ?", scopeID)
+	}
+
+	var taken []string
+	if err := query.Pluck("slug", &taken).Error; err != nil {
+		return "", fmt.Errorf("failed to check slug %q: %w", slug, err)
+	}
+
+	used := make(map[int]bool, len(taken))
+	for _, existing := range taken {
+		if existing == slug {
+			used[1] = true
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(existing, slug+"-")); err == nil {
+			used[n] = true
+		}
+	}
+
+	if !used[1] {
+		return slug, nil
+	}
+	for n := 2; ; n++ {
+		if !used[n] {
+			return fmt.Sprintf("%s-%d", slug, n), nil
+		}
+	}
+}
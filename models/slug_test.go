@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestGenerateUniqueSlug(t *testing.T) {
+	db := openTestDB(t, &Table{})
+
+	for _, table := range []Table{
+		{WorkspaceID: 1, Name: "Roadmap", Slug: "roadmap"},
+		{WorkspaceID: 1, Name: "Roadmap", Slug: "roadmap-2"},
+		{WorkspaceID: 1, Name: "Roadmap Q3", Slug: "roadmap-q3"},
+		{WorkspaceID: 1, Name: "Archive", Slug: "archive"},
+		{WorkspaceID: 2, Name: "Budget", Slug: "budget"},
+	} {
+		table := table
+		table.TenantID = 1
+		if err := db.Create(&table).Error; err != nil {
+			t.Fatalf("failed to seed: %v", err)
+		}
+	}
+	if err := db.Where("slug = ?", "archive").Delete(&Table{}).Error; err != nil {
+		t.Fatalf("failed to soft delete: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		base        string
+		workspaceID uint
+		scopeField  string
+		want        string
+	}{
+		{name: "free slug", base: "Launch Plan!", workspaceID: 1, scopeField: "workspace_id", want: "launch-plan"},
+		{name: "next free suffix", base: "Roadmap", workspaceID: 1, scopeField: "workspace_id", want: "roadmap-3"},
+		{name: "other scope", base: "Roadmap", workspaceID: 2, scopeField: "workspace_id", want: "roadmap"},
+		{name: "conflict in other scope only", base: "Budget", workspaceID: 1, scopeField: "workspace_id", want: "budget"},
+		{name: "whole table", base: "Budget", want: "budget-2"},
+		{name: "soft-deleted rows don't conflict", base: "Archive", workspaceID: 1, scopeField: "workspace_id", want: "archive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateUniqueSlug(db, &Table{}, tt.base, tt.scopeField, tt.workspaceID)
+			if err != nil {
+				t.Fatalf("GenerateUniqueSlug() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateUniqueSlug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GenerateUniqueSlug(db, &Table{}, "!!!", "workspace_id", 1); err == nil {
+		t.Error("expected an error for a base without letters or digits")
+	}
+	if _, err := GenerateUniqueSlug(db, &Table{}, "x", "workspace_id = 1 OR 1", 1); err == nil {
+		t.Error("expected an error for an unsafe scope field")
+	}
+}
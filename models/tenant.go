@@ -1,6 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 )
 
@@ -8,7 +12,7 @@ import (
 type Tenant struct {
 	BaseModel
 	Name        string    `json:"name" gorm:"not null"`
-	Slug        string    `json:"slug" gorm:"uniqueIndex;not null"`
+	Slug        string    `json:"slug" gorm:"uniqueIndex:idx_tenants_slug,where:deleted_at IS NULL;not null"`
 	Domain      string    `json:"domain" gorm:"uniqueIndex"`
 	Email       string    `json:"email" gorm:"not null"`
 	Phone       string    `json:"phone"`
@@ -64,14 +68,19 @@ func (t *Tenant) GetWorkspaceCount() int {
 
 // CanCreateWorkspace checks if the tenant can create more workspaces
 func (t *Tenant) CanCreateWorkspace() bool {
-	limits := t.GetPlanLimits()
-	return t.GetWorkspaceCount() < limits.MaxWorkspaces
+	limits, _ := t.EffectivePlanLimits()
+	return withinLimit(t.GetWorkspaceCount(), limits.MaxWorkspaces)
 }
 
 // CanInviteUser checks if the tenant can invite more users
 func (t *Tenant) CanInviteUser() bool {
-	limits := t.GetPlanLimits()
-	return t.GetUserCount() < limits.MaxUsers
+	limits, _ := t.EffectivePlanLimits()
+	return withinLimit(t.GetUserCount(), limits.MaxUsers)
+}
+
+// withinLimit reports whether count is below limit, treating PlanUnlimited as no limit
+func withinLimit(count, limit int) bool {
+	return limit == PlanUnlimited || count < limit
 }
 
 // GetPlanLimits returns the limits for the current plan
@@ -114,6 +123,94 @@ func (t *Tenant) GetPlanLimits() PlanLimits {
 	}
 }
 
+// PlanUnlimited marks a plan limit without a cap
+const PlanUnlimited = -1
+
+// PlanLimitsSettingsKey is the Settings key holding negotiated limit overrides, e.g.
+// {"plan_limits": {"max_users": 250}}
+const PlanLimitsSettingsKey = "plan_limits"
+
+// ErrInvalidPlanLimits is returned by EffectivePlanLimits for malformed overrides
+var ErrInvalidPlanLimits = errors.New("invalid plan limit overrides")
+
+// EffectivePlanLimits returns the plan limits with the tenant's negotiated overrides
+// from Settings applied. Overrides must be positive whole numbers or PlanUnlimited;
+// limits that are unlimited on the plan stay unlimited. When the overrides are
+// invalid, the plan defaults are returned along with an ErrInvalidPlanLimits error.
+func (t *Tenant) EffectivePlanLimits() (PlanLimits, error) {
+	limits := t.GetPlanLimits()
+
+	raw, ok := t.Settings[PlanLimitsSettingsKey]
+	if !ok || raw == nil {
+		return limits, nil
+	}
+	overrides, ok := raw.(map[string]interface{})
+	if !ok {
+		return limits, fmt.Errorf("%w: %s must be an object", ErrInvalidPlanLimits, PlanLimitsSettingsKey)
+	}
+
+	effective := limits
+	for name, value := range overrides {
+		limit, err := planLimitOverride(value)
+		if err != nil {
+			return limits, fmt.Errorf("%w: %s: %v", ErrInvalidPlanLimits, name, err)
+		}
+
+		switch name {
+		case "max_users":
+			effective.MaxUsers = int(overrideLimit(int64(limits.MaxUsers), limit))
+		case "max_workspaces":
+			effective.MaxWorkspaces = int(overrideLimit(int64(limits.MaxWorkspaces), limit))
+		case "max_records":
+			effective.MaxRecords = int(overrideLimit(int64(limits.MaxRecords), limit))
+		case "max_storage":
+			effective.MaxStorage = overrideLimit(limits.MaxStorage, limit)
+		default:
+			return limits, fmt.Errorf("%w: unknown limit %q", ErrInvalidPlanLimits, name)
+		}
+	}
+
+	return effective, nil
+}
+
+// overrideLimit applies an override unless the plan limit is already unlimited
+func overrideLimit(planLimit, override int64) int64 {
+	if planLimit == PlanUnlimited {
+		return PlanUnlimited
+	}
+	return override
+}
+
+// planLimitOverride validates a single override value from Settings
+func planLimitOverride(value interface{}) (int64, error) {
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("must be a number")
+		}
+		number = f
+	default:
+		return 0, fmt.Errorf("must be a number, got %T", value)
+	}
+
+	// Larger values can't be represented exactly by JSON numbers
+	if number != math.Trunc(number) || number > 1<<53 {
+		return 0, fmt.Errorf("must be a whole number, got %v", number)
+	}
+	if number != PlanUnlimited && number < 1 {
+		return 0, fmt.Errorf("must be positive or %d for unlimited, got %v", PlanUnlimited, number)
+	}
+	return int64(number), nil
+}
+
 // PlanLimits represents the limits for a subscription plan
 type PlanLimits struct {
 	MaxUsers      int      `json:"max_users"`
@@ -284,4 +381,22 @@ type FeatureSettings struct {
 	DisabledFeatures []string          `json:"disabled_features"`
 	FeatureFlags     map[string]bool   `json:"feature_flags"`
 	CustomSettings   map[string]interface{} `json:"custom_settings"`
-}
\ No newline at end of file
+}
+// IsEnabled reports whether feature is on. An explicit flag in FeatureFlags wins,
+// then DisabledFeatures, then EnabledFeatures; unknown features are off.
+func (s FeatureSettings) IsEnabled(feature string) bool {
+	if enabled, ok := s.FeatureFlags[feature]; ok {
+		return enabled
+	}
+	for _, disabled := range s.DisabledFeatures {
+		if disabled == feature {
+			return false
+		}
+	}
+	for _, enabled := range s.EnabledFeatures {
+		if enabled == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+)
+
+// settingsFromJSON decodes settings the way they come back from a jsonb column
+func settingsFromJSON(t *testing.T, raw string) JSON {
+	t.Helper()
+
+	var settings JSON
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		t.Fatalf("invalid settings JSON: %v", err)
+	}
+	return settings
+}
+
+func TestTenant_EffectivePlanLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		plan           PlanType
+		settings       string
+		wantUsers      int
+		wantWorkspaces int
+		wantStorage    int64
+		wantErr        bool
+	}{
+		{
+			name:           "no overrides",
+			plan:           PlanTypeBasic,
+			settings:       `{"theme": "dark"}`,
+			wantUsers:      25,
+			wantWorkspaces: 10,
+			wantStorage:    1024 * 1024 * 1024,
+		},
+		{
+			name:           "higher custom max users",
+			plan:           PlanTypeProfessional,
+			settings:       `{"plan_limits": {"max_users": 250, "max_storage": 53687091200}}`,
+			wantUsers:      250,
+			wantWorkspaces: 50,
+			wantStorage:    50 * 1024 * 1024 * 1024,
+		},
+		{
+			name:           "override to unlimited",
+			plan:           PlanTypeFree,
+			settings:       `{"plan_limits": {"max_workspaces": -1}}`,
+			wantUsers:      5,
+			wantWorkspaces: PlanUnlimited,
+			wantStorage:    1024 * 1024 * 100,
+		},
+		{
+			name:           "unlimited stays unlimited",
+			plan:           PlanTypeEnterprise,
+			settings:       `{"plan_limits": {"max_users": 10}}`,
+			wantUsers:      PlanUnlimited,
+			wantWorkspaces: PlanUnlimited,
+			wantStorage:    PlanUnlimited,
+		},
+		{
+			name:           "invalid override falls back to plan defaults",
+			plan:           PlanTypeBasic,
+			settings:       `{"plan_limits": {"max_users": 250, "max_workspaces": 0}}`,
+			wantUsers:      25,
+			wantWorkspaces: 10,
+			wantStorage:    1024 * 1024 * 1024,
+			wantErr:        true,
+		},
+		{
+			name:           "fractional override",
+			plan:           PlanTypeBasic,
+			settings:       `{"plan_limits": {"max_users": 30.5}}`,
+			wantUsers:      25,
+			wantWorkspaces: 10,
+			wantStorage:    1024 * 1024 * 1024,
+			wantErr:        true,
+		},
+		{
+			name:           "unknown limit",
+			plan:           PlanTypeBasic,
+			settings:       `{"plan_limits": {"max_tables": 5}}`,
+			wantUsers:      25,
+			wantWorkspaces: 10,
+			wantStorage:    1024 * 1024 * 1024,
+			wantErr:        true,
+		},
+		{
+			name:           "overrides not an object",
+			plan:           PlanTypeBasic,
+			settings:       `{"plan_limits": "lots"}`,
+			wantUsers:      25,
+			wantWorkspaces: 10,
+			wantStorage:    1024 * 1024 * 1024,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := &Tenant{PlanType: tt.plan, Settings: settingsFromJSON(t, tt.settings)}
+
+			limits, err := tenant.EffectivePlanLimits()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("EffectivePlanLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !stderrors.Is(err, ErrInvalidPlanLimits) {
+				t.Errorf("expected ErrInvalidPlanLimits, got %v", err)
+			}
+			if limits.MaxUsers != tt.wantUsers || limits.MaxWorkspaces != tt.wantWorkspaces || limits.MaxStorage != tt.wantStorage {
+				t.Errorf("EffectivePlanLimits() = %+v", limits)
+			}
+		})
+	}
+}
+
+func TestTenant_CanInviteUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     PlanType
+		settings string
+		users    int
+		want     bool
+	}{
+		{name: "below plan limit", plan: PlanTypeFree, users: 4, want: true},
+		{name: "at plan limit", plan: PlanTypeFree, users: 5, want: false},
+		{name: "negotiated limit", plan: PlanTypeFree, settings: `{"plan_limits": {"max_users": 10}}`, users: 5, want: true},
+		{name: "unlimited", plan: PlanTypeEnterprise, users: 5000, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := &Tenant{PlanType: tt.plan, Users: make([]User, tt.users)}
+			if tt.settings != "" {
+				tenant.Settings = settingsFromJSON(t, tt.settings)
+			}
+
+			if got := tenant.CanInviteUser(); got != tt.want {
+				t.Errorf("CanInviteUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,14 @@ package models
 
 import (
 	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
 )
 
 // User represents a user in the system
 type User struct {
 	TenantModel
-	Email           string    `json:"email" gorm:"uniqueIndex;not null"`
+	Email           string    `json:"email" gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null"`
 	FirstName       string    `json:"first_name" gorm:"not null"`
 	LastName        string    `json:"last_name" gorm:"not null"`
 	PasswordHash    string    `json:"-" gorm:"not null"`
@@ -82,6 +84,27 @@ func (u *User) HasPermission(permissionName string) bool {
 	return false
 }
 
+// PermissionNames returns the names of the user's direct and role permissions,
+// without duplicates. Roles and their permissions must be preloaded.
+func (u *User) PermissionNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(permissions []Permission) {
+		for _, permission := range permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+
+	add(u.Permissions)
+	for _, role := range u.Roles {
+		add(role.Permissions)
+	}
+	return names
+}
+
 // UpdateLastLogin updates the last login timestamp and count
 func (u *User) UpdateLastLogin() {
 	now := time.Now()
@@ -118,7 +141,7 @@ type Permission struct {
 type Session struct {
 	BaseModel
 	UserID    uint      `json:"user_id" gorm:"index;not null"`
-	Token     string    `json:"token" gorm:"uniqueIndex;not null"`
+	Token     string    `json:"-" gorm:"uniqueIndex;not null"` // SHA-256 hash of the session token
 	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
 	IPAddress string    `json:"ip_address"`
 	UserAgent string    `json:"user_agent"`
@@ -214,10 +237,11 @@ type UserPreferences struct {
 // PasswordResetToken represents a password reset token
 type PasswordResetToken struct {
 	BaseModel
-	UserID    uint      `json:"user_id" gorm:"index;not null"`
-	Token     string    `json:"token" gorm:"uniqueIndex;not null"`
-	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
-	UsedAt    *time.Time `json:"used_at"`
+	UserID      uint       `json:"user_id" gorm:"index;not null"`
+	Token       string     `json:"-" gorm:"uniqueIndex;not null"`   // SHA-256 hash of the token sent to the user
+	TokenHashed bool       `json:"-" gorm:"not null;default:false"` // False only on rows written before tokens were hashed
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt      *time.Time `json:"used_at"`
 	
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -242,4 +266,44 @@ func (t *PasswordResetToken) IsValid() bool {
 func (t *PasswordResetToken) MarkAsUsed() {
 	now := time.Now()
 	t.UsedAt = &now
-}
\ No newline at end of file
+}
+
+// NewPasswordResetToken creates a reset token for userID valid for ttl, storing the
+// SHA-256 hash of the raw token sent to the user
+func NewPasswordResetToken(userID uint, token string, ttl time.Duration) *PasswordResetToken {
+	return &PasswordResetToken{
+		UserID:      userID,
+		Token:       utils.HashSHA256(token),
+		TokenHashed: true,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+}
+
+// EmailVerificationToken represents a token confirming a user's email address
+type EmailVerificationToken struct {
+	BaseModel
+	UserID      uint       `json:"user_id" gorm:"index;not null"`
+	Token       string     `json:"-" gorm:"uniqueIndex;not null"`   // SHA-256 hash of the token sent to the user
+	TokenHashed bool       `json:"-" gorm:"not null;default:false"` // False only on rows written before tokens were hashed
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt      *time.Time `json:"used_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// NewEmailVerificationToken creates a verification token for userID valid for ttl,
+// storing the SHA-256 hash of the raw token sent to the user
+func NewEmailVerificationToken(userID uint, token string, ttl time.Duration) *EmailVerificationToken {
+	return &EmailVerificationToken{
+		UserID:      userID,
+		Token:       utils.HashSHA256(token),
+		TokenHashed: true,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+}
+
+// IsValid checks if the token is unexpired and unused
+func (t *EmailVerificationToken) IsValid() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
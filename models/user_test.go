@@ -0,0 +1,24 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUserPermissionNames(t *testing.T) {
+	user := &User{
+		Permissions: []Permission{{Name: "records:export"}},
+		Roles: []Role{
+			{Name: "editor", Permissions: []Permission{{Name: "records:read"}, {Name: "records:write"}}},
+			{Name: "viewer", Permissions: []Permission{{Name: "records:read"}}},
+		},
+	}
+
+	want := []string{"records:export", "records:read", "records:write"}
+	if got := user.PermissionNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PermissionNames() = %v, want %v", got, want)
+	}
+	if got := (&User{}).PermissionNames(); len(got) != 0 {
+		t.Errorf("PermissionNames() without permissions = %v, want none", got)
+	}
+}
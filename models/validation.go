@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes why a single field failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors aggregates every field that failed validation
+type ValidationErrors []FieldError
+
+// Add appends a field error
+func (v *ValidationErrors) Add(field, message string) {
+	*v = append(*v, FieldError{Field: field, Message: message})
+}
+
+// Error implements the error interface
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, fieldErr := range v {
+		messages[i] = fieldErr.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Fields maps each failing field to its messages, e.g. for error response details
+func (v ValidationErrors) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(v))
+	for _, fieldErr := range v {
+		messages, _ := fields[fieldErr.Field].([]string)
+		fields[fieldErr.Field] = append(messages, fieldErr.Message)
+	}
+	return fields
+}
+
+// ErrOrNil returns v as an error, or nil when nothing failed
+func (v ValidationErrors) ErrOrNil() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
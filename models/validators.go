@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom binding tags registered by RegisterValidators
+const (
+	ValidatorSlug      = "slug"
+	ValidatorHexColor  = "hexcolor"
+	ValidatorTimezone  = "timezone"
+	ValidatorE164Phone = "e164phone"
+)
+
+// customValidators maps binding tags to their string checks
+var customValidators = map[string]func(string) bool{
+	ValidatorSlug:      utils.IsValidSlug,
+	ValidatorHexColor:  isNormalizableColor,
+	ValidatorTimezone:  utils.IsValidTimezone,
+	ValidatorE164Phone: utils.IsValidE164Phone,
+}
+
+// isNormalizableColor accepts the colors models normalize on save: #RGB, #RRGGBB
+// and CSS basic color names
+func isNormalizableColor(s string) bool {
+	_, err := utils.NormalizeHexColor(s)
+	return err == nil
+}
+
+var (
+	validatorsMu         sync.Mutex
+	registeredValidators = make(map[*validator.Validate]bool)
+)
+
+// RegisterValidators registers the slug, hexcolor, timezone and e164phone tags with
+// gin's validator engine so request structs can use them in binding tags, e.g.
+// `binding:"required,slug"`. The hexcolor and timezone tags replace the validator
+// built-ins of the same name. Call it once at startup, before serving requests;
+// later calls are no-ops for the same engine.
+func RegisterValidators() error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unsupported validator engine %T", binding.Validator.Engine())
+	}
+
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+
+	if registeredValidators[engine] {
+		return nil
+	}
+
+	for tag, valid := range customValidators {
+		valid := valid
+		err := engine.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			field := fl.Field()
+			return field.Kind() == reflect.String && valid(field.String())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register %q validator: %w", tag, err)
+		}
+	}
+
+	registeredValidators[engine] = true
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type validatedRequest struct {
+	Slug     string  `json:"slug" binding:"omitempty,slug"`
+	Color    string  `json:"color" binding:"omitempty,hexcolor"`
+	Timezone string  `json:"timezone" binding:"omitempty,timezone"`
+	Phone    *string `json:"phone" binding:"omitempty,e164phone"`
+}
+
+func TestRegisterValidators(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for i := 0; i < 2; i++ {
+		if err := RegisterValidators(); err != nil {
+			t.Fatalf("RegisterValidators() call %d error = %v", i+1, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "all valid", body: `{"slug":"my-table-2","color":"#1A2b3C","timezone":"Europe/Berlin","phone":"+14155552671"}`},
+		{name: "short color", body: `{"color":"#fff"}`},
+		{name: "utc", body: `{"timezone":"UTC"}`},
+		{name: "named color", body: `{"color":"Navy"}`},
+		{name: "empty fields are skipped", body: `{}`},
+		{name: "uppercase slug", body: `{"slug":"My-Table"}`, wantErr: "slug"},
+		{name: "double hyphen slug", body: `{"slug":"my--table"}`, wantErr: "slug"},
+		{name: "trailing hyphen slug", body: `{"slug":"table-"}`, wantErr: "slug"},
+		{name: "color without hash", body: `{"color":"ffffff"}`, wantErr: "hexcolor"},
+		{name: "color with alpha", body: `{"color":"#ffffff80"}`, wantErr: "hexcolor"},
+		{name: "unknown color name", body: `{"color":"chartreuse"}`, wantErr: "hexcolor"},
+		{name: "unknown timezone", body: `{"timezone":"Mars/Olympus"}`, wantErr: "timezone"},
+		{name: "local timezone", body: `{"timezone":"Local"}`, wantErr: "timezone"},
+		{name: "phone without plus", body: `{"phone":"14155552671"}`, wantErr: "e164phone"},
+		{name: "phone too long", body: `{"phone":"+1415555267112345"}`, wantErr: "e164phone"},
+		{name: "phone leading zero", body: `{"phone":"+0415555267"}`, wantErr: "e164phone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			var req validatedRequest
+			err := c.ShouldBindJSON(&req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ShouldBindJSON() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "'"+tt.wantErr+"' tag") {
+				t.Errorf("ShouldBindJSON() error = %v, want %s failure", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// Webhook event names
+const (
+	WebhookEventRecordCreated   = "record.created"
+	WebhookEventRecordUpdated   = "record.updated"
+	WebhookEventRecordDeleted   = "record.deleted"
+	WebhookEventTableCreated    = "table.created"
+	WebhookEventTableUpdated    = "table.updated"
+	WebhookEventTableDeleted    = "table.deleted"
+	WebhookEventFieldCreated    = "field.created"
+	WebhookEventFieldUpdated    = "field.updated"
+	WebhookEventFieldDeleted    = "field.deleted"
+	WebhookEventMemberAdded     = "workspace.member_added"
+	WebhookEventMemberRemoved   = "workspace.member_removed"
+	WebhookEventImportCompleted = "import.completed"
+)
+
+// MinWebhookSecretLength is the minimum length of a webhook signing secret
+const MinWebhookSecretLength = 16
+
+var (
+	webhookEventsMu sync.RWMutex
+	webhookEvents   = map[string]struct{}{
+		WebhookEventRecordCreated:   {},
+		WebhookEventRecordUpdated:   {},
+		WebhookEventRecordDeleted:   {},
+		WebhookEventTableCreated:    {},
+		WebhookEventTableUpdated:    {},
+		WebhookEventTableDeleted:    {},
+		WebhookEventFieldCreated:    {},
+		WebhookEventFieldUpdated:    {},
+		WebhookEventFieldDeleted:    {},
+		WebhookEventMemberAdded:     {},
+		WebhookEventMemberRemoved:   {},
+		WebhookEventImportCompleted: {},
+	}
+)
+
+// RegisterWebhookEvent adds a service-specific event to the known event registry
+func RegisterWebhookEvent(event string) {
+	webhookEventsMu.Lock()
+	defer webhookEventsMu.Unlock()
+	webhookEvents[event] = struct{}{}
+}
+
+// IsKnownWebhookEvent reports whether event is in the registry
+func IsKnownWebhookEvent(event string) bool {
+	webhookEventsMu.RLock()
+	defer webhookEventsMu.RUnlock()
+	_, ok := webhookEvents[event]
+	return ok
+}
+
+// WebhookEvents returns the registered event names in sorted order
+func WebhookEvents() []string {
+	webhookEventsMu.RLock()
+	defer webhookEventsMu.RUnlock()
+
+	events := make([]string, 0, len(webhookEvents))
+	for event := range webhookEvents {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	return events
+}
+
+// ValidateWebhookConfig checks a webhook before it is saved: the URL must be an
+// https URL with a host, every event must be registered and a secret, when set,
+// must be at least MinWebhookSecretLength characters. All problems are returned
+// together as ValidationErrors.
+func ValidateWebhookConfig(w WebhookConfig) error {
+	var errs ValidationErrors
+
+	if w.URL == "" {
+		errs.Add("url", "is required")
+	} else if parsed, err := url.Parse(w.URL); err != nil || !utils.IsValidURL(w.URL) || parsed.Hostname() == "" {
+		errs.Add("url", "must be a valid URL")
+	} else if parsed.Scheme != "https" {
+		errs.Add("url", "must use https")
+	}
+
+	if len(w.Events) == 0 {
+		errs.Add("events", "at least one event is required")
+	}
+	for _, event := range w.Events {
+		if !IsKnownWebhookEvent(event) {
+			errs.Add("events", fmt.Sprintf("unknown event %q", event))
+		}
+	}
+
+	if w.Secret != "" && len(strings.TrimSpace(w.Secret)) < MinWebhookSecretLength {
+		errs.Add("secret", fmt.Sprintf("must be at least %d characters", MinWebhookSecretLength))
+	}
+
+	return errs.ErrOrNil()
+}
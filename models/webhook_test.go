@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateWebhookConfig(t *testing.T) {
+	valid := WebhookConfig{
+		URL:     "https://hooks.example.com/pyairtable",
+		Events:  []string{WebhookEventRecordCreated, WebhookEventRecordUpdated},
+		Secret:  "0123456789abcdef",
+		Enabled: true,
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(w *WebhookConfig)
+		wantFields []string
+	}{
+		{name: "valid config", modify: func(w *WebhookConfig) {}},
+		{name: "valid without secret", modify: func(w *WebhookConfig) { w.Secret = "" }},
+		{name: "invalid url", modify: func(w *WebhookConfig) { w.URL = "not a url" }, wantFields: []string{"url"}},
+		{name: "plain http", modify: func(w *WebhookConfig) { w.URL = "http://hooks.example.com" }, wantFields: []string{"url"}},
+		{name: "missing url", modify: func(w *WebhookConfig) { w.URL = "" }, wantFields: []string{"url"}},
+		{name: "unknown event", modify: func(w *WebhookConfig) { w.Events = []string{"record.created", "record.exploded"} }, wantFields: []string{"events"}},
+		{name: "no events", modify: func(w *WebhookConfig) { w.Events = nil }, wantFields: []string{"events"}},
+		{name: "short secret", modify: func(w *WebhookConfig) { w.Secret = "short" }, wantFields: []string{"secret"}},
+		{
+			name: "errors are aggregated",
+			modify: func(w *WebhookConfig) {
+				w.URL = "ftp://example.com"
+				w.Events = []string{"nope"}
+				w.Secret = "short"
+			},
+			wantFields: []string{"url", "events", "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+
+			err := ValidateWebhookConfig(config)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateWebhookConfig() error = %v", err)
+				}
+				return
+			}
+
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("expected ValidationErrors, got %T (%v)", err, err)
+			}
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("failed fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRegisterWebhookEvent(t *testing.T) {
+	const event = "automation.triggered"
+	if IsKnownWebhookEvent(event) {
+		t.Fatalf("%q should not be registered yet", event)
+	}
+
+	RegisterWebhookEvent(event)
+
+	config := WebhookConfig{URL: "https://hooks.example.com", Events: []string{event}}
+	if err := ValidateWebhookConfig(config); err != nil {
+		t.Errorf("registered event rejected: %v", err)
+	}
+}
@@ -2,13 +2,17 @@ package models
 
 import (
 	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"gorm.io/gorm"
 )
 
 // Workspace represents a workspace in the system
 type Workspace struct {
 	TenantModel
 	Name        string    `json:"name" gorm:"not null"`
-	Slug        string    `json:"slug" gorm:"not null"`
+	Slug        string    `json:"slug" gorm:"not null;uniqueIndex:idx_workspace_tenant_slug,expression:tenant_id\\,slug,where:deleted_at IS NULL"` // Unique per tenant; TenantID is embedded, so the index lists both columns
 	Description string    `json:"description"`
 	Color       string    `json:"color" gorm:"default:'#3B82F6'"`
 	Icon        string    `json:"icon"`
@@ -39,6 +43,11 @@ func (w *Workspace) GetTableCount() int {
 	return len(w.Tables)
 }
 
+// BeforeSave normalizes Color to lowercase #rrggbb and rejects invalid colors
+func (w *Workspace) BeforeSave(tx *gorm.DB) error {
+	return normalizeColor(&w.Color)
+}
+
 // HasMember checks if a user is a member of the workspace
 func (w *Workspace) HasMember(userID uint) bool {
 	for _, member := range w.Members {
@@ -103,8 +112,8 @@ func (r WorkspaceRole) CanManageMembers() bool {
 // WorkspaceMember represents a member of a workspace
 type WorkspaceMember struct {
 	BaseModel
-	WorkspaceID uint          `json:"workspace_id" gorm:"index;not null"`
-	UserID      uint          `json:"user_id" gorm:"index;not null"`
+	WorkspaceID uint          `json:"workspace_id" gorm:"index;not null;uniqueIndex:idx_workspace_member_user"`
+	UserID      uint          `json:"user_id" gorm:"index;not null;uniqueIndex:idx_workspace_member_user"`
 	Role        WorkspaceRole `json:"role" gorm:"not null"`
 	JoinedAt    time.Time     `json:"joined_at" gorm:"default:CURRENT_TIMESTAMP"`
 	
@@ -119,7 +128,8 @@ type WorkspaceInvitation struct {
 	WorkspaceID uint          `json:"workspace_id" gorm:"index;not null"`
 	Email       string        `json:"email" gorm:"not null"`
 	Role        WorkspaceRole `json:"role" gorm:"not null"`
-	Token       string        `json:"token" gorm:"uniqueIndex;not null"`
+	Token       string        `json:"-" gorm:"uniqueIndex;not null"`   // SHA-256 hash of the token sent to the invitee
+	TokenHashed bool          `json:"-" gorm:"not null;default:false"` // False only on rows written before tokens were hashed
 	InvitedBy   uint          `json:"invited_by" gorm:"not null"`
 	ExpiresAt   time.Time     `json:"expires_at" gorm:"not null"`
 	AcceptedAt  *time.Time    `json:"accepted_at"`
@@ -129,6 +139,20 @@ type WorkspaceInvitation struct {
 	InvitedByUser User      `json:"invited_by_user,omitempty" gorm:"foreignKey:InvitedBy"`
 }
 
+// NewWorkspaceInvitation creates an invitation to workspaceID valid for ttl,
+// storing the SHA-256 hash of the raw token sent to the invitee
+func NewWorkspaceInvitation(workspaceID uint, email string, role WorkspaceRole, invitedBy uint, token string, ttl time.Duration) *WorkspaceInvitation {
+	return &WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		Token:       utils.HashSHA256(token),
+		TokenHashed: true,
+		InvitedBy:   invitedBy,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+}
+
 // IsExpired checks if the invitation is expired
 func (i *WorkspaceInvitation) IsExpired() bool {
 	return time.Now().After(i.ExpiresAt)
@@ -153,9 +177,9 @@ func (i *WorkspaceInvitation) Accept() {
 // Table represents a table within a workspace
 type Table struct {
 	TenantModel
-	WorkspaceID uint   `json:"workspace_id" gorm:"index;not null"`
+	WorkspaceID uint   `json:"workspace_id" gorm:"index;not null;uniqueIndex:idx_table_workspace_slug,where:deleted_at IS NULL"`
 	Name        string `json:"name" gorm:"not null"`
-	Slug        string `json:"slug" gorm:"not null"`
+	Slug        string `json:"slug" gorm:"not null;uniqueIndex:idx_table_workspace_slug,where:deleted_at IS NULL"`
 	Description string `json:"description"`
 	Color       string `json:"color" gorm:"default:'#10B981'"`
 	Icon        string `json:"icon"`
@@ -188,11 +212,30 @@ func (t *Table) GetViewCount() int {
 	return len(t.Views)
 }
 
+// BeforeSave normalizes Color to lowercase #rrggbb and rejects invalid colors
+func (t *Table) BeforeSave(tx *gorm.DB) error {
+	return normalizeColor(&t.Color)
+}
+
+// normalizeColor rewrites a non-empty color with utils.NormalizeHexColor; an empty
+// color is left for the column default
+func normalizeColor(color *string) error {
+	if *color == "" {
+		return nil
+	}
+	normalized, err := utils.NormalizeHexColor(*color)
+	if err != nil {
+		return errors.NewInvalidInputError("color", err.Error())
+	}
+	*color = normalized
+	return nil
+}
+
 // Field represents a field/column in a table
 type Field struct {
 	BaseModel
-	TableID     uint      `json:"table_id" gorm:"index;not null"`
-	Name        string    `json:"name" gorm:"not null"`
+	TableID     uint      `json:"table_id" gorm:"index;not null;uniqueIndex:idx_field_table_name"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex:idx_field_table_name"`
 	Type        FieldType `json:"type" gorm:"not null"`
 	Description string    `json:"description"`
 	Required    bool      `json:"required" gorm:"default:false"`
@@ -264,7 +307,7 @@ const (
 type Record struct {
 	BaseModel
 	TableID uint                   `json:"table_id" gorm:"index;not null"`
-	Data    map[string]interface{} `json:"data" gorm:"type:jsonb"`
+	Data    JSON                   `json:"data" gorm:"type:jsonb"`
 	
 	// Relationships
 	Table Table `json:"table,omitempty" gorm:"foreignKey:TableID"`
@@ -278,6 +321,21 @@ func (r *Record) GetFieldValue(fieldName string) interface{} {
 	return r.Data[fieldName]
 }
 
+// Checksum returns a SHA-256 hex digest of the canonical JSON of Data, so records
+// with the same data have the same checksum whatever their key order. A nil Data
+// hashes like an empty one. It returns "" if Data can't be encoded as JSON.
+func (r *Record) Checksum() string {
+	data := r.Data
+	if data == nil {
+		data = JSON{}
+	}
+	canonical, err := utils.CanonicalJSON(data)
+	if err != nil {
+		return ""
+	}
+	return utils.HashSHA256Bytes(canonical)
+}
+
 // SetFieldValue sets the value of a specific field
 func (r *Record) SetFieldValue(fieldName string, value interface{}) {
 	if r.Data == nil {
@@ -286,6 +344,41 @@ func (r *Record) SetFieldValue(fieldName string, value interface{}) {
 	r.Data[fieldName] = value
 }
 
+// GetString returns the string value of a field
+func (r *Record) GetString(fieldName string) (string, bool) {
+	return r.Data.GetString(fieldName)
+}
+
+// GetInt64 returns the integer value of a field. Numbers decoded from jsonb as
+// float64 are accepted when they have no fractional part.
+func (r *Record) GetInt64(fieldName string) (int64, bool) {
+	return r.Data.GetInt(fieldName)
+}
+
+// GetFloat64 returns the numeric value of a field
+func (r *Record) GetFloat64(fieldName string) (float64, bool) {
+	return r.Data.GetFloat(fieldName)
+}
+
+// GetBool returns the boolean value of a field
+func (r *Record) GetBool(fieldName string) (bool, bool) {
+	return r.Data.GetBool(fieldName)
+}
+
+// GetTime returns the time value of a field, parsing strings in the date and
+// date/time formats accepted by CoerceValue
+func (r *Record) GetTime(fieldName string) (time.Time, bool) {
+	switch v := r.Data[fieldName].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := parseTime(v, nil, append(dateTimeLayouts, dateLayouts...))
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
 // WorkspaceSettings represents workspace-specific settings
 type WorkspaceSettings struct {
 	Theme           string                 `json:"theme"`
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestColorNormalizationHooks(t *testing.T) {
+	tests := []struct {
+		color   string
+		want    string
+		wantErr bool
+	}{
+		{color: "", want: ""},
+		{color: "#3B82F6", want: "#3b82f6"},
+		{color: "#0f0", want: "#00ff00"},
+		{color: "teal", want: "#008080"},
+		{color: "not-a-color", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			workspace := &Workspace{Color: tt.color}
+			table := &Table{Color: tt.color}
+
+			for name, save := range map[string]func() (string, error){
+				"workspace": func() (string, error) { err := workspace.BeforeSave(nil); return workspace.Color, err },
+				"table":     func() (string, error) { err := table.BeforeSave(nil); return table.Color, err },
+			} {
+				got, err := save()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("%s BeforeSave() error = %v, wantErr %v", name, err, tt.wantErr)
+				}
+				if !tt.wantErr && got != tt.want {
+					t.Errorf("%s color = %q, want %q", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordChecksum(t *testing.T) {
+	var a, b JSON
+	if err := a.Scan(`{"Name":"Ada","Tags":["x","y"],"Score":1.0,"Meta":{"z":1,"a":2}}`); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if err := b.Scan(`{"Meta":{"a":2,"z":1},"Score":1,"Tags":["x","y"],"Name":"Ada"}`); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	base := (&Record{Data: a}).Checksum()
+	if len(base) != 64 {
+		t.Fatalf("Checksum() = %q, want a SHA-256 hex digest", base)
+	}
+	if got := (&Record{Data: b}).Checksum(); got != base {
+		t.Errorf("reordered data checksum = %s, want %s", got, base)
+	}
+
+	for name, data := range map[string]JSON{
+		"changed value":  {"Name": "Ada", "Tags": []interface{}{"x", "y"}, "Score": 2, "Meta": JSON{"z": 1, "a": 2}},
+		"reordered list": {"Name": "Ada", "Tags": []interface{}{"y", "x"}, "Score": 1, "Meta": JSON{"z": 1, "a": 2}},
+		"missing field":  {"Name": "Ada", "Tags": []interface{}{"x", "y"}, "Score": 1},
+		"number as text": {"Name": "Ada", "Tags": []interface{}{"x", "y"}, "Score": "1", "Meta": JSON{"z": 1, "a": 2}},
+	} {
+		if got := (&Record{Data: data}).Checksum(); got == base {
+			t.Errorf("%s: checksum should differ", name)
+		}
+	}
+
+	if (&Record{}).Checksum() != (&Record{Data: JSON{}}).Checksum() {
+		t.Error("nil and empty data should have the same checksum")
+	}
+}
+
+func TestRecordTypedAccessors(t *testing.T) {
+	var data JSON
+	raw := `{"name": "Launch", "count": 3, "ratio": 0.5, "done": true, "due": "2024-03-15", "at": "2024-03-15T10:30:00Z"}`
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatalf("failed to decode data: %v", err)
+	}
+	data["created"] = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	record := &Record{Data: data}
+
+	t.Run("GetString", func(t *testing.T) {
+		if got, ok := record.GetString("name"); !ok || got != "Launch" {
+			t.Errorf("GetString(name) = %q, %v", got, ok)
+		}
+		if _, ok := record.GetString("count"); ok {
+			t.Error("GetString(count) should not match a number")
+		}
+		if _, ok := record.GetString("missing"); ok {
+			t.Error("GetString(missing) should not match")
+		}
+	})
+
+	t.Run("GetInt64", func(t *testing.T) {
+		if got, ok := record.GetInt64("count"); !ok || got != 3 {
+			t.Errorf("GetInt64(count) = %d, %v", got, ok)
+		}
+		if _, ok := record.GetInt64("ratio"); ok {
+			t.Error("GetInt64(ratio) should not match a fraction")
+		}
+		if _, ok := record.GetInt64("name"); ok {
+			t.Error("GetInt64(name) should not match a string")
+		}
+		if _, ok := record.GetInt64("missing"); ok {
+			t.Error("GetInt64(missing) should not match")
+		}
+	})
+
+	t.Run("GetFloat64", func(t *testing.T) {
+		if got, ok := record.GetFloat64("ratio"); !ok || got != 0.5 {
+			t.Errorf("GetFloat64(ratio) = %v, %v", got, ok)
+		}
+		if got, ok := record.GetFloat64("count"); !ok || got != 3 {
+			t.Errorf("GetFloat64(count) = %v, %v", got, ok)
+		}
+		if _, ok := record.GetFloat64("done"); ok {
+			t.Error("GetFloat64(done) should not match a boolean")
+		}
+		if _, ok := record.GetFloat64("missing"); ok {
+			t.Error("GetFloat64(missing) should not match")
+		}
+	})
+
+	t.Run("GetBool", func(t *testing.T) {
+		if got, ok := record.GetBool("done"); !ok || !got {
+			t.Errorf("GetBool(done) = %v, %v", got, ok)
+		}
+		if _, ok := record.GetBool("name"); ok {
+			t.Error("GetBool(name) should not match a string")
+		}
+		if _, ok := record.GetBool("missing"); ok {
+			t.Error("GetBool(missing) should not match")
+		}
+	})
+
+	t.Run("GetTime", func(t *testing.T) {
+		tests := []struct {
+			field string
+			want  time.Time
+		}{
+			{field: "due", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+			{field: "at", want: time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)},
+			{field: "created", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}
+		for _, tt := range tests {
+			if got, ok := record.GetTime(tt.field); !ok || !got.Equal(tt.want) {
+				t.Errorf("GetTime(%s) = %v, %v, want %v", tt.field, got, ok, tt.want)
+			}
+		}
+		for _, field := range []string{"name", "count", "missing"} {
+			if _, ok := record.GetTime(field); ok {
+				t.Errorf("GetTime(%s) should not match", field)
+			}
+		}
+	})
+
+	var empty Record
+	if _, ok := empty.GetString("name"); ok {
+		t.Error("accessors on a record without data should not match")
+	}
+}
@@ -0,0 +1,145 @@
+package response
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultSourceTimeout bounds a Source that doesn't set its own Timeout
+const DefaultSourceTimeout = 5 * time.Second
+
+// Source is one fetch contributing to an aggregate response
+type Source struct {
+	Name    string
+	Timeout time.Duration
+	// Required sources fail the whole request instead of degrading it
+	Required bool
+	Fetch    func(ctx context.Context) (interface{}, error)
+}
+
+// Aggregate holds the results of Gather: the data of every healthy source keyed by
+// name, and a warning for each optional source that failed or timed out
+type Aggregate struct {
+	Data     map[string]interface{}
+	Warnings []models.Warning
+	err      error
+}
+
+// Err returns the error of the first failed required source, if any
+func (a *Aggregate) Err() error {
+	return a.err
+}
+
+// Gather runs every source concurrently, each bounded by its timeout, and waits
+// for all of them. A source that ignores its context is abandoned once its
+// timeout passes. Warnings and Err follow the order of sources.
+func Gather(ctx context.Context, sources ...Source) *Aggregate {
+	type outcome struct {
+		data interface{}
+		err  error
+	}
+	outcomes := make([]outcome, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			outcomes[i].data, outcomes[i].err = fetchSource(ctx, source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	agg := &Aggregate{Data: make(map[string]interface{}, len(sources))}
+	for i, source := range sources {
+		if outcomes[i].err == nil {
+			agg.Data[source.Name] = outcomes[i].data
+			continue
+		}
+
+		sourceErr := sourceError(source.Name, outcomes[i].err)
+		if source.Required {
+			if agg.err == nil {
+				agg.err = sourceErr
+			}
+			continue
+		}
+		agg.Warnings = append(agg.Warnings, models.Warning{
+			Source:  source.Name,
+			Code:    sourceErr.Code,
+			Message: sourceErr.Message,
+		})
+	}
+	return agg
+}
+
+// RespondAggregate writes the data of agg with its warnings in the response meta,
+// or the error of a failed required source
+func RespondAggregate(c *gin.Context, agg *Aggregate, opts ...Option) {
+	if agg.err != nil {
+		RespondError(c, agg.err)
+		return
+	}
+
+	resp := models.NewSuccessResponse(agg.Data)
+	requestID := logger.RequestIDFromContext(c.Request.Context())
+	if requestID != "" || len(agg.Warnings) > 0 {
+		resp.Meta = &models.APIMeta{RequestID: requestID, Warnings: agg.Warnings}
+	}
+	RespondJSON(c, http.StatusOK, resp, opts...)
+}
+
+// fetchSource runs source.Fetch under its timeout, recovering panics
+func fetchSource(ctx context.Context, source Source) (interface{}, error) {
+	timeout := source.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSourceTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		data interface{}
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		data, err := source.Fetch(ctx)
+		done <- result{data: data, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sourceError converts a fetch failure to a shared error. Shared errors are kept;
+// anything else becomes a timeout or unavailable error so details don't leak.
+func sourceError(name string, err error) *errors.Error {
+	var appErr *errors.Error
+	switch {
+	case stderrors.As(err, &appErr):
+		return appErr
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return errors.NewTimeoutError(name).WithCause(err)
+	default:
+		return errors.NewServiceUnavailableError(name).WithCause(err)
+	}
+}
@@ -0,0 +1,109 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+)
+
+func TestGather(t *testing.T) {
+	value := func(v interface{}) func(context.Context) (interface{}, error) {
+		return func(context.Context) (interface{}, error) { return v, nil }
+	}
+	hang := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	ignoreContext := func(context.Context) (interface{}, error) {
+		time.Sleep(time.Second)
+		return "late", nil
+	}
+
+	start := time.Now()
+	agg := Gather(context.Background(),
+		Source{Name: "records", Required: true, Fetch: value([]int{1, 2})},
+		Source{Name: "stats", Fetch: value(map[string]int{"views": 3})},
+		Source{Name: "billing", Fetch: func(context.Context) (interface{}, error) { return nil, stderrors.New("dial tcp: refused") }},
+		Source{Name: "search", Timeout: 20 * time.Millisecond, Fetch: hang},
+		Source{Name: "crm", Timeout: 20 * time.Millisecond, Fetch: ignoreContext},
+		Source{Name: "quota", Fetch: func(context.Context) (interface{}, error) { return nil, errors.NewQuotaExceededError("api_calls", 10) }},
+		Source{Name: "avatars", Fetch: func(context.Context) (interface{}, error) { panic("boom") }},
+	)
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Gather() took %v, expected slow sources to be abandoned at their timeout", elapsed)
+	}
+	if agg.Err() != nil {
+		t.Fatalf("Err() = %v, want nil", agg.Err())
+	}
+	if len(agg.Data) != 2 || agg.Data["records"] == nil || agg.Data["stats"] == nil {
+		t.Errorf("Data = %v, want records and stats", agg.Data)
+	}
+
+	want := []models.Warning{
+		{Source: "billing", Code: errors.ErrCodeServiceUnavailable},
+		{Source: "search", Code: errors.ErrCodeTimeout},
+		{Source: "crm", Code: errors.ErrCodeTimeout},
+		{Source: "quota", Code: errors.ErrCodeQuotaExceeded},
+		{Source: "avatars", Code: errors.ErrCodeServiceUnavailable},
+	}
+	if len(agg.Warnings) != len(want) {
+		t.Fatalf("Warnings = %+v, want %+v", agg.Warnings, want)
+	}
+	for i, warning := range agg.Warnings {
+		if warning.Source != want[i].Source || warning.Code != want[i].Code || warning.Message == "" {
+			t.Errorf("Warnings[%d] = %+v, want %+v", i, warning, want[i])
+		}
+	}
+}
+
+func TestRespondAggregate(t *testing.T) {
+	failing := func(context.Context) (interface{}, error) { return nil, stderrors.New("connection reset") }
+
+	tests := []struct {
+		name         string
+		required     bool
+		wantStatus   int
+		wantWarnings int
+	}{
+		{name: "optional source degrades", wantStatus: http.StatusOK, wantWarnings: 1},
+		{name: "required source fails the request", required: true, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newResponseContext("req-1")
+			agg := Gather(c.Request.Context(),
+				Source{Name: "table", Fetch: func(context.Context) (interface{}, error) { return "ok", nil }},
+				Source{Name: "activity", Required: tt.required, Fetch: failing},
+			)
+
+			RespondAggregate(c, agg)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			data, _ := resp.Data.(map[string]interface{})
+			if data["table"] != "ok" {
+				t.Errorf("data = %v, want the healthy source", resp.Data)
+			}
+			if resp.Meta == nil || resp.Meta.RequestID != "req-1" || len(resp.Meta.Warnings) != tt.wantWarnings {
+				t.Errorf("meta = %+v, want request ID and %d warnings", resp.Meta, tt.wantWarnings)
+			}
+		})
+	}
+}
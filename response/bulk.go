@@ -0,0 +1,105 @@
+// Package response provides helpers for writing standard API responses from gin handlers
+package response
+
+import (
+	stderrors "errors"
+	"net/http"
+	"sort"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// BulkBuilder accumulates per-item results of a bulk operation by request index
+type BulkBuilder struct {
+	success []models.BulkResult
+	failed  []models.BulkResult
+}
+
+// NewBulkBuilder creates an empty bulk result builder
+func NewBulkBuilder() *BulkBuilder {
+	return &BulkBuilder{
+		success: []models.BulkResult{},
+		failed:  []models.BulkResult{},
+	}
+}
+
+// Succeeded records that the item at index was processed
+func (b *BulkBuilder) Succeeded(index int, id, data interface{}) *BulkBuilder {
+	b.success = append(b.success, models.BulkResult{Index: index, ID: id, Data: data})
+	return b
+}
+
+// Failed records that the item at index was rejected with err. Shared errors keep
+// their code and HTTP status; other errors are reported as internal errors.
+func (b *BulkBuilder) Failed(index int, err error) *BulkBuilder {
+	result := models.BulkResult{
+		Index:  index,
+		Code:   errors.ErrCodeInternalError,
+		Status: http.StatusInternalServerError,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	var customErr *errors.Error
+	if stderrors.As(err, &customErr) {
+		result.Error = customErr.Message
+		result.Code = customErr.Code
+		result.Status = customErr.HTTPCode
+	}
+
+	b.failed = append(b.failed, result)
+	return b
+}
+
+// Build returns the BulkResponse with results ordered by index and counts filled in
+func (b *BulkBuilder) Build() *models.BulkResponse {
+	success := append([]models.BulkResult{}, b.success...)
+	failed := append([]models.BulkResult{}, b.failed...)
+	sort.SliceStable(success, func(i, j int) bool { return success[i].Index < success[j].Index })
+	sort.SliceStable(failed, func(i, j int) bool { return failed[i].Index < failed[j].Index })
+
+	return &models.BulkResponse{
+		Success:      success,
+		Failed:       failed,
+		TotalCount:   len(success) + len(failed),
+		SuccessCount: len(success),
+		FailedCount:  len(failed),
+	}
+}
+
+// BulkStatus picks the HTTP status for a bulk operation:
+//   - 200 when every item succeeded (including an empty batch)
+//   - 207 Multi-Status when some items succeeded and some failed
+//   - 400 when every item failed as a malformed request
+//   - 422 when every item failed for any other reason
+func BulkStatus(bulk *models.BulkResponse) int {
+	switch {
+	case bulk.FailedCount == 0:
+		return http.StatusOK
+	case bulk.SuccessCount > 0:
+		return http.StatusMultiStatus
+	}
+
+	for _, result := range bulk.Failed {
+		if result.Status != http.StatusBadRequest {
+			return http.StatusUnprocessableEntity
+		}
+	}
+	return http.StatusBadRequest
+}
+
+// Bulk writes bulk with the status chosen by BulkStatus, wrapped in the standard
+// APIResponse envelope with the request ID in its meta like RespondSuccess.
+// Success is only true when no item failed.
+func Bulk(c *gin.Context, bulk *models.BulkResponse, opts ...Option) {
+	resp := models.NewSuccessResponse(bulk)
+	resp.Success = bulk.FailedCount == 0
+	if requestID := logger.RequestIDFromContext(c.Request.Context()); requestID != "" {
+		resp.Meta = &models.APIMeta{RequestID: requestID}
+	}
+	RespondJSON(c, BulkStatus(bulk), resp, opts...)
+}
@@ -0,0 +1,140 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBulk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		build       func(b *BulkBuilder)
+		wantStatus  int
+		wantSuccess bool
+		wantCounts  [3]int // total, success, failed
+	}{
+		{
+			name: "all succeeded",
+			build: func(b *BulkBuilder) {
+				b.Succeeded(0, 1, nil).Succeeded(1, 2, nil)
+			},
+			wantStatus:  http.StatusOK,
+			wantSuccess: true,
+			wantCounts:  [3]int{2, 2, 0},
+		},
+		{
+			name:        "empty batch",
+			build:       func(b *BulkBuilder) {},
+			wantStatus:  http.StatusOK,
+			wantSuccess: true,
+		},
+		{
+			name: "partial failure",
+			build: func(b *BulkBuilder) {
+				b.Succeeded(0, 1, nil).Failed(1, errors.NewAlreadyExistsError("Record"))
+			},
+			wantStatus: http.StatusMultiStatus,
+			wantCounts: [3]int{2, 1, 1},
+		},
+		{
+			name: "all failed validation",
+			build: func(b *BulkBuilder) {
+				b.Failed(0, errors.NewMissingFieldError("name")).Failed(1, errors.NewInvalidInputError("email", "invalid"))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCounts: [3]int{2, 0, 2},
+		},
+		{
+			name: "all failed with conflicts",
+			build: func(b *BulkBuilder) {
+				b.Failed(0, errors.NewMissingFieldError("name")).Failed(1, errors.NewConflictError("locked"))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCounts: [3]int{2, 0, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewBulkBuilder()
+			tt.build(builder)
+
+			c, w := newResponseContext("")
+			Bulk(c, builder.Build())
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var body struct {
+				Success bool                `json:"success"`
+				Data    models.BulkResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if body.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v", body.Success, tt.wantSuccess)
+			}
+			got := [3]int{body.Data.TotalCount, body.Data.SuccessCount, body.Data.FailedCount}
+			if got != tt.wantCounts {
+				t.Errorf("counts = %v, want %v", got, tt.wantCounts)
+			}
+		})
+	}
+}
+
+func TestBulk_RequestIDAndOptions(t *testing.T) {
+	c, w := newResponseContext("req_abc")
+
+	Bulk(c, NewBulkBuilder().Succeeded(0, 1, nil).Failed(1, errors.NewValidationError("bad", nil)).Build(), WithCacheControl("no-store"))
+
+	if w.Code != http.StatusMultiStatus {
+		t.Errorf("status = %d, want 207", w.Code)
+	}
+	if got := w.Header().Get("X-Request-ID"); got != "req_abc" {
+		t.Errorf("X-Request-ID = %q, want req_abc", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	var body models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body.Meta == nil || body.Meta.RequestID != "req_abc" {
+		t.Errorf("meta = %+v, want the request ID", body.Meta)
+	}
+}
+
+func TestBulkBuilder_OrdersByIndexAndKeepsErrorDetails(t *testing.T) {
+	bulk := NewBulkBuilder().
+		Failed(3, errors.NewNotFoundError("Record")).
+		Succeeded(2, "rec2", nil).
+		Failed(1, fmt.Errorf("connection reset")).
+		Succeeded(0, "rec0", nil).
+		Build()
+
+	if bulk.Success[0].Index != 0 || bulk.Success[1].Index != 2 {
+		t.Errorf("success not ordered by index: %+v", bulk.Success)
+	}
+	if bulk.Failed[0].Index != 1 || bulk.Failed[1].Index != 3 {
+		t.Errorf("failed not ordered by index: %+v", bulk.Failed)
+	}
+
+	if got := bulk.Failed[0]; got.Code != errors.ErrCodeInternalError || got.Status != http.StatusInternalServerError || got.Error != "connection reset" {
+		t.Errorf("unexpected result for plain error: %+v", got)
+	}
+	if got := bulk.Failed[1]; got.Code != errors.ErrCodeNotFound || got.Status != http.StatusNotFound {
+		t.Errorf("unexpected result for shared error: %+v", got)
+	}
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	stderrors "errors"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RespondError writes err as an ErrorResponse with the status from errors.GetHTTPCode
+// and aborts the chain. Use it instead of c.JSON(status, err) so the status can't
+// drift from the error code. Errors that aren't *errors.Error become a generic
+// internal error so details don't leak to clients.
+func RespondError(c *gin.Context, err error) {
+	var appErr *errors.Error
+	if !stderrors.As(err, &appErr) {
+		appErr = errors.NewInternalError("Internal server error").WithCause(err)
+	}
+
+	writeHeaders(c, nil)
+	requestID := logger.RequestIDFromContext(c.Request.Context())
+	c.AbortWithStatusJSON(errors.GetHTTPCode(appErr), errors.NewErrorResponse(appErr, requestID))
+}
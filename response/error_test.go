@@ -0,0 +1,159 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondError_StatusMatchesCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		err        *errors.Error
+		wantStatus int
+	}{
+		{errors.NewUnauthorizedError("no"), http.StatusUnauthorized},
+		{errors.NewInvalidCredentialsError(), http.StatusUnauthorized},
+		{errors.NewTokenExpiredError(), http.StatusUnauthorized},
+		{errors.NewTokenInvalidError(), http.StatusUnauthorized},
+		{errors.NewForbiddenError("no"), http.StatusForbidden},
+		{errors.NewInsufficientScopeError("records:write"), http.StatusForbidden},
+		{errors.NewValidationError("invalid", nil), http.StatusBadRequest},
+		{errors.NewInvalidInputError("name", "too long"), http.StatusBadRequest},
+		{errors.NewMissingFieldError("name"), http.StatusBadRequest},
+		{errors.NewInvalidReferenceError("table_id"), http.StatusBadRequest},
+		{errors.NewPayloadTooLargeError("too big", 1024), http.StatusRequestEntityTooLarge},
+		{errors.NewUnsupportedMediaTypeError("text/html", []string{"text/csv"}), http.StatusUnsupportedMediaType},
+		{errors.NewNotFoundError("Workspace"), http.StatusNotFound},
+		{errors.NewAlreadyExistsError("Workspace"), http.StatusConflict},
+		{errors.NewConflictError("locked"), http.StatusConflict},
+		{errors.NewBusinessRuleError("plan", "upgrade required"), http.StatusBadRequest},
+		{errors.NewQuotaExceededError("records", 1000), http.StatusTooManyRequests},
+		{errors.NewRateLimitedError(30), http.StatusTooManyRequests},
+		{errors.NewInternalError("boom"), http.StatusInternalServerError},
+		{errors.NewServiceUnavailableError("airtable"), http.StatusServiceUnavailable},
+		{errors.NewTimeoutError("sync"), http.StatusRequestTimeout},
+		{errors.NewDatabaseError("insert", nil), http.StatusInternalServerError},
+		{errors.NewCacheError("get", nil), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Code, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), "req_123"))
+
+			RespondError(c, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !c.IsAborted() {
+				t.Error("expected the chain to be aborted")
+			}
+
+			var body errors.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if body.Error == nil || body.Error.Code != tt.err.Code || body.RequestID != "req_123" {
+				t.Errorf("unexpected body: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRespondError_HidesUnknownErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	RespondError(c, fmt.Errorf("dial tcp 10.0.0.5:5432: connection refused"))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "10.0.0.5") {
+		t.Errorf("internal error details leaked: %s", w.Body.String())
+	}
+}
+
+// TestNoRawErrorRendering fails when code renders a shared error with c.JSON or
+// similar and a hand-picked status instead of RespondError
+func TestNoRawErrorRendering(t *testing.T) {
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && d.Name() != ".." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "JSON" && sel.Sel.Name != "AbortWithStatusJSON") {
+				return true
+			}
+			if constructor := sharedErrorConstructor(call.Args[1]); constructor != "" {
+				t.Errorf("%s: renders errors.%s directly; use response.RespondError", fset.Position(call.Pos()), constructor)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to scan sources: %v", err)
+	}
+}
+
+// sharedErrorConstructor returns the errors.New* constructor expr is built from,
+// following chained calls such as errors.NewInternalError(...).WithCause(err)
+func sharedErrorConstructor(expr ast.Expr) string {
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return ""
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return ""
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "errors" {
+			if strings.HasPrefix(sel.Sel.Name, "New") && sel.Sel.Name != "NewErrorResponse" {
+				return sel.Sel.Name
+			}
+			return ""
+		}
+		expr = sel.X
+	}
+}
@@ -0,0 +1,192 @@
+package response
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FieldsQueryParam is the query parameter listing the fields a client wants
+const FieldsQueryParam = "fields"
+
+// fieldTree is a set of requested fields; a nil subtree selects the whole value
+type fieldTree map[string]fieldTree
+
+// ParseFields splits a fields parameter such as "id,name,owner.email" into paths,
+// dropping blanks and duplicates
+func ParseFields(raw string) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// SelectFields returns data reduced to the given fields, named by their JSON tags.
+// Nested fields are selected with dotted paths like "owner.email", and slices are
+// pruned element by element. Fields are validated against the type of data, so an
+// unknown name is rejected with an INVALID_INPUT error; keys below a map can't be
+// checked and are accepted. With no fields, data is returned unchanged.
+func SelectFields(data interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	tree := fieldTree{}
+	for _, field := range fields {
+		if !hasFieldPath(reflect.TypeOf(data), strings.Split(field, ".")) {
+			return nil, errors.NewInvalidInputError(FieldsQueryParam, fmt.Sprintf("unknown field %q", field))
+		}
+		tree.add(strings.Split(field, "."))
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+	var decoded interface{}
+	if err := utils.FromJSONUseNumber(encoded, &decoded); err != nil {
+		return nil, err
+	}
+
+	return tree.prune(decoded), nil
+}
+
+// RespondSuccessFields is RespondSuccess that honours the fields query parameter,
+// responding with 400 when it names an unknown field
+func RespondSuccessFields(c *gin.Context, data interface{}, opts ...Option) {
+	selected, err := SelectFields(data, ParseFields(c.Query(FieldsQueryParam)))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+	RespondSuccess(c, selected, opts...)
+}
+
+// add marks path as selected; selecting a parent keeps all of its children
+func (t fieldTree) add(path []string) {
+	subtree, exists := t[path[0]]
+	if len(path) == 1 {
+		t[path[0]] = nil
+		return
+	}
+	if exists && subtree == nil {
+		return
+	}
+	if subtree == nil {
+		subtree = fieldTree{}
+		t[path[0]] = subtree
+	}
+	subtree.add(path[1:])
+}
+
+// prune keeps only the selected keys of decoded JSON objects
+func (t fieldTree) prune(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = t.prune(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		pruned := make(map[string]interface{}, len(t))
+		for key, subtree := range t {
+			child, ok := v[key]
+			if !ok {
+				continue
+			}
+			if subtree != nil {
+				child = subtree.prune(child)
+			}
+			pruned[key] = child
+		}
+		return pruned
+	default:
+		return value
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// hasFieldPath reports whether path names a JSON field of t
+func hasFieldPath(t reflect.Type, path []string) bool {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		t = t.Elem()
+	}
+
+	switch {
+	case t == nil || t.Kind() == reflect.Interface || t.Kind() == reflect.Map:
+		return true // dynamic, so any key may exist
+	case t.Kind() != reflect.Struct || isCustomMarshaler(t):
+		return false
+	}
+
+	fieldType, ok := jsonFields(t)[path[0]]
+	if !ok {
+		return false
+	}
+	return len(path) == 1 || hasFieldPath(fieldType, path[1:])
+}
+
+// isCustomMarshaler reports whether t encodes itself, e.g. time.Time, so its Go
+// fields don't appear in the JSON
+func isCustomMarshaler(t reflect.Type) bool {
+	ptr := reflect.PtrTo(t)
+	return t.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
+// jsonFields maps the JSON names of t's fields to their types, including the
+// fields of untagged embedded structs as encoding/json does
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFields(embedded) {
+					if _, shadowed := fields[embeddedName]; !shadowed {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,140 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+)
+
+type fieldsOwner struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type fieldsBase struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	internal  string
+}
+
+type fieldsWorkspace struct {
+	fieldsBase
+	Name     string        `json:"name"`
+	Secret   string        `json:"-"`
+	Owner    *fieldsOwner  `json:"owner"`
+	Members  []fieldsOwner `json:"members"`
+	Settings models.JSON   `json:"settings"`
+}
+
+func TestSelectFields(t *testing.T) {
+	owner := &fieldsOwner{ID: 2, Name: "Ada", Email: "ada@example.com"}
+	workspace := fieldsWorkspace{
+		fieldsBase: fieldsBase{ID: 1, CreatedAt: time.Unix(0, 0).UTC()},
+		Name:       "Roadmap",
+		Owner:      owner,
+		Members:    []fieldsOwner{*owner, {ID: 3, Name: "Grace", Email: "grace@example.com"}},
+		Settings:   models.JSON{"theme": "dark", "locale": "en"},
+	}
+
+	tests := []struct {
+		name   string
+		data   interface{}
+		fields string
+		want   string
+	}{
+		{
+			name:   "top-level and embedded fields",
+			data:   workspace,
+			fields: "id,name",
+			want:   `{"id":1,"name":"Roadmap"}`,
+		},
+		{
+			name:   "nested object",
+			data:   &workspace,
+			fields: "name,owner.email",
+			want:   `{"name":"Roadmap","owner":{"email":"ada@example.com"}}`,
+		},
+		{
+			name:   "nested slice and parent wins over child",
+			data:   workspace,
+			fields: "members.name,owner,owner.id",
+			want:   `{"members":[{"name":"Ada"},{"name":"Grace"}],"owner":{"email":"ada@example.com","id":2,"name":"Ada"}}`,
+		},
+		{
+			name:   "map keys",
+			data:   workspace,
+			fields: "settings.theme",
+			want:   `{"settings":{"theme":"dark"}}`,
+		},
+		{
+			name:   "slice of structs",
+			data:   []fieldsOwner{*owner},
+			fields: "email",
+			want:   `[{"email":"ada@example.com"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := SelectFields(tt.data, ParseFields(tt.fields))
+			if err != nil {
+				t.Fatalf("SelectFields() error = %v", err)
+			}
+			got, _ := json.Marshal(selected)
+			if string(got) != tt.want {
+				t.Errorf("SelectFields() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectFields_RejectsUnknownFields(t *testing.T) {
+	for _, fields := range []string{"id,nickname", "owner.phone", "created_at.wall", "internal", "Secret", "name.first"} {
+		if _, err := SelectFields(fieldsWorkspace{}, ParseFields(fields)); err == nil {
+			t.Errorf("SelectFields(%q) expected an error", fields)
+		}
+	}
+
+	if data, err := SelectFields(fieldsWorkspace{Name: "x"}, nil); err != nil || !reflect.DeepEqual(data, fieldsWorkspace{Name: "x"}) {
+		t.Errorf("SelectFields() without fields = %v, %v, want data unchanged", data, err)
+	}
+}
+
+func TestRespondSuccessFields(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantData   string
+	}{
+		{query: "?fields=id,owner.name", wantStatus: http.StatusOK, wantData: `{"id":1,"owner":{"name":"Ada"}}`},
+		{query: "?fields=password", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, w := newResponseContext("")
+			c.Request = httptest.NewRequest(http.MethodGet, "/workspaces/1"+tt.query, nil)
+
+			RespondSuccessFields(c, fieldsWorkspace{fieldsBase: fieldsBase{ID: 1}, Owner: &fieldsOwner{Name: "Ada"}})
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantData == "" {
+				return
+			}
+			var body struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || string(body.Data) != tt.wantData {
+				t.Errorf("data = %s, want %s", body.Data, tt.wantData)
+			}
+		})
+	}
+}
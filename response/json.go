@@ -0,0 +1,52 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Option customizes the headers written by the response helpers
+type Option func(c *gin.Context)
+
+// WithCacheControl sets the Cache-Control header, e.g. "no-store" or "private, max-age=60"
+func WithCacheControl(directive string) Option {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", directive)
+	}
+}
+
+// RespondJSON writes data as JSON with status, echoing the request ID from the
+// context in the X-Request-ID header
+func RespondJSON(c *gin.Context, status int, data interface{}, opts ...Option) {
+	writeHeaders(c, opts)
+	c.JSON(status, data)
+}
+
+// RespondSuccess writes data in the standard APIResponse envelope with status 200.
+// The request ID is also included in the response meta.
+func RespondSuccess(c *gin.Context, data interface{}, opts ...Option) {
+	resp := models.NewSuccessResponse(data)
+	if requestID := logger.RequestIDFromContext(c.Request.Context()); requestID != "" {
+		resp.Meta = &models.APIMeta{RequestID: requestID}
+	}
+	RespondJSON(c, http.StatusOK, resp, opts...)
+}
+
+// RespondNoContent writes an empty 204 response with the standard headers
+func RespondNoContent(c *gin.Context, opts ...Option) {
+	writeHeaders(c, opts)
+	c.Status(http.StatusNoContent)
+}
+
+// writeHeaders sets the request ID header and applies opts
+func writeHeaders(c *gin.Context, opts []Option) {
+	if requestID := logger.RequestIDFromContext(c.Request.Context()); requestID != "" {
+		c.Header(logger.RequestIDHeader, requestID)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+}
@@ -0,0 +1,107 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+func newResponseContext(requestID string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if requestID != "" {
+		req = req.WithContext(logger.ContextWithRequestID(req.Context(), requestID))
+	}
+	c.Request = req
+	return c, w
+}
+
+func TestRespondJSON(t *testing.T) {
+	c, w := newResponseContext("req_abc")
+
+	RespondJSON(c, http.StatusCreated, gin.H{"id": 7}, WithCacheControl("no-store"))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", w.Code)
+	}
+	headers := map[string]string{
+		"Content-Type":  "application/json; charset=utf-8",
+		"X-Request-ID":  "req_abc",
+		"Cache-Control": "no-store",
+	}
+	for name, want := range headers {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if w.Body.String() != `{"id":7}` {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
+
+func TestRespondSuccess(t *testing.T) {
+	c, w := newResponseContext("req_abc")
+
+	RespondSuccess(c, gin.H{"name": "Projects"})
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("X-Request-ID"); got != "req_abc" {
+		t.Errorf("X-Request-ID = %q, want req_abc", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "" {
+		t.Errorf("Cache-Control should be unset without the option, got %q", got)
+	}
+
+	var body models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	data, _ := body.Data.(map[string]interface{})
+	if !body.Success || data["name"] != "Projects" || body.Timestamp.IsZero() {
+		t.Errorf("unexpected envelope: %s", w.Body.String())
+	}
+	if body.Meta == nil || body.Meta.RequestID != "req_abc" {
+		t.Errorf("expected request ID in meta, got %s", w.Body.String())
+	}
+}
+
+func TestRespondSuccess_WithoutRequestID(t *testing.T) {
+	c, w := newResponseContext("")
+
+	RespondSuccess(c, "ok")
+
+	if got := w.Header().Get("X-Request-ID"); got != "" {
+		t.Errorf("X-Request-ID = %q, want empty", got)
+	}
+	var body models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Meta != nil {
+		t.Errorf("unexpected envelope: %s", w.Body.String())
+	}
+}
+
+func TestRespondNoContent(t *testing.T) {
+	c, w := newResponseContext("req_abc")
+
+	RespondNoContent(c, WithCacheControl("no-cache"))
+	c.Writer.WriteHeaderNow()
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", w.Body.String())
+	}
+	if w.Header().Get("X-Request-ID") != "req_abc" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+}
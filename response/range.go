@@ -0,0 +1,185 @@
+package response
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxRanges caps the number of ranges accepted in one Range header
+const MaxRanges = 16
+
+// ByteRange is an inclusive range of byte offsets
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the number of bytes in the range
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// ContentRange formats the range as a Content-Range header value
+func (r ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End, size)
+}
+
+// ParseRange parses a Range header such as "bytes=0-499", "bytes=500-",
+// "bytes=-500" or "bytes=0-99,200-299" against content of the given size,
+// clamping ends past the content. Overlapping and adjacent ranges are merged and
+// returned in order. It returns no ranges, meaning the whole
+// content, for an empty header or a unit other than bytes. A malformed header,
+// more than MaxRanges ranges, or ranges that all start past the content return a
+// RANGE_NOT_SATISFIABLE error, as net/http does.
+func ParseRange(header string, size int64) ([]ByteRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+	specs, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return nil, nil
+	}
+
+	parts := strings.Split(specs, ",")
+	if len(parts) > MaxRanges {
+		return nil, errors.NewRangeNotSatisfiableError(size)
+	}
+
+	var ranges []ByteRange
+	for _, part := range parts {
+		rawStart, rawEnd, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, errors.NewRangeNotSatisfiableError(size)
+		}
+
+		if rawStart == "" {
+			// Suffix range: the last n bytes
+			n, err := strconv.ParseInt(rawEnd, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errors.NewRangeNotSatisfiableError(size)
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			ranges = append(ranges, ByteRange{Start: size - n, End: size - 1})
+			continue
+		}
+
+		start, err := strconv.ParseInt(rawStart, 10, 64)
+		if err != nil || start < 0 {
+			return nil, errors.NewRangeNotSatisfiableError(size)
+		}
+		end := size - 1
+		if rawEnd != "" {
+			if end, err = strconv.ParseInt(rawEnd, 10, 64); err != nil || end < start {
+				return nil, errors.NewRangeNotSatisfiableError(size)
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+		if start >= size {
+			continue
+		}
+		ranges = append(ranges, ByteRange{Start: start, End: end})
+	}
+
+	if len(ranges) == 0 {
+		return nil, errors.NewRangeNotSatisfiableError(size)
+	}
+	return mergeRanges(ranges), nil
+}
+
+// mergeRanges sorts ranges by start and coalesces overlapping or adjacent ones,
+// so overlapping requests can't make a response larger than the content
+func mergeRanges(ranges []ByteRange) []ByteRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start > last.End+1 {
+			merged = append(merged, r)
+			continue
+		}
+		if r.End > last.End {
+			last.End = r.End
+		}
+	}
+	return merged
+}
+
+// ServeRange writes content of the given size and type, honoring the request's
+// Range header for resumable downloads: 200 with the whole content without one,
+// 206 Partial Content with Content-Range for a single range or a
+// multipart/byteranges body for several, and 416 with "Content-Range: bytes */size"
+// when the range can't be satisfied. Accept-Ranges is always set. Errors copying
+// content happen after the status is written and are returned for logging.
+func ServeRange(c *gin.Context, content io.ReadSeeker, size int64, contentType string, opts ...Option) error {
+	writeHeaders(c, opts)
+	c.Header("Accept-Ranges", "bytes")
+
+	ranges, err := ParseRange(c.GetHeader("Range"), size)
+	if err != nil {
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", size))
+		RespondError(c, err)
+		return nil
+	}
+
+	switch len(ranges) {
+	case 0:
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Length", strconv.FormatInt(size, 10))
+		c.Status(http.StatusOK)
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		_, err := io.CopyN(c.Writer, content, size)
+		return err
+
+	case 1:
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Range", ranges[0].ContentRange(size))
+		c.Header("Content-Length", strconv.FormatInt(ranges[0].Length(), 10))
+		c.Status(http.StatusPartialContent)
+		return copyRange(c.Writer, content, ranges[0])
+	}
+
+	parts := multipart.NewWriter(c.Writer)
+	c.Header("Content-Type", "multipart/byteranges; boundary="+parts.Boundary())
+	c.Status(http.StatusPartialContent)
+	for _, r := range ranges {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":  {contentType},
+			"Content-Range": {r.ContentRange(size)},
+		})
+		if err != nil {
+			return err
+		}
+		if err := copyRange(part, content, r); err != nil {
+			return err
+		}
+	}
+	return parts.Close()
+}
+
+// copyRange copies the bytes of r from content to w
+func copyRange(w io.Writer, content io.ReadSeeker, r ByteRange) error {
+	if _, err := content.Seek(r.Start, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w, content, r.Length())
+	return err
+}
@@ -0,0 +1,143 @@
+package response
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    []ByteRange
+		wantErr bool
+	}{
+		{name: "no header"},
+		{name: "other unit", header: "items=0-5"},
+		{name: "single range", header: "bytes=0-9", want: []ByteRange{{0, 9}}},
+		{name: "open-ended", header: "bytes=90-", want: []ByteRange{{90, 99}}},
+		{name: "suffix", header: "bytes=-10", want: []ByteRange{{90, 99}}},
+		{name: "suffix longer than content", header: "bytes=-500", want: []ByteRange{{0, 99}}},
+		{name: "end past content is clamped", header: "bytes=50-500", want: []ByteRange{{50, 99}}},
+		{name: "multiple ranges", header: "bytes=0-9, 20-29", want: []ByteRange{{0, 9}, {20, 29}}},
+		{name: "overlapping ranges are merged", header: "bytes=0-50,10-20,40-99,0-99", want: []ByteRange{{0, 99}}},
+		{name: "adjacent ranges are merged", header: "bytes=10-19,0-9,30-39", want: []ByteRange{{0, 19}, {30, 39}}},
+		{name: "unsatisfiable ranges are dropped", header: "bytes=0-9,200-", want: []ByteRange{{0, 9}}},
+		{name: "start past content", header: "bytes=100-", wantErr: true},
+		{name: "empty suffix", header: "bytes=-0", wantErr: true},
+		{name: "end before start", header: "bytes=9-0", wantErr: true},
+		{name: "malformed", header: "bytes=abc", wantErr: true},
+		{name: "too many ranges", header: "bytes=" + strings.Repeat("0-1,", MaxRanges) + "0-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRange(tt.header, 100)
+			if tt.wantErr {
+				if !errors.Is(err, errors.ErrCodeRangeNotSatisfiable) {
+					t.Errorf("ParseRange() error = %v, want RANGE_NOT_SATISFIABLE", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRange() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeRange(t *testing.T) {
+	const content = "0123456789abcdefghij"
+
+	tests := []struct {
+		name             string
+		header           string
+		wantStatus       int
+		wantBody         string
+		wantContentRange string
+	}{
+		{name: "whole content", wantStatus: http.StatusOK, wantBody: content},
+		{name: "single range", header: "bytes=2-5", wantStatus: http.StatusPartialContent, wantBody: "2345", wantContentRange: "bytes 2-5/20"},
+		{name: "open-ended range", header: "bytes=15-", wantStatus: http.StatusPartialContent, wantBody: "fghij", wantContentRange: "bytes 15-19/20"},
+		{name: "unsatisfiable range", header: "bytes=20-", wantStatus: http.StatusRequestedRangeNotSatisfiable, wantContentRange: "bytes */20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newResponseContext("req_abc")
+			if tt.header != "" {
+				c.Request.Header.Set("Range", tt.header)
+			}
+
+			if err := ServeRange(c, strings.NewReader(content), int64(len(content)), "text/csv"); err != nil {
+				t.Fatalf("ServeRange() error = %v", err)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+				t.Errorf("Accept-Ranges = %q, want bytes", got)
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.wantContentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantContentRange)
+			}
+			if tt.wantStatus == http.StatusRequestedRangeNotSatisfiable {
+				if !strings.Contains(w.Body.String(), errors.ErrCodeRangeNotSatisfiable) {
+					t.Errorf("body = %s, want the error envelope", w.Body.String())
+				}
+				return
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServeRange_MultipleRanges(t *testing.T) {
+	const content = "0123456789abcdefghij"
+
+	c, w := newResponseContext("")
+	c.Request.Header.Set("Range", "bytes=0-1,-3")
+	if err := ServeRange(c, strings.NewReader(content), int64(len(content)), "text/csv"); err != nil {
+		t.Fatalf("ServeRange() error = %v", err)
+	}
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", w.Code)
+	}
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("Content-Type = %q, want multipart/byteranges", w.Header().Get("Content-Type"))
+	}
+
+	want := []struct{ contentRange, body string }{
+		{"bytes 0-1/20", "01"},
+		{"bytes 17-19/20", "hij"},
+	}
+	reader := multipart.NewReader(w.Body, params["boundary"])
+	for i, part := range want {
+		p, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		body, _ := io.ReadAll(p)
+		if got := p.Header.Get("Content-Range"); got != part.contentRange || string(body) != part.body {
+			t.Errorf("part %d = %q %q, want %q %q", i, got, body, part.contentRange, part.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected exactly %d parts, got error %v", len(want), err)
+	}
+}
@@ -0,0 +1,100 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RenderStream writes data in the standard APIResponse envelope with status 200,
+// like RespondSuccess, but encodes it straight to the response writer. Slices and
+// arrays are encoded one element at a time, so large lists are never marshaled
+// into memory as a whole. Once the first byte is written the status can't change,
+// so an encoding error leaves the body truncated; it is returned for logging.
+func RenderStream(c *gin.Context, data interface{}, opts ...Option) error {
+	return renderStream(c, data, nil, opts)
+}
+
+// RenderStreamPaginated is RenderStream with pagination in the response meta
+func RenderStreamPaginated(c *gin.Context, data interface{}, pagination *models.Pagination, opts ...Option) error {
+	return renderStream(c, data, pagination, opts)
+}
+
+func renderStream(c *gin.Context, data interface{}, pagination *models.Pagination, opts []Option) error {
+	resp := models.NewPaginatedResponse(nil, pagination)
+	if pagination == nil {
+		resp.Meta = nil
+	}
+	if requestID := logger.RequestIDFromContext(c.Request.Context()); requestID != "" {
+		if resp.Meta == nil {
+			resp.Meta = &models.APIMeta{}
+		}
+		resp.Meta.RequestID = requestID
+	}
+
+	writeHeaders(c, opts)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if _, err := io.WriteString(w, `{"success":true,"data":`); err != nil {
+		return err
+	}
+	if err := encodeStream(w, data); err != nil {
+		return err
+	}
+
+	if resp.Meta != nil {
+		meta, err := json.Marshal(resp.Meta)
+		if err != nil {
+			return fmt.Errorf("failed to encode meta: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, `,"meta":%s`, meta); err != nil {
+			return err
+		}
+	}
+
+	timestamp, err := json.Marshal(resp.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to encode timestamp: %w", err)
+	}
+	_, err = fmt.Fprintf(w, `,"timestamp":%s}`, timestamp)
+	return err
+}
+
+// encodeStream writes data as JSON, element by element for slices and arrays
+func encodeStream(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+
+	value := reflect.ValueOf(data)
+	isList := value.Kind() == reflect.Array || (value.Kind() == reflect.Slice && !value.IsNil())
+	// []byte marshals as a base64 string, not a list
+	if !isList || value.Type().Elem().Kind() == reflect.Uint8 {
+		if err := encoder.Encode(data); err != nil {
+			return fmt.Errorf("failed to encode data: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(value.Index(i).Interface()); err != nil {
+			return fmt.Errorf("failed to encode data[%d]: %w", i, err)
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
@@ -0,0 +1,114 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+)
+
+type streamedRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestRenderStream(t *testing.T) {
+	c, w := newResponseContext("req_abc")
+
+	records := make([]streamedRecord, 10000)
+	for i := range records {
+		records[i] = streamedRecord{ID: i, Name: "record"}
+	}
+
+	if err := RenderStream(c, records); err != nil {
+		t.Fatalf("RenderStream() error = %v", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var body struct {
+		models.APIResponse
+		Data []streamedRecord `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+	if !body.Success || body.Timestamp.IsZero() {
+		t.Errorf("unexpected envelope: %+v", body.APIResponse)
+	}
+	if body.Meta == nil || body.Meta.RequestID != "req_abc" {
+		t.Errorf("expected the request ID in meta, got %+v", body.Meta)
+	}
+	if len(body.Data) != len(records) || body.Data[9999] != records[9999] {
+		t.Errorf("expected %d streamed records, got %d", len(records), len(body.Data))
+	}
+}
+
+func TestRenderStreamShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		wantData string
+	}{
+		{name: "empty slice", data: []int{}, wantData: `[]`},
+		{name: "nil slice", data: []int(nil), wantData: `null`},
+		{name: "array", data: [2]string{"a", "b"}, wantData: `["a","b"]`},
+		{name: "bytes", data: []byte("hi"), wantData: `"aGk="`},
+		{name: "object", data: map[string]int{"count": 3}, wantData: `{"count":3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newResponseContext("")
+
+			if err := RenderStream(c, tt.data); err != nil {
+				t.Fatalf("RenderStream() error = %v", err)
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected valid JSON, got %v: %s", err, w.Body.String())
+			}
+			if got := compactJSON(t, body["data"]); got != tt.wantData {
+				t.Errorf("data = %s, want %s", got, tt.wantData)
+			}
+			if _, ok := body["meta"]; ok {
+				t.Error("expected no meta without a request ID or pagination")
+			}
+		})
+	}
+}
+
+func TestRenderStreamPaginated(t *testing.T) {
+	c, w := newResponseContext("")
+
+	pagination := &models.Pagination{Page: 2, PageSize: 10, Total: 25, TotalPages: 3, HasNext: true, HasPrev: true}
+	if err := RenderStreamPaginated(c, []int{1, 2, 3}, pagination); err != nil {
+		t.Fatalf("RenderStreamPaginated() error = %v", err)
+	}
+
+	var body models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+	if body.Meta == nil || body.Meta.Pagination == nil || body.Meta.Pagination.Total != 25 {
+		t.Errorf("expected pagination in meta, got %+v", body.Meta)
+	}
+}
+
+func compactJSON(t *testing.T, raw json.RawMessage) string {
+	t.Helper()
+
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", raw, err)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
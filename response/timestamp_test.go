@@ -0,0 +1,54 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func TestEnvelopeTimestampsRFC3339(t *testing.T) {
+	defer utils.SetTimestampFormat(utils.CurrentTimestampFormat())
+	utils.SetTimestampFormat(utils.TimestampRFC3339)
+
+	envelopes := map[string]func(c *gin.Context){
+		"success": func(c *gin.Context) { RespondSuccess(c, gin.H{"id": 1}) },
+		"error":   func(c *gin.Context) { RespondError(c, errors.NewNotFoundError("Record")) },
+		"stream":  func(c *gin.Context) { RenderStream(c, []int{1, 2}) },
+		"bulk":    func(c *gin.Context) { Bulk(c, NewBulkBuilder().Succeeded(0, 1, nil).Build()) },
+		"paginated": func(c *gin.Context) {
+			RespondJSON(c, 200, models.NewPaginatedResponse([]int{1}, &models.Pagination{Page: 1, PageSize: 10, Total: 1}))
+		},
+	}
+
+	for name, respond := range envelopes {
+		t.Run(name, func(t *testing.T) {
+			c, w := newResponseContext("req_abc")
+			respond(c)
+			assertRFC3339Timestamp(t, w)
+		})
+	}
+}
+
+func assertRFC3339Timestamp(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	var body struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v: %s", err, w.Body.String())
+	}
+	parsed, err := time.Parse(time.RFC3339, body.Timestamp)
+	if err != nil {
+		t.Fatalf("timestamp %q is not RFC3339: %v", body.Timestamp, err)
+	}
+	if time.Since(parsed) > time.Minute {
+		t.Errorf("timestamp %q is not the current time", body.Timestamp)
+	}
+}
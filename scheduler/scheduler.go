@@ -0,0 +1,360 @@
+// Package scheduler runs recurring background jobs on cron-like schedules
+package scheduler
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Job run statuses used in logs and metrics
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusPanic   = "panic"
+	StatusTimeout = "timeout"
+	StatusSkipped = "skipped"
+)
+
+// DefaultTimeout is the per-job timeout used when none is configured
+const DefaultTimeout = 5 * time.Minute
+
+// JobFunc is a scheduled job; it should stop when ctx is cancelled
+type JobFunc func(ctx context.Context) error
+
+// Config holds scheduler configuration
+type Config struct {
+	Cache          *cache.Client     // Enables a distributed lock so only one replica runs each job
+	LockPrefix     string            // Prefix for lock keys (default "scheduler:lock:")
+	DefaultTimeout time.Duration     // Timeout for jobs without WithTimeout (default 5m)
+	Logger         *logger.Logger    // Defaults to the global logger
+	Metrics        *metrics.Registry // Optional job metrics
+}
+
+// JobOption configures a job added with AddFunc
+type JobOption func(*job)
+
+// WithName sets the job name used for locking, logs and metrics. Defaults to the
+// function name; set it explicitly for closures.
+func WithName(name string) JobOption {
+	return func(j *job) {
+		j.name = name
+	}
+}
+
+// WithTimeout sets the maximum duration of a single run
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = timeout
+	}
+}
+
+// job is a registered job and its run state
+type job struct {
+	name     string
+	schedule cron.Schedule
+	fn       JobFunc
+	timeout  time.Duration
+	running  int32
+}
+
+// Scheduler runs jobs on their schedules with panic recovery, timeouts,
+// overlap prevention and optional cross-replica locking
+type Scheduler struct {
+	config     Config
+	log        *zap.Logger
+	instanceID string // Marks the ticks this instance claimed
+
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a new scheduler
+func New(cfg Config) *Scheduler {
+	if cfg.LockPrefix == "" {
+		cfg.LockPrefix = "scheduler:lock:"
+	}
+	if cfg.DefaultTimeout <= 0 {
+		cfg.DefaultTimeout = DefaultTimeout
+	}
+
+	var log *zap.Logger
+	if cfg.Logger != nil {
+		log = cfg.Logger.Logger
+	} else {
+		log = logger.With()
+	}
+
+	instanceID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		instanceID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return &Scheduler{
+		config:     cfg,
+		log:        log.With(zap.String("component", "scheduler")),
+		instanceID: instanceID,
+	}
+}
+
+// AddFunc registers fn to run on the given schedule. The spec accepts standard
+// 5-field cron expressions, descriptors such as "@hourly", and "@every <duration>",
+// which fires on multiples of the duration so replicas agree on each run's time.
+// Jobs may be added before or after Start.
+func (s *Scheduler) AddFunc(spec string, fn JobFunc, opts ...JobOption) error {
+	schedule, err := parseSpec(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+
+	j := &job{
+		name:     funcName(fn),
+		schedule: schedule,
+		fn:       fn,
+		timeout:  s.config.DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.jobs {
+		if existing.name == j.name {
+			return fmt.Errorf("job %q is already registered", j.name)
+		}
+	}
+
+	s.jobs = append(s.jobs, j)
+	if s.started {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+
+	return nil
+}
+
+// Start begins running jobs on their schedules
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop stops scheduling new runs, cancels the context of running jobs and
+// waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = false
+	s.cancel()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// loop fires a job on its schedule until the scheduler stops
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	for {
+		now := time.Now()
+		tick := j.schedule.Next(now)
+		timer := time.NewTimer(tick.Sub(now))
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.run(j, tick)
+			}()
+		}
+	}
+}
+
+// run executes the run of a job scheduled for tick unless it is already running
+// locally or another replica has taken that tick
+func (s *Scheduler) run(j *job, tick time.Time) {
+	log := s.log.With(zap.String("job", j.name))
+
+	if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		log.Warn("Skipping job run, previous run still in progress")
+		s.record(j, StatusSkipped, 0)
+		return
+	}
+	defer atomic.StoreInt32(&j.running, 0)
+
+	if s.config.Cache != nil {
+		lock, err := s.acquireLock(j)
+		if stderrors.Is(err, cache.ErrLockNotAcquired) {
+			log.Debug("Skipping job run, lock held by another instance")
+			s.record(j, StatusSkipped, 0)
+			return
+		}
+		if err != nil {
+			log.Error("Failed to acquire job lock", zap.Error(err))
+			s.record(j, StatusSkipped, 0)
+			return
+		}
+		defer func() {
+			if err := lock.Unlock(context.Background()); err != nil {
+				log.Warn("Failed to release job lock", zap.Error(err))
+			}
+		}()
+
+		claimed, err := s.claimTick(j, tick)
+		if err != nil {
+			log.Error("Failed to claim job run", zap.Error(err))
+			s.record(j, StatusSkipped, 0)
+			return
+		}
+		if !claimed {
+			log.Debug("Skipping job run, already run by another instance", zap.Time("tick", tick))
+			s.record(j, StatusSkipped, 0)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := safeRun(ctx, j.fn)
+	duration := time.Since(start)
+
+	status := StatusSuccess
+	switch {
+	case isPanic(err):
+		status = StatusPanic
+		log.Error("Job panicked", zap.Error(err), zap.Duration("duration", duration))
+	case ctx.Err() == context.DeadlineExceeded:
+		status = StatusTimeout
+		log.Error("Job timed out", zap.Error(err), zap.Duration("timeout", j.timeout))
+	case err != nil:
+		status = StatusFailure
+		log.Error("Job failed", zap.Error(err), zap.Duration("duration", duration))
+	default:
+		log.Debug("Job completed", zap.Duration("duration", duration))
+	}
+
+	s.record(j, status, duration)
+}
+
+// acquireLock takes the distributed lock for a job run; it is renewed while the job runs
+func (s *Scheduler) acquireLock(j *job) (*cache.Lock, error) {
+	return s.config.Cache.Lock(s.ctx, s.config.LockPrefix+j.name, j.timeout)
+}
+
+// claimTick marks the job's run at tick as taken by this instance until the next
+// tick, reporting false when another instance already took it. The job lock is
+// released as soon as a run ends, so without the claim a replica whose timer
+// fires a little later would run the same tick again.
+func (s *Scheduler) claimTick(j *job, tick time.Time) (bool, error) {
+	period := j.schedule.Next(tick).Sub(tick)
+	return s.config.Cache.SetNX(s.ctx, tickKey(s.config.LockPrefix, j.name, tick), s.instanceID, period)
+}
+
+// tickKey is the key claiming a job's run at tick, e.g. "scheduler:lock:prune:tick:1700000000000"
+func tickKey(prefix, name string, tick time.Time) string {
+	return fmt.Sprintf("%s%s:tick:%d", prefix, name, tick.UnixMilli())
+}
+
+// record reports a job run to metrics
+func (s *Scheduler) record(j *job, status string, duration time.Duration) {
+	if s.config.Metrics != nil {
+		s.config.Metrics.RecordSchedulerJob(j.name, status, duration)
+	}
+}
+
+// panicError wraps a value recovered from a panicking job
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", e.value)
+}
+
+// isPanic reports whether err came from a recovered panic
+func isPanic(err error) bool {
+	_, ok := err.(*panicError)
+	return ok
+}
+
+// safeRun calls fn, converting a panic into an error
+func safeRun(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r}
+		}
+	}()
+	return fn(ctx)
+}
+
+// parseSpec parses a schedule spec; "@every" accepts sub-second durations
+func parseSpec(spec string) (cron.Schedule, error) {
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, err
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("interval must be positive")
+		}
+		return everySchedule(interval), nil
+	}
+	return cron.ParseStandard(spec)
+}
+
+// everySchedule fires at a fixed interval, on multiples of the interval so every
+// replica computes the same ticks
+type everySchedule time.Duration
+
+// Next returns the next activation time
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(e)).Add(time.Duration(e))
+}
+
+// funcName returns the fully qualified name of fn
+func funcName(fn JobFunc) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return fmt.Sprintf("%p", fn)
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScheduler_FiresOnSchedule(t *testing.T) {
+	s := New(Config{})
+
+	var runs int64
+	if err := s.AddFunc("@every 20ms", func(ctx context.Context) error {
+		atomic.AddInt64(&runs, 1)
+		return nil
+	}, WithName("tick")); err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+
+	s.Start()
+	time.Sleep(150 * time.Millisecond)
+	s.Stop()
+
+	if got := atomic.LoadInt64(&runs); got < 3 {
+		t.Errorf("expected job to fire at least 3 times, fired %d", got)
+	}
+
+	// No further runs after Stop
+	after := atomic.LoadInt64(&runs)
+	time.Sleep(60 * time.Millisecond)
+	if got := atomic.LoadInt64(&runs); got != after {
+		t.Errorf("job fired %d times after Stop", got-after)
+	}
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	registry := metrics.New("test")
+	s := New(Config{Metrics: registry})
+
+	var running, peak, runs int64
+	err := s.AddFunc("@every 10ms", func(ctx context.Context) error {
+		current := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		if current > atomic.LoadInt64(&peak) {
+			atomic.StoreInt64(&peak, current)
+		}
+		atomic.AddInt64(&runs, 1)
+		time.Sleep(80 * time.Millisecond)
+		return nil
+	}, WithName("slow"))
+	if err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+
+	s.Start()
+	time.Sleep(200 * time.Millisecond)
+	s.Stop()
+
+	if got := atomic.LoadInt64(&peak); got != 1 {
+		t.Errorf("expected runs never to overlap, observed %d concurrent", got)
+	}
+	if got := atomic.LoadInt64(&runs); got > 3 {
+		t.Errorf("expected at most 3 runs of an 80ms job in 200ms, got %d", got)
+	}
+	if skipped := testutil.ToFloat64(registry.SchedulerJobRunsTotal.WithLabelValues("slow", StatusSkipped)); skipped == 0 {
+		t.Error("expected skipped runs to be recorded")
+	}
+}
+
+func TestScheduler_DistributedLock(t *testing.T) {
+	client, server := sharedtesting.NewTestCache(t)
+
+	var running, peak, runs int64
+	job := func(ctx context.Context) error {
+		current := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		if current > atomic.LoadInt64(&peak) {
+			atomic.StoreInt64(&peak, current)
+		}
+		atomic.AddInt64(&runs, 1)
+		time.Sleep(40 * time.Millisecond)
+		return nil
+	}
+
+	// Two replicas scheduling the same job against the same Redis
+	replicas := []*Scheduler{New(Config{Cache: client}), New(Config{Cache: client})}
+	for _, s := range replicas {
+		if err := s.AddFunc("@every 10ms", job, WithName("prune-tokens")); err != nil {
+			t.Fatalf("AddFunc() error = %v", err)
+		}
+		s.Start()
+	}
+	time.Sleep(200 * time.Millisecond)
+	for _, s := range replicas {
+		s.Stop()
+	}
+
+	if got := atomic.LoadInt64(&peak); got != 1 {
+		t.Errorf("expected one replica at a time to run the job, observed %d concurrent", got)
+	}
+	if atomic.LoadInt64(&runs) == 0 {
+		t.Error("expected the job to run")
+	}
+	if server.Exists("scheduler:lock:prune-tokens") {
+		t.Error("expected lock to be released after the run")
+	}
+
+	t.Run("lock held elsewhere", func(t *testing.T) {
+		if err := server.Set("scheduler:lock:held", "other-instance"); err != nil {
+			t.Fatalf("failed to seed lock: %v", err)
+		}
+
+		var heldRuns int64
+		s := New(Config{Cache: client})
+		if err := s.AddFunc("@every 10ms", func(ctx context.Context) error {
+			atomic.AddInt64(&heldRuns, 1)
+			return nil
+		}, WithName("held")); err != nil {
+			t.Fatalf("AddFunc() error = %v", err)
+		}
+
+		s.Start()
+		time.Sleep(60 * time.Millisecond)
+		s.Stop()
+
+		if got := atomic.LoadInt64(&heldRuns); got != 0 {
+			t.Errorf("expected job not to run while another instance holds the lock, ran %d times", got)
+		}
+	})
+}
+
+func TestScheduler_LateReplicaSkipsTick(t *testing.T) {
+	client, _ := sharedtesting.NewTestCache(t)
+
+	var runs int64
+	replicas := []*Scheduler{New(Config{Cache: client}), New(Config{Cache: client})}
+	for _, s := range replicas {
+		if err := s.AddFunc("@every 1m", func(ctx context.Context) error {
+			atomic.AddInt64(&runs, 1)
+			return nil
+		}, WithName("report")); err != nil {
+			t.Fatalf("AddFunc() error = %v", err)
+		}
+		s.ctx = context.Background()
+	}
+
+	// Both replicas fire for the same tick, one after the other has finished
+	tick := time.Now().Truncate(time.Minute)
+	for _, s := range replicas {
+		s.run(s.jobs[0], tick)
+	}
+	if got := atomic.LoadInt64(&runs); got != 1 {
+		t.Errorf("tick ran %d times, want once", got)
+	}
+
+	replicas[1].run(replicas[1].jobs[0], tick.Add(time.Minute))
+	if got := atomic.LoadInt64(&runs); got != 2 {
+		t.Errorf("next tick didn't run, %d runs", got)
+	}
+}
+
+func TestScheduler_RecoversPanicsAndTimeouts(t *testing.T) {
+	registry := metrics.New("test")
+	s := New(Config{Metrics: registry})
+
+	if err := s.AddFunc("@every 10ms", func(ctx context.Context) error {
+		panic("boom")
+	}, WithName("panics")); err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	if err := s.AddFunc("@every 10ms", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithName("hangs"), WithTimeout(15*time.Millisecond)); err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+	if err := s.AddFunc("@every 10ms", func(ctx context.Context) error {
+		return errors.New("failed")
+	}, WithName("fails")); err != nil {
+		t.Fatalf("AddFunc() error = %v", err)
+	}
+
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+	s.Stop()
+
+	checks := map[string]string{"panics": StatusPanic, "hangs": StatusTimeout, "fails": StatusFailure}
+	for name, status := range checks {
+		if got := testutil.ToFloat64(registry.SchedulerJobRunsTotal.WithLabelValues(name, status)); got == 0 {
+			t.Errorf("expected %s runs for job %q", status, name)
+		}
+	}
+}
+
+func TestScheduler_AddFuncValidation(t *testing.T) {
+	s := New(Config{})
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := s.AddFunc("not a schedule", noop); err == nil {
+		t.Error("expected invalid spec to be rejected")
+	}
+	if err := s.AddFunc("@every -1s", noop); err == nil {
+		t.Error("expected negative interval to be rejected")
+	}
+	if err := s.AddFunc("*/5 * * * *", noop, WithName("cleanup")); err != nil {
+		t.Errorf("expected cron spec to be accepted, got %v", err)
+	}
+	if err := s.AddFunc("@hourly", noop, WithName("cleanup")); err == nil {
+		t.Error("expected duplicate job name to be rejected")
+	}
+}
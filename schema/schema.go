@@ -0,0 +1,197 @@
+// Package schema generates JSON Schema for the shared response envelopes so
+// OpenAPI specs can be built from the Go types instead of maintained by hand
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+)
+
+// Schema is a JSON Schema object in the subset used by OpenAPI 3 components
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// RefPrefix is prepended to component names in $ref
+const RefPrefix = "#/components/schemas/"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	timestampType = reflect.TypeOf(utils.Timestamp{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Generator builds schemas from Go types by reflection, following encoding/json:
+// fields are named by their json tag, "-" fields are skipped, embedded structs
+// are flattened and fields without omitempty are required. Named structs become
+// components referenced with $ref.
+type Generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+// NewGenerator creates a generator with no components
+func NewGenerator() *Generator {
+	return &Generator{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// Schema returns the schema of v's type, adding the structs it uses to the components
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// Components returns the struct schemas generated so far by component name
+func (g *Generator) Components() map[string]*Schema {
+	return g.components
+}
+
+// Envelopes returns the components of the shared envelopes: models.APIResponse,
+// APIError, APIMeta and Pagination, errors.ErrorResponse and the types they use
+func Envelopes() map[string]*Schema {
+	g := NewGenerator()
+	g.Schema(models.APIResponse{})
+	g.Schema(models.APIError{})
+	g.Schema(models.APIMeta{})
+	g.Schema(models.Pagination{})
+	g.Schema(errors.ErrorResponse{})
+	return g.Components()
+}
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timestampType:
+		return &Schema{Type: "string", Description: "Unix seconds or RFC3339, see utils.SetTimestampFormat"}
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Custom encodings can't be described by reflection
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: RefPrefix + g.component(t)}
+	default:
+		// interface{} and anything else accepts any value
+		return &Schema{}
+	}
+}
+
+// component returns the component name of a named struct, generating its schema
+// on first use. Structs of the same name from different packages are qualified
+// with the package name.
+func (g *Generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		name = pathBase(t.PkgPath()) + "." + name
+	}
+	g.names[t] = name
+	g.components[name] = &Schema{} // Placeholder so recursive types terminate
+	*g.components[name] = *g.structSchema(t)
+	return name
+}
+
+// structSchema describes a struct's JSON object
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the fields of t to s, flattening embedded structs
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schemaOf(field.Type)
+		if !hasOption(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// hasOption reports whether a json tag's comma separated options include option
+func hasOption(options, option string) bool {
+	for options != "" {
+		var current string
+		current, options, _ = strings.Cut(options, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+// pathBase returns the last element of an import path
+func pathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
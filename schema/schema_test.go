@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvelopes(t *testing.T) {
+	components := Envelopes()
+
+	tests := []struct {
+		component string
+		required  []string
+		refs      map[string]string // property -> referenced component
+		types     map[string]string // property -> JSON type
+	}{
+		{
+			component: "APIResponse",
+			required:  []string{"success", "timestamp"},
+			refs:      map[string]string{"error": "APIError", "meta": "APIMeta"},
+			types:     map[string]string{"success": "boolean", "data": "", "timestamp": "string"},
+		},
+		{
+			component: "APIError",
+			required:  []string{"code", "message"},
+			types:     map[string]string{"code": "string", "message": "string", "details": "object"},
+		},
+		{
+			component: "APIMeta",
+			refs:      map[string]string{"pagination": "Pagination", "rate_limit": "RateLimit", "performance": "Performance"},
+			types:     map[string]string{"request_id": "string", "version": "string", "warnings": "array"},
+		},
+		{
+			component: "Pagination",
+			required:  []string{"page", "page_size", "total", "total_pages", "has_next", "has_prev"},
+			types:     map[string]string{"page": "integer", "total": "integer", "has_next": "boolean"},
+		},
+		{
+			component: "ErrorResponse",
+			required:  []string{"error", "timestamp"},
+			refs:      map[string]string{"error": "Error"},
+			types:     map[string]string{"request_id": "string"},
+		},
+		{
+			component: "Error",
+			required:  []string{"code", "message"},
+			types:     map[string]string{"code": "string", "details": "object"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.component, func(t *testing.T) {
+			s, ok := components[tt.component]
+			if !ok {
+				t.Fatalf("no %s component", tt.component)
+			}
+			if s.Type != "object" {
+				t.Errorf("type = %q, want object", s.Type)
+			}
+			if !reflect.DeepEqual(s.Required, tt.required) {
+				t.Errorf("required = %v, want %v", s.Required, tt.required)
+			}
+			for property, component := range tt.refs {
+				if got := s.Properties[property]; got == nil || got.Ref != RefPrefix+component {
+					t.Errorf("%s = %+v, want a reference to %s", property, got, component)
+				}
+				if _, ok := components[component]; !ok {
+					t.Errorf("referenced component %s is missing", component)
+				}
+			}
+			for property, typ := range tt.types {
+				if got := s.Properties[property]; got == nil || got.Type != typ {
+					t.Errorf("%s = %+v, want type %q", property, got, typ)
+				}
+			}
+		})
+	}
+
+	// Fields excluded from JSON stay out of the schema
+	for _, hidden := range []string{"HTTPCode", "Cause"} {
+		if _, ok := components["Error"].Properties[hidden]; ok {
+			t.Errorf("Error schema exposes %s", hidden)
+		}
+	}
+
+	warnings := components["APIMeta"].Properties["warnings"]
+	if warnings.Items == nil || warnings.Items.Ref != RefPrefix+"Warning" {
+		t.Errorf("warnings items = %+v, want a reference to Warning", warnings.Items)
+	}
+}
+
+func TestEnvelopes_JSON(t *testing.T) {
+	data, err := json.Marshal(Envelopes()["APIResponse"])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"$ref":"#/components/schemas/APIError"`, `"required":["success","timestamp"]`, `"data":{}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("schema %s does not contain %s", data, want)
+		}
+	}
+}
+
+type audit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type Node struct {
+	audit
+	Name     string            `json:"name"`
+	Children []*Node           `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Raw      []byte            `json:"raw,omitempty"`
+	Untagged int64
+	private  bool
+}
+
+func TestGenerator_Schema(t *testing.T) {
+	g := NewGenerator()
+	if ref := g.Schema(&Node{}); ref.Ref != RefPrefix+"Node" {
+		t.Fatalf("Schema() = %+v, want a reference to Node", ref)
+	}
+
+	node := g.Components()["Node"]
+	if want := []string{"created_by", "name", "Untagged"}; !reflect.DeepEqual(node.Required, want) {
+		t.Errorf("required = %v, want %v", node.Required, want)
+	}
+	if len(node.Properties) != 6 {
+		t.Errorf("properties = %v, want embedded fields flattened and unexported fields skipped", node.Properties)
+	}
+	if children := node.Properties["children"]; children.Type != "array" || children.Items.Ref != RefPrefix+"Node" {
+		t.Errorf("children = %+v, want an array of Node references", children)
+	}
+	if labels := node.Properties["labels"]; labels.AdditionalProperties == nil || labels.AdditionalProperties.Type != "string" {
+		t.Errorf("labels = %+v, want a string map", labels)
+	}
+	if raw := node.Properties["raw"]; raw.Type != "string" || raw.Format != "byte" {
+		t.Errorf("raw = %+v, want base64 string", raw)
+	}
+	if untagged := node.Properties["Untagged"]; untagged.Format != "int64" {
+		t.Errorf("Untagged = %+v, want int64", untagged)
+	}
+}
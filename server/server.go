@@ -0,0 +1,102 @@
+// Package server assembles gin engines with the standard middleware stack
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/Reg-Kris/pyairtable-go-shared/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Options configures the engine built by NewEngine. Layers left nil are skipped.
+type Options struct {
+	Mode                string                 // Gin mode (gin.ReleaseMode, gin.DebugMode, gin.TestMode); unchanged when empty
+	DisableRecovery     bool                   // Skip panic recovery
+	Logger              *logger.Logger         // Request logging, request IDs and panic logs
+	Metrics             *metrics.Registry      // HTTP metrics
+	DisableErrorHandler bool                   // Skip rendering c.Error errors as ErrorResponse
+	HandlerTimeout      time.Duration          // Request deadline, e.g. cfg.Server.HandlerTimeoutDuration(); none when zero
+	CORS                *middleware.CORSConfig // Cross-origin headers and preflight handling
+	RateLimit           gin.HandlerFunc        // e.g. middleware.TokenBucketRateLimit(...)
+	Auth                gin.HandlerFunc        // e.g. middleware.JWT(...) or middleware.APIKey(...)
+	Middleware          []gin.HandlerFunc      // Additional middleware run after auth
+	TrustedProxies      []string               // e.g. cfg.Server.TrustedProxies; forwarded client IPs from anyone else are ignored
+}
+
+// NewEngine returns a gin.Engine with the standard middleware stack in this order:
+//
+//  1. Recovery - outermost, so a panic in any later layer or handler becomes a 500
+//     instead of killing the connection.
+//  2. Request ID and logging - assigns the request ID before anything can fail or
+//     respond, so every later log line, metric exemplar and error carries it. The
+//     request ID is assigned even without a Logger.
+//  3. Metrics - sees every request that reaches the service, including ones later
+//     rejected by CORS, rate limiting or auth.
+//  4. Error handler - renders errors added with c.Error before metrics and logging
+//     read the final status.
+//  5. Timeout - bounds everything after it, including rate limit and auth lookups,
+//     and renders the timeout error before the error handler sees the request.
+//  6. CORS - answers preflight requests before they count against rate limits or
+//     fail authentication, and adds headers to every response including errors.
+//  7. Rate limiting - rejects abusive clients before the cost of authentication.
+//  8. Auth - last, closest to the handlers that need the identity.
+//
+// TrustedProxies applies to both gin's c.ClientIP and middleware.ClientIP for this
+// engine only, ahead of every other layer; with none, X-Forwarded-For is never
+// trusted. NewEngine panics on an invalid entry, which config.Validate reports
+// beforehand.
+func NewEngine(opts Options) *gin.Engine {
+	if opts.Mode != "" {
+		gin.SetMode(opts.Mode)
+	}
+
+	engine := gin.New()
+	proxies := make([]string, 0, len(opts.TrustedProxies))
+	for _, proxy := range opts.TrustedProxies {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := engine.SetTrustedProxies(proxies); err != nil {
+		panic(fmt.Sprintf("server: invalid trusted proxies: %v", err))
+	}
+	if len(proxies) > 0 {
+		engine.Use(middleware.TrustProxies(proxies))
+	}
+
+	if !opts.DisableRecovery {
+		engine.Use(middleware.Recovery(opts.Logger))
+	}
+	if opts.Logger != nil {
+		engine.Use(middleware.RequestLogging(opts.Logger))
+	} else {
+		engine.Use(middleware.RequestID())
+	}
+	if opts.Metrics != nil {
+		engine.Use(opts.Metrics.Middleware())
+	}
+	if !opts.DisableErrorHandler {
+		engine.Use(middleware.ErrorHandler())
+	}
+	if opts.HandlerTimeout > 0 {
+		engine.Use(middleware.Timeout(opts.HandlerTimeout))
+	}
+	if opts.CORS != nil {
+		engine.Use(middleware.CORS(*opts.CORS))
+	}
+	if opts.RateLimit != nil {
+		engine.Use(opts.RateLimit)
+	}
+	if opts.Auth != nil {
+		engine.Use(opts.Auth)
+	}
+	if len(opts.Middleware) > 0 {
+		engine.Use(opts.Middleware...)
+	}
+
+	return engine
+}
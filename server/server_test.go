@@ -0,0 +1,252 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/logger"
+	"github.com/Reg-Kris/pyairtable-go-shared/metrics"
+	"github.com/Reg-Kris/pyairtable-go-shared/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+// probe records the layers it observes as having run before it
+type probe struct {
+	calls []string
+}
+
+func (p *probe) handler(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seen := []string{name}
+		if middleware.GetRequestIDFromContext(c.Request.Context()) != "" {
+			seen = append(seen, "request-id")
+		}
+		if c.Writer.Header().Get("Access-Control-Allow-Origin") != "" {
+			seen = append(seen, "cors")
+		}
+		p.calls = append(p.calls, strings.Join(seen, "+"))
+		c.Next()
+	}
+}
+
+func newTestEngine(p *probe, registry *metrics.Registry) *gin.Engine {
+	engine := NewEngine(Options{
+		Mode:      gin.TestMode,
+		Logger:    &logger.Logger{Logger: zap.NewNop()},
+		Metrics:   registry,
+		CORS:      &middleware.CORSConfig{AllowOrigins: []string{"https://app.example.com"}},
+		RateLimit: p.handler("rate-limit"),
+		Auth:      p.handler("auth"),
+		Middleware: []gin.HandlerFunc{
+			p.handler("custom"),
+		},
+	})
+
+	engine.GET("/ok", p.handler("handler"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/panic", func(c *gin.Context) { panic("boom") })
+	engine.GET("/error", func(c *gin.Context) { c.Error(errors.NewNotFoundError("Workspace")) })
+
+	return engine
+}
+
+func TestNewEngine_MiddlewareOrder(t *testing.T) {
+	p := &probe{}
+	engine := newTestEngine(p, metrics.New("test"))
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	want := []string{
+		"rate-limit+request-id+cors",
+		"auth+request-id+cors",
+		"custom+request-id+cors",
+		"handler+request-id+cors",
+	}
+	if strings.Join(p.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("middleware order = %v, want %v", p.calls, want)
+	}
+}
+
+func TestNewEngine_PreflightSkipsRateLimitAndAuth(t *testing.T) {
+	p := &probe{}
+	engine := newTestEngine(p, metrics.New("test"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/ok", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+	if len(p.calls) != 0 {
+		t.Errorf("expected preflight to stop at CORS, later layers ran: %v", p.calls)
+	}
+	if w.Header().Get(middleware.RequestIDHeader) == "" {
+		t.Error("expected request ID on preflight response")
+	}
+}
+
+func TestNewEngine_RecoversPanics(t *testing.T) {
+	engine := newTestEngine(&probe{}, metrics.New("test"))
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	var body errors.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body.Error == nil || body.Error.Code != errors.ErrCodeInternalError {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+	if body.RequestID == "" || body.RequestID != w.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("expected panic response to carry the request ID, got %q", body.RequestID)
+	}
+}
+
+func TestNewEngine_MetricsSeeRenderedErrors(t *testing.T) {
+	registry := metrics.New("test")
+	engine := newTestEngine(&probe{}, registry)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	if got := testutil.ToFloat64(registry.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/error", "404")); got != 1 {
+		t.Errorf("expected the rendered 404 to be counted, got %v", got)
+	}
+}
+
+func TestNewEngine_HandlerTimeout(t *testing.T) {
+	engine := NewEngine(Options{
+		Mode:           gin.TestMode,
+		Logger:         &logger.Logger{Logger: zap.NewNop()},
+		HandlerTimeout: 20 * time.Millisecond,
+	})
+	engine.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			c.Error(c.Request.Context().Err())
+		case <-time.After(time.Second):
+			c.Status(http.StatusOK)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if w.Code != http.StatusRequestTimeout {
+		t.Fatalf("status = %d, want 408", w.Code)
+	}
+
+	var body errors.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body.Error == nil || body.Error.Code != errors.ErrCodeTimeout {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+	if body.RequestID == "" || body.RequestID != w.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("expected the timeout response to carry the request ID, got %q", body.RequestID)
+	}
+}
+
+func TestNewEngine_DisabledLayers(t *testing.T) {
+	engine := NewEngine(Options{Mode: gin.TestMode, DisableRecovery: true, DisableErrorHandler: true})
+
+	if n := len(engine.Handlers); n != 1 {
+		t.Errorf("expected only the request ID middleware, got %d", n)
+	}
+}
+
+func TestNewEngine_RequestIDWithoutLogger(t *testing.T) {
+	engine := NewEngine(Options{Mode: gin.TestMode})
+	engine.GET("/error", func(c *gin.Context) { c.Error(errors.NewNotFoundError("Workspace")) })
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/error", nil))
+
+	var body errors.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body.RequestID == "" || body.RequestID != w.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("request ID = %q, header %q, want the same generated ID", body.RequestID, w.Header().Get(middleware.RequestIDHeader))
+	}
+}
+
+func TestNewEngine_RejectsWildcardOriginWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected NewEngine to panic")
+		}
+	}()
+
+	NewEngine(Options{
+		Mode: gin.TestMode,
+		CORS: &middleware.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true},
+	})
+}
+
+func TestNewEngine_TrustedProxies(t *testing.T) {
+	newEngine := func(proxies []string) *gin.Engine {
+		engine := NewEngine(Options{Mode: gin.TestMode, TrustedProxies: proxies})
+		engine.GET("/ip", func(c *gin.Context) {
+			c.String(http.StatusOK, c.ClientIP()+" "+middleware.ClientIP(c))
+		})
+		return engine
+	}
+	engine := newEngine([]string{"10.0.0.0/8"})
+	// A second engine in the same process must not change what the first trusts
+	untrusting := newEngine(nil)
+
+	tests := []struct {
+		name       string
+		engine     *gin.Engine
+		remoteAddr string
+		want       string
+	}{
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:4000", want: "203.0.113.7 203.0.113.7"},
+		{name: "untrusted peer", remoteAddr: "198.51.100.9:4000", want: "198.51.100.9 198.51.100.9"},
+		{name: "engine without proxies", engine: untrusting, remoteAddr: "10.1.2.3:4000", want: "10.1.2.3 10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+			target := engine
+			if tt.engine != nil {
+				target = tt.engine
+			}
+			w := httptest.NewRecorder()
+			target.ServeHTTP(w, req)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("client IPs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
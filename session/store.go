@@ -0,0 +1,235 @@
+// Package session provides session storage backed by the database and Redis cache
+package session
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/database"
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
+	"gorm.io/gorm"
+)
+
+// tokenLength is the length of generated session tokens
+const tokenLength = 43
+
+// revocationTTL is how long a revoked session's tombstone stops reads that loaded
+// it before the revocation from caching it again
+const revocationTTL = time.Minute
+
+// Store creates, validates and revokes user sessions.
+// Sessions are looked up by the raw token handed to the client; only its hash is stored.
+type Store interface {
+	// Create persists the session and returns the raw token for the client
+	Create(ctx context.Context, session *models.Session) (string, error)
+	// Get returns the active session for a token, or a NOT_FOUND error
+	Get(ctx context.Context, token string) (*models.Session, error)
+	// Touch extends the session expiry to now + ttl
+	Touch(ctx context.Context, token string, ttl time.Duration) error
+	// Revoke deactivates a single session
+	Revoke(ctx context.Context, token string) error
+	// RevokeAllForUser deactivates every session of a user
+	RevokeAllForUser(ctx context.Context, userID uint) error
+}
+
+// CompositeStore is a Store that keeps sessions in the database and caches them in
+// Redis. Reads hit the cache first and fall back to the database; writes go to both.
+// Cache failures are tolerated so sessions keep working when Redis is unavailable.
+type CompositeStore struct {
+	db        *database.DB
+	cache     *cache.Client
+	keyPrefix string
+}
+
+var _ Store = (*CompositeStore)(nil)
+
+// NewCompositeStore creates a session store backed by db and cache
+func NewCompositeStore(db *database.DB, cache *cache.Client) *CompositeStore {
+	return &CompositeStore{
+		db:        db,
+		cache:     cache,
+		keyPrefix: "session:",
+	}
+}
+
+// HashToken returns the hash under which a session token is stored
+func HashToken(token string) string {
+	return utils.HashSHA256(token)
+}
+
+// Create generates a token for the session, stores its hash and caches the session
+func (s *CompositeStore) Create(ctx context.Context, session *models.Session) (string, error) {
+	token, err := utils.GenerateRandomString(tokenLength)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	session.Token = HashToken(token)
+	session.IsActive = true
+
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return "", errors.NewDatabaseError("create session", err)
+	}
+
+	s.cacheSession(ctx, session)
+
+	return token, nil
+}
+
+// Get returns the active session for token
+func (s *CompositeStore) Get(ctx context.Context, token string) (*models.Session, error) {
+	hash := HashToken(token)
+
+	var session models.Session
+	if err := s.cache.Get(ctx, s.key(hash), &session); err == nil && !s.revoked(ctx, hash) {
+		// The token hash isn't serialized; restore it from the lookup key
+		session.Token = hash
+		if !session.IsValid() {
+			return nil, errors.NewNotFoundError("Session")
+		}
+		return &session, nil
+	}
+
+	found, err := s.load(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheSession(ctx, found)
+
+	return found, nil
+}
+
+// Touch extends the session expiry to now + ttl
+func (s *CompositeStore) Touch(ctx context.Context, token string, ttl time.Duration) error {
+	session, err := s.load(ctx, HashToken(token))
+	if err != nil {
+		return err
+	}
+
+	session.ExpiresAt = time.Now().Add(ttl)
+	err = s.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ?", session.ID).
+		Update("expires_at", session.ExpiresAt).Error
+	if err != nil {
+		return errors.NewDatabaseError("touch session", err)
+	}
+
+	s.cacheSession(ctx, session)
+
+	return nil
+}
+
+// Revoke deactivates the session for token in both layers
+func (s *CompositeStore) Revoke(ctx context.Context, token string) error {
+	hash := HashToken(token)
+
+	err := s.db.WithContext(ctx).Model(&models.Session{}).
+		Where("token = ?", hash).
+		Update("is_active", false).Error
+	if err != nil {
+		return errors.NewDatabaseError("revoke session", err)
+	}
+
+	return s.evict(ctx, hash)
+}
+
+// RevokeAllForUser deactivates every active session of the user in both layers
+func (s *CompositeStore) RevokeAllForUser(ctx context.Context, userID uint) error {
+	var hashes []string
+	err := s.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Pluck("token", &hashes).Error
+	if err != nil {
+		return errors.NewDatabaseError("list user sessions", err)
+	}
+
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	err = s.db.WithContext(ctx).Model(&models.Session{}).
+		Where("token IN ?", hashes).
+		Update("is_active", false).Error
+	if err != nil {
+		return errors.NewDatabaseError("revoke user sessions", err)
+	}
+
+	for _, hash := range hashes {
+		if err := s.evict(ctx, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load reads an active session from the database
+func (s *CompositeStore) load(ctx context.Context, hash string) (*models.Session, error) {
+	var session models.Session
+	err := s.db.WithContext(ctx).Where("token = ?", hash).First(&session).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Session")
+		}
+		return nil, errors.NewDatabaseError("get session", err)
+	}
+
+	if !session.IsValid() {
+		return nil, errors.NewNotFoundError("Session")
+	}
+
+	return &session, nil
+}
+
+// cacheSession caches a session until it expires; failures only cost a cache miss.
+// The tombstone is checked after writing, so a session loaded before a concurrent
+// Revoke is removed again whichever of the two reaches Redis first.
+func (s *CompositeStore) cacheSession(ctx context.Context, session *models.Session) {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	key := s.key(session.Token)
+	if err := s.cache.Set(ctx, key, session, ttl); err != nil {
+		return
+	}
+	if s.revoked(ctx, session.Token) {
+		_ = s.cache.Delete(ctx, key)
+	}
+}
+
+// revoked reports whether the session was revoked within revocationTTL. Errors count
+// as revoked, so callers fall back to the database rather than trust the cache.
+func (s *CompositeStore) revoked(ctx context.Context, hash string) bool {
+	revoked, err := s.cache.Exists(ctx, s.revokedKey(hash))
+	return err != nil || revoked
+}
+
+// evict tombstones a session and removes it from the cache. Unlike other cache writes
+// this must succeed, otherwise a revoked session would stay valid until its cache
+// entry expires.
+func (s *CompositeStore) evict(ctx context.Context, hash string) error {
+	if err := s.cache.Set(ctx, s.revokedKey(hash), true, revocationTTL); err != nil {
+		return errors.NewCacheError("revoke session", err)
+	}
+	if err := s.cache.Delete(ctx, s.key(hash)); err != nil {
+		return errors.NewCacheError("revoke session", err)
+	}
+	return nil
+}
+
+// key returns the cache key for a token hash
+func (s *CompositeStore) key(hash string) string {
+	return s.keyPrefix + hash
+}
+
+// revokedKey returns the cache key of a revoked session's tombstone
+func (s *CompositeStore) revokedKey(hash string) string {
+	return s.keyPrefix + "revoked:" + hash
+}
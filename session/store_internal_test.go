@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+)
+
+func TestCompositeStore_RevokeDuringGetIsNotRecached(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	t.Cleanup(testDB.Cleanup)
+	if err := testDB.Migrate(&models.Session{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	client, server := sharedtesting.NewTestCache(t)
+	store := NewCompositeStore(testDB.DB, client)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		revoke func(token string, session *models.Session) error
+	}{
+		{name: "revoke", revoke: func(token string, session *models.Session) error {
+			return store.Revoke(ctx, token)
+		}},
+		{name: "revoke all for user", revoke: func(token string, session *models.Session) error {
+			return store.RevokeAllForUser(ctx, session.UserID)
+		}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := store.Create(ctx, &models.Session{UserID: uint(i + 1), ExpiresAt: time.Now().Add(time.Hour)})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			hash := HashToken(token)
+			server.Del(store.key(hash))
+
+			// Get misses the cache and loads the still active row...
+			loaded, err := store.load(ctx, hash)
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+			// ...the session is revoked before Get caches it...
+			if err := tt.revoke(token, loaded); err != nil {
+				t.Fatalf("revoke error = %v", err)
+			}
+			// ...and Get's late cache write must not bring it back
+			store.cacheSession(ctx, loaded)
+
+			if server.Exists(store.key(hash)) {
+				t.Error("expected the revoked session to stay out of the cache")
+			}
+			if _, err := store.Get(ctx, token); !errors.Is(err, errors.ErrCodeNotFound) {
+				t.Errorf("Get() after revoke error = %v, want NOT_FOUND", err)
+			}
+		})
+	}
+}
+
+func TestCompositeStore_TombstoneHidesStaleCacheHit(t *testing.T) {
+	testDB := sharedtesting.NewTestDB(t)
+	t.Cleanup(testDB.Cleanup)
+	if err := testDB.Migrate(&models.Session{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	client, _ := sharedtesting.NewTestCache(t)
+	store := NewCompositeStore(testDB.DB, client)
+	ctx := context.Background()
+
+	token, err := store.Create(ctx, &models.Session{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	session, err := store.Get(ctx, token)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err := store.Revoke(ctx, token); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	// An active copy written by a racing reader after the cleanup still loses
+	if err := client.Set(ctx, store.key(session.Token), session, time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := store.Get(ctx, token); !errors.Is(err, errors.ErrCodeNotFound) {
+		t.Errorf("Get() error = %v, want NOT_FOUND", err)
+	}
+}
@@ -0,0 +1,197 @@
+package session_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/errors"
+	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/session"
+	sharedtesting "github.com/Reg-Kris/pyairtable-go-shared/testing"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestStore(t *testing.T) (*session.CompositeStore, *sharedtesting.TestDB, *miniredis.Miniredis) {
+	t.Helper()
+
+	testDB := sharedtesting.NewTestDB(t)
+	t.Cleanup(testDB.Cleanup)
+	if err := testDB.Migrate(&models.Session{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	client, server := sharedtesting.NewTestCache(t)
+
+	return session.NewCompositeStore(testDB.DB, client), testDB, server
+}
+
+func newSession(userID uint) *models.Session {
+	return &models.Session{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+		IPAddress: "127.0.0.1",
+	}
+}
+
+func TestCompositeStore_CreateAndGet(t *testing.T) {
+	store, testDB, server := newTestStore(t)
+	ctx := context.Background()
+
+	token, err := store.Create(ctx, newSession(1))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if token == "" {
+		t.Fatal("expected a token")
+	}
+
+	var stored models.Session
+	if err := testDB.First(&stored).Error; err != nil {
+		t.Fatalf("failed to load stored session: %v", err)
+	}
+	if stored.Token == token || stored.Token != session.HashToken(token) {
+		t.Errorf("expected only the token hash to be stored, got %q", stored.Token)
+	}
+	if !server.Exists("session:" + session.HashToken(token)) {
+		t.Error("expected session to be written through to the cache")
+	}
+
+	got, err := store.Get(ctx, token)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.UserID != 1 || got.Token != stored.Token {
+		t.Errorf("Get() = %+v, want session of user 1", got)
+	}
+
+	if _, err := store.Get(ctx, "unknown-token"); !errors.Is(err, errors.ErrCodeNotFound) {
+		t.Errorf("expected NOT_FOUND for unknown token, got %v", err)
+	}
+}
+
+func TestCompositeStore_CacheHitAndDatabaseFallback(t *testing.T) {
+	store, testDB, server := newTestStore(t)
+	ctx := context.Background()
+
+	token, err := store.Create(ctx, newSession(1))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Change the row behind the cache's back: a cache hit still returns the cached copy
+	if err := testDB.Model(&models.Session{}).Where("user_id = ?", 1).Update("ip_address", "10.0.0.1").Error; err != nil {
+		t.Fatalf("failed to update session: %v", err)
+	}
+
+	got, err := store.Get(ctx, token)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.IPAddress != "127.0.0.1" {
+		t.Errorf("expected cached session, got IP %q", got.IPAddress)
+	}
+
+	// On a cache miss the store falls back to the database and repopulates the cache
+	key := "session:" + session.HashToken(token)
+	server.Del(key)
+
+	got, err = store.Get(ctx, token)
+	if err != nil {
+		t.Fatalf("Get() after cache miss error = %v", err)
+	}
+	if got.IPAddress != "10.0.0.1" {
+		t.Errorf("expected database session, got IP %q", got.IPAddress)
+	}
+	if !server.Exists(key) {
+		t.Error("expected session to be cached again after fallback")
+	}
+}
+
+func TestCompositeStore_Touch(t *testing.T) {
+	store, testDB, _ := newTestStore(t)
+	ctx := context.Background()
+
+	s := newSession(1)
+	s.ExpiresAt = time.Now().Add(time.Minute)
+	token, err := store.Create(ctx, s)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := store.Touch(ctx, token, 2*time.Hour); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+
+	var stored models.Session
+	if err := testDB.First(&stored).Error; err != nil {
+		t.Fatalf("failed to load stored session: %v", err)
+	}
+	if time.Until(stored.ExpiresAt) < time.Hour {
+		t.Errorf("expected expiry to be extended, got %v", stored.ExpiresAt)
+	}
+
+	got, err := store.Get(ctx, token)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if time.Until(got.ExpiresAt) < time.Hour {
+		t.Errorf("expected cached expiry to be extended, got %v", got.ExpiresAt)
+	}
+}
+
+func TestCompositeStore_Revoke(t *testing.T) {
+	store, _, server := newTestStore(t)
+	ctx := context.Background()
+
+	token, err := store.Create(ctx, newSession(1))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := store.Revoke(ctx, token); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	if server.Exists("session:" + session.HashToken(token)) {
+		t.Error("expected revoked session to be evicted from the cache")
+	}
+	if _, err := store.Get(ctx, token); !errors.Is(err, errors.ErrCodeNotFound) {
+		t.Errorf("expected revoked session to be rejected, got %v", err)
+	}
+}
+
+func TestCompositeStore_RevokeAllForUser(t *testing.T) {
+	store, _, server := newTestStore(t)
+	ctx := context.Background()
+
+	var userTokens []string
+	for i := 0; i < 3; i++ {
+		token, err := store.Create(ctx, newSession(1))
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		userTokens = append(userTokens, token)
+	}
+	otherToken, err := store.Create(ctx, newSession(2))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := store.RevokeAllForUser(ctx, 1); err != nil {
+		t.Fatalf("RevokeAllForUser() error = %v", err)
+	}
+
+	for _, token := range userTokens {
+		if server.Exists("session:" + session.HashToken(token)) {
+			t.Error("expected revoked session to be evicted from the cache")
+		}
+		if _, err := store.Get(ctx, token); !errors.Is(err, errors.ErrCodeNotFound) {
+			t.Errorf("expected revoked session to be rejected, got %v", err)
+		}
+	}
+
+	if _, err := store.Get(ctx, otherToken); err != nil {
+		t.Errorf("expected other user's session to stay valid, got %v", err)
+	}
+}
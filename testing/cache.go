@@ -0,0 +1,41 @@
+package testing
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/cache"
+	"github.com/Reg-Kris/pyairtable-go-shared/config"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// NewTestCache starts an in-memory Redis server and returns a cache client connected to it.
+// Both are closed when the test finishes; use the returned server to inspect keys or
+// fast-forward TTLs.
+func NewTestCache(t *testing.T) (*cache.Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+
+	host, portStr, err := net.SplitHostPort(server.Addr())
+	if err != nil {
+		t.Fatalf("Invalid test Redis address: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("Invalid test Redis port: %v", err)
+	}
+
+	client, err := cache.New(&config.RedisConfig{
+		Host:     host,
+		Port:     port,
+		PoolSize: 10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test cache: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client, server
+}
@@ -1,9 +1,11 @@
 package testing
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/Reg-Kris/pyairtable-go-shared/models"
+	"github.com/Reg-Kris/pyairtable-go-shared/utils"
 )
 
 // TestFixtures provides test data fixtures
@@ -352,9 +354,10 @@ func (f *TestFixtures) CreateTestPasswordResetToken(overrides ...func(*models.Pa
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
-		UserID:    1,
-		Token:     "reset_token_123",
-		ExpiresAt: time.Now().Add(1 * time.Hour),
+		UserID:      1,
+		Token:       utils.HashSHA256("reset_token_123"), // stored hashed, like models.NewPasswordResetToken
+		TokenHashed: true,
+		ExpiresAt:   time.Now().Add(1 * time.Hour),
 	}
 	
 	// Apply overrides
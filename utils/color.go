@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// namedColors are the CSS basic color keywords accepted by NormalizeHexColor
+var namedColors = map[string]string{
+	"black":   "#000000",
+	"silver":  "#c0c0c0",
+	"gray":    "#808080",
+	"grey":    "#808080",
+	"white":   "#ffffff",
+	"maroon":  "#800000",
+	"red":     "#ff0000",
+	"purple":  "#800080",
+	"fuchsia": "#ff00ff",
+	"green":   "#008000",
+	"lime":    "#00ff00",
+	"olive":   "#808000",
+	"yellow":  "#ffff00",
+	"navy":    "#000080",
+	"blue":    "#0000ff",
+	"teal":    "#008080",
+	"aqua":    "#00ffff",
+	"orange":  "#ffa500",
+}
+
+// NormalizeHexColor returns a #RGB or #RRGGBB color, or a CSS basic color name
+// such as "navy", as lowercase #rrggbb
+func NormalizeHexColor(s string) (string, error) {
+	color := strings.ToLower(strings.TrimSpace(s))
+	if named, ok := namedColors[color]; ok {
+		return named, nil
+	}
+	if !IsValidHexColor(color) {
+		return "", fmt.Errorf("invalid color %q: expected #RGB, #RRGGBB or a color name", s)
+	}
+
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color, nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestNormalizeHexColor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "#3b82f6", want: "#3b82f6"},
+		{input: "#3B82F6", want: "#3b82f6"},
+		{input: "#FA0", want: "#ffaa00"},
+		{input: " #abc ", want: "#aabbcc"},
+		{input: "Navy", want: "#000080"},
+		{input: "3b82f6", wantErr: true},
+		{input: "#3b82f", wantErr: true},
+		{input: "#3b82f6ff", wantErr: true},
+		{input: "#ggg", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "blurple", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeHexColor(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeHexColor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeHexColor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAESGCM(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	plaintext := []byte("integration api key")
+
+	ciphertext, err := EncryptAESGCM(key, plaintext)
+	if err != nil {
+		t.Fatalf("EncryptAESGCM() error = %v", err)
+	}
+	if bytes.Contains(ciphertext, plaintext) {
+		t.Error("expected the plaintext not to appear in the ciphertext")
+	}
+
+	again, _ := EncryptAESGCM(key, plaintext)
+	if bytes.Equal(ciphertext, again) {
+		t.Error("expected a fresh nonce for each encryption")
+	}
+
+	decrypted, err := DecryptAESGCM(key, ciphertext)
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("DecryptAESGCM() = %q, %v", decrypted, err)
+	}
+
+	tests := []struct {
+		name       string
+		key        []byte
+		ciphertext []byte
+	}{
+		{name: "wrong key", key: bytes.Repeat([]byte("x"), 32), ciphertext: ciphertext},
+		{name: "tampered", key: key, ciphertext: append(append([]byte{}, ciphertext[:len(ciphertext)-1]...), ciphertext[len(ciphertext)-1]^1)},
+		{name: "too short", key: key, ciphertext: ciphertext[:4]},
+		{name: "invalid key size", key: []byte("short"), ciphertext: ciphertext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecryptAESGCM(tt.key, tt.ciphertext); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Diff compares two structs or maps and returns the changed fields keyed by JSON
+// name, each with its [old, new] value. Nested structs and maps are compared field
+// by field and reported with dotted keys such as "settings.theme"; slices and
+// other values are compared as a whole. Fields only present on one side are
+// reported with nil on the other, and a nil before or after compares against an
+// empty value. Struct fields tagged `diff:"-"` (e.g. UpdatedAt, Version) are ignored.
+// Values are normalized through JSON, so numbers are returned as json.Number.
+func Diff(before, after interface{}) (map[string][2]interface{}, error) {
+	beforeTree, err := diffTree(reflect.ValueOf(before))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read before value: %w", err)
+	}
+	afterTree, err := diffTree(reflect.ValueOf(after))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read after value: %w", err)
+	}
+
+	beforeMap, ok := asDiffObject(beforeTree)
+	if !ok {
+		return nil, fmt.Errorf("before must be a struct or map, got %T", before)
+	}
+	afterMap, ok := asDiffObject(afterTree)
+	if !ok {
+		return nil, fmt.Errorf("after must be a struct or map, got %T", after)
+	}
+
+	changes := make(map[string][2]interface{})
+	diffObjects("", beforeMap, afterMap, changes)
+	return changes, nil
+}
+
+// asDiffObject treats nil as an empty object
+func asDiffObject(tree interface{}) (map[string]interface{}, bool) {
+	if tree == nil {
+		return map[string]interface{}{}, true
+	}
+	object, ok := tree.(map[string]interface{})
+	return object, ok
+}
+
+// diffObjects records every key whose value differs between before and after
+func diffObjects(prefix string, before, after map[string]interface{}, changes map[string][2]interface{}) {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		oldValue, newValue := before[key], after[key]
+		oldObject, oldIsObject := oldValue.(map[string]interface{})
+		newObject, newIsObject := newValue.(map[string]interface{})
+
+		switch {
+		case oldIsObject && newIsObject:
+			diffObjects(path, oldObject, newObject, changes)
+		case oldIsObject && newValue == nil:
+			diffObjects(path, oldObject, nil, changes)
+		case newIsObject && oldValue == nil:
+			diffObjects(path, nil, newObject, changes)
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes[path] = [2]interface{}{oldValue, newValue}
+		}
+	}
+}
+
+// diffTree converts v into nested map[string]interface{} for structs and maps,
+// and JSON-normalized values for everything else
+func diffTree(v reflect.Value) (interface{}, error) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && !implementsMarshaler(v):
+		object := make(map[string]interface{})
+		if err := addStructFields(v, object); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && !implementsMarshaler(v):
+		if v.IsNil() {
+			return nil, nil
+		}
+		object := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := diffTree(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			object[iter.Key().String()] = value
+		}
+		return object, nil
+	}
+
+	return normalizeJSON(v.Interface())
+}
+
+// addStructFields adds the exported fields of v under their JSON names, flattening
+// embedded structs the way encoding/json does
+func addStructFields(v reflect.Value, object map[string]interface{}) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("diff") == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !implementsMarshaler(embedded) {
+				if err := addStructFields(embedded, object); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		value, err := diffTree(fieldValue)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		object[name] = value
+	}
+	return nil
+}
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// implementsMarshaler reports whether v has custom JSON encoding (e.g. time.Time)
+func implementsMarshaler(v reflect.Value) bool {
+	return v.Type().Implements(marshalerType) || reflect.PointerTo(v.Type()).Implements(marshalerType)
+}
+
+// normalizeJSON round-trips value through JSON so equal values compare equal
+// regardless of their Go types
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var normalized interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
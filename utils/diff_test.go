@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type diffAudit struct {
+	UpdatedAt time.Time `json:"updated_at" diff:"-"`
+	Version   int       `json:"version" diff:"-"`
+}
+
+type diffSettings struct {
+	Theme    string `json:"theme"`
+	Timezone string `json:"timezone"`
+}
+
+type diffWorkspace struct {
+	diffAudit
+	Name     string        `json:"name"`
+	Tags     []string      `json:"tags"`
+	Settings diffSettings  `json:"settings"`
+	Owner    *diffSettings `json:"owner,omitempty"`
+	Secret   string        `json:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	base := diffWorkspace{
+		diffAudit: diffAudit{UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Version: 1},
+		Name:      "Projects",
+		Tags:      []string{"a"},
+		Settings:  diffSettings{Theme: "light", Timezone: "UTC"},
+	}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  func() interface{}
+		want   map[string][2]interface{}
+	}{
+		{
+			name:   "changed field",
+			before: base,
+			after: func() interface{} {
+				w := base
+				w.Name = "Roadmap"
+				return w
+			},
+			want: map[string][2]interface{}{"name": {"Projects", "Roadmap"}},
+		},
+		{
+			name:   "ignored fields",
+			before: base,
+			after: func() interface{} {
+				w := base
+				w.UpdatedAt = time.Now()
+				w.Version = 2
+				w.Secret = "changed"
+				return w
+			},
+			want: map[string][2]interface{}{},
+		},
+		{
+			name:   "nested change",
+			before: base,
+			after: func() interface{} {
+				w := base
+				w.Settings.Theme = "dark"
+				w.Tags = []string{"a", "b"}
+				return w
+			},
+			want: map[string][2]interface{}{
+				"settings.theme": {"light", "dark"},
+				"tags":           {[]interface{}{"a"}, []interface{}{"a", "b"}},
+			},
+		},
+		{
+			name:   "nil pointer becomes set",
+			before: base,
+			after: func() interface{} {
+				w := base
+				w.Owner = &diffSettings{Theme: "dark"}
+				return w
+			},
+			want: map[string][2]interface{}{
+				"owner.theme":    {nil, "dark"},
+				"owner.timezone": {nil, ""},
+			},
+		},
+		{
+			name:   "added and removed map keys",
+			before: map[string]interface{}{"name": "Projects", "count": 1, "old": true},
+			after: func() interface{} {
+				return map[string]interface{}{"name": "Projects", "count": 2.0, "new": "yes"}
+			},
+			want: map[string][2]interface{}{
+				"count": {json.Number("1"), json.Number("2")},
+				"old":   {true, nil},
+				"new":   {nil, "yes"},
+			},
+		},
+		{
+			name:   "struct against map",
+			before: diffSettings{Theme: "light", Timezone: "UTC"},
+			after: func() interface{} {
+				return map[string]interface{}{"theme": "light", "timezone": "Europe/Paris"}
+			},
+			want: map[string][2]interface{}{"timezone": {"UTC", "Europe/Paris"}},
+		},
+		{
+			name:   "nil before",
+			before: nil,
+			after: func() interface{} {
+				return &diffSettings{Theme: "light"}
+			},
+			want: map[string][2]interface{}{
+				"theme":    {nil, "light"},
+				"timezone": {nil, ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after())
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff_RejectsScalars(t *testing.T) {
+	if _, err := Diff(1, 2); err == nil {
+		t.Error("expected an error comparing non-struct values")
+	}
+}
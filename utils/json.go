@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"strconv"
+	"strings"
 )
 
 // ToJSON converts a struct to JSON string
@@ -58,6 +62,159 @@ func FromJSONReader(reader io.Reader, v interface{}) error {
 	return nil
 }
 
+// FromJSONUseNumber parses JSON bytes into v, keeping numbers as json.Number
+// so large integers don't lose precision by being converted to float64
+func FromJSONUseNumber(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return nil
+}
+
+// MarshalPreservingNumbers marshals v to JSON, writing json.Number values verbatim
+// and leaving HTML characters unescaped so decoded payloads round-trip unchanged
+func MarshalPreservingNumbers(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to marshal to JSON: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// CanonicalJSON marshals v to a canonical form for hashing and comparison: object
+// keys are sorted, whitespace and HTML escaping are dropped, and numbers are written
+// in one form so 1, 1.0 and 1e0 encode alike
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal to JSON: %w", err)
+	}
+	var tree interface{}
+	if err := FromJSONUseNumber(data, &tree); err != nil {
+		return nil, err
+	}
+	return MarshalPreservingNumbers(canonicalNumbers(tree))
+}
+
+// canonicalNumbers rewrites the json.Number values of a decoded tree in place.
+// Integers are kept digit for digit; anything else is rewritten as the shortest
+// float64 representation.
+func canonicalNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = canonicalNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = canonicalNumbers(item)
+		}
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			return v
+		}
+		if f, err := v.Float64(); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+	return value
+}
+
+// NumberToInt64 converts a decoded JSON number (json.Number or a Go numeric type) to int64
+func NumberToInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Int64()
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint:
+		return uint64ToInt64(uint64(n))
+	case uint32:
+		return int64(n), nil
+	case uint64:
+		return uint64ToInt64(n)
+	case float32:
+		return floatToInt64(float64(n))
+	case float64:
+		return floatToInt64(n)
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("cannot convert %T to int64", v)
+	}
+}
+
+// NumberToFloat64 converts a decoded JSON number (json.Number or a Go numeric type) to float64
+func NumberToFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case float32:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("cannot convert %T to float64", v)
+	}
+}
+
+// NumberToString converts a decoded JSON number to its exact decimal string form
+func NumberToString(v interface{}) (string, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.String(), nil
+	case int, int32, int64, uint, uint32, uint64:
+		return fmt.Sprintf("%d", n), nil
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("cannot convert %T to number string", v)
+	}
+}
+
+// floatToInt64 converts a float64 to int64 if it has no fractional part
+func floatToInt64(f float64) (int64, error) {
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("number %v overflows int64", f)
+	}
+	if f != float64(int64(f)) {
+		return 0, fmt.Errorf("number %v is not an integer", f)
+	}
+	return int64(f), nil
+}
+
+// uint64ToInt64 converts n, failing when it overflows int64
+func uint64ToInt64(n uint64) (int64, error) {
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("number %d overflows int64", n)
+	}
+	return int64(n), nil
+}
+
 // IsValidJSON checks if a string is valid JSON
 func IsValidJSON(jsonStr string) bool {
 	var temp interface{}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by the JSONLimitError DecodeLimited returns
+var (
+	ErrJSONTooLarge = errors.New("JSON payload is too large")
+	ErrJSONTooDeep  = errors.New("JSON payload is nested too deeply")
+)
+
+// JSONLimitError reports which limit an untrusted JSON payload exceeded. It
+// matches ErrJSONTooLarge or ErrJSONTooDeep with errors.Is.
+type JSONLimitError struct {
+	Err   error
+	Limit int64 // Bytes for ErrJSONTooLarge, levels for ErrJSONTooDeep
+}
+
+// Error implements the error interface
+func (e *JSONLimitError) Error() string {
+	return fmt.Sprintf("%v (limit %d)", e.Err, e.Limit)
+}
+
+// Unwrap returns ErrJSONTooLarge or ErrJSONTooDeep
+func (e *JSONLimitError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeLimited parses untrusted JSON into v after checking it is at most
+// maxBytes long and nests objects and arrays at most maxDepth levels deep
+// (`{"a": [1]}` has depth 2). Both checks run before anything is decoded; a limit
+// of 0 or less disables its check.
+func DecodeLimited(data []byte, v interface{}, maxDepth int, maxBytes int64) error {
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return &JSONLimitError{Err: ErrJSONTooLarge, Limit: maxBytes}
+	}
+	if maxDepth > 0 && jsonDepthExceeds(data, maxDepth) {
+		return &JSONLimitError{Err: ErrJSONTooDeep, Limit: int64(maxDepth)}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	return nil
+}
+
+// jsonDepthExceeds reports whether objects and arrays in data nest deeper than
+// maxDepth, skipping brackets inside strings
+func jsonDepthExceeds(data []byte, maxDepth int) bool {
+	depth := 0
+	inString, escaped := false, false
+
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeLimited(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat(`{"a":`, depth-1) + `[1]` + strings.Repeat(`}`, depth-1)
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		maxDepth int
+		maxBytes int64
+		wantErr  error
+	}{
+		{name: "within limits", data: `{"name": "Launch", "tags": ["a", "b"]}`, maxDepth: 2, maxBytes: 100},
+		{name: "at the depth limit", data: nested(5), maxDepth: 5},
+		{name: "too deep", data: nested(6), maxDepth: 5, wantErr: ErrJSONTooDeep},
+		{name: "deep arrays", data: strings.Repeat("[", 10000) + strings.Repeat("]", 10000), maxDepth: 32, wantErr: ErrJSONTooDeep},
+		{name: "brackets in strings don't count", data: `{"text": "[[[{{{\"[["}`, maxDepth: 1},
+		{name: "too large", data: `{"name": "` + strings.Repeat("x", 100) + `"}`, maxBytes: 64, wantErr: ErrJSONTooLarge},
+		{name: "limits disabled", data: nested(50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			err := DecodeLimited([]byte(tt.data), &v, tt.maxDepth, tt.maxBytes)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("DecodeLimited() error = %v", err)
+				}
+				if v == nil {
+					t.Error("DecodeLimited() decoded nothing")
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeLimited() error = %v, want %v", err, tt.wantErr)
+			}
+			var limitErr *JSONLimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit == 0 {
+				t.Errorf("DecodeLimited() error = %#v, want a JSONLimitError with the limit", err)
+			}
+			if v != nil {
+				t.Errorf("DecodeLimited() decoded %v despite the error", v)
+			}
+		})
+	}
+
+	var v map[string]interface{}
+	if err := DecodeLimited([]byte(`{"a": `), &v, 5, 100); err == nil || errors.Is(err, ErrJSONTooDeep) {
+		t.Errorf("DecodeLimited() of malformed JSON error = %v, want a syntax error", err)
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestFromJSONUseNumber_RoundTrip(t *testing.T) {
+	input := []byte(`{"id":1234567890123456789,"price":19.99,"name":"<b>record</b>"}`)
+
+	var data map[string]interface{}
+	if err := FromJSONUseNumber(input, &data); err != nil {
+		t.Fatalf("FromJSONUseNumber() error = %v", err)
+	}
+
+	id, ok := data["id"].(json.Number)
+	if !ok {
+		t.Fatalf("expected id to be json.Number, got %T", data["id"])
+	}
+	if id.String() != "1234567890123456789" {
+		t.Errorf("expected id 1234567890123456789, got %s", id.String())
+	}
+
+	output, err := MarshalPreservingNumbers(data)
+	if err != nil {
+		t.Fatalf("MarshalPreservingNumbers() error = %v", err)
+	}
+
+	var roundTripped map[string]interface{}
+	if err := FromJSONUseNumber(output, &roundTripped); err != nil {
+		t.Fatalf("FromJSONUseNumber() on output error = %v", err)
+	}
+
+	got, err := NumberToInt64(roundTripped["id"])
+	if err != nil {
+		t.Fatalf("NumberToInt64() error = %v", err)
+	}
+	if got != 1234567890123456789 {
+		t.Errorf("precision lost in round trip: got %d", got)
+	}
+	if roundTripped["name"] != "<b>record</b>" {
+		t.Errorf("expected name to round trip unchanged, got %v", roundTripped["name"])
+	}
+}
+
+func TestFromJSONBytes_LosesPrecision(t *testing.T) {
+	var data map[string]interface{}
+	if err := FromJSONBytes([]byte(`{"id":1234567890123456789}`), &data); err != nil {
+		t.Fatalf("FromJSONBytes() error = %v", err)
+	}
+
+	if _, ok := data["id"].(float64); !ok {
+		t.Fatalf("expected float64 without UseNumber, got %T", data["id"])
+	}
+}
+
+func TestNumberConverters(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantInt   int64
+		wantFloat float64
+		wantStr   string
+		intErr    bool
+	}{
+		{name: "json number integer", value: json.Number("42"), wantInt: 42, wantFloat: 42, wantStr: "42"},
+		{name: "json number decimal", value: json.Number("1.5"), wantFloat: 1.5, wantStr: "1.5", intErr: true},
+		{name: "integral float64", value: float64(7), wantInt: 7, wantFloat: 7, wantStr: "7"},
+		{name: "fractional float64", value: 2.25, wantFloat: 2.25, wantStr: "2.25", intErr: true},
+		{name: "int64", value: int64(-3), wantInt: -3, wantFloat: -3, wantStr: "-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotInt, err := NumberToInt64(tt.value)
+			if (err != nil) != tt.intErr {
+				t.Errorf("NumberToInt64() error = %v, wantErr %v", err, tt.intErr)
+			}
+			if !tt.intErr && gotInt != tt.wantInt {
+				t.Errorf("NumberToInt64() = %d, want %d", gotInt, tt.wantInt)
+			}
+
+			gotFloat, err := NumberToFloat64(tt.value)
+			if err != nil {
+				t.Errorf("NumberToFloat64() error = %v", err)
+			}
+			if gotFloat != tt.wantFloat {
+				t.Errorf("NumberToFloat64() = %v, want %v", gotFloat, tt.wantFloat)
+			}
+
+			gotStr, err := NumberToString(tt.value)
+			if err != nil {
+				t.Errorf("NumberToString() error = %v", err)
+			}
+			if gotStr != tt.wantStr {
+				t.Errorf("NumberToString() = %q, want %q", gotStr, tt.wantStr)
+			}
+		})
+	}
+
+	if _, err := NumberToInt64(true); err == nil {
+		t.Error("expected error converting bool to int64")
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	type nested struct {
+		Zeta  int    `json:"zeta"`
+		Alpha string `json:"alpha"`
+	}
+
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{name: "sorted keys", input: map[string]interface{}{"b": 1, "a": map[string]interface{}{"d": true, "c": nil}}, want: `{"a":{"c":null,"d":true},"b":1}`},
+		{name: "struct fields sorted", input: nested{Zeta: 1, Alpha: "<x>"}, want: `{"alpha":"<x>","zeta":1}`},
+		{name: "numbers in one form", input: []interface{}{json.Number("1.0"), json.Number("1e2"), 2.5, json.Number("1234567890123456789")}, want: `[1,100,2.5,1234567890123456789]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(tt.input)
+			if err != nil {
+				t.Fatalf("CanonicalJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumberToInt64_Overflow(t *testing.T) {
+	for _, value := range []interface{}{uint64(math.MaxInt64) + 1, uint64(math.MaxUint64), float64(math.MaxInt64), -math.MaxFloat64} {
+		if got, err := NumberToInt64(value); err == nil {
+			t.Errorf("NumberToInt64(%v) = %d, want an overflow error", value, got)
+		}
+	}
+
+	if got, err := NumberToInt64(uint64(math.MaxInt64)); err != nil || got != math.MaxInt64 {
+		t.Errorf("NumberToInt64(MaxInt64) = %d, %v", got, err)
+	}
+	if math.MaxUint == math.MaxUint64 {
+		if _, err := NumberToInt64(uint(math.MaxUint)); err == nil {
+			t.Error("expected a uint above MaxInt64 to overflow")
+		}
+	}
+}
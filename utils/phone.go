@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// ErrInvalidPhone is wrapped by the errors NormalizePhone returns
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// NormalizePhone validates a phone number and returns it in E.164 format, e.g.
+// "(415) 555-2671" with region "US" becomes "+14155552671". defaultRegion is the
+// ISO 3166 country code used for numbers written without a "+" prefix; numbers
+// with a prefix are parsed as international whatever the region.
+func NormalizePhone(raw, defaultRegion string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidPhone)
+	}
+
+	number, err := phonenumbers.Parse(raw, strings.ToUpper(defaultRegion))
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %v", ErrInvalidPhone, raw, err)
+	}
+	if !phonenumbers.IsValidNumber(number) {
+		return "", fmt.Errorf("%w %q: not a valid number for its region", ErrInvalidPhone, raw)
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// IsValidPhone checks if raw is a valid phone number, reading numbers without a
+// "+" prefix as local to region
+func IsValidPhone(raw, region string) bool {
+	_, err := NormalizePhone(raw, region)
+	return err == nil
+}
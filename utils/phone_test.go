@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+		want   string
+	}{
+		{name: "US national format", raw: "(415) 555-2671", region: "US", want: "+14155552671"},
+		{name: "US with country code", raw: "+1 415-555-2671", region: "", want: "+14155552671"},
+		{name: "lowercase region", raw: "415.555.2671", region: "us", want: "+14155552671"},
+		{name: "UK national format", raw: "020 7946 0958", region: "GB", want: "+442079460958"},
+		{name: "international ignores region", raw: "+49 30 901820", region: "US", want: "+4930901820"},
+		{name: "international dialling prefix", raw: "00 33 1 42 68 53 00", region: "FR", want: "+33142685300"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhone(tt.raw, tt.region)
+			if err != nil {
+				t.Fatalf("NormalizePhone() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhone() = %q, want %q", got, tt.want)
+			}
+			if !IsValidPhone(tt.raw, tt.region) {
+				t.Error("IsValidPhone() = false, want true")
+			}
+		})
+	}
+}
+
+func TestNormalizePhone_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+	}{
+		{name: "empty", raw: "  ", region: "US"},
+		{name: "letters", raw: "call me", region: "US"},
+		{name: "too short", raw: "555-12", region: "US"},
+		{name: "national number without region", raw: "415 555 2671", region: ""},
+		{name: "unassigned country code", raw: "+999 123 4567", region: ""},
+		{name: "invalid for region", raw: "(123) 456-7890", region: "US"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := NormalizePhone(tt.raw, tt.region); !errors.Is(err, ErrInvalidPhone) {
+				t.Errorf("NormalizePhone() = %q, %v, want ErrInvalidPhone", got, err)
+			}
+			if IsValidPhone(tt.raw, tt.region) {
+				t.Error("IsValidPhone() = true, want false")
+			}
+		})
+	}
+}
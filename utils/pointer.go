@@ -0,0 +1,25 @@
+package utils
+
+// Ptr returns a pointer to v, e.g. for optional fields such as ExpiresAt: utils.Ptr(time.Now())
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Deref returns the value p points to, or def when p is nil
+func Deref[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// Coalesce returns the first value that is not the zero value of T, or the zero value if all are
+func Coalesce[T comparable](vals ...T) T {
+	var zero T
+	for _, v := range vals {
+		if v != zero {
+			return v
+		}
+	}
+	return zero
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPtrAndDeref(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	p := Ptr(now)
+	if p == nil || !p.Equal(now) {
+		t.Fatalf("Ptr() = %v, want pointer to %v", p, now)
+	}
+	if Ptr(now) == p {
+		t.Error("Ptr() should return a new pointer on each call")
+	}
+
+	fallback := time.Unix(0, 0).UTC()
+	tests := []struct {
+		name string
+		p    *time.Time
+		want time.Time
+	}{
+		{name: "non-nil", p: p, want: now},
+		{name: "nil uses default", p: nil, want: fallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Deref(tt.p, fallback); !got.Equal(tt.want) {
+				t.Errorf("Deref() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := Deref[int](nil, 0); got != 0 {
+		t.Errorf("Deref(nil, 0) = %d, want 0", got)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		name string
+		vals []string
+		want string
+	}{
+		{name: "first non-zero", vals: []string{"", "primary", "secondary"}, want: "primary"},
+		{name: "first value set", vals: []string{"a", "", "b"}, want: "a"},
+		{name: "all zero", vals: []string{"", ""}, want: ""},
+		{name: "no values", vals: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Coalesce(tt.vals...); got != tt.want {
+				t.Errorf("Coalesce(%q) = %q, want %q", tt.vals, got, tt.want)
+			}
+		})
+	}
+
+	if got := Coalesce(0, 0, 3, 4); got != 3 {
+		t.Errorf("Coalesce(ints) = %d, want 3", got)
+	}
+}
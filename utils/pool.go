@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"bytes"
+	"sync"
+)
+
+// DefaultMaxPooledBufferSize is the largest buffer a BufferPool keeps by default
+const DefaultMaxPooledBufferSize = 64 << 10 // 64KB
+
+// BufferPool reuses bytes.Buffers across requests to cut allocations and GC
+// pressure. Buffers that grew beyond the pool's max size are dropped on Put so a
+// single large response doesn't pin memory.
+type BufferPool struct {
+	pool    sync.Pool
+	maxSize int
+}
+
+// NewBufferPool creates a pool keeping buffers up to maxSize bytes, or
+// DefaultMaxPooledBufferSize when maxSize is not positive
+func NewBufferPool(maxSize int) *BufferPool {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPooledBufferSize
+	}
+
+	return &BufferPool{
+		pool: sync.Pool{
+			New: func() interface{} { return new(bytes.Buffer) },
+		},
+		maxSize: maxSize,
+	}
+}
+
+// Get returns an empty buffer
+func (p *BufferPool) Get() *bytes.Buffer {
+	return p.pool.Get().(*bytes.Buffer)
+}
+
+// Put resets buf and returns it to the pool. buf must not be used afterwards.
+func (p *BufferPool) Put(buf *bytes.Buffer) {
+	if buf == nil || buf.Cap() > p.maxSize {
+		return
+	}
+	buf.Reset()
+	p.pool.Put(buf)
+}
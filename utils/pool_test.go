@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBufferPool(t *testing.T) {
+	pool := NewBufferPool(1024)
+
+	buf := pool.Get()
+	buf.WriteString("hello")
+	pool.Put(buf)
+
+	if got := pool.Get(); got.Len() != 0 {
+		t.Errorf("Get() returned a buffer holding %q, want it reset", got.String())
+	}
+
+	large := bytes.NewBuffer(make([]byte, 0, 4096))
+	pool.Put(large) // dropped, larger than maxSize
+	pool.Put(nil)
+}
+
+var benchmarkPayload = bytes.Repeat([]byte(`{"id":"rec_1","fields":{"name":"Ada"}},`), 100)
+
+func BenchmarkBuffer(b *testing.B) {
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := new(bytes.Buffer)
+			buf.Write(benchmarkPayload)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		pool := NewBufferPool(0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := pool.Get()
+			buf.Write(benchmarkPayload)
+			pool.Put(buf)
+		}
+	})
+}
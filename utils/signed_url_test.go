@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedURL(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	defer func(original func() time.Time) { TimeNow = original }(TimeNow)
+	TimeNow = func() time.Time { return now }
+
+	signed := SignURL("https://app.example.com/views/42?mode=grid", map[string]string{"view": "42", "user": "7"}, secret, time.Hour)
+	if signed == "" {
+		t.Fatal("SignURL() returned an empty URL")
+	}
+
+	tamper := func(key, value string) string {
+		u, _ := url.Parse(signed)
+		query := u.Query()
+		query.Set(key, value)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		secret  []byte
+		at      time.Time
+		wantErr error
+	}{
+		{name: "valid", url: signed, secret: secret, at: now},
+		{name: "valid until expiry", url: signed, secret: secret, at: now.Add(time.Hour - time.Second)},
+		{name: "expired", url: signed, secret: secret, at: now.Add(time.Hour), wantErr: ErrSignedURLExpired},
+		{name: "modified parameter", url: tamper("view", "43"), secret: secret, at: now, wantErr: ErrSignedURLInvalid},
+		{name: "modified original parameter", url: tamper("mode", "kanban"), secret: secret, at: now, wantErr: ErrSignedURLInvalid},
+		{name: "extended expiry", url: tamper(SignedURLExpiresParam, "9999999999"), secret: secret, at: now, wantErr: ErrSignedURLInvalid},
+		{name: "modified path", url: strings.Replace(signed, "/views/42", "/views/43", 1), secret: secret, at: now, wantErr: ErrSignedURLInvalid},
+		{name: "wrong secret", url: signed, secret: []byte("other"), at: now, wantErr: ErrSignedURLInvalid},
+		{name: "unsigned", url: "https://app.example.com/views/42", secret: secret, at: now, wantErr: ErrSignedURLInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TimeNow = func() time.Time { return tt.at }
+
+			ok, err := VerifySignedURL(tt.url, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifySignedURL() error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != (tt.wantErr == nil) {
+				t.Errorf("VerifySignedURL() = %v, want %v", ok, tt.wantErr == nil)
+			}
+		})
+	}
+}
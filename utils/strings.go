@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // IsEmpty checks if a string is empty or contains only whitespace
@@ -79,17 +80,62 @@ func ToPascalCase(s string) string {
 	return result.String()
 }
 
-// Truncate truncates a string to the specified length
+// Truncate truncates a string to at most length bytes, replacing the end with "..."
+// when it was cut. It never splits a multi-byte UTF-8 character, so the result may
+// be a few bytes shorter than length.
 func Truncate(s string, length int) string {
 	if len(s) <= length {
 		return s
 	}
 	
 	if length <= 3 {
-		return s[:length]
+		return cutBytes(s, length)
 	}
 	
-	return s[:length-3] + "..."
+	return cutBytes(s, length-3) + "..."
+}
+
+// TruncateRunes truncates a string to at most n characters (runes), replacing the
+// end with "..." when it was cut
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	if n <= 3 {
+		return cutRunes(s, n)
+	}
+
+	return cutRunes(s, n-3) + "..."
+}
+
+// cutBytes returns the longest prefix of s of at most n bytes that ends on a rune boundary
+func cutBytes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// cutRunes returns the first n runes of s
+func cutRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
 }
 
 // Contains checks if a slice of strings contains a specific string
@@ -140,6 +186,28 @@ func IsValidURL(url string) bool {
 	return urlRegex.MatchString(url)
 }
 
+var (
+	slugRegex     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	hexColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	e164Regex     = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+)
+
+// IsValidSlug checks if a string is a slug as produced by GenerateSlug: lowercase
+// letters and digits separated by single hyphens
+func IsValidSlug(s string) bool {
+	return slugRegex.MatchString(s)
+}
+
+// IsValidHexColor checks if a string is a #RGB or #RRGGBB color
+func IsValidHexColor(s string) bool {
+	return hexColorRegex.MatchString(s)
+}
+
+// IsValidE164Phone checks if a string is a phone number in E.164 format, e.g. +14155552671
+func IsValidE164Phone(s string) bool {
+	return e164Regex.MatchString(s)
+}
+
 // MaskEmail masks an email address for privacy
 func MaskEmail(email string) string {
 	parts := strings.Split(email, "@")
@@ -158,6 +226,57 @@ func MaskEmail(email string) string {
 	return masked + "@" + domain
 }
 
+// MaskString replaces all but the first revealStart and last revealEnd characters
+// of s with '*', e.g. MaskString("sk_live_abcdef", 8, 2) is "sk_live_****ef".
+// When the revealed parts would cover the whole string nothing is revealed.
+func MaskString(s string, revealStart, revealEnd int) string {
+	runes := []rune(s)
+	if revealStart < 0 {
+		revealStart = 0
+	}
+	if revealEnd < 0 {
+		revealEnd = 0
+	}
+	if revealStart+revealEnd >= len(runes) {
+		revealStart, revealEnd = 0, 0
+	}
+
+	for i := revealStart; i < len(runes)-revealEnd; i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+// MaskPhone masks every digit of a phone number except the last four, keeping
+// formatting such as "+1 (555) 123-4567" -> "+* (***) ***-4567". Numbers with
+// fewer than seven digits are masked completely.
+func MaskPhone(phone string) string {
+	digits := 0
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+
+	reveal := 4
+	if digits < 7 {
+		reveal = 0
+	}
+
+	runes := []rune(phone)
+	seen := 0
+	for i, r := range runes {
+		if !unicode.IsDigit(r) {
+			continue
+		}
+		seen++
+		if seen <= digits-reveal {
+			runes[i] = '*'
+		}
+	}
+	return string(runes)
+}
+
 // SanitizeString removes potentially harmful characters from a string
 func SanitizeString(s string) string {
 	// Remove control characters
@@ -212,14 +331,15 @@ func WordCount(s string) int {
 }
 
 // Ellipsis adds ellipsis to a string if it exceeds the maximum length
+// in bytes. Like Truncate it cuts on a UTF-8 character boundary.
 func Ellipsis(s string, maxLength int) string {
 	if len(s) <= maxLength {
 		return s
 	}
 	
 	if maxLength <= 3 {
-		return s[:maxLength]
+		return cutBytes(s, maxLength)
 	}
 	
-	return s[:maxLength-3] + "..."
+	return cutBytes(s, maxLength-3) + "..."
 }
\ No newline at end of file
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMaskString(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           string
+		revealStart int
+		revealEnd   int
+		want        string
+	}{
+		{name: "api key prefix", s: "sk_live_abcdef", revealStart: 8, revealEnd: 2, want: "sk_live_****ef"},
+		{name: "reveal end only", s: "secretvalue", revealStart: 0, revealEnd: 3, want: "********lue"},
+		{name: "reveal nothing", s: "secret", revealStart: 0, revealEnd: 0, want: "******"},
+		{name: "negative reveal", s: "secret", revealStart: -1, revealEnd: -5, want: "******"},
+		{name: "reveal covers everything", s: "abcd", revealStart: 2, revealEnd: 2, want: "****"},
+		{name: "reveal exceeds length", s: "ab", revealStart: 4, revealEnd: 4, want: "**"},
+		{name: "single character", s: "a", revealStart: 1, revealEnd: 0, want: "*"},
+		{name: "empty", s: "", revealStart: 2, revealEnd: 2, want: ""},
+		{name: "multibyte", s: "pässwörd", revealStart: 1, revealEnd: 1, want: "p******d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskString(tt.s, tt.revealStart, tt.revealEnd); got != tt.want {
+				t.Errorf("MaskString(%q, %d, %d) = %q, want %q", tt.s, tt.revealStart, tt.revealEnd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskPhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{phone: "+1 (555) 123-4567", want: "+* (***) ***-4567"},
+		{phone: "+447911123456", want: "+********3456"},
+		{phone: "5551234", want: "***1234"},
+		{phone: "12345", want: "*****"},
+		{phone: "ext. 12", want: "ext. **"},
+		{phone: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			if got := MaskPhone(tt.phone); got != tt.want {
+				t.Errorf("MaskPhone(%q) = %q, want %q", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		length int
+		want   string
+	}{
+		{name: "short ascii", s: "hello", length: 10, want: "hello"},
+		{name: "exact ascii", s: "hello", length: 5, want: "hello"},
+		{name: "ascii", s: "hello world", length: 8, want: "hello..."},
+		{name: "no room for suffix", s: "hello", length: 2, want: "he"},
+		{name: "zero length", s: "hello", length: 0, want: ""},
+		{name: "negative length", s: "hello", length: -1, want: ""},
+		{name: "accented cut mid rune", s: "café au lait", length: 7, want: "caf..."},
+		{name: "accented on boundary", s: "café au lait", length: 8, want: "café..."},
+		{name: "emoji", s: "👍👍👍👍", length: 9, want: "👍..."},
+		{name: "emoji without suffix", s: "👍👍", length: 3, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, truncate := range map[string]func(string, int) string{"Truncate": Truncate, "Ellipsis": Ellipsis} {
+				got := truncate(tt.s, tt.length)
+				if got != tt.want {
+					t.Errorf("%s(%q, %d) = %q, want %q", name, tt.s, tt.length, got, tt.want)
+				}
+				if !utf8.ValidString(got) {
+					t.Errorf("%s(%q, %d) = %q is not valid UTF-8", name, tt.s, tt.length, got)
+				}
+				if tt.length >= 0 && len(got) > tt.length {
+					t.Errorf("%s(%q, %d) = %q exceeds %d bytes", name, tt.s, tt.length, got, tt.length)
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{name: "short", s: "héllo", n: 5, want: "héllo"},
+		{name: "accented", s: "Ünïcödé strïng", n: 8, want: "Ünïcö..."},
+		{name: "emoji", s: "🎉🎉🎉🎉🎉🎉", n: 5, want: "🎉🎉..."},
+		{name: "cjk", s: "日本語のテキスト", n: 4, want: "日..."},
+		{name: "no room for suffix", s: "日本語のテキスト", n: 2, want: "日本"},
+		{name: "zero", s: "日本語", n: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateRunes(tt.s, tt.n)
+			if got != tt.want {
+				t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("TruncateRunes(%q, %d) = %q is not valid UTF-8", tt.s, tt.n, got)
+			}
+			if count := utf8.RuneCountInString(got); count > tt.n && tt.n >= 0 {
+				t.Errorf("TruncateRunes(%q, %d) has %d runes", tt.s, tt.n, count)
+			}
+		})
+	}
+}
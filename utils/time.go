@@ -1,12 +1,23 @@
 package utils
 
 import (
+	"strings"
 	"time"
 )
 
 // TimeNow returns the current time (useful for testing)
 var TimeNow = time.Now
 
+// IsValidTimezone checks if a string is an IANA time zone name such as
+// "Europe/Berlin" or "UTC". "Local" is rejected since it depends on the host.
+func IsValidTimezone(name string) bool {
+	if name == "" || strings.EqualFold(name, "local") {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
 // FormatTime formats time in ISO 8601 format
 func FormatTime(t time.Time) string {
 	return t.Format(time.RFC3339)
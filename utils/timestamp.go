@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// TimestampFormat selects how Timestamp values are written to JSON
+type TimestampFormat int32
+
+const (
+	// TimestampUnixString writes Unix seconds as a string, e.g. "1640995200". It is
+	// the legacy envelope format and stays the default until the next major version.
+	TimestampUnixString TimestampFormat = iota
+	// TimestampRFC3339 writes UTC RFC3339, e.g. "2022-01-01T00:00:00Z", the same
+	// format as time.Time fields in models and health checks
+	TimestampRFC3339
+)
+
+var timestampFormat atomic.Int32
+
+// SetTimestampFormat selects the JSON format of all Timestamp values. Services opt
+// in to RFC3339 with SetTimestampFormat(TimestampRFC3339) at startup once their
+// clients accept it.
+func SetTimestampFormat(format TimestampFormat) {
+	timestampFormat.Store(int32(format))
+}
+
+// CurrentTimestampFormat returns the format set by SetTimestampFormat
+func CurrentTimestampFormat() TimestampFormat {
+	return TimestampFormat(timestampFormat.Load())
+}
+
+// Timestamp is a point in time used by response envelopes, serialized in the
+// format chosen with SetTimestampFormat. It reads both formats back.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// TimestampNow returns the current time as a Timestamp
+func TimestampNow() Timestamp {
+	return Timestamp{Time: TimeNow()}
+}
+
+// MarshalJSON implements json.Marshaler
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if CurrentTimestampFormat() == TimestampRFC3339 {
+		return json.Marshal(t.UTC().Format(time.RFC3339))
+	}
+	return json.Marshal(strconv.FormatInt(t.Unix(), 10))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting RFC3339 strings and Unix
+// seconds as a string or number
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		raw = string(data)
+	}
+
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		t.Time = time.Unix(seconds, 0).UTC()
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+	t.Time = parsed
+	return nil
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_JSON(t *testing.T) {
+	defer SetTimestampFormat(CurrentTimestampFormat())
+
+	ts := NewTimestamp(time.Date(2022, 1, 1, 1, 0, 0, 500, time.FixedZone("CET", 3600)))
+
+	tests := []struct {
+		name   string
+		format TimestampFormat
+		want   string
+	}{
+		{name: "legacy unix string", format: TimestampUnixString, want: `"1640995200"`},
+		{name: "rfc3339 in utc", format: TimestampRFC3339, want: `"2022-01-01T00:00:00Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTimestampFormat(tt.format)
+
+			data, err := json.Marshal(ts)
+			if err != nil || string(data) != tt.want {
+				t.Fatalf("Marshal() = %s, %v; want %s", data, err, tt.want)
+			}
+
+			var decoded Timestamp
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if decoded.Unix() != ts.Unix() {
+				t.Errorf("round trip = %v, want %v", decoded.Time, ts.Time)
+			}
+		})
+	}
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: `"2022-01-01T00:00:00Z"`, want: 1640995200},
+		{input: `"2022-01-01T01:00:00+01:00"`, want: 1640995200},
+		{input: `"1640995200"`, want: 1640995200},
+		{input: `1640995200`, want: 1640995200},
+		{input: `null`, want: time.Time{}.Unix()},
+		{input: `"yesterday"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var ts Timestamp
+			err := json.Unmarshal([]byte(tt.input), &ts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && ts.Unix() != tt.want {
+				t.Errorf("Unmarshal() = %d, want %d", ts.Unix(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Package warmup runs startup tasks such as priming caches and opening connection
+// pools, and reports their progress so readiness can wait for them to finish.
+package warmup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/health"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout bounds the whole warmup when no timeout is set
+const DefaultTimeout = 30 * time.Second
+
+// Func is a warmup task, e.g. priming a cache or compiling templates
+type Func func(ctx context.Context) error
+
+// State is the state of a single warmup task
+type State string
+
+const (
+	StatePending State = "pending"
+	StateRunning State = "running"
+	StateDone    State = "done"
+	StateFailed  State = "failed"
+)
+
+// TaskStatus reports the progress of one warmup task
+type TaskStatus struct {
+	Name     string        `json:"name"`
+	State    State         `json:"state"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Progress reports the progress of all warmup tasks
+type Progress struct {
+	Ready     bool         `json:"ready"`
+	Total     int          `json:"total"`
+	Completed int          `json:"completed"`
+	Failed    int          `json:"failed"`
+	Tasks     []TaskStatus `json:"tasks"`
+}
+
+type task struct {
+	fn     Func
+	status TaskStatus
+}
+
+// Warmer holds the registered warmup tasks and runs them once
+type Warmer struct {
+	mu      sync.RWMutex
+	tasks   []*task
+	timeout time.Duration
+	started bool
+	done    chan struct{}
+}
+
+// New creates a warmer with the default timeout
+func New() *Warmer {
+	return &Warmer{
+		timeout: DefaultTimeout,
+		done:    make(chan struct{}),
+	}
+}
+
+// SetTimeout sets how long all warmup tasks together may take
+func (w *Warmer) SetTimeout(timeout time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timeout = timeout
+}
+
+// Register adds a warmup task. Tasks registered after Start are ignored.
+func (w *Warmer) Register(name string, fn Func) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.tasks = append(w.tasks, &task{fn: fn, status: TaskStatus{Name: name, State: StatePending}})
+}
+
+// Start runs the warmup in the background and returns immediately
+func (w *Warmer) Start(ctx context.Context) {
+	go w.Run(ctx)
+}
+
+// Run runs all tasks concurrently and waits for them to finish or time out.
+// Warmup is best effort: once every task has finished, successfully or not, the
+// warmer is ready. The returned error lists the tasks that failed.
+// Only the first call runs the tasks; later calls wait for it.
+func (w *Warmer) Run(ctx context.Context) error {
+	w.mu.Lock()
+	if w.started {
+		w.mu.Unlock()
+		<-w.done
+		return w.err()
+	}
+	w.started = true
+	tasks := w.tasks
+	timeout := w.timeout
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		go func(t *task) {
+			defer wg.Done()
+			w.runTask(ctx, t)
+		}(t)
+	}
+	wg.Wait()
+
+	close(w.done)
+	return w.err()
+}
+
+// runTask runs one task, giving up when ctx ends even if the task ignores it
+func (w *Warmer) runTask(ctx context.Context, t *task) {
+	w.setState(t, StateRunning, nil, 0)
+	start := time.Now()
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("warmup panicked: %v", r)
+			}
+		}()
+		result <- t.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		w.setState(t, StateFailed, err, time.Since(start))
+		return
+	}
+	w.setState(t, StateDone, nil, time.Since(start))
+}
+
+func (w *Warmer) setState(t *task, state State, err error, duration time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	t.status.State = state
+	t.status.Duration = duration
+	if err != nil {
+		t.status.Error = err.Error()
+	}
+}
+
+// err summarizes failed tasks
+func (w *Warmer) err() error {
+	var failed []string
+	for _, status := range w.Progress().Tasks {
+		if status.State == StateFailed {
+			failed = append(failed, fmt.Sprintf("%s: %s", status.Name, status.Error))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("warmup failed: %v", failed)
+	}
+	return nil
+}
+
+// Done is closed once every warmup task has finished
+func (w *Warmer) Done() <-chan struct{} {
+	return w.done
+}
+
+// Ready reports whether the warmup has finished
+func (w *Warmer) Ready() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Progress returns a snapshot of every task's status
+func (w *Warmer) Progress() Progress {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	progress := Progress{
+		Ready: w.Ready(),
+		Total: len(w.tasks),
+		Tasks: make([]TaskStatus, 0, len(w.tasks)),
+	}
+	for _, t := range w.tasks {
+		switch t.status.State {
+		case StateDone:
+			progress.Completed++
+		case StateFailed:
+			progress.Completed++
+			progress.Failed++
+		}
+		progress.Tasks = append(progress.Tasks, t.status)
+	}
+	return progress
+}
+
+// Check returns a health check that is down until the warmup has finished, so
+// adding it to the readiness checker keeps traffic away from a cold instance
+func (w *Warmer) Check() health.Check {
+	return func(ctx context.Context) health.CheckResult {
+		progress := w.Progress()
+		result := health.CheckResult{
+			Status:    health.StatusUp,
+			Message:   "Warmup complete",
+			Timestamp: time.Now(),
+			Details: map[string]interface{}{
+				"total":     progress.Total,
+				"completed": progress.Completed,
+				"failed":    progress.Failed,
+			},
+		}
+		if !progress.Ready {
+			result.Status = health.StatusDown
+			result.Message = "Warmup in progress"
+		} else if progress.Failed > 0 {
+			// Still ready: a failed warmup only means a colder start
+			result.Message = "Warmup completed with failures"
+		}
+		return result
+	}
+}
+
+// Handler returns a Gin handler reporting warmup progress: 200 once the warmup
+// has finished, 503 while it is still running
+func (w *Warmer) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		progress := w.Progress()
+
+		statusCode := http.StatusOK
+		if !progress.Ready {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		c.JSON(statusCode, progress)
+	}
+}
@@ -0,0 +1,128 @@
+package warmup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Reg-Kris/pyairtable-go-shared/health"
+	"github.com/gin-gonic/gin"
+)
+
+func TestWarmer_ReadinessGatesOnWarmup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	release := make(chan struct{})
+	warmer := New()
+	warmer.Register("prime-cache", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	warmer.Register("open-pool", func(ctx context.Context) error { return nil })
+
+	checker := health.NewChecker()
+	checker.AddCheck("warmup", warmer.Check())
+
+	router := gin.New()
+	router.GET("/ready", checker.ReadinessHandler())
+	router.GET("/warmup", warmer.Handler())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/ready"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness before start = %d, want 503", w.Code)
+	}
+
+	warmer.Start(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for warmer.Progress().Completed < 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if w := get("/ready"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("readiness while warming = %d, want 503", w.Code)
+	}
+	w := get("/warmup")
+	var progress Progress
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil {
+		t.Fatalf("failed to decode progress: %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable || progress.Ready || progress.Total != 2 || progress.Completed != 1 {
+		t.Errorf("unexpected progress while warming: %d %+v", w.Code, progress)
+	}
+
+	close(release)
+	select {
+	case <-warmer.Done():
+	case <-time.After(time.Second):
+		t.Fatal("warmup did not finish")
+	}
+
+	if w := get("/ready"); w.Code != http.StatusOK {
+		t.Errorf("readiness after warmup = %d, want 200", w.Code)
+	}
+	if w := get("/warmup"); w.Code != http.StatusOK {
+		t.Errorf("warmup handler after warmup = %d, want 200", w.Code)
+	}
+}
+
+func TestWarmer_RunsConcurrentlyWithTimeout(t *testing.T) {
+	var running, peak int64
+	warmer := New()
+	warmer.SetTimeout(50 * time.Millisecond)
+
+	for _, name := range []string{"a", "b", "c"} {
+		warmer.Register(name, func(ctx context.Context) error {
+			current := atomic.AddInt64(&running, 1)
+			defer atomic.AddInt64(&running, -1)
+			for {
+				old := atomic.LoadInt64(&peak)
+				if current <= old || atomic.CompareAndSwapInt64(&peak, old, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}
+	warmer.Register("hangs", func(ctx context.Context) error {
+		select {} // ignores ctx entirely
+	})
+
+	start := time.Now()
+	err := warmer.Run(context.Background())
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Error("expected an error for the hanging warmup")
+	}
+	if elapsed > time.Second {
+		t.Errorf("Run() took %v, expected the timeout to cut it short", elapsed)
+	}
+	if got := atomic.LoadInt64(&peak); got < 2 {
+		t.Errorf("expected warmups to run concurrently, peak was %d", got)
+	}
+
+	progress := warmer.Progress()
+	if !progress.Ready || progress.Completed != 4 || progress.Failed != 1 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+	for _, task := range progress.Tasks {
+		if task.Name == "hangs" && (task.State != StateFailed || task.Error == "") {
+			t.Errorf("expected hanging task to fail with timeout, got %+v", task)
+		}
+	}
+
+	if result := warmer.Check()(context.Background()); result.Status != health.StatusUp {
+		t.Errorf("failed warmups should not keep the instance unready, got %q", result.Status)
+	}
+}